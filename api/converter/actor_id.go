@@ -0,0 +1,47 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package converter
+
+import (
+	"fmt"
+
+	"github.com/yorkie-team/yorkie/pkg/document/time"
+)
+
+// toActorID returns the string of the given ActorID in Protobuf format.
+func toActorID(id *time.ActorID) string {
+	if id == nil {
+		return ""
+	}
+
+	return id.String()
+}
+
+func fromActorID(str string) (*time.ActorID, error) {
+	// NOTE: Tickets and changes always have actors. Tickets without actors
+	// can't be compared with others.
+	if str == "" {
+		return nil, fmt.Errorf("actor id required: %w", ErrInvalidMessage)
+	}
+
+	id, err := time.ParseActorID(str)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", err.Error(), ErrInvalidMessage)
+	}
+
+	return &id, nil
+}
@@ -0,0 +1,174 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package converter
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	api "github.com/yorkie-team/yorkie/api/yorkie/v1"
+	"github.com/yorkie-team/yorkie/pkg/document/change"
+	"github.com/yorkie-team/yorkie/pkg/document/json"
+	"github.com/yorkie-team/yorkie/pkg/document/operation"
+	"github.com/yorkie-team/yorkie/pkg/document/time"
+)
+
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ToOperationSummaries converts the operations of the given change to a
+// human-readable form for audits. Elements are described by their paths in
+// the given root, e.g. $.todos[2].title, which should contain the change.
+// Elements not found in the root are described by their creation time.
+func ToOperationSummaries(root *json.Object, c *change.Change) []*api.OperationSummary {
+	return newPathMap(root).summarizeChange(c)
+}
+
+// pathMap resolves the paths of the elements of a root by their creation
+// time. Removed elements keep the paths where they were.
+type pathMap struct {
+	paths    map[string]string
+	elements map[string]json.Element
+}
+
+func newPathMap(root *json.Object) *pathMap {
+	m := &pathMap{
+		paths:    make(map[string]string),
+		elements: make(map[string]json.Element),
+	}
+	if root != nil {
+		m.add(root, "$")
+	}
+	return m
+}
+
+func (m *pathMap) add(elem json.Element, path string) {
+	m.paths[elem.CreatedAt().Key()] = path
+	m.elements[elem.CreatedAt().Key()] = elem
+
+	switch elem := elem.(type) {
+	case *json.Object:
+		for _, node := range elem.RHTNodes() {
+			m.add(node.Element(), memberPath(path, node.Key()))
+		}
+	case *json.Array:
+		index := 0
+		for _, node := range elem.RGANodes() {
+			m.add(node.Element(), fmt.Sprintf("%s[%d]", path, index))
+			if node.Element().RemovedAt() == nil {
+				index++
+			}
+		}
+	}
+}
+
+// pathOf returns the path of the element of the given creation time.
+func (m *pathMap) pathOf(createdAt *time.Ticket) string {
+	if path, ok := m.paths[createdAt.Key()]; ok {
+		return path
+	}
+	return "@" + createdAt.Key()
+}
+
+// rangeOf returns the range of the given positions in the text of the given
+// creation time.
+func (m *pathMap) rangeOf(createdAt *time.Ticket, from, to *json.TextNodePos) string {
+	if text, ok := m.elements[createdAt.Key()].(*json.Text); ok {
+		fromIdx, fromOK := text.IndexOf(from)
+		toIdx, toOK := text.IndexOf(to)
+		if fromOK && toOK {
+			return fmt.Sprintf("%d..%d", fromIdx, toIdx)
+		}
+	}
+	return fmt.Sprintf("%s..%s", from.AnnotatedString(), to.AnnotatedString())
+}
+
+func (m *pathMap) summarizeChange(c *change.Change) []*api.OperationSummary {
+	var summaries []*api.OperationSummary
+	for _, op := range c.Operations() {
+		summaries = append(summaries, m.summarize(op))
+	}
+	return summaries
+}
+
+func (m *pathMap) summarize(op operation.Operation) *api.OperationSummary {
+	switch op := op.(type) {
+	case *operation.Set:
+		path := memberPath(m.pathOf(op.ParentCreatedAt()), op.Key())
+		return &api.OperationSummary{
+			Type:        "set",
+			Path:        path,
+			Description: fmt.Sprintf("set %s = %s", path, op.Value().Marshal()),
+		}
+	case *operation.Add:
+		path := m.pathOf(op.ParentCreatedAt())
+		if valuePath, ok := m.paths[op.Value().CreatedAt().Key()]; ok {
+			path = valuePath
+		}
+		return &api.OperationSummary{
+			Type:        "add",
+			Path:        path,
+			Description: fmt.Sprintf("add %s = %s", path, op.Value().Marshal()),
+		}
+	case *operation.Move:
+		path := m.pathOf(op.CreatedAt())
+		return &api.OperationSummary{
+			Type:        "move",
+			Path:        path,
+			Description: fmt.Sprintf("move %s after %s", path, m.pathOf(op.PrevCreatedAt())),
+		}
+	case *operation.Remove:
+		path := m.pathOf(op.CreatedAt())
+		return &api.OperationSummary{
+			Type:        "remove",
+			Path:        path,
+			Description: fmt.Sprintf("remove %s", path),
+		}
+	case *operation.Edit:
+		path := m.pathOf(op.ParentCreatedAt())
+		description := fmt.Sprintf("edit %s %s", path, m.rangeOf(op.ParentCreatedAt(), op.From(), op.To()))
+		if op.Content() != "" {
+			description += " with " + strconv.Quote(op.Content())
+		}
+		return &api.OperationSummary{
+			Type:        "edit",
+			Path:        path,
+			Description: description,
+		}
+	case *operation.Select:
+		path := m.pathOf(op.ParentCreatedAt())
+		return &api.OperationSummary{
+			Type:        "select",
+			Path:        path,
+			Description: fmt.Sprintf("select %s %s", path, m.rangeOf(op.ParentCreatedAt(), op.From(), op.To())),
+		}
+	default:
+		return &api.OperationSummary{
+			Type:        "unknown",
+			Path:        m.pathOf(op.ParentCreatedAt()),
+			Description: fmt.Sprintf("unknown operation %T", op),
+		}
+	}
+}
+
+// memberPath returns the path of the member of the given key.
+func memberPath(parent, key string) string {
+	if identifierPattern.MatchString(key) {
+		return parent + "." + key
+	}
+	return fmt.Sprintf("%s[%s]", parent, strconv.Quote(key))
+}
@@ -0,0 +1,96 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package converter
+
+import "sync"
+
+// maxPooledBufferSize is the maximum capacity of a buffer kept in BufferPool.
+// Larger buffers are left to the garbage collector so that a single huge
+// snapshot doesn't pin its memory.
+const maxPooledBufferSize = 16 << 20
+
+// BufferPool is a pool of byte buffers used to marshal protobuf messages.
+type BufferPool struct {
+	pool sync.Pool
+}
+
+// NewBufferPool creates a new instance of BufferPool.
+func NewBufferPool() *BufferPool {
+	return &BufferPool{}
+}
+
+// Get returns a buffer of the given size from the pool.
+func (p *BufferPool) Get(size int) []byte {
+	if buf, ok := p.pool.Get().(*[]byte); ok && cap(*buf) >= size {
+		return (*buf)[:size]
+	}
+	return make([]byte, size)
+}
+
+// Put returns the given buffer to the pool. The buffer must not be used after
+// it is returned.
+func (p *BufferPool) Put(buf []byte) {
+	if cap(buf) == 0 || cap(buf) > maxPooledBufferSize {
+		return
+	}
+	p.pool.Put(&buf)
+}
+
+// Option configures how the converter encodes messages.
+type Option func(*options)
+
+type options struct {
+	bufferPool *BufferPool
+}
+
+// WithBufferPool makes the converter marshal messages into buffers taken from
+// the given pool. Callers should return the encoded bytes to the pool with
+// BufferPool.Put once they no longer need them.
+func WithBufferPool(pool *BufferPool) Option {
+	return func(o *options) {
+		o.bufferPool = pool
+	}
+}
+
+// sizedMarshaler is implemented by the generated protobuf messages.
+type sizedMarshaler interface {
+	Size() int
+	MarshalToSizedBuffer(dAtA []byte) (int, error)
+}
+
+// marshal encodes the given message with the given options.
+func marshal(msg sizedMarshaler, opts []Option) ([]byte, error) {
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	size := msg.Size()
+	var buf []byte
+	if o.bufferPool != nil {
+		buf = o.bufferPool.Get(size)
+	} else {
+		buf = make([]byte, size)
+	}
+
+	n, err := msg.MarshalToSizedBuffer(buf)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	return buf[size-n:], nil
+}
@@ -17,14 +17,19 @@
 package converter_test
 
 import (
+	"errors"
 	"testing"
-	"time"
+	time2 "time"
 
+	"github.com/gogo/protobuf/proto"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/yorkie-team/yorkie/api/converter"
+	api "github.com/yorkie-team/yorkie/api/yorkie/v1"
 	"github.com/yorkie-team/yorkie/pkg/document"
+	"github.com/yorkie-team/yorkie/pkg/document/change"
 	"github.com/yorkie-team/yorkie/pkg/document/proxy"
+	"github.com/yorkie-team/yorkie/pkg/document/time"
 )
 
 func TestConverter(t *testing.T) {
@@ -65,7 +70,7 @@ func TestConverter(t *testing.T) {
 				SetDouble("1.4", 1.79).
 				SetString("k1.5", "4").
 				SetBytes("k1.6", []byte{65, 66}).
-				SetDate("k1.7", time.Now())
+				SetDate("k1.7", time2.Now())
 
 			// an array
 			root.SetNewArray("k2").
@@ -75,7 +80,7 @@ func TestConverter(t *testing.T) {
 				AddDouble(3.0).
 				AddString("4").
 				AddBytes([]byte{65}).
-				AddDate(time.Now())
+				AddDate(time2.Now())
 
 			// plain text
 			root.SetNewText("k3").
@@ -110,7 +115,7 @@ func TestConverter(t *testing.T) {
 				SetDouble("1.4", 1.79).
 				SetString("k1.5", "4").
 				SetBytes("k1.6", []byte{65, 66}).
-				SetDate("k1.7", time.Now())
+				SetDate("k1.7", time2.Now())
 
 			// an array
 			root.SetNewArray("k2").
@@ -120,7 +125,7 @@ func TestConverter(t *testing.T) {
 				AddDouble(3.0).
 				AddString("4").
 				AddBytes([]byte{65}).
-				AddDate(time.Now())
+				AddDate(time2.Now())
 
 			// plain text
 			root.SetNewText("k3").
@@ -145,4 +150,292 @@ func TestConverter(t *testing.T) {
 
 		assert.Equal(t, d1.Marshal(), d2.Marshal())
 	})
+
+	t.Run("invalid actorID test", func(t *testing.T) {
+		d1 := document.New("c1", "d1")
+		d1.SetActor(time.ActorIDFromHex("0123456789abcdef01234567"))
+		err := d1.Update(func(root *proxy.ObjectProxy) error {
+			root.SetNewText("k1").Edit(0, 0, "A")
+			return nil
+		})
+		assert.NoError(t, err)
+
+		pbPack := converter.ToChangePack(d1.CreateChangePack())
+		assert.Equal(t, "0123456789abcdef01234567", pbPack.Changes[0].Id.ActorId)
+
+		pbPack.Changes[0].Id.ActorId = "123e4567-e89b-12d3-a456-426614174000"
+		_, err = converter.FromChangePack(pbPack)
+		assert.True(t, errors.Is(err, converter.ErrInvalidMessage))
+	})
+
+	t.Run("user test", func(t *testing.T) {
+		d1 := document.New("c1", "d1")
+		err := d1.Update(func(root *proxy.ObjectProxy) error {
+			root.SetString("k1", "v1")
+			return nil
+		})
+		assert.NoError(t, err)
+
+		user := &change.User{ID: "u1", Name: "User 1"}
+		pack := d1.CreateChangePack()
+		pack.User = user
+		pack.Changes[0].SetUser(user)
+
+		pack, err = converter.FromChangePack(converter.ToChangePack(pack))
+		assert.NoError(t, err)
+		assert.Equal(t, user, pack.User)
+		assert.Equal(t, user, pack.Changes[0].User())
+
+		d2 := document.New("c1", "d2")
+		err = d2.Update(func(root *proxy.ObjectProxy) error {
+			root.SetString("k1", "v1")
+			return nil
+		})
+		assert.NoError(t, err)
+
+		pack, err = converter.FromChangePack(converter.ToChangePack(d2.CreateChangePack()))
+		assert.NoError(t, err)
+		assert.Nil(t, pack.User)
+		assert.Nil(t, pack.Changes[0].User())
+	})
+
+	t.Run("message and metadata test", func(t *testing.T) {
+		metadata := map[string]string{"source": "import", "reason": "migration"}
+
+		d1 := document.New("c1", "d1")
+		err := d1.UpdateWithMetadata(func(root *proxy.ObjectProxy) error {
+			root.SetString("k1", "v1")
+			return nil
+		}, metadata, "import %s", "k1")
+		assert.NoError(t, err)
+		err = d1.Update(func(root *proxy.ObjectProxy) error {
+			root.SetString("k2", "v2")
+			return nil
+		})
+		assert.NoError(t, err)
+
+		pack, err := converter.FromChangePack(converter.ToChangePack(d1.CreateChangePack()))
+		assert.NoError(t, err)
+		assert.Equal(t, "import k1", pack.Changes[0].Message())
+		assert.Equal(t, metadata, pack.Changes[0].Metadata())
+		assert.Nil(t, pack.Changes[1].Metadata())
+
+		bytes, err := converter.ChangesToBytes(pack.Changes)
+		assert.NoError(t, err)
+		changes, err := converter.BytesToChanges(bytes)
+		assert.NoError(t, err)
+		assert.Equal(t, metadata, changes[0].Metadata())
+	})
+
+	t.Run("snapshot encoder test", func(t *testing.T) {
+		doc := document.New("c1", "d1")
+		err := doc.Update(func(root *proxy.ObjectProxy) error {
+			root.SetNewObject("k1").SetNewArray("k1.1").AddInteger(1, 2)
+			root.SetNewArray("k2").AddString("a", "b")
+			root.SetNewText("k3").Edit(0, 0, "ABC")
+			return nil
+		})
+		assert.NoError(t, err)
+
+		encoder := converter.NewSnapshotEncoder()
+		bytes, err := encoder.Encode(doc.RootObject(), 1)
+		assert.NoError(t, err)
+		obj, err := converter.BytesToObject(bytes)
+		assert.NoError(t, err)
+		assert.Equal(t, doc.Marshal(), obj.Marshal())
+
+		err = doc.Update(func(root *proxy.ObjectProxy) error {
+			root.GetObject("k1").GetArray("k1.1").Delete(0)
+			root.GetText("k3").Edit(1, 2, "D")
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, `{"k1":{"k1.1":[2]},"k2":["a","b"],"k3":"ADC"}`, doc.Marshal())
+
+		encoder.Touch(doc.CreateChangePack().Changes[1:])
+		bytes, err = encoder.Encode(doc.RootObject(), 2)
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(2), encoder.ServerSeq())
+
+		obj, err = converter.BytesToObject(bytes)
+		assert.NoError(t, err)
+		assert.Equal(t, doc.Marshal(), obj.Marshal())
+
+		expected, err := converter.ObjectToBytes(doc.RootObject())
+		assert.NoError(t, err)
+		assert.Equal(t, len(expected), len(bytes))
+	})
+
+	t.Run("buffer pool test", func(t *testing.T) {
+		doc := document.New("c1", "d1")
+		err := doc.Update(func(root *proxy.ObjectProxy) error {
+			root.SetString("k1", "v1")
+			root.SetNewArray("k2").AddInteger(1, 2, 3)
+			return nil
+		})
+		assert.NoError(t, err)
+
+		expected, err := converter.ObjectToBytes(doc.RootObject())
+		assert.NoError(t, err)
+
+		pool := converter.NewBufferPool()
+		for i := 0; i < 3; i++ {
+			bytes, err := converter.ObjectToBytes(
+				doc.RootObject(),
+				converter.WithBufferPool(pool),
+			)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, bytes)
+
+			obj, err := converter.BytesToObject(bytes)
+			assert.NoError(t, err)
+			assert.Equal(t, doc.Marshal(), obj.Marshal())
+			pool.Put(bytes)
+		}
+	})
+
+	t.Run("operation summary test", func(t *testing.T) {
+		doc := document.New("c1", "d1")
+		err := doc.Update(func(root *proxy.ObjectProxy) error {
+			root.SetString("title", "foo")
+			root.SetNewText("content").Edit(0, 0, "hello world")
+			root.SetNewArray("todo list").AddInteger(1, 2)
+			return nil
+		})
+		assert.NoError(t, err)
+		err = doc.Update(func(root *proxy.ObjectProxy) error {
+			root.GetText("content").Edit(6, 11, "yorkie")
+			root.GetArray("todo list").Delete(0)
+			root.Delete("title")
+			return nil
+		})
+		assert.NoError(t, err)
+
+		var descriptions []string
+		for _, c := range doc.CreateChangePack().Changes {
+			for _, op := range converter.ToOperationSummaries(doc.RootObject(), c) {
+				descriptions = append(descriptions, op.Description)
+			}
+		}
+		assert.Equal(t, []string{
+			`set $.title = "foo"`,
+			`set $.content = ""`,
+			`edit $.content 0..0 with "hello world"`,
+			`set $["todo list"] = []`,
+			`add $["todo list"][0] = 1`,
+			`add $["todo list"][0] = 2`,
+			`edit $.content 6..12 with "yorkie"`,
+			`remove $["todo list"][0]`,
+			`remove $.title`,
+		}, descriptions)
+	})
+
+	t.Run("malformed snapshot test", func(t *testing.T) {
+		ticket := func(lamport uint64) *api.TimeTicket {
+			return &api.TimeTicket{Lamport: lamport, ActorId: time.InitialActorID.String()}
+		}
+		toBytes := func(elem *api.JSONElement) []byte {
+			bytes, err := proto.Marshal(elem)
+			assert.NoError(t, err)
+			return bytes
+		}
+		object := func(createdAt *api.TimeTicket, nodes ...*api.RHTNode) *api.JSONElement {
+			return &api.JSONElement{Body: &api.JSONElement_Object_{Object: &api.JSONElement_Object{
+				Nodes:     nodes,
+				CreatedAt: createdAt,
+			}}}
+		}
+		primitive := func(createdAt *api.TimeTicket, valueType api.ValueType, value []byte) *api.JSONElement {
+			return &api.JSONElement{Body: &api.JSONElement_Primitive_{Primitive: &api.JSONElement_Primitive{
+				Type:      valueType,
+				Value:     value,
+				CreatedAt: createdAt,
+			}}}
+		}
+
+		for name, elem := range map[string]*api.JSONElement{
+			"root of primitive": primitive(ticket(0), api.ValueType_STRING, nil),
+			"missing ticket":    object(nil),
+			"missing element":   object(ticket(0), &api.RHTNode{Key: "k"}),
+			"truncated value": object(ticket(0), &api.RHTNode{
+				Key:     "k",
+				Element: primitive(ticket(1), api.ValueType_LONG, []byte{1, 2}),
+			}),
+			"type confusion": object(ticket(0), &api.RHTNode{
+				Key:     "k",
+				Element: primitive(ticket(1), api.ValueType_JSON_OBJECT, nil),
+			}),
+			"duplicated element": object(ticket(0), &api.RHTNode{
+				Key:     "k",
+				Element: primitive(ticket(0), api.ValueType_STRING, nil),
+			}),
+			"invalid actor": object(&api.TimeTicket{ActorId: "invalid"}),
+		} {
+			_, err := converter.BytesToObject(toBytes(elem))
+			assert.True(t, errors.Is(err, converter.ErrInvalidMessage), name)
+		}
+
+		deep := object(ticket(0))
+		for i := 1; i <= converter.MaxElementDepth+1; i++ {
+			deep = object(ticket(uint64(i)), &api.RHTNode{Key: "k", Element: deep})
+		}
+		_, err := converter.BytesToObject(toBytes(deep))
+		assert.True(t, errors.Is(err, converter.ErrInvalidMessage))
+	})
+
+	t.Run("malformed change pack test", func(t *testing.T) {
+		_, err := converter.FromChangePack(&api.ChangePack{
+			DocumentKey: &api.DocumentKey{Collection: "c1", Document: "d1"},
+			Checkpoint:  &api.Checkpoint{},
+			Changes: []*api.Change{{
+				Id: &api.ChangeID{ActorId: time.InitialActorID.String()},
+				Operations: []*api.Operation{{
+					Body: &api.Operation_Remove_{Remove: &api.Operation_Remove{}},
+				}},
+			}},
+		})
+		assert.True(t, errors.Is(err, converter.ErrInvalidMessage))
+
+		_, err = converter.FromChangePack(&api.ChangePack{
+			Checkpoint: &api.Checkpoint{},
+			Changes:    []*api.Change{{}},
+		})
+		assert.True(t, errors.Is(err, converter.ErrInvalidMessage))
+
+		_, err = converter.FromChangePack(&api.ChangePack{
+			DocumentKey: &api.DocumentKey{Collection: "c1", Document: "d1"},
+			Checkpoint:  &api.Checkpoint{},
+			Changes:     []*api.Change{{}},
+		})
+		assert.True(t, errors.Is(err, converter.ErrInvalidMessage))
+	})
+
+	t.Run("corrupted bytes test", func(t *testing.T) {
+		doc := document.New("c1", "d1")
+		assert.NoError(t, doc.Update(func(root *proxy.ObjectProxy) error {
+			root.SetNewObject("k1").SetLong("k1.1", 1).SetDate("k1.2", time2.Now())
+			root.SetNewArray("k2").AddString("1").AddDouble(2)
+			root.SetNewText("k3").Edit(0, 0, "ABC").Edit(1, 2, "D")
+			return nil
+		}))
+		snapshot, err := converter.ObjectToBytes(doc.RootObject())
+		assert.NoError(t, err)
+		changes, err := converter.ChangesToBytes(doc.CreateChangePack().Changes)
+		assert.NoError(t, err)
+
+		// NOTE: Truncated or flipped bytes should be rejected or decoded
+		// without panics.
+		for _, bytes := range [][]byte{snapshot, changes} {
+			for i := 0; i < len(bytes); i++ {
+				corrupted := append([]byte(nil), bytes...)
+				corrupted[i] ^= 0xff
+				assert.NotPanics(t, func() {
+					_, _ = converter.BytesToObject(bytes[:i])
+					_, _ = converter.BytesToObject(corrupted)
+					_, _ = converter.BytesToChanges(bytes[:i])
+					_, _ = converter.BytesToChanges(corrupted)
+				})
+			}
+		}
+	})
 }
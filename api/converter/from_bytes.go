@@ -17,14 +17,22 @@
 package converter
 
 import (
+	"fmt"
+
 	"github.com/gogo/protobuf/proto"
 
-	"github.com/yorkie-team/yorkie/api"
+	api "github.com/yorkie-team/yorkie/api/yorkie/v1"
+	"github.com/yorkie-team/yorkie/pkg/document/change"
 	"github.com/yorkie-team/yorkie/pkg/document/json"
 	"github.com/yorkie-team/yorkie/pkg/document/time"
-	"github.com/yorkie-team/yorkie/pkg/log"
 )
 
+// MaxElementDepth is the maximum depth of the nested elements of a snapshot.
+// It prevents malicious snapshots from exhausting the stack.
+const MaxElementDepth = 512
+
+// BytesToObject converts the given snapshot to the root object. It returns
+// ErrInvalidMessage if the snapshot is malformed.
 func BytesToObject(snapshot []byte) (*json.Object, error) {
 	if snapshot == nil {
 		return json.NewObject(json.NewRHT(), time.InitialTicket), nil
@@ -35,109 +43,256 @@ func BytesToObject(snapshot []byte) (*json.Object, error) {
 		return nil, err
 	}
 
-	return fromJSONObject(pbElem.GetObject()), nil
+	pbObj := pbElem.GetObject()
+	if pbObj == nil {
+		return nil, fmt.Errorf("root should be an object: %w", ErrInvalidMessage)
+	}
+
+	d := &elementDecoder{seen: make(map[string]bool)}
+	return d.fromJSONObject(pbObj, 0)
+}
+
+// BytesToChanges converts the given byte array to changes.
+func BytesToChanges(bytes []byte) ([]*change.Change, error) {
+	pbPack := &api.ChangePack{}
+	if err := proto.Unmarshal(bytes, pbPack); err != nil {
+		return nil, err
+	}
+
+	return fromChanges(pbPack.Changes)
+}
+
+// elementDecoder decodes the elements of a snapshot. It rejects elements
+// sharing a creation time, which would confuse the lookup of elements by
+// their creation time.
+type elementDecoder struct {
+	seen map[string]bool
 }
 
-func fromJSONElement(pbElem *api.JSONElement) json.Element {
+func (d *elementDecoder) fromJSONElement(pbElem *api.JSONElement, depth int) (json.Element, error) {
+	if pbElem == nil {
+		return nil, fmt.Errorf("element required: %w", ErrInvalidMessage)
+	}
+	if depth > MaxElementDepth {
+		return nil, fmt.Errorf("elements nested too deeply: %w", ErrInvalidMessage)
+	}
+
 	switch decoded := pbElem.Body.(type) {
 	case *api.JSONElement_Object_:
-		return fromJSONObject(decoded.Object)
+		return d.fromJSONObject(decoded.Object, depth)
 	case *api.JSONElement_Array_:
-		return fromJSONArray(decoded.Array)
+		return d.fromJSONArray(decoded.Array, depth)
 	case *api.JSONElement_Primitive_:
-		return fromJSONPrimitive(decoded.Primitive)
+		return d.fromJSONPrimitive(decoded.Primitive)
 	case *api.JSONElement_Text_:
-		return fromJSONText(decoded.Text)
-	default:
-		panic("unsupported element")
+		return d.fromJSONText(decoded.Text)
+	}
+
+	return nil, fmt.Errorf("unsupported element %T: %w", pbElem.Body, ErrInvalidMessage)
+}
+
+// createdAt decodes the creation time of an element, which must be unique.
+func (d *elementDecoder) createdAt(pbTicket *api.TimeTicket) (*time.Ticket, error) {
+	createdAt, err := fromRequiredTimeTicket(pbTicket, "created_at")
+	if err != nil {
+		return nil, err
+	}
+
+	if d.seen[createdAt.Key()] {
+		return nil, fmt.Errorf("duplicated element %s: %w", createdAt.Key(), ErrInvalidMessage)
 	}
+	d.seen[createdAt.Key()] = true
+	return createdAt, nil
 }
 
-func fromJSONObject(pbObj *api.JSONElement_Object) *json.Object {
+func (d *elementDecoder) fromJSONObject(pbObj *api.JSONElement_Object, depth int) (*json.Object, error) {
+	if pbObj == nil {
+		return nil, fmt.Errorf("object required: %w", ErrInvalidMessage)
+	}
+
+	createdAt, err := d.createdAt(pbObj.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	removedAt, err := fromTimeTicket(pbObj.RemovedAt)
+	if err != nil {
+		return nil, err
+	}
+	updatedAt, err := fromTimeTicket(pbObj.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
 	members := json.NewRHT()
 	for _, pbNode := range pbObj.Nodes {
-		members.Set(pbNode.Key, fromJSONElement(pbNode.Element))
+		if pbNode == nil {
+			return nil, fmt.Errorf("node required: %w", ErrInvalidMessage)
+		}
+		elem, err := d.fromJSONElement(pbNode.Element, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		members.Set(pbNode.Key, elem)
 	}
 
-	obj := json.NewObject(
-		members,
-		fromTimeTicket(pbObj.CreatedAt),
-	)
-	obj.SetUpdatedAt(fromTimeTicket(pbObj.UpdatedAt))
-	obj.Remove(fromTimeTicket(pbObj.RemovedAt))
-	return obj
+	obj := json.NewObject(members, createdAt)
+	obj.SetUpdatedAt(updatedAt)
+	obj.Remove(removedAt)
+	return obj, nil
 }
 
-func fromJSONArray(pbArr *api.JSONElement_Array) *json.Array {
+func (d *elementDecoder) fromJSONArray(pbArr *api.JSONElement_Array, depth int) (*json.Array, error) {
+	if pbArr == nil {
+		return nil, fmt.Errorf("array required: %w", ErrInvalidMessage)
+	}
+
+	createdAt, err := d.createdAt(pbArr.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	removedAt, err := fromTimeTicket(pbArr.RemovedAt)
+	if err != nil {
+		return nil, err
+	}
+	updatedAt, err := fromTimeTicket(pbArr.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
 	elements := json.NewRGATreeList()
 	for _, pbNode := range pbArr.Nodes {
-		elements.Add(fromJSONElement(pbNode.Element))
+		if pbNode == nil {
+			return nil, fmt.Errorf("node required: %w", ErrInvalidMessage)
+		}
+		elem, err := d.fromJSONElement(pbNode.Element, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		elements.Add(elem)
 	}
 
-	arr := json.NewArray(
-		elements,
-		fromTimeTicket(pbArr.CreatedAt),
-	)
-	arr.SetUpdatedAt(fromTimeTicket(pbArr.UpdatedAt))
-	arr.Remove(fromTimeTicket(pbArr.RemovedAt))
-	return arr
+	arr := json.NewArray(elements, createdAt)
+	arr.SetUpdatedAt(updatedAt)
+	arr.Remove(removedAt)
+	return arr, nil
 }
 
-func fromJSONPrimitive(pbPrim *api.JSONElement_Primitive) *json.Primitive {
-	primitive := json.NewPrimitive(
-		json.ValueFromBytes(fromValueType(pbPrim.Type), pbPrim.Value),
-		fromTimeTicket(pbPrim.CreatedAt),
-	)
-	primitive.SetUpdatedAt(fromTimeTicket(pbPrim.UpdatedAt))
-	primitive.Remove(fromTimeTicket(pbPrim.RemovedAt))
-	return primitive
+func (d *elementDecoder) fromJSONPrimitive(pbPrim *api.JSONElement_Primitive) (*json.Primitive, error) {
+	if pbPrim == nil {
+		return nil, fmt.Errorf("primitive required: %w", ErrInvalidMessage)
+	}
+
+	createdAt, err := d.createdAt(pbPrim.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	removedAt, err := fromTimeTicket(pbPrim.RemovedAt)
+	if err != nil {
+		return nil, err
+	}
+	updatedAt, err := fromTimeTicket(pbPrim.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	value, err := fromValue(pbPrim.Type, pbPrim.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	primitive := json.NewPrimitive(value, createdAt)
+	primitive.SetUpdatedAt(updatedAt)
+	primitive.Remove(removedAt)
+	return primitive, nil
 }
 
-func fromJSONText(pbText *api.JSONElement_Text) *json.Text {
-	rgaTreeSplit := json.NewRGATreeSplit()
+func (d *elementDecoder) fromJSONText(pbText *api.JSONElement_Text) (*json.Text, error) {
+	if pbText == nil {
+		return nil, fmt.Errorf("text required: %w", ErrInvalidMessage)
+	}
+
+	createdAt, err := d.createdAt(pbText.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	removedAt, err := fromTimeTicket(pbText.RemovedAt)
+	if err != nil {
+		return nil, err
+	}
+	updatedAt, err := fromTimeTicket(pbText.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
 
+	rgaTreeSplit := json.NewRGATreeSplit()
 	current := rgaTreeSplit.InitialHead()
 	for _, pbNode := range pbText.Nodes {
-		textNode := fromTextNode(pbNode)
-		current = rgaTreeSplit.InsertAfter(current, textNode)
-		insPrevID := fromTextNodeID(pbNode.InsPrevId)
-		if insPrevID != nil {
-			insPrevNode := rgaTreeSplit.FindTextNode(insPrevID)
+		textNode, err := fromTextNode(pbNode)
+		if err != nil {
+			return nil, err
+		}
+		if found := rgaTreeSplit.FindTextNode(textNode.ID()); found != nil && found.ID().Equal(textNode.ID()) {
+			return nil, fmt.Errorf("duplicated text node %s: %w", textNode.ID().AnnotatedString(), ErrInvalidMessage)
+		}
+
+		// NOTE: insPrev must be one of the preceding nodes. Otherwise the
+		// nodes could refer to each other.
+		var insPrevNode *json.TextNode
+		if pbNode.InsPrevId != nil {
+			insPrevID, err := fromTextNodeID(pbNode.InsPrevId)
+			if err != nil {
+				return nil, err
+			}
+			insPrevNode = rgaTreeSplit.FindTextNode(insPrevID)
 			if insPrevNode == nil {
-				log.Logger.Warn("insPrevNode should be presence")
+				return nil, fmt.Errorf("insPrev of %s not found: %w", textNode.ID().AnnotatedString(), ErrInvalidMessage)
 			}
+		}
+
+		current = rgaTreeSplit.InsertAfter(current, textNode)
+		if insPrevNode != nil {
 			current.SetInsPrev(insPrevNode)
 		}
 	}
 
-	text := json.NewText(
-		rgaTreeSplit,
-		fromTimeTicket(pbText.CreatedAt),
-	)
-	text.SetUpdatedAt(fromTimeTicket(pbText.UpdatedAt))
-	text.Remove(fromTimeTicket(pbText.RemovedAt))
-
-	return text
+	text := json.NewText(rgaTreeSplit, createdAt)
+	text.SetUpdatedAt(updatedAt)
+	text.Remove(removedAt)
+	return text, nil
 }
 
-func fromTextNode(pbTextNode *api.TextNode) *json.TextNode {
-	textNode := json.NewTextNode(
-		fromTextNodeID(pbTextNode.Id),
-		pbTextNode.Value,
-	)
+func fromTextNode(pbTextNode *api.TextNode) (*json.TextNode, error) {
+	if pbTextNode == nil {
+		return nil, fmt.Errorf("text node required: %w", ErrInvalidMessage)
+	}
+
+	id, err := fromTextNodeID(pbTextNode.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	textNode := json.NewTextNode(id, pbTextNode.Value)
 	if pbTextNode.RemovedAt != nil {
-		textNode.Remove(fromTimeTicket(pbTextNode.RemovedAt), time.MaxTicket)
+		removedAt, err := fromTimeTicket(pbTextNode.RemovedAt)
+		if err != nil {
+			return nil, err
+		}
+		textNode.Remove(removedAt, time.MaxTicket)
 	}
-	return textNode
+	return textNode, nil
 }
 
-func fromTextNodeID(pbTextNodeID *api.TextNodeID) *json.TextNodeID {
+func fromTextNodeID(pbTextNodeID *api.TextNodeID) (*json.TextNodeID, error) {
 	if pbTextNodeID == nil {
-		return nil
+		return nil, fmt.Errorf("text node id required: %w", ErrInvalidMessage)
+	}
+	if pbTextNodeID.Offset < 0 {
+		return nil, fmt.Errorf("negative offset: %w", ErrInvalidMessage)
+	}
+
+	createdAt, err := fromRequiredTimeTicket(pbTextNodeID.CreatedAt, "created_at")
+	if err != nil {
+		return nil, err
 	}
 
-	return json.NewTextNodeID(
-		fromTimeTicket(pbTextNodeID.CreatedAt),
-		int(pbTextNodeID.Offset),
-	)
+	return json.NewTextNodeID(createdAt, int(pbTextNodeID.Offset)), nil
 }
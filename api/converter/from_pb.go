@@ -18,8 +18,9 @@ package converter
 
 import (
 	"errors"
+	"fmt"
 
-	"github.com/yorkie-team/yorkie/api"
+	api "github.com/yorkie-team/yorkie/api/yorkie/v1"
 	"github.com/yorkie-team/yorkie/pkg/document/change"
 	"github.com/yorkie-team/yorkie/pkg/document/checkpoint"
 	"github.com/yorkie-team/yorkie/pkg/document/json"
@@ -29,29 +30,44 @@ import (
 	"github.com/yorkie-team/yorkie/pkg/log"
 )
 
+var logger = log.Named(log.Document)
+
 var (
+	// ErrInvalidMessage is returned when a Protobuf message, e.g. a change
+	// pack or a snapshot, can't be converted to model format.
+	ErrInvalidMessage = errors.New("invalid message")
+
 	errPackRequired       = errors.New("pack required")
 	errCheckpointRequired = errors.New("checkpoint required")
 )
 
-// FromChangePack converts the given Protobuf format to model format.
-// TODO There is no guarantee that the message sent by the client is perfect.
-//      We should check mandatory fields and change the interface with error return.
+// FromChangePack converts the given Protobuf format to model format. It
+// returns ErrInvalidMessage if a mandatory field is missing or malformed.
 func FromChangePack(pbPack *api.ChangePack) (*change.Pack, error) {
 	if pbPack == nil {
-		log.Logger.Error(errPackRequired)
+		logger.Error(errPackRequired)
 		return nil, errPackRequired
 	}
 	if pbPack.Checkpoint == nil {
-		log.Logger.Error(errCheckpointRequired)
+		logger.Error(errCheckpointRequired)
 		return nil, errCheckpointRequired
 	}
+	if pbPack.DocumentKey == nil {
+		return nil, fmt.Errorf("document key required: %w", ErrInvalidMessage)
+	}
+
+	changes, err := fromChanges(pbPack.Changes)
+	if err != nil {
+		return nil, err
+	}
 
 	return &change.Pack{
 		DocumentKey: fromDocumentKey(pbPack.DocumentKey),
 		Checkpoint:  fromCheckpoint(pbPack.Checkpoint),
-		Changes:     fromChanges(pbPack.Changes),
+		Changes:     changes,
 		Snapshot:    pbPack.Snapshot,
+		Encrypted:   pbPack.Encrypted,
+		User:        fromUser(pbPack.User),
 	}, nil
 }
 
@@ -62,32 +78,76 @@ func fromDocumentKey(pbKey *api.DocumentKey) *key.Key {
 	}
 }
 
-func fromCheckpoint(pbCheckpoint *api.Checkpoint) *checkpoint.Checkpoint {
+func fromCheckpoint(pbCheckpoint *api.Checkpoint) checkpoint.Checkpoint {
 	return checkpoint.New(
 		pbCheckpoint.ServerSeq,
 		pbCheckpoint.ClientSeq,
 	)
 }
 
-func fromChanges(pbChanges []*api.Change) []*change.Change {
+func fromChanges(pbChanges []*api.Change) ([]*change.Change, error) {
 	var changes []*change.Change
 	for _, pbChange := range pbChanges {
-		changes = append(changes, change.New(
-			fromChangeID(pbChange.Id),
-			pbChange.Message,
-			FromOperations(pbChange.Operations),
-		))
+		if pbChange == nil {
+			return nil, fmt.Errorf("change required: %w", ErrInvalidMessage)
+		}
+
+		id, err := fromChangeID(pbChange.Id)
+		if err != nil {
+			return nil, err
+		}
+		ops, err := FromOperations(pbChange.Operations)
+		if err != nil {
+			return nil, err
+		}
+
+		c := change.New(id, pbChange.Message, ops)
+		c.SetUser(fromUser(pbChange.User))
+		if len(pbChange.Metadata) > 0 {
+			c.SetMetadata(pbChange.Metadata)
+		}
+		changes = append(changes, c)
 	}
 
-	return changes
+	return changes, nil
 }
 
-func fromChangeID(id *api.ChangeID) *change.ID {
+func fromUser(pbUser *api.User) *change.User {
+	if pbUser == nil {
+		return nil
+	}
+
+	return &change.User{
+		ID:   pbUser.Id,
+		Name: pbUser.Name,
+	}
+}
+
+func fromChangeID(id *api.ChangeID) (change.ID, error) {
+	if id == nil {
+		return change.InitialID, fmt.Errorf("change id required: %w", ErrInvalidMessage)
+	}
+
+	actorID, err := fromActorID(id.ActorId)
+	if err != nil {
+		return change.InitialID, err
+	}
+
 	return change.NewID(
 		id.ClientSeq,
 		id.Lamport,
-		time.ActorIDFromHex(id.ActorId),
-	)
+		actorID,
+	), nil
+}
+
+// FromDocumentKey converts the given Protobuf format to model format.
+func FromDocumentKey(pbKey *api.DocumentKey) *key.Key {
+	return fromDocumentKey(pbKey)
+}
+
+// FromCheckpoint converts the given Protobuf format to model format.
+func FromCheckpoint(pbCheckpoint *api.Checkpoint) checkpoint.Checkpoint {
+	return fromCheckpoint(pbCheckpoint)
 }
 
 // FromDocumentKeys converts the given Protobuf format to model format.
@@ -99,150 +159,277 @@ func FromDocumentKeys(pbKeys []*api.DocumentKey) []*key.Key {
 	return keys
 }
 
-// FromOperations converts the given Protobuf format to model format.
-func FromOperations(pbOps []*api.Operation) []operation.Operation {
+// FromDocumentKeyPrefixes converts the given Protobuf format to model format.
+// The document of a key is the path of a prefix.
+func FromDocumentKeyPrefixes(pbKeys []*api.DocumentKey) ([]*key.Prefix, error) {
+	var prefixes []*key.Prefix
+	for _, pbKey := range pbKeys {
+		prefix, err := key.NewPrefix(pbKey.Collection, pbKey.Document)
+		if err != nil {
+			return nil, err
+		}
+		prefixes = append(prefixes, prefix)
+	}
+	return prefixes, nil
+}
+
+// FromOperations converts the given Protobuf format to model format. It
+// returns ErrInvalidMessage if an operation is missing or malformed.
+func FromOperations(pbOps []*api.Operation) ([]operation.Operation, error) {
 	var ops []operation.Operation
 
 	for _, pbOp := range pbOps {
-		var op operation.Operation
-		switch decoded := pbOp.Body.(type) {
-		case *api.Operation_Set_:
-			op = operation.NewSet(
-				fromTimeTicket(decoded.Set.ParentCreatedAt),
-				decoded.Set.Key,
-				fromElement(decoded.Set.Value),
-				fromTimeTicket(decoded.Set.ExecutedAt),
-			)
-		case *api.Operation_Add_:
-			op = operation.NewAdd(
-				fromTimeTicket(decoded.Add.ParentCreatedAt),
-				fromTimeTicket(decoded.Add.PrevCreatedAt),
-				fromElement(decoded.Add.Value),
-				fromTimeTicket(decoded.Add.ExecutedAt),
-			)
-		case *api.Operation_Remove_:
-			op = operation.NewRemove(
-				fromTimeTicket(decoded.Remove.ParentCreatedAt),
-				fromTimeTicket(decoded.Remove.CreatedAt),
-				fromTimeTicket(decoded.Remove.ExecutedAt),
-			)
-		case *api.Operation_Edit_:
-			op = operation.NewEdit(
-				fromTimeTicket(decoded.Edit.ParentCreatedAt),
-				fromTextNodePos(decoded.Edit.From),
-				fromTextNodePos(decoded.Edit.To),
-				fromCreatedAtMapByActor(decoded.Edit.CreatedAtMapByActor),
-				decoded.Edit.Content,
-				fromTimeTicket(decoded.Edit.ExecutedAt),
-			)
-		case *api.Operation_Select_:
-			op = operation.NewSelect(
-				fromTimeTicket(decoded.Select.ParentCreatedAt),
-				fromTextNodePos(decoded.Select.From),
-				fromTextNodePos(decoded.Select.To),
-				fromTimeTicket(decoded.Select.ExecutedAt),
-			)
-		case *api.Operation_Move_:
-			op = operation.NewMove(
-				fromTimeTicket(decoded.Move.ParentCreatedAt),
-				fromTimeTicket(decoded.Move.PrevCreatedAt),
-				fromTimeTicket(decoded.Move.CreatedAt),
-				fromTimeTicket(decoded.Move.ExecutedAt),
-			)
-		default:
-			panic("unsupported operation")
+		if pbOp == nil {
+			return nil, fmt.Errorf("operation required: %w", ErrInvalidMessage)
+		}
+
+		op, err := fromOperation(pbOp)
+		if err != nil {
+			return nil, err
 		}
 		ops = append(ops, op)
 	}
 
-	return ops
+	return ops, nil
+}
+
+func fromOperation(pbOp *api.Operation) (operation.Operation, error) {
+	// NOTE: The tickets of operations are decoded in a batch. The first
+	// error of them is returned.
+	d := &ticketDecoder{}
+
+	switch decoded := pbOp.Body.(type) {
+	case *api.Operation_Set_:
+		if decoded.Set == nil {
+			break
+		}
+		value, err := fromElement(decoded.Set.Value)
+		if err != nil {
+			return nil, err
+		}
+		op := operation.NewSet(
+			d.ticket(decoded.Set.ParentCreatedAt, "parent_created_at"),
+			decoded.Set.Key,
+			value,
+			d.ticket(decoded.Set.ExecutedAt, "executed_at"),
+		)
+		return op, d.err
+	case *api.Operation_Add_:
+		if decoded.Add == nil {
+			break
+		}
+		value, err := fromElement(decoded.Add.Value)
+		if err != nil {
+			return nil, err
+		}
+		op := operation.NewAdd(
+			d.ticket(decoded.Add.ParentCreatedAt, "parent_created_at"),
+			d.ticket(decoded.Add.PrevCreatedAt, "prev_created_at"),
+			value,
+			d.ticket(decoded.Add.ExecutedAt, "executed_at"),
+		)
+		return op, d.err
+	case *api.Operation_Remove_:
+		if decoded.Remove == nil {
+			break
+		}
+		op := operation.NewRemove(
+			d.ticket(decoded.Remove.ParentCreatedAt, "parent_created_at"),
+			d.ticket(decoded.Remove.CreatedAt, "created_at"),
+			d.ticket(decoded.Remove.ExecutedAt, "executed_at"),
+		)
+		return op, d.err
+	case *api.Operation_Edit_:
+		if decoded.Edit == nil {
+			break
+		}
+		createdAtMapByActor, err := fromCreatedAtMapByActor(decoded.Edit.CreatedAtMapByActor)
+		if err != nil {
+			return nil, err
+		}
+		op := operation.NewEdit(
+			d.ticket(decoded.Edit.ParentCreatedAt, "parent_created_at"),
+			d.textNodePos(decoded.Edit.From, "from"),
+			d.textNodePos(decoded.Edit.To, "to"),
+			createdAtMapByActor,
+			decoded.Edit.Content,
+			d.ticket(decoded.Edit.ExecutedAt, "executed_at"),
+		)
+		return op, d.err
+	case *api.Operation_Select_:
+		if decoded.Select == nil {
+			break
+		}
+		op := operation.NewSelect(
+			d.ticket(decoded.Select.ParentCreatedAt, "parent_created_at"),
+			d.textNodePos(decoded.Select.From, "from"),
+			d.textNodePos(decoded.Select.To, "to"),
+			d.ticket(decoded.Select.ExecutedAt, "executed_at"),
+		)
+		return op, d.err
+	case *api.Operation_Move_:
+		if decoded.Move == nil {
+			break
+		}
+		op := operation.NewMove(
+			d.ticket(decoded.Move.ParentCreatedAt, "parent_created_at"),
+			d.ticket(decoded.Move.PrevCreatedAt, "prev_created_at"),
+			d.ticket(decoded.Move.CreatedAt, "created_at"),
+			d.ticket(decoded.Move.ExecutedAt, "executed_at"),
+		)
+		return op, d.err
+	}
+
+	return nil, fmt.Errorf("unsupported operation %T: %w", pbOp.Body, ErrInvalidMessage)
+}
+
+// ticketDecoder decodes the mandatory tickets of an operation, keeping the
+// first error.
+type ticketDecoder struct {
+	err error
+}
+
+func (d *ticketDecoder) ticket(pbTicket *api.TimeTicket, field string) *time.Ticket {
+	ticket, err := fromRequiredTimeTicket(pbTicket, field)
+	if err != nil && d.err == nil {
+		d.err = err
+	}
+	return ticket
+}
+
+func (d *ticketDecoder) textNodePos(pbPos *api.TextNodePos, field string) *json.TextNodePos {
+	pos, err := fromTextNodePos(pbPos, field)
+	if err != nil && d.err == nil {
+		d.err = err
+	}
+	return pos
 }
 
 func fromCreatedAtMapByActor(
 	pbCreatedAtMapByActor map[string]*api.TimeTicket,
-) map[string]*time.Ticket {
+) (map[string]*time.Ticket, error) {
 	createdAtMapByActor := make(map[string]*time.Ticket)
 	for actor, pbTicket := range pbCreatedAtMapByActor {
-		createdAtMapByActor[actor] = fromTimeTicket(pbTicket)
+		ticket, err := fromRequiredTimeTicket(pbTicket, "created_at_map_by_actor")
+		if err != nil {
+			return nil, err
+		}
+		createdAtMapByActor[actor] = ticket
 	}
-	return createdAtMapByActor
+	return createdAtMapByActor, nil
 }
 
-func fromTextNodePos(pbPos *api.TextNodePos) *json.TextNodePos {
+func fromTextNodePos(pbPos *api.TextNodePos, field string) (*json.TextNodePos, error) {
+	if pbPos == nil {
+		return nil, fmt.Errorf("%s required: %w", field, ErrInvalidMessage)
+	}
+	if pbPos.Offset < 0 || pbPos.RelativeOffset < 0 {
+		return nil, fmt.Errorf("%s has negative offset: %w", field, ErrInvalidMessage)
+	}
+
+	createdAt, err := fromRequiredTimeTicket(pbPos.CreatedAt, field)
+	if err != nil {
+		return nil, err
+	}
+
 	return json.NewTextNodePos(
-		json.NewTextNodeID(fromTimeTicket(pbPos.CreatedAt), int(pbPos.Offset)),
+		json.NewTextNodeID(createdAt, int(pbPos.Offset)),
 		int(pbPos.RelativeOffset),
-	)
+	), nil
 }
 
-func fromTimeTicket(pbTicket *api.TimeTicket) *time.Ticket {
+// fromRequiredTimeTicket converts the given ticket of a mandatory field.
+func fromRequiredTimeTicket(pbTicket *api.TimeTicket, field string) (*time.Ticket, error) {
 	if pbTicket == nil {
-		return nil
+		return nil, fmt.Errorf("%s required: %w", field, ErrInvalidMessage)
+	}
+
+	return fromTimeTicket(pbTicket)
+}
+
+func fromTimeTicket(pbTicket *api.TimeTicket) (*time.Ticket, error) {
+	if pbTicket == nil {
+		return nil, nil
+	}
+
+	actorID, err := fromActorID(pbTicket.ActorId)
+	if err != nil {
+		return nil, err
 	}
 
 	return time.NewTicket(
 		pbTicket.Lamport,
 		pbTicket.Delimiter,
-		time.ActorIDFromHex(pbTicket.ActorId),
-	)
+		actorID,
+	), nil
 }
 
-func fromElement(pbElement *api.JSONElementSimple) json.Element {
+func fromElement(pbElement *api.JSONElementSimple) (json.Element, error) {
+	if pbElement == nil {
+		return nil, fmt.Errorf("value required: %w", ErrInvalidMessage)
+	}
+
+	createdAt, err := fromRequiredTimeTicket(pbElement.CreatedAt, "created_at")
+	if err != nil {
+		return nil, err
+	}
+
 	switch pbType := pbElement.Type; pbType {
 	case api.ValueType_JSON_OBJECT:
-		return json.NewObject(
-			json.NewRHT(),
-			fromTimeTicket(pbElement.CreatedAt),
-		)
+		return json.NewObject(json.NewRHT(), createdAt), nil
 	case api.ValueType_JSON_ARRAY:
-		return json.NewArray(
-			json.NewRGATreeList(),
-			fromTimeTicket(pbElement.CreatedAt),
-		)
-	case api.ValueType_BOOLEAN:
-		fallthrough
-	case api.ValueType_INTEGER:
-		fallthrough
-	case api.ValueType_LONG:
-		fallthrough
-	case api.ValueType_DOUBLE:
-		fallthrough
-	case api.ValueType_STRING:
-		fallthrough
-	case api.ValueType_BYTES:
-		fallthrough
-	case api.ValueType_DATE:
-		return json.NewPrimitive(
-			json.ValueFromBytes(fromValueType(pbType), pbElement.Value),
-			fromTimeTicket(pbElement.CreatedAt),
-		)
+		return json.NewArray(json.NewRGATreeList(), createdAt), nil
 	case api.ValueType_TEXT:
-		return json.NewText(
-			json.NewRGATreeSplit(),
-			fromTimeTicket(pbElement.CreatedAt),
-		)
+		return json.NewText(json.NewRGATreeSplit(), createdAt), nil
+	default:
+		value, err := fromValue(pbType, pbElement.Value)
+		if err != nil {
+			return nil, err
+		}
+		return json.NewPrimitive(value, createdAt), nil
 	}
+}
+
+// valueSizes is the sizes of the encoded values of fixed-size types.
+var valueSizes = map[json.ValueType]int{
+	json.Boolean: 1,
+	json.Integer: 4,
+	json.Long:    8,
+	json.Double:  8,
+	json.Date:    8,
+}
 
-	panic("fail to decode element")
+// fromValue converts the given encoded value of a primitive. Unlike
+// json.ValueFromBytes, it returns an error if the value is truncated.
+func fromValue(pbType api.ValueType, value []byte) (interface{}, error) {
+	valueType, err := fromValueType(pbType)
+	if err != nil {
+		return nil, err
+	}
+
+	if size, ok := valueSizes[valueType]; ok && len(value) < size {
+		return nil, fmt.Errorf("truncated value of %s: %w", pbType, ErrInvalidMessage)
+	}
+	return json.ValueFromBytes(valueType, value), nil
 }
 
-func fromValueType(valueType api.ValueType) json.ValueType {
+func fromValueType(valueType api.ValueType) (json.ValueType, error) {
 	switch valueType {
 	case api.ValueType_BOOLEAN:
-		return json.Boolean
+		return json.Boolean, nil
 	case api.ValueType_INTEGER:
-		return json.Integer
+		return json.Integer, nil
 	case api.ValueType_LONG:
-		return json.Long
+		return json.Long, nil
 	case api.ValueType_DOUBLE:
-		return json.Double
+		return json.Double, nil
 	case api.ValueType_STRING:
-		return json.String
+		return json.String, nil
 	case api.ValueType_BYTES:
-		return json.Bytes
+		return json.Bytes, nil
 	case api.ValueType_DATE:
-		return json.Date
+		return json.Date, nil
 	}
 
-	panic("fail to decode value type")
+	return json.Null, fmt.Errorf("unsupported value type %s: %w", valueType, ErrInvalidMessage)
 }
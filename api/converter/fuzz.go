@@ -0,0 +1,75 @@
+//go:build gofuzz
+// +build gofuzz
+
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package converter
+
+import (
+	"github.com/gogo/protobuf/proto"
+
+	api "github.com/yorkie-team/yorkie/api/yorkie/v1"
+)
+
+// The entry points of go-fuzz. The agent decodes snapshots and change packs
+// sent by any client, so the decoders must return errors instead of panicking
+// on malformed inputs. To run one of them:
+//
+//	go-fuzz-build -func FuzzBytesToObject ./api/converter
+//	go-fuzz -bin converter-fuzz.zip -workdir fuzz/snapshot
+
+// FuzzBytesToObject fuzzes the decoding of snapshots.
+func FuzzBytesToObject(data []byte) int {
+	obj, err := BytesToObject(data)
+	if err != nil {
+		return 0
+	}
+
+	// NOTE: Decoded objects should be encoded and marshaled without panics.
+	if _, err := ObjectToBytes(obj); err != nil {
+		panic(err)
+	}
+	_ = obj.Marshal()
+	return 1
+}
+
+// FuzzChangePack fuzzes the decoding of change packs.
+func FuzzChangePack(data []byte) int {
+	pbPack := &api.ChangePack{}
+	if err := proto.Unmarshal(data, pbPack); err != nil {
+		return 0
+	}
+
+	pack, err := FromChangePack(pbPack)
+	if err != nil {
+		return 0
+	}
+
+	if _, err := ChangesToBytes(pack.Changes); err != nil {
+		panic(err)
+	}
+	_ = ToChangePack(pack)
+	return 1
+}
+
+// FuzzBytesToChanges fuzzes the decoding of the changes spilled to disk.
+func FuzzBytesToChanges(data []byte) int {
+	if _, err := BytesToChanges(data); err != nil {
+		return 0
+	}
+	return 1
+}
@@ -0,0 +1,250 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package converter
+
+import (
+	"github.com/gogo/protobuf/proto"
+
+	api "github.com/yorkie-team/yorkie/api/yorkie/v1"
+	"github.com/yorkie-team/yorkie/pkg/document/change"
+	"github.com/yorkie-team/yorkie/pkg/document/json"
+	"github.com/yorkie-team/yorkie/pkg/document/operation"
+)
+
+// elementFieldNumber is the field number of the element in RHTNode and
+// RGANode messages.
+const elementFieldNumber = 2
+
+// SnapshotEncoder encodes snapshots of a document. It keeps the encoded fields
+// of the subtrees of the last snapshot, so the subtrees untouched since then
+// are reused instead of being encoded again.
+//
+// SnapshotEncoder is not safe for concurrent use.
+type SnapshotEncoder struct {
+	serverSeq uint64
+	touched   map[string]bool
+	encoded   map[string][]byte
+}
+
+// NewSnapshotEncoder creates a new instance of SnapshotEncoder.
+func NewSnapshotEncoder() *SnapshotEncoder {
+	return &SnapshotEncoder{
+		touched: make(map[string]bool),
+		encoded: make(map[string][]byte),
+	}
+}
+
+// ServerSeq returns the server sequence of the last encoded snapshot.
+func (e *SnapshotEncoder) ServerSeq() uint64 {
+	return e.serverSeq
+}
+
+// Touch marks the elements modified by the given changes, so they and their
+// ancestors are encoded again in the next snapshot.
+func (e *SnapshotEncoder) Touch(changes []*change.Change) {
+	for _, c := range changes {
+		for _, op := range c.Operations() {
+			e.touched[op.ParentCreatedAt().Key()] = true
+
+			switch op := op.(type) {
+			case *operation.Remove:
+				e.touched[op.CreatedAt().Key()] = true
+			case *operation.Move:
+				e.touched[op.CreatedAt().Key()] = true
+			}
+		}
+	}
+}
+
+// Encode encodes the given root object of the snapshot of the given server
+// sequence.
+func (e *SnapshotEncoder) Encode(
+	obj *json.Object,
+	serverSeq uint64,
+	opts ...Option,
+) ([]byte, error) {
+	dirty := make(map[string]bool)
+	e.markDirty(obj, dirty)
+
+	encoded := make(map[string][]byte)
+	pbElem, err := e.toJSONObject(obj, dirty, encoded)
+	if err != nil {
+		return nil, err
+	}
+
+	bytes, err := marshal(pbElem, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	e.serverSeq = serverSeq
+	e.touched = make(map[string]bool)
+	e.encoded = encoded
+
+	return bytes, nil
+}
+
+// markDirty marks the given element as dirty if it or one of its descendants
+// has been touched, and returns whether it is dirty.
+func (e *SnapshotEncoder) markDirty(elem json.Element, dirty map[string]bool) bool {
+	isDirty := e.touched[elem.CreatedAt().Key()]
+
+	switch elem := elem.(type) {
+	case *json.Object:
+		for _, node := range elem.RHTNodes() {
+			if e.markDirty(node.Element(), dirty) {
+				isDirty = true
+			}
+		}
+	case *json.Array:
+		for _, node := range elem.RGANodes() {
+			if e.markDirty(node.Element(), dirty) {
+				isDirty = true
+			}
+		}
+	}
+
+	if isDirty {
+		dirty[elem.CreatedAt().Key()] = true
+	}
+	return isDirty
+}
+
+func (e *SnapshotEncoder) toJSONObject(
+	obj *json.Object,
+	dirty map[string]bool,
+	encoded map[string][]byte,
+) (*api.JSONElement, error) {
+	var pbNodes []*api.RHTNode
+	for _, node := range obj.RHTNodes() {
+		pbNode := &api.RHTNode{Key: node.Key()}
+		if err := e.encodeElement(
+			node.Element(),
+			dirty,
+			encoded,
+			&pbNode.Element,
+			&pbNode.XXX_unrecognized,
+		); err != nil {
+			return nil, err
+		}
+		pbNodes = append(pbNodes, pbNode)
+	}
+
+	return &api.JSONElement{
+		Body: &api.JSONElement_Object_{Object: &api.JSONElement_Object{
+			Nodes:     pbNodes,
+			CreatedAt: toTimeTicket(obj.CreatedAt()),
+			UpdatedAt: toTimeTicket(obj.UpdatedAt()),
+			RemovedAt: toTimeTicket(obj.RemovedAt()),
+		}},
+	}, nil
+}
+
+func (e *SnapshotEncoder) toJSONArray(
+	arr *json.Array,
+	dirty map[string]bool,
+	encoded map[string][]byte,
+) (*api.JSONElement, error) {
+	var pbNodes []*api.RGANode
+	for _, node := range arr.RGANodes() {
+		pbNode := &api.RGANode{}
+		if err := e.encodeElement(
+			node.Element(),
+			dirty,
+			encoded,
+			&pbNode.Element,
+			&pbNode.XXX_unrecognized,
+		); err != nil {
+			return nil, err
+		}
+		pbNodes = append(pbNodes, pbNode)
+	}
+
+	return &api.JSONElement{
+		Body: &api.JSONElement_Array_{Array: &api.JSONElement_Array{
+			Nodes:     pbNodes,
+			CreatedAt: toTimeTicket(arr.CreatedAt()),
+			UpdatedAt: toTimeTicket(arr.UpdatedAt()),
+			RemovedAt: toTimeTicket(arr.RemovedAt()),
+		}},
+	}, nil
+}
+
+// encodeElement encodes the given element into a node of its parent. Dirty
+// containers are converted to protobuf messages, and the other subtrees are
+// written as the already encoded element field of the node.
+func (e *SnapshotEncoder) encodeElement(
+	elem json.Element,
+	dirty map[string]bool,
+	encoded map[string][]byte,
+	pbElem **api.JSONElement,
+	raw *[]byte,
+) error {
+	key := elem.CreatedAt().Key()
+
+	var err error
+	switch elem := elem.(type) {
+	case *json.Primitive:
+		*pbElem = toPrimitive(elem)
+		return nil
+	case *json.Object:
+		if dirty[key] {
+			*pbElem, err = e.toJSONObject(elem, dirty, encoded)
+			return err
+		}
+	case *json.Array:
+		if dirty[key] {
+			*pbElem, err = e.toJSONArray(elem, dirty, encoded)
+			return err
+		}
+	case *json.Text:
+		if dirty[key] {
+			*pbElem = toText(elem)
+			return nil
+		}
+	}
+
+	field, ok := e.encoded[key]
+	if !ok {
+		if field, err = encodeElementField(toJSONElement(elem)); err != nil {
+			return err
+		}
+	}
+	encoded[key] = field
+	*raw = field
+
+	return nil
+}
+
+// encodeElementField encodes the given element as the element field of
+// RHTNode and RGANode messages, so that the encoded bytes can be written into
+// the nodes as they are without copying.
+func encodeElementField(pbElem *api.JSONElement) ([]byte, error) {
+	size := pbElem.Size()
+	tag := uint64(elementFieldNumber<<3 | proto.WireBytes)
+
+	buf := make([]byte, 0, proto.SizeVarint(tag)+proto.SizeVarint(uint64(size))+size)
+	buf = append(buf, proto.EncodeVarint(tag)...)
+	buf = append(buf, proto.EncodeVarint(uint64(size))...)
+
+	n, err := pbElem.MarshalToSizedBuffer(buf[len(buf) : len(buf)+size])
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	return buf[:len(buf)+n], nil
+}
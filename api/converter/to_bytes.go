@@ -17,21 +17,24 @@
 package converter
 
 import (
-	"github.com/gogo/protobuf/proto"
-
-	"github.com/yorkie-team/yorkie/api"
+	api "github.com/yorkie-team/yorkie/api/yorkie/v1"
+	"github.com/yorkie-team/yorkie/pkg/document/change"
 	"github.com/yorkie-team/yorkie/pkg/document/json"
-	"github.com/yorkie-team/yorkie/pkg/log"
 )
 
 // ObjectToBytes converts the given object to byte array.
-func ObjectToBytes(obj *json.Object) ([]byte, error) {
-	bytes, err := proto.Marshal(toJSONElement(obj))
-	if err != nil {
-		log.Logger.Error(err)
-		return nil, err
-	}
-	return bytes, nil
+func ObjectToBytes(obj *json.Object, opts ...Option) ([]byte, error) {
+	return marshal(toJSONElement(obj), opts)
+}
+
+// ChangesToBytes converts the given changes to byte array.
+func ChangesToBytes(changes []*change.Change, opts ...Option) ([]byte, error) {
+	return marshal(&api.ChangePack{Changes: toChanges(changes)}, opts)
+}
+
+// ChangeSize returns the size of the given change in Protobuf format.
+func ChangeSize(c *change.Change) int {
+	return toChanges([]*change.Change{c})[0].Size()
 }
 
 func toJSONElement(elem json.Element) *api.JSONElement {
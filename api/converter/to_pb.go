@@ -17,7 +17,7 @@
 package converter
 
 import (
-	"github.com/yorkie-team/yorkie/api"
+	api "github.com/yorkie-team/yorkie/api/yorkie/v1"
 	"github.com/yorkie-team/yorkie/pkg/document/change"
 	"github.com/yorkie-team/yorkie/pkg/document/checkpoint"
 	"github.com/yorkie-team/yorkie/pkg/document/json"
@@ -33,6 +33,8 @@ func ToChangePack(pack *change.Pack) *api.ChangePack {
 		Checkpoint:  toCheckpoint(pack.Checkpoint),
 		Changes:     toChanges(pack.Changes),
 		Snapshot:    pack.Snapshot,
+		Encrypted:   pack.Encrypted,
+		User:        toUser(pack.User),
 	}
 }
 
@@ -43,7 +45,7 @@ func toDocumentKey(key *key.Key) *api.DocumentKey {
 	}
 }
 
-func toCheckpoint(cp *checkpoint.Checkpoint) *api.Checkpoint {
+func toCheckpoint(cp checkpoint.Checkpoint) *api.Checkpoint {
 	return &api.Checkpoint{
 		ServerSeq: cp.ServerSeq,
 		ClientSeq: cp.ClientSeq,
@@ -57,20 +59,70 @@ func toChanges(changes []*change.Change) []*api.Change {
 			Id:         toChangeID(c.ID()),
 			Message:    c.Message(),
 			Operations: ToOperations(c.Operations()),
+			User:       toUser(c.User()),
+			Metadata:   c.Metadata(),
 		})
 	}
 
 	return pbChanges
 }
 
-func toChangeID(id *change.ID) *api.ChangeID {
+func toUser(user *change.User) *api.User {
+	if user == nil {
+		return nil
+	}
+
+	return &api.User{
+		Id:   user.ID,
+		Name: user.Name,
+	}
+}
+
+// ToChangeSummaries converts the given changes stored on the server to
+// Protobuf format. The operations are described with the paths in the given
+// root, and are omitted if the root is nil.
+func ToChangeSummaries(root *json.Object, changes []*change.Change) []*api.ChangeSummary {
+	var paths *pathMap
+	if root != nil {
+		paths = newPathMap(root)
+	}
+
+	var summaries []*api.ChangeSummary
+	for _, c := range changes {
+		summary := &api.ChangeSummary{
+			ServerSeq: c.ServerSeq(),
+			Id:        toChangeID(c.ID()),
+			Message:   c.Message(),
+			User:      toUser(c.User()),
+			Metadata:  c.Metadata(),
+		}
+		if paths != nil {
+			summary.Operations = paths.summarizeChange(c)
+		}
+		summaries = append(summaries, summary)
+	}
+
+	return summaries
+}
+
+func toChangeID(id change.ID) *api.ChangeID {
 	return &api.ChangeID{
 		ClientSeq: id.ClientSeq(),
 		Lamport:   id.Lamport(),
-		ActorId:   id.Actor().String(),
+		ActorId:   toActorID(id.Actor()),
 	}
 }
 
+// ToDocumentKey converts the given model format to Protobuf format.
+func ToDocumentKey(key *key.Key) *api.DocumentKey {
+	return toDocumentKey(key)
+}
+
+// ToCheckpoint converts the given model format to Protobuf format.
+func ToCheckpoint(cp checkpoint.Checkpoint) *api.Checkpoint {
+	return toCheckpoint(cp)
+}
+
 // ToDocumentKeys converts the given model format to Protobuf format.
 func ToDocumentKeys(keys ...*key.Key) []*api.DocumentKey {
 	var pbKeys []*api.DocumentKey
@@ -80,6 +132,18 @@ func ToDocumentKeys(keys ...*key.Key) []*api.DocumentKey {
 	return pbKeys
 }
 
+// ToDocumentKeyPrefixes converts the given model format to Protobuf format.
+func ToDocumentKeyPrefixes(prefixes ...*key.Prefix) []*api.DocumentKey {
+	var pbKeys []*api.DocumentKey
+	for _, prefix := range prefixes {
+		pbKeys = append(pbKeys, &api.DocumentKey{
+			Collection: prefix.Collection,
+			Document:   prefix.Path,
+		})
+	}
+	return pbKeys
+}
+
 // ToOperations converts the given model format to Protobuf format.
 func ToOperations(operations []operation.Operation) []*api.Operation {
 	var pbOperations []*api.Operation
@@ -204,7 +268,7 @@ func toTimeTicket(ticket *time.Ticket) *api.TimeTicket {
 	return &api.TimeTicket{
 		Lamport:   ticket.Lamport(),
 		Delimiter: ticket.Delimiter(),
-		ActorId:   ticket.ActorIDHex(),
+		ActorId:   toActorID(ticket.ActorID()),
 	}
 }
 
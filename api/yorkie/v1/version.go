@@ -0,0 +1,61 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package v1
+
+import (
+	"google.golang.org/grpc"
+)
+
+const (
+	// Revision is the latest revision of the v1 API. It is increased when
+	// backward compatible changes are added, so that the SDKs and the agent
+	// can negotiate the features both of them support.
+	Revision uint32 = 2
+
+	// LegacyRevision is the revision of the SDKs released before the API was
+	// versioned. They don't send the revision they support.
+	LegacyRevision uint32 = 0
+
+	// SnapshotChunkRevision is the first revision that supports snapshots
+	// omitted from change packs and fetched in chunks with FetchSnapshot.
+	SnapshotChunkRevision uint32 = 2
+)
+
+// NegotiateRevision returns the revision of the API used with a SDK supporting
+// up to the given revision.
+func NegotiateRevision(header *RequestHeader) uint32 {
+	if header == nil || header.Version == LegacyRevision {
+		return LegacyRevision
+	}
+	if header.Version > Revision {
+		return Revision
+	}
+	return header.Version
+}
+
+// RegisterLegacyServers registers the given servers under the names of the
+// services before the API was versioned, so that the SDKs released before can
+// still call them. The messages of the v1 API are compatible with them.
+func RegisterLegacyServers(s *grpc.Server, yorkieServer YorkieServer, adminServer AdminServer) {
+	yorkieDesc := _Yorkie_serviceDesc
+	yorkieDesc.ServiceName = "api.Yorkie"
+	s.RegisterService(&yorkieDesc, yorkieServer)
+
+	adminDesc := _Admin_serviceDesc
+	adminDesc.ServiceName = "api.Admin"
+	s.RegisterService(&adminDesc, adminServer)
+}
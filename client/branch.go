@@ -0,0 +1,71 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+
+	"github.com/yorkie-team/yorkie/api/converter"
+	api "github.com/yorkie-team/yorkie/api/yorkie/v1"
+	"github.com/yorkie-team/yorkie/pkg/document/key"
+)
+
+// Fork forks the document of the given key into a new document of the given
+// branch key, e.g. to make a draft for review. The branch shares the history
+// of the document up to the current server sequence, and can be attached and
+// edited like other documents. The changes of this client not pushed yet are
+// not included in the branch.
+func (c *Client) Fork(ctx context.Context, docKey *key.Key, branchKey *key.Key, opts ...AttachOption) error {
+	if c.status != activated {
+		return ErrClientNotActivated
+	}
+
+	if _, err := c.client.ForkDocument(ctx, &api.ForkDocumentRequest{
+		ClientId:    c.id.String(),
+		DocumentKey: converter.ToDocumentKeys(docKey)[0],
+		AccessToken: accessTokenOf(opts),
+		BranchKey:   converter.ToDocumentKeys(branchKey)[0],
+	}); err != nil {
+		c.logger.Error("fail to fork document", Field{"document", docKey.BSONKey()}, Field{"error", err})
+		return err
+	}
+
+	return nil
+}
+
+// Merge merges the changes of the branch of the given key made since the last
+// merge into the document it was forked from, and returns the number of the
+// merged changes. The conflicts with the changes of the document are resolved
+// like those of concurrent edits. The clients attaching the document receive
+// the merged changes on their next synchronization.
+func (c *Client) Merge(ctx context.Context, branchKey *key.Key, opts ...AttachOption) (int, error) {
+	if c.status != activated {
+		return 0, ErrClientNotActivated
+	}
+
+	res, err := c.client.MergeDocument(ctx, &api.MergeDocumentRequest{
+		ClientId:    c.id.String(),
+		BranchKey:   converter.ToDocumentKeys(branchKey)[0],
+		AccessToken: accessTokenOf(opts),
+	})
+	if err != nil {
+		c.logger.Error("fail to merge document", Field{"branch", branchKey.BSONKey()}, Field{"error", err})
+		return 0, err
+	}
+
+	return int(res.MergedChanges), nil
+}
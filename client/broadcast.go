@@ -0,0 +1,71 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+
+	"github.com/yorkie-team/yorkie/api/converter"
+	api "github.com/yorkie-team/yorkie/api/yorkie/v1"
+	"github.com/yorkie-team/yorkie/pkg/document/key"
+)
+
+// Broadcast is a message sent by the agent to the clients watching a
+// document, e.g. to notify that the document will be archived. The agent
+// keeps track of the clients that acknowledged it with AcknowledgeBroadcast.
+type Broadcast struct {
+	ID      string
+	Key     *key.Key
+	Type    string
+	Payload []byte
+}
+
+// AcknowledgeBroadcast notifies the agent that this client has handled the
+// given broadcast.
+func (c *Client) AcknowledgeBroadcast(ctx context.Context, broadcast *Broadcast) error {
+	if c.status != activated {
+		return ErrClientNotActivated
+	}
+
+	if _, err := c.client.AcknowledgeBroadcast(ctx, &api.AcknowledgeBroadcastRequest{
+		ClientId:    c.id.String(),
+		BroadcastId: broadcast.ID,
+	}); err != nil {
+		c.logger.Error("fail to acknowledge broadcast", Field{"broadcast", broadcast.ID}, Field{"error", err})
+		return err
+	}
+
+	return nil
+}
+
+// fromBroadcast converts the given Protobuf format to model format.
+func fromBroadcast(pbBroadcast *api.Broadcast) *Broadcast {
+	if pbBroadcast == nil {
+		return nil
+	}
+
+	broadcast := &Broadcast{
+		ID:      pbBroadcast.Id,
+		Type:    pbBroadcast.Type,
+		Payload: pbBroadcast.Payload,
+	}
+	if pbBroadcast.DocumentKey != nil {
+		broadcast.Key = converter.FromDocumentKey(pbBroadcast.DocumentKey)
+	}
+
+	return broadcast
+}
@@ -0,0 +1,93 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"regexp"
+	"strings"
+
+	api "github.com/yorkie-team/yorkie/api/yorkie/v1"
+)
+
+// indexPattern matches the index at the end of the path of an array element.
+var indexPattern = regexp.MustCompile(`\[[0-9]+\]$`)
+
+// ChangedPath is a path of a document changed by the changes of a watch
+// response with the types of the operations applied to it, e.g. "set". The
+// paths are in the form of $.todos[2].title.
+type ChangedPath struct {
+	Path  string
+	Types []string
+}
+
+// Affects returns whether the changes of this response may affect the element
+// of the given path or its descendants, e.g. $.todos. It returns true if the
+// changes are not summarized by the agent, so that the documents are pulled
+// unless the changes are known to be elsewhere.
+func (r WatchResponse) Affects(path string) bool {
+	if len(r.ChangedPaths) == 0 {
+		return true
+	}
+
+	for _, changed := range r.ChangedPaths {
+		changedPath := changed.Path
+
+		// NOTE: Adding, moving or removing an array element shifts the
+		// indexes of the elements after it, so the whole array is affected.
+		if indexPattern.MatchString(changedPath) && hasStructuralType(changed.Types) {
+			changedPath = indexPattern.ReplaceAllString(changedPath, "")
+		}
+
+		if isPathWithin(path, changedPath) || isPathWithin(changedPath, path) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isPathWithin returns whether the given path is the given ancestor or one of
+// its descendants.
+func isPathWithin(path, ancestor string) bool {
+	if !strings.HasPrefix(path, ancestor) {
+		return false
+	}
+
+	rest := path[len(ancestor):]
+	return rest == "" || rest[0] == '.' || rest[0] == '['
+}
+
+func hasStructuralType(types []string) bool {
+	for _, t := range types {
+		if t == "add" || t == "move" || t == "remove" {
+			return true
+		}
+	}
+	return false
+}
+
+// fromChangedPaths converts the given Protobuf format to model format.
+func fromChangedPaths(pbPaths []*api.ChangedPath) []ChangedPath {
+	var paths []ChangedPath
+	for _, pbPath := range pbPaths {
+		paths = append(paths, ChangedPath{
+			Path:  pbPath.Path,
+			Types: pbPath.Types,
+		})
+	}
+	return paths
+}
@@ -0,0 +1,238 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+
+	api "github.com/yorkie-team/yorkie/api/yorkie/v1"
+	"github.com/yorkie-team/yorkie/pkg/document/key"
+)
+
+var (
+	// ErrInvalidCiphertext is returned when an encrypted payload can't be
+	// decrypted.
+	ErrInvalidCiphertext = errors.New("invalid ciphertext")
+
+	// ErrSnapshotOfEncryptedDocument is returned when the agent sends a
+	// snapshot of an end-to-end encrypted document, which it can't build.
+	ErrSnapshotOfEncryptedDocument = errors.New("unexpected snapshot of encrypted document")
+)
+
+// Cipher encrypts and decrypts the payloads of the operations of end-to-end
+// encrypted documents. The key of the document is given, so that
+// implementations can manage the keys per document.
+type Cipher interface {
+	// Encrypt encrypts the given payload of the given document.
+	Encrypt(docKey *key.Key, plaintext []byte) ([]byte, error)
+
+	// Decrypt decrypts the given payload of the given document.
+	Decrypt(docKey *key.Key, ciphertext []byte) ([]byte, error)
+}
+
+// KeyFunc returns the 256-bit key of the given document.
+type KeyFunc func(docKey *key.Key) ([]byte, error)
+
+// aesCipher is a Cipher using AES-GCM with the keys returned by a KeyFunc.
+type aesCipher struct {
+	keyOf KeyFunc
+}
+
+// NewAESCipher creates a Cipher using AES-GCM with the keys returned by the
+// given function.
+func NewAESCipher(keyOf KeyFunc) Cipher {
+	return &aesCipher{keyOf: keyOf}
+}
+
+// Encrypt encrypts the given payload of the given document.
+func (c *aesCipher) Encrypt(docKey *key.Key, plaintext []byte) ([]byte, error) {
+	aead, err := c.aead(docKey)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// Decrypt decrypts the given payload of the given document.
+func (c *aesCipher) Decrypt(docKey *key.Key, ciphertext []byte) ([]byte, error) {
+	aead, err := c.aead(docKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, ErrInvalidCiphertext
+	}
+
+	nonce := ciphertext[:aead.NonceSize()]
+	plaintext, err := aead.Open(nil, nonce, ciphertext[aead.NonceSize():], nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return plaintext, nil
+}
+
+func (c *aesCipher) aead(docKey *key.Key) (cipher.AEAD, error) {
+	k, err := c.keyOf(docKey)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(k)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// encryptChangePack encrypts the payloads of the operations of the given pack
+// in place. CRDT metadata such as time tickets and positions stays in the
+// clear, so that the agent can still order and store the changes.
+//
+// Primitive values are encrypted with their types and sent as bytes, which
+// the agent stores as they are. Text contents are sent as base64 encoded
+// ciphertexts.
+func encryptChangePack(c Cipher, docKey *key.Key, pbPack *api.ChangePack) error {
+	pbPack.Encrypted = true
+
+	return forEachPayload(pbPack, func(value *api.JSONElementSimple) error {
+		if !isPrimitiveType(value.Type) {
+			return nil
+		}
+
+		payload := make([]byte, 0, len(value.Value)+1)
+		payload = append(payload, byte(value.Type))
+		payload = append(payload, value.Value...)
+		encrypted, err := c.Encrypt(docKey, payload)
+		if err != nil {
+			return err
+		}
+
+		value.Type = api.ValueType_BYTES
+		value.Value = encrypted
+		return nil
+	}, func(content *string) error {
+		if *content == "" {
+			return nil
+		}
+
+		encrypted, err := c.Encrypt(docKey, []byte(*content))
+		if err != nil {
+			return err
+		}
+
+		*content = base64.StdEncoding.EncodeToString(encrypted)
+		return nil
+	})
+}
+
+// decryptChangePack decrypts the payloads of the operations of the given pack
+// encrypted by encryptChangePack in place.
+func decryptChangePack(c Cipher, docKey *key.Key, pbPack *api.ChangePack) error {
+	if len(pbPack.Snapshot) > 0 {
+		return ErrSnapshotOfEncryptedDocument
+	}
+
+	return forEachPayload(pbPack, func(value *api.JSONElementSimple) error {
+		if value.Type != api.ValueType_BYTES {
+			return nil
+		}
+
+		payload, err := c.Decrypt(docKey, value.Value)
+		if err != nil {
+			return err
+		}
+		if len(payload) == 0 || !isPrimitiveType(api.ValueType(payload[0])) {
+			return ErrInvalidCiphertext
+		}
+
+		value.Type = api.ValueType(payload[0])
+		value.Value = payload[1:]
+		return nil
+	}, func(content *string) error {
+		if *content == "" {
+			return nil
+		}
+
+		encrypted, err := base64.StdEncoding.DecodeString(*content)
+		if err != nil {
+			return ErrInvalidCiphertext
+		}
+
+		decrypted, err := c.Decrypt(docKey, encrypted)
+		if err != nil {
+			return err
+		}
+
+		*content = string(decrypted)
+		return nil
+	})
+}
+
+// forEachPayload calls the given functions for each value of Set and Add
+// operations and each content of Edit operations of the given pack.
+func forEachPayload(
+	pbPack *api.ChangePack,
+	valueFn func(value *api.JSONElementSimple) error,
+	contentFn func(content *string) error,
+) error {
+	for _, pbChange := range pbPack.Changes {
+		for _, pbOp := range pbChange.Operations {
+			var err error
+			switch body := pbOp.Body.(type) {
+			case *api.Operation_Set_:
+				err = valueFn(body.Set.Value)
+			case *api.Operation_Add_:
+				err = valueFn(body.Add.Value)
+			case *api.Operation_Edit_:
+				err = contentFn(&body.Edit.Content)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func isPrimitiveType(valueType api.ValueType) bool {
+	switch valueType {
+	case api.ValueType_NULL,
+		api.ValueType_BOOLEAN,
+		api.ValueType_INTEGER,
+		api.ValueType_LONG,
+		api.ValueType_DOUBLE,
+		api.ValueType_STRING,
+		api.ValueType_BYTES,
+		api.ValueType_DATE:
+		return true
+	}
+	return false
+}
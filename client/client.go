@@ -19,17 +19,25 @@ package client
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io"
+	"sort"
+	time2 "time"
 
 	"github.com/google/uuid"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
+	grpcstatus "google.golang.org/grpc/status"
 
-	"github.com/yorkie-team/yorkie/api"
 	"github.com/yorkie-team/yorkie/api/converter"
+	api "github.com/yorkie-team/yorkie/api/yorkie/v1"
 	"github.com/yorkie-team/yorkie/pkg/document"
+	"github.com/yorkie-team/yorkie/pkg/document/change"
+	"github.com/yorkie-team/yorkie/pkg/document/checkpoint"
 	"github.com/yorkie-team/yorkie/pkg/document/key"
 	"github.com/yorkie-team/yorkie/pkg/document/time"
-	"github.com/yorkie-team/yorkie/pkg/log"
 )
 
 type status int
@@ -40,8 +48,36 @@ const (
 )
 
 var (
-	ErrClientNotActivated  = errors.New("client is not activated")
-	ErrDocumentNotAttached = errors.New("document is not attached")
+	ErrClientNotActivated    = errors.New("client is not activated")
+	ErrDocumentNotAttached   = errors.New("document is not attached")
+	ErrDocumentAlreadyExists = errors.New("document already exists")
+
+	// ErrDocumentLimitExceeded is returned when the changes are rejected by
+	// the agent because the document would exceed its limits.
+	ErrDocumentLimitExceeded = errors.New("document exceeds the limit")
+
+	// ErrInvalidSessionToken is returned when the session to resume is not
+	// the current session of the client.
+	ErrInvalidSessionToken = errors.New("invalid session token")
+)
+
+// maxRenameAttempts is the number of keys tried by RenameOnCollision.
+const maxRenameAttempts = 10
+
+// CollisionPolicy decides what happens when a document created offline is
+// attached under the key of a document that already exists in the agent.
+type CollisionPolicy int
+
+const (
+	// MergeOnCollision merges the local changes into the existing document.
+	MergeOnCollision CollisionPolicy = iota
+
+	// FailOnCollision fails the attachment with ErrDocumentAlreadyExists.
+	FailOnCollision
+
+	// RenameOnCollision attaches the document under a new key made by
+	// appending a suffix like "-1" to the name of the document.
+	RenameOnCollision
 )
 
 // Client is a normal client that can communicate with the agent.
@@ -55,6 +91,23 @@ type Client struct {
 	key          string
 	status       status
 	attachedDocs map[string]*document.Document
+	accessTokens map[string]string
+	cipher       Cipher
+	user         *change.User
+	logger       Logger
+
+	// apiVersion is the revision of the API negotiated with the agent.
+	apiVersion uint32
+
+	// sessionToken is the token of the session of this client.
+	sessionToken string
+
+	// resumables are the checkpoints of the documents attached in the
+	// resumed session, which are not attached to this client yet.
+	resumables map[string]checkpoint.Checkpoint
+
+	// usage is the amount of the data exchanged for each document.
+	usage *usageTracker
 }
 
 // Option configures how we set up the client.
@@ -62,6 +115,47 @@ type Option struct {
 	Key                string
 	CertFile           string
 	ServerNameOverride string
+
+	// Cipher encrypts the payloads of the operations of the documents
+	// end-to-end if it is set. All clients of a document must use the
+	// same keys.
+	Cipher Cipher
+
+	// User is the user of this client. It is attached to the pushed changes
+	// and stored with them in the agent, so that the history of documents
+	// can tell who made the changes.
+	User *change.User
+
+	// Logger is the logger of this client. The package logger of Yorkie is
+	// used if it is nil. Use NewNopLogger to silence this client.
+	Logger Logger
+
+	// Compression is the name of the compressor of the requests, e.g.
+	// GzipCompression. Requests are not compressed if it is empty. It can be
+	// overridden per call with WithCompression.
+	Compression string
+
+	// CompressionThreshold is the size in bytes of the smallest request to
+	// compress. Compressing tiny messages costs more than it saves.
+	CompressionThreshold int
+
+	// SessionToken is the token of a previous session of the client of the
+	// Key, returned by SessionToken. If it is set, the session is resumed on
+	// activation, so that the documents attached in the session are attached
+	// again without starting over. See ResumableDocuments.
+	SessionToken string
+
+	// MaxMessageSize is the maximum size in bytes of the messages the client
+	// sends and receives. The default size of gRPC, 4MiB, is used if it is 0.
+	// Snapshots larger than the chunk size of the agent are fetched in chunks
+	// regardless of it.
+	MaxMessageSize int
+
+	// UsageReporter is called every UsageReportInterval with the usages of
+	// the documents while the client is activated, so that applications can
+	// find the documents using the most data. See Usages.
+	UsageReporter       UsageReporter
+	UsageReportInterval time2.Duration
 }
 
 // NewClient creates an instance of Client.
@@ -83,19 +177,56 @@ func NewClient(rpcAddr string, opts ...Option) (*Client, error) {
 		serverNameOverride = opts[0].ServerNameOverride
 	}
 
-	dialOpts := grpc.WithInsecure()
+	var cipher Cipher
+	var user *change.User
+	var compression string
+	var compressionThreshold int
+	var sessionToken string
+	var maxMessageSize int
+	var usageReporter UsageReporter
+	var usageReportInterval time2.Duration
+	logger := defaultLogger()
+	if len(opts) > 0 {
+		cipher = opts[0].Cipher
+		user = opts[0].User
+		if opts[0].Logger != nil {
+			logger = opts[0].Logger
+		}
+		compression = opts[0].Compression
+		compressionThreshold = opts[0].CompressionThreshold
+		sessionToken = opts[0].SessionToken
+		maxMessageSize = opts[0].MaxMessageSize
+		usageReporter = opts[0].UsageReporter
+		usageReportInterval = opts[0].UsageReportInterval
+	}
+
+	if err := checkCompressor(compression); err != nil {
+		logger.Error("fail to find compressor", Field{"compression", compression}, Field{"error", err})
+		return nil, err
+	}
+
+	dialOpts := []grpc.DialOption{
+		grpc.WithInsecure(),
+		grpc.WithUnaryInterceptor(compressionInterceptor(compression, compressionThreshold)),
+	}
+	if maxMessageSize > 0 {
+		dialOpts = append(dialOpts, grpc.WithDefaultCallOptions(
+			grpc.MaxCallRecvMsgSize(maxMessageSize),
+			grpc.MaxCallSendMsgSize(maxMessageSize),
+		))
+	}
 	if certFile != "" {
 		creds, err := credentials.NewClientTLSFromFile(certFile, serverNameOverride)
 		if err != nil {
-			log.Logger.Error(err)
+			logger.Error("fail to load certificate", Field{"cert_file", certFile}, Field{"error", err})
 			return nil, err
 		}
-		dialOpts = grpc.WithTransportCredentials(creds)
+		dialOpts[0] = grpc.WithTransportCredentials(creds)
 	}
 
-	conn, err := grpc.Dial(rpcAddr, dialOpts)
+	conn, err := grpc.Dial(rpcAddr, dialOpts...)
 	if err != nil {
-		log.Logger.Error(err)
+		logger.Error("fail to dial", Field{"rpc_addr", rpcAddr}, Field{"error", err})
 		return nil, err
 	}
 
@@ -107,6 +238,13 @@ func NewClient(rpcAddr string, opts ...Option) (*Client, error) {
 		key:          k,
 		status:       deactivated,
 		attachedDocs: make(map[string]*document.Document),
+		accessTokens: make(map[string]string),
+		cipher:       cipher,
+		user:         user,
+		logger:       logger,
+		sessionToken: sessionToken,
+		resumables:   make(map[string]checkpoint.Checkpoint),
+		usage:        newUsageTracker(usageReportInterval, usageReporter),
 	}, nil
 }
 
@@ -117,7 +255,7 @@ func (c *Client) Close() error {
 	}
 
 	if err := c.conn.Close(); err != nil {
-		log.Logger.Error(err)
+		c.logger.Error("fail to close connection", Field{"error", err})
 		return err
 	}
 
@@ -133,20 +271,61 @@ func (c *Client) Activate(ctx context.Context) error {
 	}
 
 	reply, err := c.client.ActivateClient(ctx, &api.ActivateClientRequest{
-		ClientKey: c.key,
+		Header:       &api.RequestHeader{Version: api.Revision},
+		ClientKey:    c.key,
+		SessionToken: c.sessionToken,
 	})
 
 	if err != nil {
-		log.Logger.Error(err)
+		if grpcstatus.Code(err) == codes.Unauthenticated {
+			return ErrInvalidSessionToken
+		}
+		c.logger.Error("fail to activate", Field{"client_key", c.key}, Field{"error", err})
 		return err
 	}
 
 	c.status = activated
+	c.usage.start()
 	c.id = time.ActorIDFromHex(reply.ClientId)
+	c.apiVersion = reply.ApiVersion
+	c.sessionToken = reply.SessionToken
+	for _, attachment := range reply.Attachments {
+		docKey := converter.FromDocumentKey(attachment.DocumentKey)
+		if _, ok := c.attachedDocs[docKey.BSONKey()]; ok {
+			continue
+		}
+		c.resumables[docKey.BSONKey()] = converter.FromCheckpoint(attachment.Checkpoint)
+	}
 
 	return nil
 }
 
+// SessionToken returns the token of the session of this client. Keep it with
+// the key of this client to resume the session after a restart.
+func (c *Client) SessionToken() string {
+	return c.sessionToken
+}
+
+// ResumableDocuments returns the keys of the documents attached in the
+// resumed session, which are not attached to this client yet. Attaching new
+// replicas of them resumes their attachments instead of attaching them
+// again.
+func (c *Client) ResumableDocuments() []*key.Key {
+	var keys []*key.Key
+	for bsonKey := range c.resumables {
+		docKey, err := key.FromBSONKey(bsonKey)
+		if err != nil {
+			continue
+		}
+		keys = append(keys, docKey)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].BSONKey() < keys[j].BSONKey()
+	})
+
+	return keys
+}
+
 // Deactivate deactivates this client.
 func (c *Client) Deactivate(ctx context.Context) error {
 	if c.status == deactivated {
@@ -157,49 +336,177 @@ func (c *Client) Deactivate(ctx context.Context) error {
 		ClientId: c.id.String(),
 	})
 	if err != nil {
-		log.Logger.Error(err)
+		c.logger.Error("fail to deactivate", Field{"client_id", c.id.String()}, Field{"error", err})
 		return err
 	}
 
 	c.status = deactivated
+	c.usage.close()
 
 	return nil
 }
 
+// AttachOption configures how we attach the document.
+type AttachOption struct {
+	// AccessToken is the token issued for the document. It is required if
+	// the agent is configured to verify access tokens.
+	AccessToken string
+
+	// OnCollision is the policy applied when the document has local changes
+	// made before it was ever synchronized, e.g. it was created offline, and
+	// a document of the same key already exists.
+	OnCollision CollisionPolicy
+}
+
 // Attach attaches the given document to this client. It tells the agent that
 // this client will synchronize the given document.
-func (c *Client) Attach(ctx context.Context, doc *document.Document) error {
+//
+// Documents can be created and edited offline before they are attached. Then
+// the local changes are pushed as the history of the document.
+func (c *Client) Attach(ctx context.Context, doc *document.Document, opts ...AttachOption) error {
 	if c.status != activated {
 		return ErrClientNotActivated
 	}
+	if doc.IsReadOnly() {
+		return document.ErrDocumentReadOnly
+	}
+	if err := doc.Key().Validate(); err != nil {
+		return err
+	}
+
+	var accessToken string
+	var onCollision CollisionPolicy
+	if len(opts) > 0 {
+		accessToken = opts[0].AccessToken
+		onCollision = opts[0].OnCollision
+	}
 
 	doc.SetActor(c.id)
 
-	res, err := c.client.AttachDocument(ctx, &api.AttachDocumentRequest{
-		ClientId:   c.id.String(),
-		ChangePack: converter.ToChangePack(doc.CreateChangePack()),
-	})
+	doc.StartSync()
+	var err error
+	if cp, ok := c.resumables[doc.Key().BSONKey()]; ok {
+		err = c.resume(ctx, doc, cp, accessToken)
+	} else {
+		err = c.attach(ctx, doc, accessToken, onCollision)
+	}
+	doc.FinishSync(err)
+	return err
+}
+
+// resume resumes the attachment of the given document in the resumed session
+// from the given checkpoint of the agent. The document pulls all the changes
+// including those of this client, since it is a new replica.
+func (c *Client) resume(
+	ctx context.Context,
+	doc *document.Document,
+	cp checkpoint.Checkpoint,
+	accessToken string,
+) error {
+	if err := doc.ResumeClientSeq(cp.ClientSeq); err != nil {
+		return err
+	}
+
+	bsonKey := doc.Key().BSONKey()
+	doc.UpdateState(document.Attached)
+	c.attachedDocs[bsonKey] = doc
+	if accessToken != "" {
+		c.accessTokens[bsonKey] = accessToken
+	}
+
+	if err := c.pushPull(ctx, doc); err != nil {
+		doc.UpdateState(document.Detached)
+		delete(c.attachedDocs, bsonKey)
+		delete(c.accessTokens, bsonKey)
+		return err
+	}
+	delete(c.resumables, bsonKey)
+
+	return nil
+}
+
+// attach attaches the given document, applying the given policy if the
+// document was created offline and collides with an existing one.
+func (c *Client) attach(
+	ctx context.Context,
+	doc *document.Document,
+	accessToken string,
+	onCollision CollisionPolicy,
+) error {
+	// NOTE: Documents that have been synchronized already share the history
+	// of the agent, so they are always merged.
+	createdOffline := doc.HasLocalChanges() && doc.Checkpoint().ServerSeq == 0
+	res, err := c.attachDocument(ctx, doc, accessToken, createdOffline && onCollision != MergeOnCollision)
+	if createdOffline && onCollision == RenameOnCollision {
+		name := doc.Key().Document
+		for i := 1; err == ErrDocumentAlreadyExists && i <= maxRenameAttempts; i++ {
+			if err := doc.SetKey(&key.Key{
+				Collection: doc.Key().Collection,
+				Document:   fmt.Sprintf("%s-%d", name, i),
+			}); err != nil {
+				return err
+			}
+			res, err = c.attachDocument(ctx, doc, accessToken, true)
+		}
+	}
 	if err != nil {
-		log.Logger.Error(err)
 		return err
 	}
 
-	pack, err := converter.FromChangePack(res.ChangePack)
+	pack, err := c.fromChangePack(ctx, doc, res.ChangePack)
 	if err != nil {
 		return err
 	}
 
 	if err := doc.ApplyChangePack(pack); err != nil {
-		log.Logger.Error(err)
+		c.logger.Error("fail to apply change pack", Field{"document", doc.Key().BSONKey()}, Field{"error", err})
 		return err
 	}
 
 	doc.UpdateState(document.Attached)
 	c.attachedDocs[doc.Key().BSONKey()] = doc
+	if accessToken != "" {
+		c.accessTokens[doc.Key().BSONKey()] = accessToken
+	}
 
 	return nil
 }
 
+// attachDocument requests the agent to attach the given document. If
+// failIfExists is true, it fails with ErrDocumentAlreadyExists when the
+// document already has changes in the agent.
+func (c *Client) attachDocument(
+	ctx context.Context,
+	doc *document.Document,
+	accessToken string,
+	failIfExists bool,
+) (*api.AttachDocumentResponse, error) {
+	pbPack, err := c.toChangePack(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := c.client.AttachDocument(ctx, &api.AttachDocumentRequest{
+		Header:       &api.RequestHeader{Version: api.Revision},
+		ClientId:     c.id.String(),
+		ChangePack:   pbPack,
+		AccessToken:  accessToken,
+		FailIfExists: failIfExists,
+	})
+	if err != nil {
+		if grpcstatus.Code(err) == codes.AlreadyExists {
+			return nil, ErrDocumentAlreadyExists
+		}
+		if limitErr := toLimitError(err); limitErr != nil {
+			return nil, limitErr
+		}
+		c.logger.Error("fail to attach", Field{"document", doc.Key().BSONKey()}, Field{"error", err})
+		return nil, err
+	}
+
+	return res, nil
+}
+
 // Detach detaches the given document from this client. It tells the
 // agent that this client will no longer synchronize the given document.
 //
@@ -215,27 +522,37 @@ func (c *Client) Detach(ctx context.Context, doc *document.Document) error {
 		return ErrDocumentNotAttached
 	}
 
+	pbPack, err := c.toChangePack(doc)
+	if err != nil {
+		return err
+	}
+
 	res, err := c.client.DetachDocument(ctx, &api.DetachDocumentRequest{
+		Header:     &api.RequestHeader{Version: api.Revision},
 		ClientId:   c.id.String(),
-		ChangePack: converter.ToChangePack(doc.CreateChangePack()),
+		ChangePack: pbPack,
 	})
 	if err != nil {
-		log.Logger.Error(err)
+		if limitErr := toLimitError(err); limitErr != nil {
+			return limitErr
+		}
+		c.logger.Error("fail to detach", Field{"document", doc.Key().BSONKey()}, Field{"error", err})
 		return err
 	}
 
-	pack, err := converter.FromChangePack(res.ChangePack)
+	pack, err := c.fromChangePack(ctx, doc, res.ChangePack)
 	if err != nil {
 		return err
 	}
 
 	if err := doc.ApplyChangePack(pack); err != nil {
-		log.Logger.Error(err)
+		c.logger.Error("fail to apply change pack", Field{"document", doc.Key().BSONKey()}, Field{"error", err})
 		return err
 	}
 
 	doc.UpdateState(document.Detached)
 	delete(c.attachedDocs, doc.Key().BSONKey())
+	delete(c.accessTokens, doc.Key().BSONKey())
 
 	return nil
 }
@@ -263,6 +580,16 @@ func (c *Client) Sync(ctx context.Context, keys ...*key.Key) error {
 type WatchResponse struct {
 	Keys []*key.Key
 	Err  error
+
+	// Broadcast is the message broadcast by the agent. It is nil if the
+	// response is for the changes of the documents. Keys hold the key of the
+	// broadcast document, so that it is synced if the response is handled as
+	// changes.
+	Broadcast *Broadcast
+
+	// ChangedPaths is the summary of the paths changed by the changes. It is
+	// empty if the agent doesn't summarize the changes. See Affects.
+	ChangedPaths []ChangedPath
 }
 
 // Watch subscribes to events on a given document.
@@ -270,15 +597,51 @@ type WatchResponse struct {
 // and "WatchResponse" from this closed channel has zero events and nil "Err()".
 func (c *Client) Watch(ctx context.Context, docs ...*document.Document) <-chan WatchResponse {
 	var keys []*key.Key
+	var accessTokens []string
 	for _, doc := range docs {
 		keys = append(keys, doc.Key())
+		if token, ok := c.accessTokens[doc.Key().BSONKey()]; ok {
+			accessTokens = append(accessTokens, token)
+		}
 	}
 
-	rch := make(chan WatchResponse)
-	stream, err := c.client.WatchDocuments(ctx, &api.WatchDocumentsRequest{
+	return c.watch(ctx, &api.WatchDocumentsRequest{
 		ClientId:     c.id.String(),
 		DocumentKeys: converter.ToDocumentKeys(keys...),
+		AccessTokens: accessTokens,
+	})
+}
+
+// WatchOption is the option of WatchPrefixes.
+type WatchOption struct {
+	// AccessToken is the token issued for the prefixes. It is required if the
+	// agent is configured to verify access tokens.
+	AccessToken string
+}
+
+// WatchPrefixes subscribes to events on all documents under the given
+// prefixes, including the documents created after the watch is started. The
+// documents don't need to be attached to this client.
+func (c *Client) WatchPrefixes(
+	ctx context.Context,
+	prefixes []*key.Prefix,
+	opts ...WatchOption,
+) <-chan WatchResponse {
+	var accessTokens []string
+	if len(opts) > 0 && opts[0].AccessToken != "" {
+		accessTokens = append(accessTokens, opts[0].AccessToken)
+	}
+
+	return c.watch(ctx, &api.WatchDocumentsRequest{
+		ClientId:            c.id.String(),
+		DocumentKeyPrefixes: converter.ToDocumentKeyPrefixes(prefixes...),
+		AccessTokens:        accessTokens,
 	})
+}
+
+func (c *Client) watch(ctx context.Context, req *api.WatchDocumentsRequest) <-chan WatchResponse {
+	rch := make(chan WatchResponse)
+	stream, err := c.client.WatchDocuments(ctx, req)
 	if err != nil {
 		rch <- WatchResponse{Err: err}
 		close(rch)
@@ -296,7 +659,9 @@ func (c *Client) Watch(ctx context.Context, docs ...*document.Document) <-chan W
 
 			if resp != nil {
 				rch <- WatchResponse{
-					Keys: converter.FromDocumentKeys(resp.DocumentKeys),
+					Keys:         converter.FromDocumentKeys(resp.DocumentKeys),
+					Broadcast:    fromBroadcast(resp.Broadcast),
+					ChangedPaths: fromChangedPaths(resp.ChangedPaths),
 				}
 			}
 		}
@@ -305,6 +670,14 @@ func (c *Client) Watch(ctx context.Context, docs ...*document.Document) <-chan W
 	return rch
 }
 
+// APIVersion returns the revision of the API negotiated with the agent when
+// this client was activated. Agents released before the API was versioned
+// return api.LegacyRevision, and the features added after it should not be
+// used with them.
+func (c *Client) APIVersion() uint32 {
+	return c.apiVersion
+}
+
 // IsActive returns whether this client is active or not.
 func (c *Client) IsActive() bool {
 	return c.status == activated
@@ -320,24 +693,142 @@ func (c *Client) sync(ctx context.Context, key *key.Key) error {
 		return ErrDocumentNotAttached
 	}
 
+	doc.StartSync()
+	err := c.pushPull(ctx, doc)
+	doc.FinishSync(err)
+	return err
+}
+
+// pushPull pushes the local changes of the given document and applies the
+// changes pulled from the agent.
+func (c *Client) pushPull(ctx context.Context, doc *document.Document) error {
+	pbPack, err := c.toChangePack(doc)
+	if err != nil {
+		return err
+	}
+
 	res, err := c.client.PushPull(ctx, &api.PushPullRequest{
+		Header:     &api.RequestHeader{Version: api.Revision},
 		ClientId:   c.id.String(),
-		ChangePack: converter.ToChangePack(doc.CreateChangePack()),
+		ChangePack: pbPack,
 	})
 	if err != nil {
-		log.Logger.Error(err)
+		if limitErr := toLimitError(err); limitErr != nil {
+			return limitErr
+		}
+		c.logger.Error("fail to push pull", Field{"document", doc.Key().BSONKey()}, Field{"error", err})
 		return err
 	}
 
-	pack, err := converter.FromChangePack(res.ChangePack)
+	pack, err := c.fromChangePack(ctx, doc, res.ChangePack)
 	if err != nil {
 		return err
 	}
 
 	if err := doc.ApplyChangePack(pack); err != nil {
-		log.Logger.Error(err)
+		c.logger.Error("fail to apply change pack", Field{"document", doc.Key().BSONKey()}, Field{"error", err})
 		return err
 	}
 
 	return nil
 }
+
+// toLimitError converts the given error of the agent rejecting the changes
+// for the limits of the document to an error wrapping
+// ErrDocumentLimitExceeded. It returns nil for other errors.
+func toLimitError(err error) error {
+	st := grpcstatus.Convert(err)
+	if st.Code() != codes.ResourceExhausted {
+		return nil
+	}
+
+	for _, detail := range st.Details() {
+		br, ok := detail.(*errdetails.BadRequest)
+		if !ok || len(br.FieldViolations) == 0 {
+			continue
+		}
+
+		violation := br.FieldViolations[0]
+		return fmt.Errorf("%s %s: %w", violation.Field, violation.Description, ErrDocumentLimitExceeded)
+	}
+
+	return nil
+}
+
+// toChangePack creates a change pack of the local changes of the given
+// document, encrypting their payloads if a cipher is set.
+func (c *Client) toChangePack(doc *document.Document) (*api.ChangePack, error) {
+	pack := doc.CreateChangePack()
+	pack.User = c.user
+
+	pbPack := converter.ToChangePack(pack)
+	if c.cipher != nil {
+		if err := encryptChangePack(c.cipher, doc.Key(), pbPack); err != nil {
+			c.logger.Error("fail to encrypt change pack", Field{"document", doc.Key().BSONKey()}, Field{"error", err})
+			return nil, err
+		}
+	}
+
+	c.usage.recordPush(doc.Key(), pbPack)
+	return pbPack, nil
+}
+
+// fromChangePack converts the given change pack of the given document,
+// decrypting its payloads if a cipher is set. The snapshot omitted from the
+// pack for its size is fetched from the agent.
+func (c *Client) fromChangePack(
+	ctx context.Context,
+	doc *document.Document,
+	pbPack *api.ChangePack,
+) (*change.Pack, error) {
+	if pbPack != nil {
+		var snapshot []byte
+		if pbPack.SnapshotId != "" {
+			var err error
+			if snapshot, err = c.fetchSnapshot(ctx, pbPack.SnapshotId); err != nil {
+				c.logger.Error("fail to fetch snapshot", Field{"document", doc.Key().BSONKey()}, Field{"error", err})
+				return nil, err
+			}
+		}
+		c.usage.recordPull(doc.Key(), pbPack, len(snapshot))
+
+		if snapshot != nil {
+			pbPack.Snapshot = snapshot
+			pbPack.SnapshotId = ""
+		}
+	}
+
+	if c.cipher != nil && pbPack != nil {
+		if err := decryptChangePack(c.cipher, doc.Key(), pbPack); err != nil {
+			c.logger.Error("fail to decrypt change pack", Field{"document", doc.Key().BSONKey()}, Field{"error", err})
+			return nil, err
+		}
+	}
+
+	return converter.FromChangePack(pbPack)
+}
+
+// fetchSnapshot fetches the snapshot of the given ID in chunks and
+// reassembles them.
+func (c *Client) fetchSnapshot(ctx context.Context, snapshotID string) ([]byte, error) {
+	stream, err := c.client.FetchSnapshot(ctx, &api.FetchSnapshotRequest{
+		Header:     &api.RequestHeader{Version: api.Revision},
+		ClientId:   c.id.String(),
+		SnapshotId: snapshotID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshot []byte
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			return snapshot, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		snapshot = append(snapshot, res.Chunk...)
+	}
+}
@@ -17,19 +17,33 @@
 package client_test
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"os"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
-
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/yorkie-team/yorkie/api/converter"
+	api "github.com/yorkie-team/yorkie/api/yorkie/v1"
 	"github.com/yorkie-team/yorkie/client"
 	"github.com/yorkie-team/yorkie/pkg/document"
+	"github.com/yorkie-team/yorkie/pkg/document/change"
+	"github.com/yorkie-team/yorkie/pkg/document/json"
+	"github.com/yorkie-team/yorkie/pkg/document/key"
 	"github.com/yorkie-team/yorkie/pkg/document/proxy"
 	"github.com/yorkie-team/yorkie/testhelper"
 	"github.com/yorkie-team/yorkie/yorkie"
@@ -561,6 +575,38 @@ func TestClientAndDocument(t *testing.T) {
 		assert.Equal(t, d1.Marshal(), d2.Marshal())
 	})
 
+	t.Run("changed paths test", func(t *testing.T) {
+		ctx := context.Background()
+
+		d1 := document.New(testhelper.Collection, t.Name())
+		assert.NoError(t, c1.Attach(ctx, d1))
+		d2 := document.New(testhelper.Collection, t.Name())
+		assert.NoError(t, c2.Attach(ctx, d2))
+
+		watchCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		rch := c1.Watch(watchCtx, d1)
+
+		assert.NoError(t, d2.Update(func(root *proxy.ObjectProxy) error {
+			root.SetNewArray("todos").AddString("a")
+			root.SetString("title", "b")
+			return nil
+		}))
+		assert.NoError(t, c2.Sync(ctx))
+
+		resp := <-rch
+		assert.NoError(t, resp.Err)
+		assert.Equal(t, []client.ChangedPath{
+			{Path: "$.title", Types: []string{"set"}},
+			{Path: "$.todos", Types: []string{"set"}},
+			{Path: "$.todos[0]", Types: []string{"add"}},
+		}, resp.ChangedPaths)
+		assert.True(t, resp.Affects("$.todos[1].done"))
+		assert.True(t, resp.Affects("$"))
+		assert.False(t, resp.Affects("$.titles"))
+		assert.False(t, resp.Affects("$.owner"))
+	})
+
 	t.Run("snapshot test", func(t *testing.T) {
 		ctx := context.Background()
 
@@ -645,6 +691,1157 @@ func TestClientAndDocument(t *testing.T) {
 	})
 }
 
+func TestSubscribeWithSelector(t *testing.T) {
+	clients := getActivatedClients(t, 2)
+	c1 := clients[0]
+	c2 := clients[1]
+	defer func() {
+		cleanupClients(t, clients)
+	}()
+
+	t.Run("select changed value test", func(t *testing.T) {
+		ctx := context.Background()
+
+		d1 := document.New(testhelper.Collection, t.Name())
+		err := c1.Attach(ctx, d1)
+		assert.NoError(t, err)
+
+		d2 := document.New(testhelper.Collection, t.Name())
+		err = c2.Attach(ctx, d2)
+		assert.NoError(t, err)
+
+		var events []client.SelectEvent
+		unsubscribe := client.SubscribeWithSelector(d2, func(root *json.Object) interface{} {
+			if e := root.Get("k1"); e != nil {
+				return e.Marshal()
+			}
+			return nil
+		}, func(e client.SelectEvent) {
+			events = append(events, e)
+		})
+
+		err = d1.Update(func(root *proxy.ObjectProxy) error {
+			root.SetString("k1", "v1")
+			root.SetString("k2", "v2")
+			return nil
+		})
+		assert.NoError(t, err)
+		syncClientsThenAssertEqual(t, []clientAndDocPair{{c1, d1}, {c2, d2}})
+
+		// changes of the other members are not delivered.
+		err = d2.Update(func(root *proxy.ObjectProxy) error {
+			root.SetString("k2", "v3")
+			return nil
+		})
+		assert.NoError(t, err)
+
+		err = d2.Update(func(root *proxy.ObjectProxy) error {
+			root.SetString("k1", "v4")
+			return nil
+		})
+		assert.NoError(t, err)
+
+		assert.Equal(t, []client.SelectEvent{{
+			Type:  document.RemoteChangeEvent,
+			Value: `"v1"`,
+			Prev:  nil,
+		}, {
+			Type:  document.LocalChangeEvent,
+			Value: `"v4"`,
+			Prev:  `"v1"`,
+		}}, events)
+
+		unsubscribe()
+		err = d2.Update(func(root *proxy.ObjectProxy) error {
+			root.SetString("k1", "v5")
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Len(t, events, 2)
+	})
+}
+
+func TestDocumentManager(t *testing.T) {
+	clients := getActivatedClients(t, 2)
+	c1 := clients[0]
+	c2 := clients[1]
+	defer func() {
+		cleanupClients(t, clients)
+	}()
+
+	t.Run("acquire/release test", func(t *testing.T) {
+		ctx := context.Background()
+		m := client.NewDocumentManager(c1, client.ManagerConfig{})
+		k := &key.Key{Collection: testhelper.Collection, Document: t.Name()}
+
+		assert.NoError(t, m.Acquire(ctx, k))
+		assert.NoError(t, m.Acquire(ctx, k))
+		assert.Equal(t, 1, m.Len())
+
+		var acquired *document.Document
+		err := m.Do(k, func(doc *document.Document) error {
+			acquired = doc
+			assert.True(t, doc.IsAttached())
+			return doc.Update(func(root *proxy.ObjectProxy) error {
+				root.SetString("k1", "v1")
+				return nil
+			})
+		})
+		assert.NoError(t, err)
+		assert.NoError(t, m.Sync(ctx))
+
+		other := document.New(testhelper.Collection, t.Name())
+		assert.NoError(t, c2.Attach(ctx, other))
+		assert.Equal(t, `{"k1":"v1"}`, other.Marshal())
+		assert.NoError(t, c2.Detach(ctx, other))
+
+		assert.NoError(t, m.Release(k))
+		assert.NoError(t, m.Release(k))
+		assert.Equal(t, client.ErrDocumentNotAcquired, m.Release(k))
+		assert.Equal(t, client.ErrDocumentNotAcquired, m.Do(k, func(doc *document.Document) error {
+			return nil
+		}))
+
+		assert.NoError(t, m.Stop(ctx))
+		assert.False(t, acquired.IsAttached())
+		assert.Equal(t, 0, m.Len())
+	})
+
+	t.Run("idle detach test", func(t *testing.T) {
+		ctx := context.Background()
+		m := client.NewDocumentManager(c1, client.ManagerConfig{IdleTimeout: time.Millisecond})
+		k := &key.Key{Collection: testhelper.Collection, Document: t.Name()}
+
+		assert.NoError(t, m.Acquire(ctx, k))
+		cnt, err := m.DetachIdleDocuments(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, cnt)
+
+		assert.NoError(t, m.Release(k))
+		time.Sleep(10 * time.Millisecond)
+		cnt, err = m.DetachIdleDocuments(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, cnt)
+		assert.Equal(t, 0, m.Len())
+
+		// detached documents can be acquired again.
+		assert.NoError(t, m.Acquire(ctx, k))
+		assert.NoError(t, m.Do(k, func(doc *document.Document) error {
+			assert.True(t, doc.IsAttached())
+			return nil
+		}))
+		assert.NoError(t, m.Stop(ctx))
+	})
+
+	t.Run("eviction test", func(t *testing.T) {
+		ctx := context.Background()
+		m := client.NewDocumentManager(c1, client.ManagerConfig{CacheSize: 2})
+
+		var keys []*key.Key
+		var docs []*document.Document
+		for i := 0; i < 3; i++ {
+			k := &key.Key{Collection: testhelper.Collection, Document: fmt.Sprintf("%s-%d", t.Name(), i)}
+			assert.NoError(t, m.Acquire(ctx, k))
+			assert.NoError(t, m.Do(k, func(doc *document.Document) error {
+				docs = append(docs, doc)
+				return nil
+			}))
+			keys = append(keys, k)
+		}
+
+		// acquired documents are not evicted.
+		assert.Equal(t, 3, m.Len())
+
+		assert.NoError(t, m.Release(keys[0]))
+		assert.NoError(t, m.Release(keys[1]))
+		assert.NoError(t, m.Acquire(ctx, keys[0]))
+		assert.NoError(t, m.Release(keys[0]))
+
+		k := &key.Key{Collection: testhelper.Collection, Document: fmt.Sprintf("%s-%d", t.Name(), 3)}
+		assert.NoError(t, m.Acquire(ctx, k))
+		assert.Equal(t, 2, m.Len())
+		assert.False(t, docs[0].IsAttached())
+		assert.False(t, docs[1].IsAttached())
+		assert.True(t, docs[2].IsAttached())
+		assert.NoError(t, m.Stop(ctx))
+	})
+
+	t.Run("background sync test", func(t *testing.T) {
+		ctx := context.Background()
+		m := client.NewDocumentManager(c1, client.ManagerConfig{SyncInterval: time.Millisecond})
+		k := &key.Key{Collection: testhelper.Collection, Document: t.Name()}
+
+		assert.NoError(t, m.Acquire(ctx, k))
+		m.Start()
+		for i := 0; i < 20; i++ {
+			assert.NoError(t, m.Do(k, func(doc *document.Document) error {
+				return doc.Update(func(root *proxy.ObjectProxy) error {
+					root.SetInteger("k1", i)
+					return nil
+				})
+			}))
+			time.Sleep(time.Millisecond)
+		}
+		assert.NoError(t, m.Release(k))
+		assert.NoError(t, m.Stop(ctx))
+
+		other := document.New(testhelper.Collection, t.Name())
+		assert.NoError(t, c2.Attach(ctx, other))
+		assert.Equal(t, `{"k1":19}`, other.Marshal())
+		assert.NoError(t, c2.Detach(ctx, other))
+	})
+}
+
+func TestUser(t *testing.T) {
+	t.Run("document history test", func(t *testing.T) {
+		ctx := context.Background()
+
+		user := &change.User{ID: "u1", Name: "User 1"}
+		cli, err := client.NewClient(testYorkie.RPCAddr(), client.Option{User: user})
+		assert.NoError(t, err)
+		assert.NoError(t, cli.Activate(ctx))
+		defer func() {
+			cleanupClients(t, []*client.Client{cli})
+		}()
+
+		doc := document.New(testhelper.Collection, t.Name())
+		assert.NoError(t, cli.Attach(ctx, doc))
+		err = doc.UpdateWithMetadata(func(root *proxy.ObjectProxy) error {
+			root.SetString("k1", "v1")
+			return nil
+		}, map[string]string{"source": "test"}, "set k1")
+		assert.NoError(t, err)
+		assert.NoError(t, cli.Sync(ctx))
+
+		conn, err := grpc.Dial(testYorkie.RPCAddr(), grpc.WithInsecure())
+		assert.NoError(t, err)
+		defer func() {
+			assert.NoError(t, conn.Close())
+		}()
+
+		adminCtx := metadata.AppendToOutgoingContext(ctx, "authorization", testhelper.AdminToken)
+		resp, err := api.NewAdminClient(conn).GetDocumentHistory(adminCtx, &api.GetDocumentHistoryRequest{
+			DocumentKey: converter.ToDocumentKeys(doc.Key())[0],
+		})
+		assert.NoError(t, err)
+		assert.Len(t, resp.Changes, 1)
+		assert.Equal(t, "set k1", resp.Changes[0].Message)
+		assert.Equal(t, user.ID, resp.Changes[0].User.Id)
+		assert.Equal(t, user.Name, resp.Changes[0].User.Name)
+		assert.Equal(t, map[string]string{"source": "test"}, resp.Changes[0].Metadata)
+		assert.Equal(t, []*api.OperationSummary{{
+			Type:        "set",
+			Path:        "$.k1",
+			Description: `set $.k1 = "v1"`,
+		}}, resp.Changes[0].Operations)
+	})
+}
+
+func TestPeers(t *testing.T) {
+	clients := getActivatedClients(t, 2)
+	c1 := clients[0]
+	c2 := clients[1]
+	defer func() {
+		cleanupClients(t, clients)
+	}()
+
+	t.Run("presence test", func(t *testing.T) {
+		ctx := context.Background()
+
+		d1 := document.New(testhelper.Collection, t.Name())
+		assert.NoError(t, c1.Attach(ctx, d1))
+		assert.NoError(t, c1.UpdatePresence(ctx, d1.Key(), map[string]string{"name": "c1"}))
+
+		d2 := document.New(testhelper.Collection, t.Name())
+		_, err := c2.Peers(ctx, d2.Key())
+		assert.Error(t, err)
+		assert.NoError(t, c2.Attach(ctx, d2))
+
+		peers, err := c2.Peers(ctx, d2.Key())
+		assert.NoError(t, err)
+		assert.Len(t, peers, 2)
+
+		presence := make(map[string]string)
+		for _, peer := range peers {
+			assert.False(t, peer.LastSeen.IsZero())
+			if name, ok := peer.Presence["name"]; ok {
+				presence[peer.ClientID] = name
+			}
+		}
+		assert.Len(t, presence, 1)
+
+		assert.NoError(t, c1.Detach(ctx, d1))
+		peers, err = c2.Peers(ctx, d2.Key())
+		assert.NoError(t, err)
+		assert.Len(t, peers, 1)
+		assert.Empty(t, peers[0].Presence)
+	})
+}
+
+func TestAPIVersion(t *testing.T) {
+	t.Run("negotiation test", func(t *testing.T) {
+		clients := getActivatedClients(t, 1)
+		defer func() {
+			cleanupClients(t, clients)
+		}()
+
+		assert.Equal(t, api.Revision, clients[0].APIVersion())
+	})
+
+	t.Run("legacy client test", func(t *testing.T) {
+		conn, err := grpc.Dial(testYorkie.RPCAddr(), grpc.WithInsecure())
+		assert.NoError(t, err)
+		defer func() {
+			assert.NoError(t, conn.Close())
+		}()
+
+		// SDKs released before the API was versioned call the unversioned
+		// service without the revision they support.
+		resp := &api.ActivateClientResponse{}
+		err = conn.Invoke(context.Background(), "/api.Yorkie/ActivateClient", &api.ActivateClientRequest{
+			ClientKey: t.Name(),
+		}, resp)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, resp.ClientId)
+		assert.Equal(t, api.LegacyRevision, resp.ApiVersion)
+
+		_, err = api.NewYorkieClient(conn).DeactivateClient(context.Background(), &api.DeactivateClientRequest{
+			ClientId: resp.ClientId,
+		})
+		assert.NoError(t, err)
+	})
+}
+
+func TestBroadcast(t *testing.T) {
+	clients := getActivatedClients(t, 1)
+	cli := clients[0]
+	defer func() {
+		cleanupClients(t, clients)
+	}()
+
+	t.Run("broadcast test", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		doc := document.New(testhelper.Collection, t.Name())
+		assert.NoError(t, cli.Attach(ctx, doc))
+		rch := cli.Watch(ctx, doc)
+
+		// NOTE: waiting for the watch stream to be registered.
+		var clientID string
+		assert.Eventually(t, func() bool {
+			peers, err := cli.Peers(ctx, doc.Key())
+			if err != nil || len(peers) != 1 || !peers[0].Watching {
+				return false
+			}
+			clientID = peers[0].ClientID
+			return true
+		}, time.Second, 10*time.Millisecond)
+
+		conn, err := grpc.Dial(testYorkie.RPCAddr(), grpc.WithInsecure())
+		assert.NoError(t, err)
+		defer func() {
+			assert.NoError(t, conn.Close())
+		}()
+		admin := api.NewAdminClient(conn)
+
+		adminCtx := metadata.AppendToOutgoingContext(ctx, "authorization", testhelper.AdminToken)
+		resp, err := admin.BroadcastDocument(adminCtx, &api.BroadcastDocumentRequest{
+			DocumentKey: converter.ToDocumentKeys(doc.Key())[0],
+			Type:        "archived",
+			Payload:     []byte("bye"),
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{clientID}, resp.Recipients)
+
+		watchResp := <-rch
+		assert.NoError(t, watchResp.Err)
+		assert.Equal(t, doc.Key().BSONKey(), watchResp.Keys[0].BSONKey())
+		assert.Equal(t, resp.BroadcastId, watchResp.Broadcast.ID)
+		assert.Equal(t, "archived", watchResp.Broadcast.Type)
+		assert.Equal(t, []byte("bye"), watchResp.Broadcast.Payload)
+		assert.Equal(t, doc.Key().BSONKey(), watchResp.Broadcast.Key.BSONKey())
+
+		assert.NoError(t, cli.AcknowledgeBroadcast(ctx, watchResp.Broadcast))
+
+		status, err := admin.GetBroadcast(adminCtx, &api.GetBroadcastRequest{BroadcastId: resp.BroadcastId})
+		assert.NoError(t, err)
+		assert.Equal(t, "archived", status.Broadcast.Type)
+		assert.Equal(t, []string{clientID}, status.Delivered)
+		assert.Equal(t, []string{clientID}, status.Acknowledged)
+	})
+}
+
+// countingCompressor is a gzip compressor counting the compressed messages.
+type countingCompressor struct {
+	encoding.Compressor
+	count int64
+}
+
+func (c *countingCompressor) Compress(w io.Writer) (io.WriteCloser, error) {
+	atomic.AddInt64(&c.count, 1)
+	return c.Compressor.Compress(w)
+}
+
+func (c *countingCompressor) Name() string {
+	return "counting-gzip"
+}
+
+func TestTags(t *testing.T) {
+	clients := getActivatedClients(t, 1)
+	cli := clients[0]
+	defer func() {
+		cleanupClients(t, clients)
+	}()
+
+	t.Run("tag test", func(t *testing.T) {
+		ctx := context.Background()
+
+		doc := document.New(testhelper.Collection, t.Name())
+		assert.NoError(t, cli.Attach(ctx, doc))
+		assert.NoError(t, doc.Update(func(root *proxy.ObjectProxy) error {
+			root.SetString("title", "draft")
+			return nil
+		}))
+		assert.NoError(t, cli.Sync(ctx))
+
+		tag, err := cli.CreateTag(ctx, doc.Key(), "v1.2 draft")
+		assert.NoError(t, err)
+		assert.Equal(t, "v1.2 draft", tag.Name)
+		assert.Equal(t, doc.Checkpoint().ServerSeq, tag.ServerSeq)
+
+		_, err = cli.CreateTag(ctx, doc.Key(), "v1.2 draft")
+		assert.Equal(t, codes.AlreadyExists, status.Code(err))
+
+		assert.NoError(t, doc.Update(func(root *proxy.ObjectProxy) error {
+			root.SetString("title", "final")
+			return nil
+		}))
+		assert.NoError(t, cli.Sync(ctx))
+		_, err = cli.CreateTag(ctx, doc.Key(), "v1.2")
+		assert.NoError(t, err)
+
+		tags, err := cli.Tags(ctx, doc.Key())
+		assert.NoError(t, err)
+		assert.Len(t, tags, 2)
+		assert.Equal(t, "v1.2 draft", tags[0].Name)
+		assert.Equal(t, "v1.2", tags[1].Name)
+
+		tagged, err := cli.OpenTag(ctx, doc.Key(), "v1.2 draft")
+		assert.NoError(t, err)
+		assert.Equal(t, `{"title":"draft"}`, tagged.Marshal())
+		assert.True(t, tagged.IsReadOnly())
+		assert.Equal(t, document.ErrDocumentReadOnly, tagged.Update(func(root *proxy.ObjectProxy) error {
+			root.SetString("title", "edited")
+			return nil
+		}))
+		assert.Equal(t, document.ErrDocumentReadOnly, cli.Attach(ctx, tagged))
+
+		_, err = cli.OpenTag(ctx, doc.Key(), "v2")
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+}
+
+func TestBranch(t *testing.T) {
+	clients := getActivatedClients(t, 2)
+	c1, c2 := clients[0], clients[1]
+	defer func() {
+		cleanupClients(t, clients)
+	}()
+
+	t.Run("fork and merge test", func(t *testing.T) {
+		ctx := context.Background()
+
+		doc := document.New(testhelper.Collection, t.Name())
+		assert.NoError(t, c1.Attach(ctx, doc))
+		assert.NoError(t, doc.Update(func(root *proxy.ObjectProxy) error {
+			root.SetString("title", "v1")
+			root.SetString("body", "hello")
+			return nil
+		}))
+		assert.NoError(t, c1.Sync(ctx))
+
+		branchKey := &key.Key{Collection: testhelper.Collection, Document: t.Name() + "-draft"}
+		assert.NoError(t, c1.Fork(ctx, doc.Key(), branchKey))
+		err := c1.Fork(ctx, doc.Key(), branchKey)
+		assert.Equal(t, codes.AlreadyExists, status.Code(err))
+
+		branch := document.New(branchKey.Collection, branchKey.Document)
+		assert.NoError(t, c2.Attach(ctx, branch))
+		assert.Equal(t, doc.Marshal(), branch.Marshal())
+
+		// edit the branch and the document concurrently.
+		assert.NoError(t, branch.Update(func(root *proxy.ObjectProxy) error {
+			root.SetString("body", "reviewed")
+			return nil
+		}))
+		assert.NoError(t, branch.Update(func(root *proxy.ObjectProxy) error {
+			root.SetString("note", "lgtm")
+			return nil
+		}))
+		assert.NoError(t, c2.Sync(ctx))
+		assert.NoError(t, doc.Update(func(root *proxy.ObjectProxy) error {
+			root.SetString("title", "v2")
+			return nil
+		}))
+		assert.NoError(t, c1.Sync(ctx))
+
+		merged, err := c2.Merge(ctx, branchKey)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, merged)
+
+		assert.NoError(t, c1.Sync(ctx))
+		assert.Equal(t, `{"body":"reviewed","note":"lgtm","title":"v2"}`, doc.Marshal())
+
+		// only the changes since the last merge are merged.
+		merged, err = c2.Merge(ctx, branchKey)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, merged)
+
+		_, err = c1.Merge(ctx, doc.Key())
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	})
+
+	t.Run("merge conflict test", func(t *testing.T) {
+		ctx := context.Background()
+
+		doc := document.New(testhelper.Collection, t.Name())
+		assert.NoError(t, c1.Attach(ctx, doc))
+		assert.NoError(t, doc.Update(func(root *proxy.ObjectProxy) error {
+			root.SetString("title", "v1")
+			return nil
+		}))
+		assert.NoError(t, c1.Sync(ctx))
+
+		branchKey := &key.Key{Collection: testhelper.Collection, Document: t.Name() + "-draft"}
+		assert.NoError(t, c1.Fork(ctx, doc.Key(), branchKey))
+		branch := document.New(branchKey.Collection, branchKey.Document)
+		assert.NoError(t, c1.Attach(ctx, branch))
+
+		// the tickets of c1 in the branch and in the document can collide.
+		assert.NoError(t, branch.Update(func(root *proxy.ObjectProxy) error {
+			root.SetString("body", "draft")
+			return nil
+		}))
+		assert.NoError(t, doc.Update(func(root *proxy.ObjectProxy) error {
+			root.SetString("title", "v2")
+			return nil
+		}))
+		assert.NoError(t, c1.Sync(ctx))
+
+		_, err := c1.Merge(ctx, branchKey)
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+		assert.Equal(t, `{"title":"v2"}`, doc.Marshal())
+	})
+}
+
+func TestSessionResumption(t *testing.T) {
+	clients := getActivatedClients(t, 1)
+	peer := clients[0]
+	defer func() {
+		cleanupClients(t, clients)
+	}()
+
+	t.Run("resume session test", func(t *testing.T) {
+		ctx := context.Background()
+		clientKey := t.Name()
+
+		cli, err := client.NewClient(testYorkie.RPCAddr(), client.Option{Key: clientKey})
+		assert.NoError(t, err)
+		assert.NoError(t, cli.Activate(ctx))
+		sessionToken := cli.SessionToken()
+		assert.NotEmpty(t, sessionToken)
+
+		doc := document.New(testhelper.Collection, t.Name())
+		assert.NoError(t, cli.Attach(ctx, doc))
+		assert.NoError(t, doc.Update(func(root *proxy.ObjectProxy) error {
+			root.SetString("k1", "v1")
+			return nil
+		}))
+		assert.NoError(t, cli.Sync(ctx))
+		assert.NoError(t, cli.Close())
+
+		invalid, err := client.NewClient(testYorkie.RPCAddr(), client.Option{Key: clientKey, SessionToken: "invalid"})
+		assert.NoError(t, err)
+		assert.Equal(t, client.ErrInvalidSessionToken, invalid.Activate(ctx))
+
+		resumed, err := client.NewClient(testYorkie.RPCAddr(), client.Option{
+			Key:          clientKey,
+			SessionToken: sessionToken,
+		})
+		assert.NoError(t, err)
+		defer func() {
+			assert.NoError(t, resumed.Close())
+		}()
+		assert.NoError(t, resumed.Activate(ctx))
+		assert.Equal(t, sessionToken, resumed.SessionToken())
+		assert.Equal(t, []*key.Key{doc.Key()}, resumed.ResumableDocuments())
+
+		replica := document.New(testhelper.Collection, t.Name())
+		assert.NoError(t, resumed.Attach(ctx, replica))
+		assert.Equal(t, `{"k1":"v1"}`, replica.Marshal())
+		assert.Empty(t, resumed.ResumableDocuments())
+
+		assert.NoError(t, replica.Update(func(root *proxy.ObjectProxy) error {
+			root.SetString("k2", "v2")
+			return nil
+		}))
+		assert.NoError(t, resumed.Sync(ctx))
+
+		doc2 := document.New(testhelper.Collection, t.Name())
+		assert.NoError(t, peer.Attach(ctx, doc2))
+		assert.Equal(t, `{"k1":"v1","k2":"v2"}`, doc2.Marshal())
+	})
+
+	t.Run("new session test", func(t *testing.T) {
+		ctx := context.Background()
+		clientKey := t.Name()
+
+		cli, err := client.NewClient(testYorkie.RPCAddr(), client.Option{Key: clientKey})
+		assert.NoError(t, err)
+		assert.NoError(t, cli.Activate(ctx))
+		sessionToken := cli.SessionToken()
+
+		doc := document.New(testhelper.Collection, t.Name())
+		assert.NoError(t, cli.Attach(ctx, doc))
+		assert.NoError(t, cli.Close())
+
+		// an invalid token doesn't affect the current session.
+		invalid, err := client.NewClient(testYorkie.RPCAddr(), client.Option{Key: clientKey, SessionToken: "invalid"})
+		assert.NoError(t, err)
+		assert.Equal(t, client.ErrInvalidSessionToken, invalid.Activate(ctx))
+
+		// a client without the token starts a new session without the
+		// attachments of the previous one.
+		renewed, err := client.NewClient(testYorkie.RPCAddr(), client.Option{Key: clientKey})
+		assert.NoError(t, err)
+		assert.NoError(t, renewed.Activate(ctx))
+		assert.NotEqual(t, sessionToken, renewed.SessionToken())
+		assert.Empty(t, renewed.ResumableDocuments())
+		assert.NoError(t, renewed.Close())
+
+		stale, err := client.NewClient(testYorkie.RPCAddr(), client.Option{
+			Key:          clientKey,
+			SessionToken: sessionToken,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, client.ErrInvalidSessionToken, stale.Activate(ctx))
+
+		resumed, err := client.NewClient(testYorkie.RPCAddr(), client.Option{
+			Key:          clientKey,
+			SessionToken: renewed.SessionToken(),
+		})
+		assert.NoError(t, err)
+		assert.NoError(t, resumed.Activate(ctx))
+		assert.Empty(t, resumed.ResumableDocuments())
+		assert.NoError(t, resumed.Close())
+	})
+}
+
+func TestHierarchicalKeys(t *testing.T) {
+	clients := getActivatedClients(t, 2)
+	c1 := clients[0]
+	c2 := clients[1]
+	defer func() {
+		cleanupClients(t, clients)
+	}()
+
+	t.Run("watch prefix test", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		prefix, err := key.NewPrefix(testhelper.Collection, t.Name()+"/workspace-42/")
+		assert.NoError(t, err)
+		rch := c1.WatchPrefixes(ctx, []*key.Prefix{prefix})
+
+		outside := document.New(testhelper.Collection, t.Name()+"/workspace-420/a")
+		inside := document.New(testhelper.Collection, t.Name()+"/workspace-42/designs/a")
+		for _, doc := range []*document.Document{outside, inside} {
+			assert.NoError(t, c2.Attach(ctx, doc))
+			assert.NoError(t, doc.Update(func(root *proxy.ObjectProxy) error {
+				root.SetString("k1", "v1")
+				return nil
+			}))
+			assert.NoError(t, c2.Sync(ctx, doc.Key()))
+		}
+
+		resp := <-rch
+		assert.NoError(t, resp.Err)
+		assert.Equal(t, inside.Key().BSONKey(), resp.Keys[0].BSONKey())
+	})
+
+	t.Run("list documents test", func(t *testing.T) {
+		ctx := context.Background()
+
+		for _, path := range []string{"b", "a", "designs/logo"} {
+			doc := document.New(testhelper.Collection, t.Name()+"/workspace-42/"+path)
+			assert.NoError(t, c1.Attach(ctx, doc))
+		}
+		assert.NoError(t, c1.Attach(ctx, document.New(testhelper.Collection, t.Name()+"/workspace-420/a")))
+
+		conn, err := grpc.Dial(testYorkie.RPCAddr(), grpc.WithInsecure())
+		assert.NoError(t, err)
+		defer func() {
+			assert.NoError(t, conn.Close())
+		}()
+
+		adminCtx := metadata.AppendToOutgoingContext(ctx, "authorization", testhelper.AdminToken)
+		resp, err := api.NewAdminClient(conn).ListDocuments(adminCtx, &api.ListDocumentsRequest{
+			KeyPrefix: &api.DocumentKey{
+				Collection: testhelper.Collection,
+				Document:   t.Name() + "/workspace-42",
+			},
+		})
+		assert.NoError(t, err)
+		var paths []string
+		for _, k := range converter.FromDocumentKeys(resp.DocumentKeys) {
+			paths = append(paths, strings.TrimPrefix(k.Document, t.Name()+"/"))
+		}
+		assert.Equal(t, []string{"workspace-42/a", "workspace-42/b", "workspace-42/designs/logo"}, paths)
+
+		_, err = api.NewAdminClient(conn).ListDocuments(adminCtx, &api.ListDocumentsRequest{
+			KeyPrefix: &api.DocumentKey{Collection: testhelper.Collection, Document: "a//b"},
+		})
+		assert.Equal(t, codes.InvalidArgument, status.Convert(err).Code())
+	})
+
+	t.Run("invalid key test", func(t *testing.T) {
+		ctx := context.Background()
+
+		doc := document.New(testhelper.Collection, t.Name()+"/../a")
+		assert.True(t, errors.Is(c1.Attach(ctx, doc), key.ErrInvalidKey))
+	})
+}
+
+func TestLabels(t *testing.T) {
+	clients := getActivatedClients(t, 1)
+	cli := clients[0]
+	defer func() {
+		cleanupClients(t, clients)
+	}()
+
+	t.Run("labels test", func(t *testing.T) {
+		ctx := context.Background()
+
+		d1 := document.New(testhelper.Collection, t.Name()+"/d1")
+		d2 := document.New(testhelper.Collection, t.Name()+"/d2")
+		assert.NoError(t, cli.Attach(ctx, d1))
+		assert.NoError(t, cli.Attach(ctx, d2))
+
+		l, err := cli.UpdateLabels(ctx, d1.Key(), map[string]string{"team": "design", "status": "draft"}, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"team": "design", "status": "draft"}, l)
+		l, err = cli.UpdateLabels(ctx, d1.Key(), map[string]string{"starred": ""}, []string{"status"})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"team": "design", "starred": ""}, l)
+		_, err = cli.UpdateLabels(ctx, d2.Key(), map[string]string{"team": "infra"}, nil)
+		assert.NoError(t, err)
+
+		l, err = cli.Labels(ctx, d1.Key())
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"team": "design", "starred": ""}, l)
+
+		_, err = cli.UpdateLabels(ctx, d1.Key(), map[string]string{"a.b": "c"}, nil)
+		assert.Equal(t, codes.InvalidArgument, status.Convert(err).Code())
+
+		conn, err := grpc.Dial(testYorkie.RPCAddr(), grpc.WithInsecure())
+		assert.NoError(t, err)
+		defer func() {
+			assert.NoError(t, conn.Close())
+		}()
+		admin := api.NewAdminClient(conn)
+
+		adminCtx := metadata.AppendToOutgoingContext(ctx, "authorization", testhelper.AdminToken)
+		resp, err := admin.ListDocuments(adminCtx, &api.ListDocumentsRequest{
+			KeyPrefix:     &api.DocumentKey{Collection: testhelper.Collection, Document: t.Name()},
+			LabelSelector: "team=design,starred",
+		})
+		assert.NoError(t, err)
+		assert.Len(t, resp.Documents, 1)
+		assert.Equal(t, d1.Key().BSONKey(), converter.FromDocumentKey(resp.Documents[0].Key).BSONKey())
+		assert.Equal(t, "design", resp.Documents[0].Labels["team"])
+
+		updated, err := admin.UpdateDocumentLabels(adminCtx, &api.UpdateDocumentLabelsRequest{
+			DocumentKey: converter.ToDocumentKey(d2.Key()),
+			Set:         map[string]string{"team": "design"},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"team": "design"}, updated.Labels)
+
+		resp, err = admin.ListDocuments(adminCtx, &api.ListDocumentsRequest{
+			KeyPrefix:     &api.DocumentKey{Collection: testhelper.Collection, Document: t.Name()},
+			LabelSelector: "team=design",
+		})
+		assert.NoError(t, err)
+		assert.Len(t, resp.Documents, 2)
+
+		_, err = admin.ListDocuments(adminCtx, &api.ListDocumentsRequest{
+			KeyPrefix:     &api.DocumentKey{Collection: testhelper.Collection},
+			LabelSelector: "a.b=c",
+		})
+		assert.Equal(t, codes.InvalidArgument, status.Convert(err).Code())
+	})
+}
+
+func TestCompression(t *testing.T) {
+	compressor := &countingCompressor{Compressor: encoding.GetCompressor(client.GzipCompression)}
+	encoding.RegisterCompressor(compressor)
+
+	t.Run("compression threshold test", func(t *testing.T) {
+		ctx := context.Background()
+		cli, err := client.NewClient(testYorkie.RPCAddr(), client.Option{
+			Compression:          compressor.Name(),
+			CompressionThreshold: 1024,
+		})
+		assert.NoError(t, err)
+		assert.NoError(t, cli.Activate(ctx))
+		defer func() {
+			cleanupClients(t, []*client.Client{cli})
+		}()
+
+		// tiny requests are not compressed.
+		assert.Equal(t, int64(0), atomic.LoadInt64(&compressor.count))
+
+		doc := document.New(testhelper.Collection, t.Name())
+		assert.NoError(t, doc.Update(func(root *proxy.ObjectProxy) error {
+			root.SetNewText("k1").Edit(0, 0, strings.Repeat("yorkie", 1024))
+			return nil
+		}))
+		assert.NoError(t, cli.Attach(ctx, doc))
+
+		// the request and its response are compressed.
+		assert.Equal(t, int64(2), atomic.LoadInt64(&compressor.count))
+
+		// the compression can be disabled per call.
+		assert.NoError(t, doc.Update(func(root *proxy.ObjectProxy) error {
+			root.GetText("k1").Edit(0, 0, strings.Repeat("yorkie", 1024))
+			return nil
+		}))
+		assert.NoError(t, cli.Sync(client.WithCompression(ctx, "")))
+		assert.Equal(t, int64(2), atomic.LoadInt64(&compressor.count))
+	})
+
+	t.Run("unknown compressor test", func(t *testing.T) {
+		_, err := client.NewClient(testYorkie.RPCAddr(), client.Option{Compression: "unknown"})
+		assert.Equal(t, client.ErrUnknownCompressor, err)
+	})
+}
+
+func TestLargeSnapshot(t *testing.T) {
+	t.Run("chunked snapshot test", func(t *testing.T) {
+		ctx := context.Background()
+		c1, err := client.NewClient(testYorkie.RPCAddr())
+		assert.NoError(t, err)
+		c2, err := client.NewClient(testYorkie.RPCAddr(), client.Option{
+			MaxMessageSize: 64 * 1024,
+		})
+		assert.NoError(t, err)
+		assert.NoError(t, c1.Activate(ctx))
+		assert.NoError(t, c2.Activate(ctx))
+		defer func() {
+			cleanupClients(t, []*client.Client{c1, c2})
+		}()
+
+		d1 := document.New(testhelper.Collection, t.Name())
+		assert.NoError(t, c1.Attach(ctx, d1))
+		for i := 0; i < testhelper.SnapshotThreshold+2; i++ {
+			assert.NoError(t, d1.Update(func(root *proxy.ObjectProxy) error {
+				root.SetString(fmt.Sprintf("k%d", i), strings.Repeat("yorkie", 4*1024))
+				return nil
+			}))
+			assert.NoError(t, c1.Sync(ctx))
+		}
+
+		// the snapshot is larger than the messages c2 can receive, so it is
+		// fetched in chunks.
+		snapshot, err := converter.ObjectToBytes(d1.RootObject())
+		assert.NoError(t, err)
+		assert.Greater(t, len(snapshot), 64*1024)
+
+		d2 := document.New(testhelper.Collection, t.Name())
+		assert.NoError(t, c2.Attach(ctx, d2))
+		assert.Equal(t, d1.Marshal(), d2.Marshal())
+	})
+}
+
+func TestUsage(t *testing.T) {
+	t.Run("usage test", func(t *testing.T) {
+		ctx := context.Background()
+		reports := make(chan map[string]client.Usage, 1)
+		c1, err := client.NewClient(testYorkie.RPCAddr(), client.Option{
+			UsageReporter: func(usages map[string]client.Usage) {
+				select {
+				case reports <- usages:
+				default:
+				}
+			},
+			UsageReportInterval: 10 * time.Millisecond,
+		})
+		assert.NoError(t, err)
+		c2, err := client.NewClient(testYorkie.RPCAddr())
+		assert.NoError(t, err)
+		assert.NoError(t, c1.Activate(ctx))
+		assert.NoError(t, c2.Activate(ctx))
+		defer func() {
+			cleanupClients(t, []*client.Client{c1, c2})
+		}()
+
+		d1 := document.New(testhelper.Collection, t.Name())
+		assert.NoError(t, c1.Attach(ctx, d1))
+		assert.NoError(t, d1.Update(func(root *proxy.ObjectProxy) error {
+			root.SetString("k1", "v1")
+			root.SetString("k2", "v2")
+			root.SetNewArray("k3").AddInteger(1)
+			return nil
+		}))
+		assert.NoError(t, c1.Sync(ctx))
+
+		d2 := document.New(testhelper.Collection, t.Name())
+		assert.NoError(t, c2.Attach(ctx, d2))
+
+		usage := c1.Usage(d1.Key())
+		assert.Equal(t, int64(4), usage.PushedOperations)
+		assert.Equal(t, int64(0), usage.PulledOperations)
+		assert.Greater(t, usage.PushedBytes, int64(0))
+		assert.Greater(t, usage.PulledBytes, int64(0))
+
+		usage = c2.Usage(d2.Key())
+		assert.Equal(t, int64(0), usage.PushedOperations)
+		assert.Equal(t, int64(4), usage.PulledOperations)
+		assert.Equal(t, usage, c2.Usages()[d2.Key().BSONKey()])
+
+		// the usages are reported periodically.
+		timeout := time.After(time.Second)
+		for {
+			select {
+			case usages := <-reports:
+				if _, ok := usages[d1.Key().BSONKey()]; !ok {
+					continue
+				}
+			case <-timeout:
+				t.Fatal("usages are not reported")
+			}
+			break
+		}
+	})
+}
+
+func TestSyncStatus(t *testing.T) {
+	clients := getActivatedClients(t, 1)
+	cli := clients[0]
+	defer func() {
+		cleanupClients(t, clients)
+	}()
+
+	t.Run("sync status test", func(t *testing.T) {
+		ctx := context.Background()
+
+		doc := document.New(testhelper.Collection, t.Name())
+		var states []document.SyncState
+		doc.SubscribeSyncStatus(func(status document.SyncStatus) {
+			states = append(states, status.State)
+		})
+		assert.NoError(t, cli.Attach(ctx, doc))
+		assert.Equal(t, document.Synced, doc.SyncStatus().State)
+
+		err := doc.Update(func(root *proxy.ObjectProxy) error {
+			root.SetString("k1", "v1")
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, document.PendingLocalChanges, doc.SyncStatus().State)
+
+		assert.NoError(t, cli.Sync(ctx))
+		assert.Equal(t, document.Synced, doc.SyncStatus().State)
+		assert.Equal(t, []document.SyncState{
+			document.Syncing,
+			document.Synced,
+			document.PendingLocalChanges,
+			document.Syncing,
+			document.Synced,
+		}, states)
+	})
+}
+
+// recordingLogger is a client.Logger recording the messages of the logs.
+type recordingLogger struct {
+	mu       sync.Mutex
+	messages []string
+	fields   []client.Field
+}
+
+func (l *recordingLogger) Debug(msg string, fields ...client.Field) { l.record(msg, fields) }
+func (l *recordingLogger) Info(msg string, fields ...client.Field)  { l.record(msg, fields) }
+func (l *recordingLogger) Warn(msg string, fields ...client.Field)  { l.record(msg, fields) }
+func (l *recordingLogger) Error(msg string, fields ...client.Field) { l.record(msg, fields) }
+
+func (l *recordingLogger) record(msg string, fields []client.Field) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.messages = append(l.messages, msg)
+	l.fields = append(l.fields, fields...)
+}
+
+func TestLogger(t *testing.T) {
+	t.Run("injected logger test", func(t *testing.T) {
+		ctx := context.Background()
+
+		logger := &recordingLogger{}
+		cli, err := client.NewClient(testYorkie.RPCAddr(), client.Option{Logger: logger})
+		assert.NoError(t, err)
+		assert.NoError(t, cli.Activate(ctx))
+		defer func() {
+			cleanupClients(t, []*client.Client{cli})
+		}()
+
+		// updating the presence of a document not attached fails in the agent.
+		doc := document.New(testhelper.Collection, t.Name())
+		assert.Error(t, cli.UpdatePresence(ctx, doc.Key(), map[string]string{"name": "c1"}))
+		assert.Equal(t, []string{"fail to update presence"}, logger.messages)
+		assert.Equal(t, client.Field{Key: "document", Value: doc.Key().BSONKey()}, logger.fields[0])
+	})
+}
+
+func TestLocalFirst(t *testing.T) {
+	clients := getActivatedClients(t, 2)
+	c1 := clients[0]
+	c2 := clients[1]
+	defer func() {
+		cleanupClients(t, clients)
+	}()
+
+	newOfflineDoc := func(t *testing.T, k, v string) *document.Document {
+		doc := document.New(testhelper.Collection, t.Name())
+		err := doc.Update(func(root *proxy.ObjectProxy) error {
+			root.SetString(k, v)
+			return nil
+		})
+		assert.NoError(t, err)
+		return doc
+	}
+
+	t.Run("attach later test", func(t *testing.T) {
+		ctx := context.Background()
+
+		d1 := newOfflineDoc(t, "k1", "v1")
+		assert.NoError(t, c1.Attach(ctx, d1, client.AttachOption{
+			OnCollision: client.FailOnCollision,
+		}))
+		assert.False(t, d1.HasLocalChanges())
+
+		d2 := document.New(testhelper.Collection, t.Name())
+		assert.NoError(t, c2.Attach(ctx, d2))
+		assert.Equal(t, `{"k1":"v1"}`, d2.Marshal())
+	})
+
+	t.Run("collision test", func(t *testing.T) {
+		ctx := context.Background()
+
+		d1 := newOfflineDoc(t, "k1", "v1")
+		assert.NoError(t, c1.Attach(ctx, d1))
+
+		d2 := newOfflineDoc(t, "k2", "v2")
+		err := c2.Attach(ctx, d2, client.AttachOption{OnCollision: client.FailOnCollision})
+		assert.Equal(t, client.ErrDocumentAlreadyExists, err)
+		assert.False(t, d2.IsAttached())
+
+		assert.NoError(t, c2.Attach(ctx, d2, client.AttachOption{OnCollision: client.RenameOnCollision}))
+		assert.Equal(t, t.Name()+"-1", d2.Key().Document)
+		assert.Equal(t, `{"k2":"v2"}`, d2.Marshal())
+
+		d3 := newOfflineDoc(t, "k3", "v3")
+		assert.NoError(t, c2.Attach(ctx, d3))
+		assert.Equal(t, t.Name(), d3.Key().Document)
+		assert.Equal(t, `{"k1":"v1","k3":"v3"}`, d3.Marshal())
+	})
+}
+
+func TestEncryptedDocument(t *testing.T) {
+	cipher := client.NewAESCipher(func(docKey *key.Key) ([]byte, error) {
+		return bytes.Repeat([]byte{1}, 32), nil
+	})
+
+	var clients []*client.Client
+	for i := 0; i < 2; i++ {
+		c, err := client.NewClient(testYorkie.RPCAddr(), client.Option{Cipher: cipher})
+		assert.NoError(t, err)
+		assert.NoError(t, c.Activate(context.Background()))
+		clients = append(clients, c)
+	}
+	c1 := clients[0]
+	c2 := clients[1]
+	defer func() {
+		cleanupClients(t, clients)
+	}()
+
+	t.Run("end-to-end encryption test", func(t *testing.T) {
+		ctx := context.Background()
+
+		d1 := document.New(testhelper.Collection, t.Name())
+		err := c1.Attach(ctx, d1)
+		assert.NoError(t, err)
+
+		err = d1.Update(func(root *proxy.ObjectProxy) error {
+			root.SetString("k1", "v1")
+			root.SetInteger("k2", 2)
+			root.SetNewText("k3").Edit(0, 0, "ABCD")
+			return nil
+		})
+		assert.NoError(t, err)
+
+		// NOTE: The changes exceed the snapshot threshold, but encrypted
+		// documents are always synchronized with changes.
+		for i := 0; i < testhelper.SnapshotThreshold; i++ {
+			err = d1.Update(func(root *proxy.ObjectProxy) error {
+				root.GetText("k3").Edit(1, 2, "X")
+				return nil
+			})
+			assert.NoError(t, err)
+		}
+		assert.NoError(t, c1.Sync(ctx))
+
+		d2 := document.New(testhelper.Collection, t.Name())
+		err = c2.Attach(ctx, d2)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"k1":"v1","k2":2,"k3":"AXCD"}`, d2.Marshal())
+
+		err = d2.Update(func(root *proxy.ObjectProxy) error {
+			root.GetText("k3").Edit(0, 1, "1234")
+			return nil
+		})
+		assert.NoError(t, err)
+
+		syncClientsThenAssertEqual(t, []clientAndDocPair{{c1, d1}, {c2, d2}})
+	})
+
+	t.Run("encryption mismatch test", func(t *testing.T) {
+		ctx := context.Background()
+		plainClients := getActivatedClients(t, 1)
+		defer func() {
+			cleanupClients(t, plainClients)
+		}()
+
+		d1 := document.New(testhelper.Collection, t.Name())
+		err := c1.Attach(ctx, d1)
+		assert.NoError(t, err)
+		err = d1.Update(func(root *proxy.ObjectProxy) error {
+			root.SetString("k1", "v1")
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.NoError(t, c1.Sync(ctx))
+
+		d2 := document.New(testhelper.Collection, t.Name())
+		err = d2.Update(func(root *proxy.ObjectProxy) error {
+			root.SetString("k1", "v2")
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Error(t, plainClients[0].Attach(ctx, d2))
+	})
+}
+
 type clientAndDocPair struct {
 	cli *client.Client
 	doc *document.Document
@@ -0,0 +1,87 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/encoding/gzip"
+)
+
+// GzipCompression is the name of the gzip compressor, which is registered in
+// both the client and the agent.
+const GzipCompression = gzip.Name
+
+// ErrUnknownCompressor occurs when the configured compressor is not
+// registered. Compressors other than gzip, e.g. zstd, can be registered with
+// encoding.RegisterCompressor of gRPC in both the client and the agent.
+var ErrUnknownCompressor = errors.New("unknown compressor")
+
+// compressionKey is the key of the compressor overriding the one of the
+// client in the context of a call.
+type compressionKey struct{}
+
+// WithCompression returns a context that makes the calls with it use the
+// given compressor instead of the one configured in the client. The calls
+// are not compressed if the compressor is empty.
+func WithCompression(ctx context.Context, compressor string) context.Context {
+	return context.WithValue(ctx, compressionKey{}, compressor)
+}
+
+// sizer is a message which knows its size in Protobuf format.
+type sizer interface {
+	Size() int
+}
+
+// compressionInterceptor returns an interceptor that compresses the requests
+// of unary calls with the given compressor unless they are smaller than the
+// given threshold in bytes. The agent compresses the responses of compressed
+// requests with the same compressor.
+func compressionInterceptor(compressor string, threshold int) grpc.UnaryClientInterceptor {
+	return func(
+		ctx context.Context,
+		method string,
+		req, reply interface{},
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption,
+	) error {
+		name := compressor
+		if override, ok := ctx.Value(compressionKey{}).(string); ok {
+			name = override
+		}
+
+		if name != "" {
+			if msg, ok := req.(sizer); !ok || msg.Size() >= threshold {
+				opts = append(opts, grpc.UseCompressor(name))
+			}
+		}
+
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// checkCompressor checks that the given compressor is registered.
+func checkCompressor(compressor string) error {
+	if compressor != "" && encoding.GetCompressor(compressor) == nil {
+		return ErrUnknownCompressor
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+
+	"github.com/yorkie-team/yorkie/api/converter"
+	api "github.com/yorkie-team/yorkie/api/yorkie/v1"
+	"github.com/yorkie-team/yorkie/pkg/document/key"
+)
+
+// UpdateLabels sets the given labels of the document of the given key and
+// removes the labels of the given keys. It returns the labels of the document
+// after the update. Labels can be used to organize documents, e.g. to list
+// the documents of a team with the admin API.
+func (c *Client) UpdateLabels(
+	ctx context.Context,
+	docKey *key.Key,
+	set map[string]string,
+	remove []string,
+	opts ...AttachOption,
+) (map[string]string, error) {
+	if c.status != activated {
+		return nil, ErrClientNotActivated
+	}
+
+	res, err := c.client.UpdateLabels(ctx, &api.UpdateLabelsRequest{
+		ClientId:    c.id.String(),
+		DocumentKey: converter.ToDocumentKey(docKey),
+		AccessToken: accessTokenOf(opts),
+		Set:         set,
+		Remove:      remove,
+	})
+	if err != nil {
+		c.logger.Error("fail to update labels", Field{"document", docKey.BSONKey()}, Field{"error", err})
+		return nil, err
+	}
+
+	return res.Labels, nil
+}
+
+// Labels returns the labels of the document of the given key.
+func (c *Client) Labels(ctx context.Context, docKey *key.Key, opts ...AttachOption) (map[string]string, error) {
+	if c.status != activated {
+		return nil, ErrClientNotActivated
+	}
+
+	res, err := c.client.GetLabels(ctx, &api.GetLabelsRequest{
+		ClientId:    c.id.String(),
+		DocumentKey: converter.ToDocumentKey(docKey),
+		AccessToken: accessTokenOf(opts),
+	})
+	if err != nil {
+		c.logger.Error("fail to get labels", Field{"document", docKey.BSONKey()}, Field{"error", err})
+		return nil, err
+	}
+
+	return res.Labels, nil
+}
@@ -0,0 +1,96 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"go.uber.org/zap"
+
+	"github.com/yorkie-team/yorkie/pkg/log"
+)
+
+// Field is a structured field of a log entry.
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// Logger is the logger of the client. Applications can implement it with
+// their own logger to integrate the logs of the client.
+type Logger interface {
+	Debug(msg string, fields ...Field)
+	Info(msg string, fields ...Field)
+	Warn(msg string, fields ...Field)
+	Error(msg string, fields ...Field)
+}
+
+// zapLogger is a Logger writing the logs with a zap logger.
+type zapLogger struct {
+	logger *zap.SugaredLogger
+}
+
+// NewZapLogger creates a Logger writing the logs with the given zap logger.
+// Clients use the package logger of Yorkie by default.
+func NewZapLogger(logger *zap.SugaredLogger) Logger {
+	return &zapLogger{logger: logger.Desugar().WithOptions(zap.AddCallerSkip(1)).Sugar()}
+}
+
+// Debug logs the given message at the debug level.
+func (l *zapLogger) Debug(msg string, fields ...Field) {
+	l.logger.Debugw(msg, keysAndValues(fields)...)
+}
+
+// Info logs the given message at the info level.
+func (l *zapLogger) Info(msg string, fields ...Field) {
+	l.logger.Infow(msg, keysAndValues(fields)...)
+}
+
+// Warn logs the given message at the warn level.
+func (l *zapLogger) Warn(msg string, fields ...Field) {
+	l.logger.Warnw(msg, keysAndValues(fields)...)
+}
+
+// Error logs the given message at the error level.
+func (l *zapLogger) Error(msg string, fields ...Field) {
+	l.logger.Errorw(msg, keysAndValues(fields)...)
+}
+
+func keysAndValues(fields []Field) []interface{} {
+	kvs := make([]interface{}, 0, len(fields)*2)
+	for _, field := range fields {
+		kvs = append(kvs, field.Key, field.Value)
+	}
+	return kvs
+}
+
+// nopLogger is a Logger discarding all the logs.
+type nopLogger struct{}
+
+// NewNopLogger creates a Logger discarding all the logs. It is useful to
+// silence the client in libraries.
+func NewNopLogger() Logger {
+	return nopLogger{}
+}
+
+func (nopLogger) Debug(msg string, fields ...Field) {}
+func (nopLogger) Info(msg string, fields ...Field)  {}
+func (nopLogger) Warn(msg string, fields ...Field)  {}
+func (nopLogger) Error(msg string, fields ...Field) {}
+
+// defaultLogger returns the Logger writing the logs with the package logger.
+func defaultLogger() Logger {
+	return NewZapLogger(log.Named(log.Client))
+}
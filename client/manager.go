@@ -0,0 +1,328 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/yorkie-team/yorkie/pkg/document"
+	"github.com/yorkie-team/yorkie/pkg/document/key"
+)
+
+const (
+	// DefaultSyncInterval is the default interval of the synchronization of
+	// the documents of a DocumentManager.
+	DefaultSyncInterval = 1 * time.Second
+
+	// DefaultCacheSize is the default number of documents a DocumentManager
+	// keeps attached.
+	DefaultCacheSize = 1000
+)
+
+var (
+	ErrDocumentNotAcquired = errors.New("document is not acquired")
+)
+
+// ManagerConfig configures how the DocumentManager handles the documents.
+type ManagerConfig struct {
+	// SyncInterval is the interval of the synchronization of the attached
+	// documents. DefaultSyncInterval is used if it is zero.
+	SyncInterval time.Duration
+
+	// IdleTimeout is the period after which documents that are no longer
+	// acquired are detached. Released documents stay attached until they are
+	// evicted if it is zero.
+	IdleTimeout time.Duration
+
+	// CacheSize is the maximum number of the attached documents. When it is
+	// exceeded, the least recently used documents that are no longer acquired
+	// are detached. DefaultCacheSize is used if it is zero.
+	CacheSize int
+}
+
+// managedDocument is a document attached by the DocumentManager.
+type managedDocument struct {
+	doc        *document.Document
+	refs       int
+	releasedAt time.Time
+	elem       *list.Element
+
+	// mu excludes the synchronization and the detachment of the document
+	// from the accesses in Do. It is acquired after the lock of the manager.
+	mu       sync.Mutex
+	detached bool
+}
+
+// DocumentManager manages the documents of a client for applications handling
+// many documents. Documents are attached when they are acquired for the first
+// time and are synchronized together in the background. Documents that are no
+// longer acquired are detached when they have been idle for the configured
+// period or when the cache is full.
+//
+// Documents are synchronized in the background, so they are only accessed
+// within Do. The client should not be used directly while it is managed.
+type DocumentManager struct {
+	cli  *Client
+	conf ManagerConfig
+
+	mu   sync.Mutex
+	docs map[string]*managedDocument
+	// lru holds the keys of the documents, the most recently used one first.
+	lru *list.List
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewDocumentManager creates a new instance of DocumentManager managing the
+// documents of the given activated client.
+func NewDocumentManager(cli *Client, conf ManagerConfig) *DocumentManager {
+	if conf.SyncInterval == 0 {
+		conf.SyncInterval = DefaultSyncInterval
+	}
+	if conf.CacheSize == 0 {
+		conf.CacheSize = DefaultCacheSize
+	}
+
+	return &DocumentManager{
+		cli:  cli,
+		conf: conf,
+		docs: make(map[string]*managedDocument),
+		lru:  list.New(),
+	}
+}
+
+// Start starts synchronizing the attached documents and detaching the idle
+// ones at the configured interval.
+func (m *DocumentManager) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancel = cancel
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+
+		ticker := time.NewTicker(m.conf.SyncInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if err := m.Sync(ctx); err != nil {
+					m.cli.logger.Error("fail to sync documents", Field{"error", err})
+				}
+				if _, err := m.DetachIdleDocuments(ctx); err != nil {
+					m.cli.logger.Error("fail to detach idle documents", Field{"error", err})
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the background synchronization and detaches all the documents.
+func (m *DocumentManager) Stop(ctx context.Context) error {
+	if m.cancel != nil {
+		m.cancel()
+		m.wg.Wait()
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for k, d := range m.docs {
+		if err := m.detach(ctx, k, d); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Acquire acquires the document of the given key, attaching it if it is not
+// attached yet. The document can be accessed with Do until it is released.
+// Each Acquire should be paired with a Release.
+func (m *DocumentManager) Acquire(ctx context.Context, k *key.Key) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	bsonKey := k.BSONKey()
+	if d, ok := m.docs[bsonKey]; ok {
+		d.refs++
+		m.lru.MoveToFront(d.elem)
+		return nil
+	}
+
+	doc := document.New(k.Collection, k.Document)
+	if err := m.cli.Attach(ctx, doc); err != nil {
+		return err
+	}
+
+	m.docs[bsonKey] = &managedDocument{
+		doc:  doc,
+		refs: 1,
+		elem: m.lru.PushFront(bsonKey),
+	}
+	m.evict(ctx)
+
+	return nil
+}
+
+// Release releases the document of the given key acquired by Acquire. The
+// document stays attached and synchronized until it is detached by the idle
+// timeout or the eviction.
+func (m *DocumentManager) Release(k *key.Key) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	d, ok := m.docs[k.BSONKey()]
+	if !ok || d.refs == 0 {
+		return ErrDocumentNotAcquired
+	}
+
+	d.refs--
+	if d.refs == 0 {
+		d.releasedAt = time.Now()
+	}
+
+	return nil
+}
+
+// Do calls the given function with the acquired document of the given key,
+// excluding the background synchronization of the document. The document
+// must not be used after the function returns.
+func (m *DocumentManager) Do(k *key.Key, fn func(doc *document.Document) error) error {
+	m.mu.Lock()
+	d, ok := m.docs[k.BSONKey()]
+	if !ok || d.refs == 0 {
+		m.mu.Unlock()
+		return ErrDocumentNotAcquired
+	}
+	m.mu.Unlock()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.detached {
+		return ErrDocumentNotAcquired
+	}
+
+	return fn(d.doc)
+}
+
+// Sync synchronizes all the attached documents. The documents are synchronized
+// one by one without holding the lock of the manager, so that documents can be
+// acquired and accessed meanwhile.
+func (m *DocumentManager) Sync(ctx context.Context) error {
+	m.mu.Lock()
+	docs := make([]*managedDocument, 0, len(m.docs))
+	for _, d := range m.docs {
+		docs = append(docs, d)
+	}
+	m.mu.Unlock()
+
+	for _, d := range docs {
+		if err := m.sync(ctx, d); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// sync synchronizes the given document unless it has been detached.
+func (m *DocumentManager) sync(ctx context.Context, d *managedDocument) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.detached {
+		return nil
+	}
+
+	return m.cli.Sync(ctx, d.doc.Key())
+}
+
+// DetachIdleDocuments detaches the documents that have not been acquired for
+// the idle timeout, and returns the number of detached documents.
+func (m *DocumentManager) DetachIdleDocuments(ctx context.Context) (int, error) {
+	if m.conf.IdleTimeout == 0 {
+		return 0, nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	detached := 0
+	idleBefore := time.Now().Add(-m.conf.IdleTimeout)
+	for k, d := range m.docs {
+		if d.refs > 0 || d.releasedAt.After(idleBefore) {
+			continue
+		}
+
+		if err := m.detach(ctx, k, d); err != nil {
+			return detached, err
+		}
+		detached++
+	}
+
+	return detached, nil
+}
+
+// Len returns the number of the attached documents.
+func (m *DocumentManager) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return len(m.docs)
+}
+
+// evict detaches the least recently used documents that are not acquired
+// until the number of the documents fits in the cache.
+func (m *DocumentManager) evict(ctx context.Context) {
+	for elem := m.lru.Back(); elem != nil && len(m.docs) > m.conf.CacheSize; {
+		prev := elem.Prev()
+
+		k := elem.Value.(string)
+		if d := m.docs[k]; d.refs == 0 {
+			if err := m.detach(ctx, k, d); err != nil {
+				m.cli.logger.Error("fail to evict document", Field{"document", k}, Field{"error", err})
+			}
+		}
+
+		elem = prev
+	}
+}
+
+// detach detaches the given document and removes it from the cache.
+func (m *DocumentManager) detach(ctx context.Context, k string, d *managedDocument) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err := m.cli.Detach(ctx, d.doc); err != nil {
+		return err
+	}
+	d.detached = true
+
+	m.lru.Remove(d.elem)
+	delete(m.docs, k)
+	return nil
+}
@@ -0,0 +1,82 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	time2 "time"
+
+	"github.com/yorkie-team/yorkie/api/converter"
+	api "github.com/yorkie-team/yorkie/api/yorkie/v1"
+	"github.com/yorkie-team/yorkie/pkg/document/key"
+)
+
+// Peer is a client that attached or is watching a document.
+type Peer struct {
+	ClientID string
+	Presence map[string]string
+	LastSeen time2.Time
+	Watching bool
+}
+
+// UpdatePresence replaces the presence of this client for the document of the
+// given key, which is delivered to the other peers by Peers.
+func (c *Client) UpdatePresence(ctx context.Context, docKey *key.Key, presence map[string]string) error {
+	if c.status != activated {
+		return ErrClientNotActivated
+	}
+
+	if _, err := c.client.UpdatePresence(ctx, &api.UpdatePresenceRequest{
+		ClientId:    c.id.String(),
+		DocumentKey: converter.ToDocumentKeys(docKey)[0],
+		Presence:    presence,
+	}); err != nil {
+		c.logger.Error("fail to update presence", Field{"document", docKey.BSONKey()}, Field{"error", err})
+		return err
+	}
+
+	return nil
+}
+
+// Peers returns the peers of the document of the given key, including this
+// client, with their presence and the time they were seen last.
+func (c *Client) Peers(ctx context.Context, docKey *key.Key) ([]Peer, error) {
+	if c.status != activated {
+		return nil, ErrClientNotActivated
+	}
+
+	res, err := c.client.GetPeers(ctx, &api.GetPeersRequest{
+		ClientId:    c.id.String(),
+		DocumentKey: converter.ToDocumentKeys(docKey)[0],
+	})
+	if err != nil {
+		c.logger.Error("fail to get peers", Field{"document", docKey.BSONKey()}, Field{"error", err})
+		return nil, err
+	}
+
+	var peers []Peer
+	for _, pbPeer := range res.Peers {
+		peers = append(peers, Peer{
+			ClientID: pbPeer.ClientId,
+			Presence: pbPeer.Presence,
+			LastSeen: time2.Unix(0, pbPeer.LastSeenAt*int64(time2.Millisecond)),
+			Watching: pbPeer.Watching,
+		})
+	}
+
+	return peers, nil
+}
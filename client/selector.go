@@ -0,0 +1,62 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"reflect"
+
+	"github.com/yorkie-team/yorkie/pkg/document"
+	"github.com/yorkie-team/yorkie/pkg/document/json"
+)
+
+// Selector selects a value from the root of a document.
+//
+// The selected value is deep-compared with the previously selected one, so it
+// should not hold the elements of the document, which are changed in place.
+// Select plain values instead, such as the values of primitives or the result
+// of Marshal.
+type Selector func(root *json.Object) interface{}
+
+// SelectEvent is the event delivered to the listener of a selector.
+type SelectEvent struct {
+	Type  document.EventType
+	Value interface{}
+	Prev  interface{}
+}
+
+// SubscribeWithSelector registers the given listener, which is called when the
+// value selected from the given document by the selector is changed by local
+// updates or remote changes. It returns a function that unregisters the
+// listener.
+func SubscribeWithSelector(
+	doc *document.Document,
+	selector Selector,
+	listener func(event SelectEvent),
+) func() {
+	prev := selector(doc.RootObject())
+
+	return doc.Subscribe(func(event document.Event) {
+		value := selector(doc.RootObject())
+		if reflect.DeepEqual(prev, value) {
+			return
+		}
+
+		e := SelectEvent{Type: event.Type, Value: value, Prev: prev}
+		prev = value
+		listener(e)
+	})
+}
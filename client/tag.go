@@ -0,0 +1,131 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	time2 "time"
+
+	"github.com/yorkie-team/yorkie/api/converter"
+	api "github.com/yorkie-team/yorkie/api/yorkie/v1"
+	"github.com/yorkie-team/yorkie/pkg/document"
+	"github.com/yorkie-team/yorkie/pkg/document/key"
+)
+
+// Tag is a named version of a document at a server sequence, e.g.
+// "v1.2 draft".
+type Tag struct {
+	Name      string
+	ServerSeq uint64
+	CreatedAt time2.Time
+}
+
+// CreateTag tags the current server sequence of the document of the given key
+// with the given name. The changes of this client not pushed yet are not
+// included in the tag.
+func (c *Client) CreateTag(ctx context.Context, docKey *key.Key, name string, opts ...AttachOption) (*Tag, error) {
+	if c.status != activated {
+		return nil, ErrClientNotActivated
+	}
+
+	res, err := c.client.CreateTag(ctx, &api.CreateTagRequest{
+		ClientId:    c.id.String(),
+		DocumentKey: converter.ToDocumentKeys(docKey)[0],
+		AccessToken: accessTokenOf(opts),
+		Name:        name,
+	})
+	if err != nil {
+		c.logger.Error("fail to create tag", Field{"document", docKey.BSONKey()}, Field{"error", err})
+		return nil, err
+	}
+
+	return fromTag(res.Tag), nil
+}
+
+// Tags returns the tags of the document of the given key in the order of
+// their server sequences.
+func (c *Client) Tags(ctx context.Context, docKey *key.Key, opts ...AttachOption) ([]*Tag, error) {
+	if c.status != activated {
+		return nil, ErrClientNotActivated
+	}
+
+	res, err := c.client.ListTags(ctx, &api.ListTagsRequest{
+		ClientId:    c.id.String(),
+		DocumentKey: converter.ToDocumentKeys(docKey)[0],
+		AccessToken: accessTokenOf(opts),
+	})
+	if err != nil {
+		c.logger.Error("fail to list tags", Field{"document", docKey.BSONKey()}, Field{"error", err})
+		return nil, err
+	}
+
+	var tags []*Tag
+	for _, pbTag := range res.Tags {
+		tags = append(tags, fromTag(pbTag))
+	}
+
+	return tags, nil
+}
+
+// OpenTag opens the version of the document of the given key at the tag of
+// the given name. The returned document is read-only; it can't be updated or
+// attached.
+func (c *Client) OpenTag(
+	ctx context.Context,
+	docKey *key.Key,
+	name string,
+	opts ...AttachOption,
+) (*document.Document, error) {
+	if c.status != activated {
+		return nil, ErrClientNotActivated
+	}
+
+	res, err := c.client.GetTag(ctx, &api.GetTagRequest{
+		ClientId:    c.id.String(),
+		DocumentKey: converter.ToDocumentKeys(docKey)[0],
+		AccessToken: accessTokenOf(opts),
+		Name:        name,
+	})
+	if err != nil {
+		c.logger.Error("fail to get tag", Field{"document", docKey.BSONKey()}, Field{"error", err})
+		return nil, err
+	}
+
+	return document.ReadOnlyFromSnapshot(
+		docKey.Collection,
+		docKey.Document,
+		res.Tag.ServerSeq,
+		res.Snapshot,
+	)
+}
+
+// accessTokenOf returns the access token of the given options.
+func accessTokenOf(opts []AttachOption) string {
+	if len(opts) == 0 {
+		return ""
+	}
+	return opts[0].AccessToken
+}
+
+// fromTag converts the given Protobuf format to model format.
+func fromTag(pbTag *api.Tag) *Tag {
+	return &Tag{
+		Name:      pbTag.Name,
+		ServerSeq: pbTag.ServerSeq,
+		CreatedAt: time2.Unix(0, pbTag.CreatedAt*int64(time2.Millisecond)),
+	}
+}
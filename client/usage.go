@@ -0,0 +1,159 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"sync"
+	"time"
+
+	api "github.com/yorkie-team/yorkie/api/yorkie/v1"
+	"github.com/yorkie-team/yorkie/pkg/document/key"
+)
+
+// Usage is the amount of the data exchanged with the agent for a document.
+// The sizes are of the change packs in Protobuf format, before compression.
+type Usage struct {
+	// PushedBytes is the size of the change packs pushed to the agent.
+	PushedBytes int64
+
+	// PulledBytes is the size of the change packs pulled from the agent,
+	// including the snapshots fetched in chunks.
+	PulledBytes int64
+
+	// PushedOperations is the number of the operations pushed to the agent.
+	PushedOperations int64
+
+	// PulledOperations is the number of the operations pulled from the agent.
+	PulledOperations int64
+}
+
+// UsageReporter is called periodically with the usages of the documents by
+// their BSON keys.
+type UsageReporter func(usages map[string]Usage)
+
+// usageTracker tracks the usages of the documents. It is safe for concurrent
+// use, as the usages are reported from another goroutine.
+type usageTracker struct {
+	mu     sync.Mutex
+	usages map[string]Usage
+
+	interval time.Duration
+	reporter UsageReporter
+	stop     chan struct{}
+}
+
+func newUsageTracker(interval time.Duration, reporter UsageReporter) *usageTracker {
+	return &usageTracker{
+		usages:   make(map[string]Usage),
+		interval: interval,
+		reporter: reporter,
+	}
+}
+
+// recordPush records the given change pack pushed for the given document.
+func (t *usageTracker) recordPush(docKey *key.Key, pbPack *api.ChangePack) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	usage := t.usages[docKey.BSONKey()]
+	usage.PushedBytes += int64(pbPack.Size())
+	usage.PushedOperations += countOperations(pbPack)
+	t.usages[docKey.BSONKey()] = usage
+}
+
+// recordPull records the given change pack pulled for the given document
+// and the size of the snapshot fetched for it.
+func (t *usageTracker) recordPull(docKey *key.Key, pbPack *api.ChangePack, snapshotSize int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	usage := t.usages[docKey.BSONKey()]
+	usage.PulledBytes += int64(pbPack.Size() + snapshotSize)
+	usage.PulledOperations += countOperations(pbPack)
+	t.usages[docKey.BSONKey()] = usage
+}
+
+func (t *usageTracker) usage(docKey *key.Key) Usage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.usages[docKey.BSONKey()]
+}
+
+func (t *usageTracker) all() map[string]Usage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	usages := make(map[string]Usage, len(t.usages))
+	for k, usage := range t.usages {
+		usages[k] = usage
+	}
+	return usages
+}
+
+// start starts reporting the usages periodically if a reporter is set.
+func (t *usageTracker) start() {
+	if t.reporter == nil || t.interval <= 0 || t.stop != nil {
+		return
+	}
+
+	t.stop = make(chan struct{})
+	go func(stop chan struct{}) {
+		ticker := time.NewTicker(t.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				t.reporter(t.all())
+			case <-stop:
+				return
+			}
+		}
+	}(t.stop)
+}
+
+// close stops reporting the usages.
+func (t *usageTracker) close() {
+	if t.stop == nil {
+		return
+	}
+
+	close(t.stop)
+	t.stop = nil
+}
+
+func countOperations(pbPack *api.ChangePack) int64 {
+	var count int64
+	for _, pbChange := range pbPack.Changes {
+		count += int64(len(pbChange.Operations))
+	}
+	return count
+}
+
+// Usage returns the amount of the data this client exchanged with the agent
+// for the document of the given key. It is kept after the document is
+// detached, so that the data used by the document is still accounted.
+func (c *Client) Usage(docKey *key.Key) Usage {
+	return c.usage.usage(docKey)
+}
+
+// Usages returns the amounts of the data this client exchanged with the
+// agent by the BSON keys of the documents.
+func (c *Client) Usages() map[string]Usage {
+	return c.usage.all()
+}
@@ -29,6 +29,8 @@ import (
 	"github.com/yorkie-team/yorkie/yorkie"
 )
 
+var logger = log.Named(log.Agent)
+
 var (
 	gracefulTimeout = 10 * time.Second
 )
@@ -90,12 +92,12 @@ func handleSignal(r *yorkie.Yorkie) int {
 		graceful = true
 	}
 
-	log.Logger.Infof("Caught signal: %s", sig.String())
+	logger.Infof("Caught signal: %s", sig.String())
 
 	gracefulCh := make(chan struct{})
 	go func() {
 		if err := r.Shutdown(graceful); err != nil {
-			log.Logger.Error(err)
+			logger.Error(err)
 			return
 		}
 		close(gracefulCh)
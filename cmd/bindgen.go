@@ -0,0 +1,94 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yorkie-team/yorkie/pkg/bindgen"
+)
+
+var (
+	flagBindgenOutput  string
+	flagBindgenPackage string
+	flagBindgenTypes   []string
+)
+
+func newBindgenCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "bindgen [schema file]",
+		Short: "Generates typed document bindings from the Go structs of a schema file.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			src, err := ioutil.ReadFile(args[0])
+			if err != nil {
+				return err
+			}
+
+			code, err := bindgen.Generate(filepath.Base(args[0]), src, &bindgen.Config{
+				Package: flagBindgenPackage,
+				Types:   flagBindgenTypes,
+			})
+			if err != nil {
+				return err
+			}
+
+			output := flagBindgenOutput
+			if output == "" {
+				output = strings.TrimSuffix(args[0], ".go") + "_yorkie.go"
+			}
+			if err := ioutil.WriteFile(output, code, 0644); err != nil {
+				return err
+			}
+
+			_, _ = fmt.Fprintf(os.Stderr, "bindings generated: %s\n", output)
+			return nil
+		},
+	}
+}
+
+func init() {
+	cmd := newBindgenCmd()
+	cmd.Flags().StringVarP(
+		&flagBindgenOutput,
+		"output",
+		"o",
+		"",
+		"output file, <schema file>_yorkie.go by default",
+	)
+	cmd.Flags().StringVarP(
+		&flagBindgenPackage,
+		"package",
+		"p",
+		"",
+		"package of the bindings, the package of the schema by default",
+	)
+	cmd.Flags().StringSliceVarP(
+		&flagBindgenTypes,
+		"types",
+		"t",
+		nil,
+		"structs to generate bindings for, all structs by default",
+	)
+	rootCmd.AddCommand(cmd)
+}
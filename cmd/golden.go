@@ -0,0 +1,75 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yorkie-team/yorkie/pkg/golden"
+)
+
+var flagGoldenOutput string
+
+func newGoldenCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "golden",
+		Short: "Generates the test vectors of the wire format for the test suites of the SDKs.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			vectors, err := golden.Generate()
+			if err != nil {
+				return err
+			}
+
+			if err := os.MkdirAll(flagGoldenOutput, 0755); err != nil {
+				return err
+			}
+
+			for _, vector := range vectors {
+				bytes, err := vector.Encode()
+				if err != nil {
+					return err
+				}
+
+				output := filepath.Join(flagGoldenOutput, vector.FileName())
+				if err := ioutil.WriteFile(output, bytes, 0644); err != nil {
+					return err
+				}
+			}
+
+			_, _ = fmt.Fprintf(os.Stderr, "%d vectors generated: %s\n", len(vectors), flagGoldenOutput)
+			return nil
+		},
+	}
+}
+
+func init() {
+	cmd := newGoldenCmd()
+	cmd.Flags().StringVarP(
+		&flagGoldenOutput,
+		"output",
+		"o",
+		"testdata",
+		"output directory",
+	)
+	rootCmd.AddCommand(cmd)
+}
@@ -0,0 +1,113 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	api "github.com/yorkie-team/yorkie/api/yorkie/v1"
+)
+
+var (
+	flagHistoryRPCAddr    string
+	flagHistoryAdminToken string
+	flagHistoryFrom       uint64
+	flagHistoryLimit      int32
+)
+
+func newHistoryCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "history [collection] [document]",
+		Short: "Prints the changes of a document with their operations in a human-readable form.",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			conn, err := grpc.Dial(flagHistoryRPCAddr, grpc.WithInsecure())
+			if err != nil {
+				return err
+			}
+			defer func() {
+				_ = conn.Close()
+			}()
+
+			ctx := context.Background()
+			if flagHistoryAdminToken != "" {
+				ctx = metadata.AppendToOutgoingContext(ctx, "authorization", flagHistoryAdminToken)
+			}
+
+			resp, err := api.NewAdminClient(conn).GetDocumentHistory(ctx, &api.GetDocumentHistoryRequest{
+				DocumentKey: &api.DocumentKey{
+					Collection: args[0],
+					Document:   args[1],
+				},
+				FromServerSeq: flagHistoryFrom,
+				Limit:         flagHistoryLimit,
+			})
+			if err != nil {
+				return err
+			}
+
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			for _, c := range resp.Changes {
+				if err := encoder.Encode(c); err != nil {
+					return err
+				}
+			}
+
+			if len(resp.Changes) == 0 {
+				_, _ = fmt.Fprintln(os.Stderr, "no changes")
+			}
+			return nil
+		},
+	}
+}
+
+func init() {
+	cmd := newHistoryCmd()
+	cmd.Flags().StringVar(
+		&flagHistoryRPCAddr,
+		"rpc-addr",
+		"localhost:9090",
+		"address of the agent",
+	)
+	cmd.Flags().StringVar(
+		&flagHistoryAdminToken,
+		"admin-token",
+		"",
+		"admin token of the agent",
+	)
+	cmd.Flags().Uint64Var(
+		&flagHistoryFrom,
+		"from",
+		1,
+		"server sequence of the first change",
+	)
+	cmd.Flags().Int32Var(
+		&flagHistoryLimit,
+		"limit",
+		0,
+		"maximum number of the changes, 100 by default",
+	)
+	rootCmd.AddCommand(cmd)
+}
@@ -0,0 +1,112 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yorkie-team/yorkie/yorkie"
+	"github.com/yorkie-team/yorkie/yorkie/backend"
+	"github.com/yorkie-team/yorkie/yorkie/backup"
+)
+
+var (
+	flagRestoreConfPath string
+	flagRestoreDir      string
+)
+
+func newRestoreCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "restore [backup name]",
+		Short: "Restores documents from a backup, or lists backups if no name is given.",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if flagRestoreConfPath == "" {
+				return errors.New("config path is required")
+			}
+
+			conf, err := yorkie.NewConfigFromFile(flagRestoreConfPath)
+			if err != nil {
+				return fmt.Errorf("fail to create config: %s", flagRestoreConfPath)
+			}
+			dir := flagRestoreDir
+			if dir == "" && conf.Backup != nil {
+				dir = conf.Backup.Dir
+			}
+			if dir == "" {
+				return errors.New("backup directory is not configured")
+			}
+
+			store, err := backup.NewDirStore(dir)
+			if err != nil {
+				return err
+			}
+
+			if len(args) == 0 {
+				names, err := store.List()
+				if err != nil {
+					return err
+				}
+				for _, name := range names {
+					fmt.Println(name)
+				}
+				return nil
+			}
+
+			be, err := backend.New(conf.Backend, conf.Mongo)
+			if err != nil {
+				return err
+			}
+			defer func() {
+				if err := be.Close(); err != nil {
+					logger.Error(err)
+				}
+			}()
+
+			restored, err := backup.Restore(context.Background(), be, store, args[0])
+			if err != nil {
+				return err
+			}
+			fmt.Printf("%d documents restored\n", restored)
+
+			return nil
+		},
+	}
+}
+
+func init() {
+	cmd := newRestoreCmd()
+	cmd.Flags().StringVarP(
+		&flagRestoreConfPath,
+		"config",
+		"c",
+		"",
+		"config path",
+	)
+	cmd.Flags().StringVarP(
+		&flagRestoreDir,
+		"dir",
+		"d",
+		"",
+		"backup directory, overriding the one of the config",
+	)
+	rootCmd.AddCommand(cmd)
+}
@@ -0,0 +1,413 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package bindgen generates typed bindings of documents from the Go structs
+// describing their schema. For each struct S, the bindings have SProxy, which
+// wraps an ObjectProxy with an accessor per field of S, and SList, which wraps
+// an ArrayProxy of S if S is used in a slice.
+//
+// Fields are mapped as follows:
+//
+//	bool, int, int64, float64, string, []byte, time.Time  primitives
+//	[]T of the types above                                 lists of primitives
+//	S, *S                                                  objects
+//	[]S, []*S                                              lists of objects
+//	string with `yorkie:",text"`                           texts
+//
+// The key of a field is given by the name of its yorkie tag, its json tag or
+// otherwise its name with the first letter lowered. Fields tagged with
+// `yorkie:"-"` are skipped.
+//
+// Bindings are usually generated with go:generate:
+//
+//	//go:generate go run github.com/yorkie-team/yorkie bindgen board.go -o board_yorkie.go
+package bindgen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	// ErrUnsupportedType is returned when a field has a type that can't be
+	// stored in a document.
+	ErrUnsupportedType = errors.New("unsupported type")
+
+	// ErrTypeNotFound is returned when the given type is not a struct of the
+	// schema.
+	ErrTypeNotFound = errors.New("type not found")
+)
+
+// kind is the kind of the value of a field.
+type kind int
+
+const (
+	kindPrimitive kind = iota
+	kindPrimitiveList
+	kindObject
+	kindObjectList
+	kindText
+)
+
+// primitives maps the Go types of primitives to the names of their typed
+// fields and lists.
+var primitives = map[string]string{
+	"bool":      "Bool",
+	"int":       "Integer",
+	"int64":     "Long",
+	"float64":   "Double",
+	"string":    "String",
+	"[]byte":    "Bytes",
+	"time.Time": "Date",
+}
+
+// Config is the configuration of the generation.
+type Config struct {
+	// Package is the package of the generated code. The package of the
+	// schema is used if it is empty.
+	Package string
+
+	// Types are the structs to generate bindings for, with the structs
+	// they refer to. All structs of the schema are used if it is empty.
+	Types []string
+}
+
+// field is a field of a struct.
+type field struct {
+	Name string
+	Key  string
+	Kind kind
+
+	// Type is the name of the typed field of primitives or the name of the
+	// struct of objects.
+	Type string
+}
+
+// structType is a struct of the schema.
+type structType struct {
+	Name   string
+	Fields []*field
+	Listed bool
+}
+
+// Generate generates the bindings of the structs of the given Go source.
+func Generate(filename string, src []byte, conf *Config) ([]byte, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), filename, src, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	specs := make(map[string]*ast.StructType)
+	var names []string
+	for _, decl := range file.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range genDecl.Specs {
+			typeSpec := spec.(*ast.TypeSpec)
+			if st, ok := typeSpec.Type.(*ast.StructType); ok {
+				specs[typeSpec.Name.Name] = st
+				names = append(names, typeSpec.Name.Name)
+			}
+		}
+	}
+
+	roots := conf.Types
+	if len(roots) == 0 {
+		roots = names
+	}
+
+	structs := make(map[string]*structType)
+	var visit func(name string) error
+	visit = func(name string) error {
+		if _, ok := structs[name]; ok {
+			return nil
+		}
+		st, ok := specs[name]
+		if !ok {
+			return fmt.Errorf("%s: %w", name, ErrTypeNotFound)
+		}
+
+		s := &structType{Name: name}
+		structs[name] = s
+		for _, f := range st.Fields.List {
+			fields, err := toFields(f, specs)
+			if err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			for _, fld := range fields {
+				if fld.Kind == kindObject || fld.Kind == kindObjectList {
+					if err := visit(fld.Type); err != nil {
+						return err
+					}
+				}
+				s.Fields = append(s.Fields, fld)
+			}
+		}
+		return nil
+	}
+	for _, name := range roots {
+		if err := visit(name); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, s := range structs {
+		for _, f := range s.Fields {
+			if f.Kind == kindObjectList {
+				structs[f.Type].Listed = true
+			}
+		}
+	}
+
+	var sorted []*structType
+	for _, s := range structs {
+		sorted = append(sorted, s)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Name < sorted[j].Name
+	})
+
+	pkg := conf.Package
+	if pkg == "" {
+		pkg = file.Name.Name
+	}
+
+	buf := &bytes.Buffer{}
+	if err := bindingsTemplate.Execute(buf, map[string]interface{}{
+		"Package": pkg,
+		"Source":  filename,
+		"Structs": sorted,
+	}); err != nil {
+		return nil, err
+	}
+
+	return format.Source(buf.Bytes())
+}
+
+// toFields returns the fields of the given declaration, which can declare
+// several names of the same type.
+func toFields(f *ast.Field, specs map[string]*ast.StructType) ([]*field, error) {
+	name, text, skip := parseTag(f.Tag)
+	if skip {
+		return nil, nil
+	}
+
+	var fields []*field
+	for _, ident := range f.Names {
+		if !ident.IsExported() {
+			continue
+		}
+
+		fld, err := toField(f.Type, specs)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", ident.Name, err)
+		}
+		if text {
+			if fld.Kind != kindPrimitive || fld.Type != "String" {
+				return nil, fmt.Errorf("%s: text of non-string: %w", ident.Name, ErrUnsupportedType)
+			}
+			fld.Kind = kindText
+		}
+
+		fld.Name = ident.Name
+		fld.Key = name
+		if fld.Key == "" {
+			fld.Key = lowerFirst(ident.Name)
+		}
+		fields = append(fields, fld)
+	}
+
+	return fields, nil
+}
+
+// toField returns the field of the given type expression without its name.
+func toField(expr ast.Expr, specs map[string]*ast.StructType) (*field, error) {
+	typeName := exprString(expr)
+	if t, ok := primitives[typeName]; ok {
+		return &field{Kind: kindPrimitive, Type: t}, nil
+	}
+
+	switch e := expr.(type) {
+	case *ast.StarExpr:
+		return toField(e.X, specs)
+	case *ast.Ident:
+		if _, ok := specs[e.Name]; ok {
+			return &field{Kind: kindObject, Type: e.Name}, nil
+		}
+	case *ast.ArrayType:
+		if e.Len != nil {
+			break
+		}
+		elem, err := toField(e.Elt, specs)
+		if err != nil {
+			return nil, err
+		}
+		switch elem.Kind {
+		case kindPrimitive:
+			return &field{Kind: kindPrimitiveList, Type: elem.Type}, nil
+		case kindObject:
+			return &field{Kind: kindObjectList, Type: elem.Type}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%s: %w", typeName, ErrUnsupportedType)
+}
+
+// parseTag returns the key and the text option of the given struct tag, and
+// whether the field is skipped.
+func parseTag(lit *ast.BasicLit) (string, bool, bool) {
+	if lit == nil {
+		return "", false, false
+	}
+
+	raw, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		return "", false, false
+	}
+	tag := reflect.StructTag(raw)
+
+	if value, ok := tag.Lookup("yorkie"); ok {
+		if value == "-" {
+			return "", false, true
+		}
+		parts := strings.Split(value, ",")
+		text := false
+		for _, opt := range parts[1:] {
+			if opt == "text" {
+				text = true
+			}
+		}
+		return parts[0], text, false
+	}
+
+	if value, ok := tag.Lookup("json"); ok {
+		if value == "-" {
+			return "", false, true
+		}
+		return strings.Split(value, ",")[0], false, false
+	}
+
+	return "", false, false
+}
+
+func exprString(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e.Name
+	case *ast.SelectorExpr:
+		return exprString(e.X) + "." + e.Sel.Name
+	case *ast.StarExpr:
+		return "*" + exprString(e.X)
+	case *ast.ArrayType:
+		if e.Len == nil {
+			return "[]" + exprString(e.Elt)
+		}
+	}
+	return fmt.Sprintf("%T", expr)
+}
+
+func lowerFirst(s string) string {
+	r, n := utf8.DecodeRuneInString(s)
+	return string(unicode.ToLower(r)) + s[n:]
+}
+
+var bindingsTemplate = template.Must(template.New("bindings").Funcs(template.FuncMap{
+	"isPrimitive":     func(k kind) bool { return k == kindPrimitive },
+	"isPrimitiveList": func(k kind) bool { return k == kindPrimitiveList },
+	"isObject":        func(k kind) bool { return k == kindObject },
+	"isObjectList":    func(k kind) bool { return k == kindObjectList },
+	"isText":          func(k kind) bool { return k == kindText },
+}).Parse(`// Code generated by yorkie bindgen from {{.Source}}. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"github.com/yorkie-team/yorkie/pkg/document/proxy"
+	"github.com/yorkie-team/yorkie/pkg/document/typed"
+)
+{{range $s := .Structs}}
+// {{$s.Name}}Proxy is a typed proxy of {{$s.Name}}.
+type {{$s.Name}}Proxy struct {
+	*proxy.ObjectProxy
+}
+
+// New{{$s.Name}}Proxy wraps the given object as {{$s.Name}}Proxy.
+func New{{$s.Name}}Proxy(obj *proxy.ObjectProxy) *{{$s.Name}}Proxy {
+	return &{{$s.Name}}Proxy{ObjectProxy: obj}
+}
+{{range $f := $s.Fields}}{{if isPrimitive $f.Kind}}
+// {{$f.Name}} returns the "{{$f.Key}}" field.
+func (p *{{$s.Name}}Proxy) {{$f.Name}}() typed.{{$f.Type}} {
+	return typed.New{{$f.Type}}(p.ObjectProxy, "{{$f.Key}}")
+}
+{{else if isPrimitiveList $f.Kind}}
+// {{$f.Name}} returns the "{{$f.Key}}" list, creating it if it doesn't exist.
+func (p *{{$s.Name}}Proxy) {{$f.Name}}() typed.{{$f.Type}}List {
+	return typed.New{{$f.Type}}List(typed.Array(p.ObjectProxy, "{{$f.Key}}"))
+}
+{{else if isObject $f.Kind}}
+// {{$f.Name}} returns the "{{$f.Key}}" object, creating it if it doesn't exist.
+func (p *{{$s.Name}}Proxy) {{$f.Name}}() *{{$f.Type}}Proxy {
+	return New{{$f.Type}}Proxy(typed.Object(p.ObjectProxy, "{{$f.Key}}"))
+}
+{{else if isObjectList $f.Kind}}
+// {{$f.Name}} returns the "{{$f.Key}}" list, creating it if it doesn't exist.
+func (p *{{$s.Name}}Proxy) {{$f.Name}}() *{{$f.Type}}List {
+	return New{{$f.Type}}List(typed.Array(p.ObjectProxy, "{{$f.Key}}"))
+}
+{{else if isText $f.Kind}}
+// {{$f.Name}} returns the "{{$f.Key}}" text, creating it if it doesn't exist.
+func (p *{{$s.Name}}Proxy) {{$f.Name}}() *proxy.TextProxy {
+	return typed.Text(p.ObjectProxy, "{{$f.Key}}")
+}
+{{end}}{{end}}{{if $s.Listed}}
+// {{$s.Name}}List is a typed proxy of a list of {{$s.Name}}.
+type {{$s.Name}}List struct {
+	*proxy.ArrayProxy
+}
+
+// New{{$s.Name}}List wraps the given array as {{$s.Name}}List.
+func New{{$s.Name}}List(arr *proxy.ArrayProxy) *{{$s.Name}}List {
+	return &{{$s.Name}}List{ArrayProxy: arr}
+}
+
+// At returns the element at the given index, or nil if it is out of range.
+func (l *{{$s.Name}}List) At(idx int) *{{$s.Name}}Proxy {
+	if idx < 0 || idx >= l.Len() {
+		return nil
+	}
+	return New{{$s.Name}}Proxy(l.GetObject(idx))
+}
+
+// Add adds a new element at the end of the list and returns it.
+func (l *{{$s.Name}}List) Add() *{{$s.Name}}Proxy {
+	return New{{$s.Name}}Proxy(l.AddNewObject())
+}
+{{end}}{{end}}`))
@@ -0,0 +1,121 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package bindgen_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yorkie-team/yorkie/pkg/bindgen"
+	"github.com/yorkie-team/yorkie/pkg/bindgen/example"
+	"github.com/yorkie-team/yorkie/pkg/document"
+	"github.com/yorkie-team/yorkie/pkg/document/proxy"
+)
+
+func TestBindgen(t *testing.T) {
+	t.Run("generate test", func(t *testing.T) {
+		src, err := ioutil.ReadFile("example/board.go")
+		assert.NoError(t, err)
+		expected, err := ioutil.ReadFile("example/board_yorkie.go")
+		assert.NoError(t, err)
+
+		code, err := bindgen.Generate("board.go", src, &bindgen.Config{Types: []string{"Board"}})
+		assert.NoError(t, err)
+		assert.Equal(t, string(expected), string(code))
+	})
+
+	t.Run("bindings test", func(t *testing.T) {
+		due := time.Unix(1600000000, 0)
+
+		doc := document.New("c1", "d1")
+		err := doc.Update(func(root *proxy.ObjectProxy) error {
+			board := example.NewBoardProxy(root)
+			board.Title().Set("board")
+			board.Owner().Name().Set("owner")
+
+			column := board.Columns().Add()
+			column.Title().Set("todo")
+			column.Tags().Add("a", "b")
+
+			card := column.Cards().Add()
+			card.Title().Set("card")
+			card.Description().Edit(0, 0, "desc")
+			card.Points().Set(3)
+			card.DueDate().Set(due)
+			return nil
+		})
+		assert.NoError(t, err)
+
+		err = doc.Update(func(root *proxy.ObjectProxy) error {
+			board := example.NewBoardProxy(root)
+			assert.Equal(t, "board", board.Title().Get())
+			assert.Nil(t, board.Columns().At(1))
+
+			column := board.Columns().At(0)
+			assert.Equal(t, "todo", column.Title().Get())
+			assert.Equal(t, 2, column.Tags().Len())
+			assert.Equal(t, "b", column.Tags().At(1))
+
+			card := column.Cards().At(0)
+			assert.Equal(t, 3, card.Points().Get())
+			assert.Equal(t, due, card.DueDate().Get())
+			assert.False(t, card.Done().Has())
+			assert.False(t, card.Done().Get())
+
+			column.Title().Set("doing")
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Contains(t, doc.Marshal(), `"title":"doing"`)
+		assert.Contains(t, doc.Marshal(), `"description":"desc"`)
+	})
+
+	t.Run("schema test", func(t *testing.T) {
+		_, err := bindgen.Generate("schema.go", []byte(`package schema
+type S struct {
+	M map[string]string
+}`), &bindgen.Config{})
+		assert.True(t, errors.Is(err, bindgen.ErrUnsupportedType))
+
+		_, err = bindgen.Generate("schema.go", []byte(`package schema
+type S struct {
+	N int `+"`yorkie:\",text\"`"+`
+}`), &bindgen.Config{})
+		assert.True(t, errors.Is(err, bindgen.ErrUnsupportedType))
+
+		_, err = bindgen.Generate("schema.go", []byte(`package schema
+type S struct {}`), &bindgen.Config{Types: []string{"T"}})
+		assert.True(t, errors.Is(err, bindgen.ErrTypeNotFound))
+
+		code, err := bindgen.Generate("schema.go", []byte(`package schema
+type S struct {
+	A, B   string
+	hidden string
+	C      string `+"`yorkie:\"-\"`"+`
+}`), &bindgen.Config{Package: "bindings"})
+		assert.NoError(t, err)
+		assert.Contains(t, string(code), "package bindings")
+		assert.Contains(t, string(code), `func (p *SProxy) A() typed.String`)
+		assert.Contains(t, string(code), `func (p *SProxy) B() typed.String`)
+		assert.NotContains(t, string(code), "hidden")
+		assert.NotContains(t, string(code), "C()")
+	})
+}
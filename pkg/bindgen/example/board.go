@@ -0,0 +1,53 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package example is the schema of a kanban board with its generated bindings.
+package example
+
+import (
+	"time"
+)
+
+//go:generate go run github.com/yorkie-team/yorkie bindgen board.go -t Board
+
+// Board is a kanban board.
+type Board struct {
+	Title   string    `json:"title"`
+	Columns []*Column `json:"columns"`
+	Owner   User      `json:"owner"`
+}
+
+// Column is a column of a board.
+type Column struct {
+	Title string   `json:"title"`
+	Cards []Card   `json:"cards"`
+	Tags  []string `json:"tags"`
+}
+
+// Card is a card in a column.
+type Card struct {
+	Title       string    `json:"title"`
+	Description string    `yorkie:"description,text"`
+	Points      int       `json:"points"`
+	DueDate     time.Time `json:"dueDate"`
+	Done        bool      `json:"done"`
+}
+
+// User is a user of a board.
+type User struct {
+	Name  string `json:"name"`
+	Email string `yorkie:"-"`
+}
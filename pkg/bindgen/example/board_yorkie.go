@@ -0,0 +1,154 @@
+// Code generated by yorkie bindgen from board.go. DO NOT EDIT.
+
+package example
+
+import (
+	"github.com/yorkie-team/yorkie/pkg/document/proxy"
+	"github.com/yorkie-team/yorkie/pkg/document/typed"
+)
+
+// BoardProxy is a typed proxy of Board.
+type BoardProxy struct {
+	*proxy.ObjectProxy
+}
+
+// NewBoardProxy wraps the given object as BoardProxy.
+func NewBoardProxy(obj *proxy.ObjectProxy) *BoardProxy {
+	return &BoardProxy{ObjectProxy: obj}
+}
+
+// Title returns the "title" field.
+func (p *BoardProxy) Title() typed.String {
+	return typed.NewString(p.ObjectProxy, "title")
+}
+
+// Columns returns the "columns" list, creating it if it doesn't exist.
+func (p *BoardProxy) Columns() *ColumnList {
+	return NewColumnList(typed.Array(p.ObjectProxy, "columns"))
+}
+
+// Owner returns the "owner" object, creating it if it doesn't exist.
+func (p *BoardProxy) Owner() *UserProxy {
+	return NewUserProxy(typed.Object(p.ObjectProxy, "owner"))
+}
+
+// CardProxy is a typed proxy of Card.
+type CardProxy struct {
+	*proxy.ObjectProxy
+}
+
+// NewCardProxy wraps the given object as CardProxy.
+func NewCardProxy(obj *proxy.ObjectProxy) *CardProxy {
+	return &CardProxy{ObjectProxy: obj}
+}
+
+// Title returns the "title" field.
+func (p *CardProxy) Title() typed.String {
+	return typed.NewString(p.ObjectProxy, "title")
+}
+
+// Description returns the "description" text, creating it if it doesn't exist.
+func (p *CardProxy) Description() *proxy.TextProxy {
+	return typed.Text(p.ObjectProxy, "description")
+}
+
+// Points returns the "points" field.
+func (p *CardProxy) Points() typed.Integer {
+	return typed.NewInteger(p.ObjectProxy, "points")
+}
+
+// DueDate returns the "dueDate" field.
+func (p *CardProxy) DueDate() typed.Date {
+	return typed.NewDate(p.ObjectProxy, "dueDate")
+}
+
+// Done returns the "done" field.
+func (p *CardProxy) Done() typed.Bool {
+	return typed.NewBool(p.ObjectProxy, "done")
+}
+
+// CardList is a typed proxy of a list of Card.
+type CardList struct {
+	*proxy.ArrayProxy
+}
+
+// NewCardList wraps the given array as CardList.
+func NewCardList(arr *proxy.ArrayProxy) *CardList {
+	return &CardList{ArrayProxy: arr}
+}
+
+// At returns the element at the given index, or nil if it is out of range.
+func (l *CardList) At(idx int) *CardProxy {
+	if idx < 0 || idx >= l.Len() {
+		return nil
+	}
+	return NewCardProxy(l.GetObject(idx))
+}
+
+// Add adds a new element at the end of the list and returns it.
+func (l *CardList) Add() *CardProxy {
+	return NewCardProxy(l.AddNewObject())
+}
+
+// ColumnProxy is a typed proxy of Column.
+type ColumnProxy struct {
+	*proxy.ObjectProxy
+}
+
+// NewColumnProxy wraps the given object as ColumnProxy.
+func NewColumnProxy(obj *proxy.ObjectProxy) *ColumnProxy {
+	return &ColumnProxy{ObjectProxy: obj}
+}
+
+// Title returns the "title" field.
+func (p *ColumnProxy) Title() typed.String {
+	return typed.NewString(p.ObjectProxy, "title")
+}
+
+// Cards returns the "cards" list, creating it if it doesn't exist.
+func (p *ColumnProxy) Cards() *CardList {
+	return NewCardList(typed.Array(p.ObjectProxy, "cards"))
+}
+
+// Tags returns the "tags" list, creating it if it doesn't exist.
+func (p *ColumnProxy) Tags() typed.StringList {
+	return typed.NewStringList(typed.Array(p.ObjectProxy, "tags"))
+}
+
+// ColumnList is a typed proxy of a list of Column.
+type ColumnList struct {
+	*proxy.ArrayProxy
+}
+
+// NewColumnList wraps the given array as ColumnList.
+func NewColumnList(arr *proxy.ArrayProxy) *ColumnList {
+	return &ColumnList{ArrayProxy: arr}
+}
+
+// At returns the element at the given index, or nil if it is out of range.
+func (l *ColumnList) At(idx int) *ColumnProxy {
+	if idx < 0 || idx >= l.Len() {
+		return nil
+	}
+	return NewColumnProxy(l.GetObject(idx))
+}
+
+// Add adds a new element at the end of the list and returns it.
+func (l *ColumnList) Add() *ColumnProxy {
+	return NewColumnProxy(l.AddNewObject())
+}
+
+// UserProxy is a typed proxy of User.
+type UserProxy struct {
+	*proxy.ObjectProxy
+}
+
+// NewUserProxy wraps the given object as UserProxy.
+func NewUserProxy(obj *proxy.ObjectProxy) *UserProxy {
+	return &UserProxy{ObjectProxy: obj}
+}
+
+// Name returns the "name" field.
+func (p *UserProxy) Name() typed.String {
+	return typed.NewString(p.ObjectProxy, "name")
+}
@@ -24,17 +24,22 @@ import (
 
 // Change represents a unit of modification in the document.
 type Change struct {
-	id *ID
+	id ID
 	// message is used to save a description of the change.
 	message string
 	// operations represent a series of user edits.
 	operations []operation.Operation
 	// serverSeq is optional and only present for changes stored on the server.
 	serverSeq *uint64
+	// user is optional and only present for changes pushed by clients
+	// configured with a user.
+	user *User
+	// metadata is the application-defined context of the change.
+	metadata map[string]string
 }
 
 // New creates a new instance of Change.
-func New(id *ID, message string, operations []operation.Operation) *Change {
+func New(id ID, message string, operations []operation.Operation) *Change {
 	return &Change{
 		id:         id,
 		message:    message,
@@ -53,7 +58,7 @@ func (c *Change) Execute(root *json.Root) error {
 }
 
 // ID returns the ID of this change.
-func (c *Change) ID() *ID {
+func (c *Change) ID() ID {
 	return c.id
 }
 
@@ -77,6 +82,27 @@ func (c *Change) ServerSeq() uint64 {
 	return *c.serverSeq
 }
 
+// SetUser sets the given user.
+func (c *Change) SetUser(user *User) {
+	c.user = user
+}
+
+// User returns the user who made this change, or nil if it is unknown.
+func (c *Change) User() *User {
+	return c.user
+}
+
+// SetMetadata sets the given metadata.
+func (c *Change) SetMetadata(metadata map[string]string) {
+	c.metadata = metadata
+}
+
+// Metadata returns the application-defined context of this change, or nil if
+// it has none.
+func (c *Change) Metadata() map[string]string {
+	return c.metadata
+}
+
 // ClientSeq returns the clientSeq of this change.
 func (c *Change) ClientSeq() uint32 {
 	return c.id.ClientSeq()
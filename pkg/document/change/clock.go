@@ -0,0 +1,53 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package change
+
+import (
+	"github.com/yorkie-team/yorkie/pkg/document/time"
+)
+
+// DefaultClock is the clock of the documents unless another one is set. It
+// issues the lamport timestamps and the time tickets in the usual way.
+var DefaultClock Clock = lamportClock{}
+
+// Clock issues the lamport timestamps of the changes and the time tickets of
+// the operations in them. A custom clock can be set to the documents so that
+// tests and simulations produce deterministic tickets and reproduce exact
+// interleavings of the changes of replicas.
+type Clock interface {
+	// NextLamport returns the lamport timestamp of the change after the
+	// change of the given lamport timestamp. It must be greater than the
+	// given one to keep the causality of the changes.
+	NextLamport(lamport uint64) uint64
+
+	// IssueTimeTicket issues the time ticket of the operation of the given
+	// delimiter in the change of the given ID.
+	IssueTimeTicket(id ID, delimiter uint32) *time.Ticket
+}
+
+// lamportClock is the default clock, which follows the lamport timestamps.
+type lamportClock struct{}
+
+// NextLamport returns the next lamport timestamp of the given one.
+func (lamportClock) NextLamport(lamport uint64) uint64 {
+	return lamport + 1
+}
+
+// IssueTimeTicket creates a ticket of the given ID and delimiter.
+func (lamportClock) IssueTimeTicket(id ID, delimiter uint32) *time.Ticket {
+	return id.NewTimeTicket(delimiter)
+}
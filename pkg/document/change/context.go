@@ -26,24 +26,33 @@ import (
 // Each time we add an operation, a new time ticket is issued.
 // Finally returns a Change after the modification has been completed.
 type Context struct {
-	id         *ID
+	id         ID
 	message    string
 	operations []operation.Operation
 	delimiter  uint32
 	root       *json.Root
+	clock      Clock
+	err        error
 }
 
 // NewContext creates a new instance of Context.
-func NewContext(id *ID, message string, root *json.Root) *Context {
+func NewContext(id ID, message string, root *json.Root) *Context {
+	return NewContextWithClock(id, message, root, DefaultClock)
+}
+
+// NewContextWithClock creates a new instance of Context issuing the time
+// tickets with the given clock.
+func NewContextWithClock(id ID, message string, root *json.Root, clock Clock) *Context {
 	return &Context{
 		id:      id,
 		message: message,
 		root:    root,
+		clock:   clock,
 	}
 }
 
 // ID returns ID.
-func (c *Context) ID() *ID {
+func (c *Context) ID() ID {
 	return c.id
 }
 
@@ -57,10 +66,15 @@ func (c *Context) HasOperations() bool {
 	return len(c.operations) > 0
 }
 
+// Operations returns the operations pushed into this context.
+func (c *Context) Operations() []operation.Operation {
+	return c.operations
+}
+
 // IssueTimeTicket creates a time ticket to be used to create a new operation.
 func (c *Context) IssueTimeTicket() *time.Ticket {
 	c.delimiter++
-	return c.id.NewTimeTicket(c.delimiter)
+	return c.clock.IssueTimeTicket(c.id, c.delimiter)
 }
 
 // Push pushes an new operation into context queue.
@@ -68,7 +82,31 @@ func (c *Context) Push(op operation.Operation) {
 	c.operations = append(c.operations, op)
 }
 
+// SetError records the given error which occurred while building the change,
+// e.g. a value of an unsupported type. Only the first error is kept.
+func (c *Context) SetError(err error) {
+	if c.err == nil {
+		c.err = err
+	}
+}
+
+// Err returns the first error recorded while building the change.
+func (c *Context) Err() error {
+	return c.err
+}
+
 // RegisterElement registers the given element to the root.
 func (c *Context) RegisterElement(elem json.Element) {
 	c.root.RegisterElement(elem)
 }
+
+// RegisterRemovedElement registers the given removed element to the root.
+func (c *Context) RegisterRemovedElement(elem json.Element) {
+	c.root.RegisterRemovedElement(elem)
+}
+
+// RegisterShadowedElement registers the given element shadowed by another
+// value to the root.
+func (c *Context) RegisterShadowedElement(elem json.Element) {
+	c.root.RegisterShadowedElement(elem)
+}
@@ -26,7 +26,8 @@ var (
 	InitialID = NewID(0, 0, time.InitialActorID)
 )
 
-// ID is for identifying the Change. This struct is immutable.
+// ID is for identifying the Change. ID is an immutable value type, so it can
+// be copied without heap allocations.
 type ID struct {
 	// clientSeq is a sequence index of the change on this client.
 	clientSeq uint32
@@ -41,19 +42,17 @@ func NewID(
 	clientSeq uint32,
 	lamport uint64,
 	actorID *time.ActorID,
-) *ID {
-	id := &ID{
+) ID {
+	return ID{
 		clientSeq: clientSeq,
 		lamport:   lamport,
 		actor:     actorID,
 	}
-
-	return id
 }
 
 // Next creates a next ID of this ID.
-func (id *ID) Next() *ID {
-	return &ID{
+func (id ID) Next() ID {
+	return ID{
 		clientSeq: id.clientSeq + 1,
 		lamport:   id.lamport + 1,
 		actor:     id.actor,
@@ -61,7 +60,7 @@ func (id *ID) Next() *ID {
 }
 
 // NewTimeTicket creates a ticket of the given delimiter.
-func (id *ID) NewTimeTicket(delimiter uint32) *time.Ticket {
+func (id ID) NewTimeTicket(delimiter uint32) *time.Ticket {
 	return time.NewTicket(
 		id.lamport,
 		delimiter,
@@ -71,7 +70,7 @@ func (id *ID) NewTimeTicket(delimiter uint32) *time.Ticket {
 
 // SyncLamport syncs lamport timestamp with the given ID.
 //  - receiving: https://en.wikipedia.org/wiki/Lamport_timestamps#Algorithm
-func (id *ID) SyncLamport(otherLamport uint64) *ID {
+func (id ID) SyncLamport(otherLamport uint64) ID {
 	if id.lamport < otherLamport {
 		return NewID(id.clientSeq, otherLamport, id.actor)
 	}
@@ -80,21 +79,21 @@ func (id *ID) SyncLamport(otherLamport uint64) *ID {
 }
 
 // SetActor sets actor.
-func (id *ID) SetActor(actor *time.ActorID) *ID {
+func (id ID) SetActor(actor *time.ActorID) ID {
 	return NewID(id.clientSeq, id.lamport, actor)
 }
 
 // ClientSeq returns the client sequence of this ID.
-func (id *ID) ClientSeq() uint32 {
+func (id ID) ClientSeq() uint32 {
 	return id.clientSeq
 }
 
 // Lamport returns the lamport clock of this ID.
-func (id *ID) Lamport() uint64 {
+func (id ID) Lamport() uint64 {
 	return id.lamport
 }
 
 // Actor returns the actor of this ID.
-func (id *ID) Actor() *time.ActorID {
+func (id ID) Actor() *time.ActorID {
 	return id.actor
 }
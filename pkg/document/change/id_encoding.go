@@ -0,0 +1,121 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package change
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+
+	"github.com/yorkie-team/yorkie/pkg/document/time"
+)
+
+// idEncodingVersion is the version of the binary encoding of IDs.
+const idEncodingVersion = 1
+
+// actorIDSize is the size of ActorIDs in bytes.
+const actorIDSize = len(time.ActorID{})
+
+// ErrInvalidID is returned when the given data is not an encoded ID.
+var ErrInvalidID = errors.New("invalid change ID encoding")
+
+// jsonID is the JSON form of IDs. The actor is in hexadecimal and omitted if
+// the ID has no actor.
+type jsonID struct {
+	ClientSeq uint32 `json:"clientSeq"`
+	Lamport   uint64 `json:"lamport"`
+	Actor     string `json:"actor,omitempty"`
+}
+
+// MarshalBinary encodes the ID into a compact binary form, a version byte
+// followed by the client sequence and the lamport in varints and the bytes
+// of the actor if the ID has one.
+func (id ID) MarshalBinary() ([]byte, error) {
+	buf := make([]byte, 1, 1+binary.MaxVarintLen32+binary.MaxVarintLen64+actorIDSize)
+	buf[0] = idEncodingVersion
+
+	var tmp [binary.MaxVarintLen64]byte
+	buf = append(buf, tmp[:binary.PutUvarint(tmp[:], uint64(id.clientSeq))]...)
+	buf = append(buf, tmp[:binary.PutUvarint(tmp[:], id.lamport)]...)
+	if id.actor != nil {
+		buf = append(buf, id.actor[:]...)
+	}
+	return buf, nil
+}
+
+// UnmarshalBinary decodes the ID encoded by MarshalBinary.
+func (id *ID) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 || data[0] != idEncodingVersion {
+		return ErrInvalidID
+	}
+	data = data[1:]
+
+	clientSeq, n := binary.Uvarint(data)
+	if n <= 0 || clientSeq > uint64(^uint32(0)) {
+		return ErrInvalidID
+	}
+	data = data[n:]
+
+	lamport, n := binary.Uvarint(data)
+	if n <= 0 {
+		return ErrInvalidID
+	}
+	data = data[n:]
+
+	var actor *time.ActorID
+	switch len(data) {
+	case 0:
+	case actorIDSize:
+		actor = &time.ActorID{}
+		copy(actor[:], data)
+	default:
+		return ErrInvalidID
+	}
+
+	*id = NewID(uint32(clientSeq), lamport, actor)
+	return nil
+}
+
+// MarshalJSON encodes the ID into JSON, e.g.
+// {"clientSeq":3,"lamport":10,"actor":"0123456789abcdef01234567"}.
+func (id ID) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonID{
+		ClientSeq: id.clientSeq,
+		Lamport:   id.lamport,
+		Actor:     id.actor.String(),
+	})
+}
+
+// UnmarshalJSON decodes the ID encoded by MarshalJSON.
+func (id *ID) UnmarshalJSON(data []byte) error {
+	var decoded jsonID
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return ErrInvalidID
+	}
+
+	var actor *time.ActorID
+	if decoded.Actor != "" {
+		parsed, err := time.ParseActorID(decoded.Actor)
+		if err != nil {
+			return ErrInvalidID
+		}
+		actor = &parsed
+	}
+
+	*id = NewID(decoded.ClientSeq, decoded.Lamport, actor)
+	return nil
+}
@@ -0,0 +1,70 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package change_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yorkie-team/yorkie/pkg/document/change"
+	"github.com/yorkie-team/yorkie/pkg/document/time"
+)
+
+func TestIDEncoding(t *testing.T) {
+	actor := time.ActorIDFromHex("0123456789abcdef01234567")
+
+	t.Run("binary test", func(t *testing.T) {
+		for _, id := range []change.ID{
+			change.InitialID,
+			change.NewID(3, 10, actor),
+			change.NewID(3, 10, nil),
+		} {
+			data, err := id.MarshalBinary()
+			assert.NoError(t, err)
+
+			var decoded change.ID
+			assert.NoError(t, decoded.UnmarshalBinary(data))
+			assert.Equal(t, id, decoded)
+		}
+
+		var decoded change.ID
+		for _, data := range [][]byte{nil, {1}, {1, 3}, {1, 3, 10, 1, 2}, {2, 3, 10}} {
+			assert.Equal(t, change.ErrInvalidID, decoded.UnmarshalBinary(data))
+		}
+	})
+
+	t.Run("json test", func(t *testing.T) {
+		id := change.NewID(3, 10, actor)
+		data, err := json.Marshal(id)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"clientSeq":3,"lamport":10,"actor":"0123456789abcdef01234567"}`, string(data))
+
+		var decoded change.ID
+		assert.NoError(t, json.Unmarshal(data, &decoded))
+		assert.Equal(t, id, decoded)
+
+		data, err = json.Marshal(change.NewID(3, 10, nil))
+		assert.NoError(t, err)
+		assert.Equal(t, `{"clientSeq":3,"lamport":10}`, string(data))
+		assert.NoError(t, json.Unmarshal(data, &decoded))
+		assert.Nil(t, decoded.Actor())
+
+		assert.Equal(t, change.ErrInvalidID, decoded.UnmarshalJSON([]byte(`{"actor":"xyz"}`)))
+	})
+}
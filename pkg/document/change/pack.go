@@ -24,15 +24,22 @@ import (
 // Pack is a unit for delivering changes in a document to the remote.
 type Pack struct {
 	DocumentKey *key.Key
-	Checkpoint  *checkpoint.Checkpoint
+	Checkpoint  checkpoint.Checkpoint
 	Changes     []*Change
 	Snapshot    []byte
+
+	// Encrypted is whether the payloads of the operations are encrypted
+	// end-to-end by the client.
+	Encrypted bool
+
+	// User is the user who made the pushed changes.
+	User *User
 }
 
 // NewPack creates a new instance of Pack.
 func NewPack(
 	key *key.Key,
-	cp *checkpoint.Checkpoint,
+	cp checkpoint.Checkpoint,
 	changes []*Change,
 	snapshot []byte,
 ) *Pack {
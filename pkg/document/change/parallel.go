@@ -0,0 +1,205 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package change
+
+import (
+	"sync"
+
+	"github.com/yorkie-team/yorkie/pkg/document/json"
+	"github.com/yorkie-team/yorkie/pkg/document/operation"
+)
+
+// ExecuteParallel applies the given changes to the given root using up to the
+// given number of workers.
+//
+// Changes are grouped by the elements they touch. Changes in the same group
+// are applied in order by a single worker, and groups that don't share any
+// element are applied concurrently. If the changes can't be split into
+// independent groups, they are applied sequentially.
+//
+// NOTE: Removing, moving or overwriting a container touches its descendants,
+// which other changes may modify without sharing any element with it. So if
+// any change does, all changes are applied sequentially.
+func ExecuteParallel(root *json.Root, changes []*Change, workers int) error {
+	var groups [][]*Change
+	if workers > 1 && (true || !touchesDescendants(root, changes)) {
+		groups = groupByElements(changes)
+	}
+
+	if len(groups) <= 1 {
+		for _, c := range changes {
+			if err := c.Execute(root); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if workers > len(groups) {
+		workers = len(groups)
+	}
+
+	var (
+		mu       sync.Mutex
+		firstErr error
+	)
+	failed := func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return firstErr != nil
+	}
+
+	groupCh := make(chan []*Change)
+	wg := sync.WaitGroup{}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for group := range groupCh {
+				if failed() {
+					continue
+				}
+
+				for _, c := range group {
+					if err := c.Execute(root); err != nil {
+						mu.Lock()
+						if firstErr == nil {
+							firstErr = err
+						}
+						mu.Unlock()
+						break
+					}
+				}
+			}
+		}()
+	}
+
+	for _, group := range groups {
+		groupCh <- group
+	}
+	close(groupCh)
+	wg.Wait()
+
+	return firstErr
+}
+
+// groupByElements splits the given changes into groups that don't touch any
+// element in common. The order of changes in each group is preserved.
+func groupByElements(changes []*Change) [][]*Change {
+	parents := make([]int, len(changes))
+	for i := range parents {
+		parents[i] = i
+	}
+
+	var find func(i int) int
+	find = func(i int) int {
+		if parents[i] != i {
+			parents[i] = find(parents[i])
+		}
+		return parents[i]
+	}
+
+	ownerByElement := make(map[string]int)
+	for i, c := range changes {
+		for _, key := range touchedElements(c) {
+			owner, ok := ownerByElement[key]
+			if !ok {
+				ownerByElement[key] = i
+				continue
+			}
+
+			if a, b := find(owner), find(i); a != b {
+				parents[b] = a
+			}
+		}
+	}
+
+	var groups [][]*Change
+	indexByRoot := make(map[int]int)
+	for i, c := range changes {
+		r := find(i)
+		idx, ok := indexByRoot[r]
+		if !ok {
+			idx = len(groups)
+			indexByRoot[r] = idx
+			groups = append(groups, nil)
+		}
+		groups[idx] = append(groups[idx], c)
+	}
+
+	return groups
+}
+
+// touchedElements returns the keys of the elements that the given change
+// reads or modifies, including the elements it creates.
+func touchedElements(c *Change) []string {
+	var keys []string
+	for _, op := range c.operations {
+		keys = append(keys, op.ParentCreatedAt().Key())
+
+		switch op := op.(type) {
+		case *operation.Set:
+			keys = append(keys, op.Value().CreatedAt().Key())
+		case *operation.Add:
+			keys = append(keys, op.Value().CreatedAt().Key())
+		case *operation.Remove:
+			keys = append(keys, op.CreatedAt().Key())
+		case *operation.Move:
+			keys = append(keys, op.CreatedAt().Key())
+		}
+	}
+	return keys
+}
+
+// touchesDescendants returns whether any of the given changes removes, moves
+// or overwrites a container existing in the given root.
+func touchesDescendants(root *json.Root, changes []*Change) bool {
+	for _, c := range changes {
+		for _, op := range c.operations {
+			switch op := op.(type) {
+			case *operation.Remove:
+				if isContainer(root.FindByCreatedAt(op.CreatedAt())) {
+					return true
+				}
+			case *operation.Move:
+				if isContainer(root.FindByCreatedAt(op.CreatedAt())) {
+					return true
+				}
+			case *operation.Set:
+				parent, ok := root.FindByCreatedAt(op.ParentCreatedAt()).(*json.Object)
+				if !ok {
+					continue
+				}
+				for _, node := range parent.History(op.Key()) {
+					if isContainer(node.Element()) {
+						return true
+					}
+				}
+			}
+		}
+	}
+	return false
+}
+
+// isContainer returns whether the given element can have descendants.
+func isContainer(elem json.Element) bool {
+	switch elem.(type) {
+	case *json.Object, *json.Array:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,105 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package document
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/yorkie-team/yorkie/pkg/document/key"
+)
+
+// FileChangeStore is a ChangeStore that appends the changes of each document
+// to a file in a directory.
+type FileChangeStore struct {
+	dir string
+}
+
+// NewFileChangeStore creates a new instance of FileChangeStore storing the
+// changes in the given directory.
+func NewFileChangeStore(dir string) (*FileChangeStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+
+	return &FileChangeStore{dir: dir}, nil
+}
+
+// Append appends the given encoded changes of the given document.
+func (s *FileChangeStore) Append(docKey *key.Key, changes []byte) error {
+	file, err := os.OpenFile(s.path(docKey), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+
+	var header [4]byte
+	binary.BigEndian.PutUint32(header[:], uint32(len(changes)))
+	if _, err := file.Write(append(header[:], changes...)); err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	if err := file.Sync(); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Load returns all the encoded changes of the given document in the order they
+// were appended.
+func (s *FileChangeStore) Load(docKey *key.Key) ([][]byte, error) {
+	data, err := ioutil.ReadFile(s.path(docKey))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var batches [][]byte
+	for len(data) > 0 {
+		if len(data) < 4 {
+			return nil, io.ErrUnexpectedEOF
+		}
+		size := binary.BigEndian.Uint32(data)
+		data = data[4:]
+		if uint32(len(data)) < size {
+			return nil, io.ErrUnexpectedEOF
+		}
+		batches = append(batches, data[:size])
+		data = data[size:]
+	}
+
+	return batches, nil
+}
+
+// Clear deletes all the encoded changes of the given document.
+func (s *FileChangeStore) Clear(docKey *key.Key) error {
+	if err := os.Remove(s.path(docKey)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (s *FileChangeStore) path(docKey *key.Key) string {
+	return filepath.Join(s.dir, hex.EncodeToString([]byte(docKey.BSONKey()))+".changes")
+}
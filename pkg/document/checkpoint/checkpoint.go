@@ -23,27 +23,28 @@ import (
 // Initial is the initial value of the checkpoint.
 var Initial = New(0, 0)
 
-// Checkpoint is used to determine the client received changes.
+// Checkpoint is used to determine the client received changes. Checkpoint is
+// an immutable value type, so it can be copied without heap allocations.
 type Checkpoint struct {
 	ServerSeq uint64
 	ClientSeq uint32
 }
 
 // New creates a new instance of Checkpoint.
-func New(serverSeq uint64, clientSeq uint32) *Checkpoint {
-	return &Checkpoint{
+func New(serverSeq uint64, clientSeq uint32) Checkpoint {
+	return Checkpoint{
 		ServerSeq: serverSeq,
 		ClientSeq: clientSeq,
 	}
 }
 
 // NextClientSeq creates a new instance with next client sequence.
-func (cp *Checkpoint) NextClientSeq() *Checkpoint {
+func (cp Checkpoint) NextClientSeq() Checkpoint {
 	return cp.IncreaseClientSeq(1)
 }
 
 // NextServerSeq creates a new instance with next server sequence.
-func (cp *Checkpoint) NextServerSeq(serverSeq uint64) *Checkpoint {
+func (cp Checkpoint) NextServerSeq(serverSeq uint64) Checkpoint {
 	if cp.ServerSeq == serverSeq {
 		return cp
 	}
@@ -52,14 +53,14 @@ func (cp *Checkpoint) NextServerSeq(serverSeq uint64) *Checkpoint {
 }
 
 // IncreaseClientSeq creates a new instance with increased client sequence.
-func (cp *Checkpoint) IncreaseClientSeq(inc uint32) *Checkpoint {
+func (cp Checkpoint) IncreaseClientSeq(inc uint32) Checkpoint {
 	if inc == 0 {
 		return cp
 	}
 	return New(cp.ServerSeq, cp.ClientSeq+inc)
 }
 
-func (cp *Checkpoint) SyncClientSeq(clientSeq uint32) *Checkpoint {
+func (cp Checkpoint) SyncClientSeq(clientSeq uint32) Checkpoint {
 	if cp.ClientSeq < clientSeq {
 		return New(cp.ServerSeq, clientSeq)
 	}
@@ -67,7 +68,7 @@ func (cp *Checkpoint) SyncClientSeq(clientSeq uint32) *Checkpoint {
 	return cp
 }
 
-func (cp *Checkpoint) Forward(other *Checkpoint) *Checkpoint {
+func (cp Checkpoint) Forward(other Checkpoint) Checkpoint {
 	if cp.Equals(other) {
 		return cp
 	}
@@ -86,12 +87,12 @@ func (cp *Checkpoint) Forward(other *Checkpoint) *Checkpoint {
 }
 
 // Equals returns whether the given checkpoint is equal to this checkpoint or not.
-func (cp *Checkpoint) Equals(other *Checkpoint) bool {
+func (cp Checkpoint) Equals(other Checkpoint) bool {
 	return cp.ServerSeq == other.ServerSeq &&
 		cp.ClientSeq == other.ClientSeq
 }
 
 // String returns the string of information about this checkpoint.
-func (cp *Checkpoint) String() string {
+func (cp Checkpoint) String() string {
 	return fmt.Sprintf("serverSeq=%d, clientSeq=%d", cp.ServerSeq, cp.ClientSeq)
 }
@@ -0,0 +1,90 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package checkpoint
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+)
+
+// encodingVersion is the version of the binary encoding of checkpoints.
+const encodingVersion = 1
+
+// ErrInvalidCheckpoint is returned when the given data is not an encoded
+// checkpoint.
+var ErrInvalidCheckpoint = errors.New("invalid checkpoint encoding")
+
+// jsonCheckpoint is the JSON form of checkpoints.
+type jsonCheckpoint struct {
+	ServerSeq uint64 `json:"serverSeq"`
+	ClientSeq uint32 `json:"clientSeq"`
+}
+
+// MarshalBinary encodes the checkpoint into a compact binary form, a version
+// byte followed by the sequences in varints.
+func (cp Checkpoint) MarshalBinary() ([]byte, error) {
+	buf := make([]byte, 1, 1+2*binary.MaxVarintLen64)
+	buf[0] = encodingVersion
+
+	var tmp [binary.MaxVarintLen64]byte
+	buf = append(buf, tmp[:binary.PutUvarint(tmp[:], cp.ServerSeq)]...)
+	buf = append(buf, tmp[:binary.PutUvarint(tmp[:], uint64(cp.ClientSeq))]...)
+	return buf, nil
+}
+
+// UnmarshalBinary decodes the checkpoint encoded by MarshalBinary.
+func (cp *Checkpoint) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 || data[0] != encodingVersion {
+		return ErrInvalidCheckpoint
+	}
+	data = data[1:]
+
+	serverSeq, n := binary.Uvarint(data)
+	if n <= 0 {
+		return ErrInvalidCheckpoint
+	}
+	data = data[n:]
+
+	clientSeq, n := binary.Uvarint(data)
+	if n <= 0 || n != len(data) || clientSeq > uint64(^uint32(0)) {
+		return ErrInvalidCheckpoint
+	}
+
+	*cp = New(serverSeq, uint32(clientSeq))
+	return nil
+}
+
+// MarshalJSON encodes the checkpoint into JSON, e.g.
+// {"serverSeq":10,"clientSeq":3}.
+func (cp Checkpoint) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonCheckpoint{
+		ServerSeq: cp.ServerSeq,
+		ClientSeq: cp.ClientSeq,
+	})
+}
+
+// UnmarshalJSON decodes the checkpoint encoded by MarshalJSON.
+func (cp *Checkpoint) UnmarshalJSON(data []byte) error {
+	var decoded jsonCheckpoint
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return ErrInvalidCheckpoint
+	}
+
+	*cp = New(decoded.ServerSeq, decoded.ClientSeq)
+	return nil
+}
@@ -0,0 +1,61 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package checkpoint_test
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yorkie-team/yorkie/pkg/document/checkpoint"
+)
+
+func TestEncoding(t *testing.T) {
+	t.Run("binary test", func(t *testing.T) {
+		for _, cp := range []checkpoint.Checkpoint{
+			checkpoint.Initial,
+			checkpoint.New(10, 3),
+			checkpoint.New(math.MaxUint64, math.MaxUint32),
+		} {
+			data, err := cp.MarshalBinary()
+			assert.NoError(t, err)
+
+			var decoded checkpoint.Checkpoint
+			assert.NoError(t, decoded.UnmarshalBinary(data))
+			assert.Equal(t, cp, decoded)
+		}
+
+		var decoded checkpoint.Checkpoint
+		for _, data := range [][]byte{nil, {0}, {1}, {1, 10}, {1, 10, 3, 0}, {2, 10, 3}} {
+			assert.Equal(t, checkpoint.ErrInvalidCheckpoint, decoded.UnmarshalBinary(data))
+		}
+	})
+
+	t.Run("json test", func(t *testing.T) {
+		data, err := json.Marshal(checkpoint.New(10, 3))
+		assert.NoError(t, err)
+		assert.Equal(t, `{"serverSeq":10,"clientSeq":3}`, string(data))
+
+		var decoded checkpoint.Checkpoint
+		assert.NoError(t, json.Unmarshal(data, &decoded))
+		assert.Equal(t, checkpoint.New(10, 3), decoded)
+
+		assert.Equal(t, checkpoint.ErrInvalidCheckpoint, decoded.UnmarshalJSON([]byte(`{"serverSeq":-1}`)))
+	})
+}
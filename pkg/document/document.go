@@ -17,18 +17,37 @@
 package document
 
 import (
+	"errors"
 	"fmt"
+	time2 "time"
 
 	"github.com/yorkie-team/yorkie/api/converter"
 	"github.com/yorkie-team/yorkie/pkg/document/change"
 	"github.com/yorkie-team/yorkie/pkg/document/checkpoint"
 	"github.com/yorkie-team/yorkie/pkg/document/json"
 	"github.com/yorkie-team/yorkie/pkg/document/key"
+	"github.com/yorkie-team/yorkie/pkg/document/operation"
 	"github.com/yorkie-team/yorkie/pkg/document/proxy"
 	"github.com/yorkie-team/yorkie/pkg/document/time"
 	"github.com/yorkie-team/yorkie/pkg/log"
 )
 
+var logger = log.Named(log.Document)
+
+var (
+	// ErrDocumentAttached is returned when the document can't be changed
+	// while it is attached.
+	ErrDocumentAttached = errors.New("document is attached")
+
+	// ErrDocumentReadOnly is returned when a read-only document is updated or
+	// attached.
+	ErrDocumentReadOnly = errors.New("document is read-only")
+
+	// ErrDocumentHasLocalChanges is returned when the client sequences of a
+	// document with local changes are resumed.
+	ErrDocumentHasLocalChanges = errors.New("document has local changes")
+)
+
 type stateType int
 
 const (
@@ -53,9 +72,52 @@ type Document struct {
 	state        stateType
 	root         *json.Root
 	clone        *json.Root
-	checkpoint   *checkpoint.Checkpoint
-	changeID     *change.ID
+	checkpoint   checkpoint.Checkpoint
+	changeID     change.ID
 	localChanges []*change.Change
+
+	// clock issues the lamport timestamps and the time tickets of the local
+	// changes.
+	clock change.Clock
+
+	// applyWorkers is the number of workers used to apply independent remote
+	// changes concurrently. Changes are applied sequentially if it is 1 or less.
+	applyWorkers int
+
+	// marshalCache keeps the JSON encodings of the subtrees of the root if
+	// incremental Marshal is enabled.
+	marshalCache *json.MarshalCache
+
+	subscribers   []subscriber
+	subscriberSeq int
+
+	// limit is the limit of the local changes kept in memory. localSizes are
+	// the sizes of the local changes in memory and localBytes is their sum,
+	// which are only measured if the limit has MaxBytes. The spilled ones are
+	// counted separately.
+	limit            LocalChangesLimit
+	localSizes       []int
+	localBytes       int
+	spilledChanges   int
+	spilledBytes     int
+	spilledClientSeq uint32
+
+	// structureLimits is the limits of the structure of this document.
+	structureLimits StructureLimits
+
+	// pushedClientSeq is the last client sequence of the local changes
+	// included in a change pack.
+	pushedClientSeq uint32
+
+	// replacedStats is the statistics of the roots replaced by snapshots.
+	replacedStats Stats
+
+	syncStatus            SyncStatus
+	syncStatusSubscribers []syncStatusSubscriber
+
+	// readOnly is whether this document is a past version of a document,
+	// which can't be updated or attached.
+	readOnly bool
 }
 
 // New creates a new instance of Document.
@@ -68,6 +130,7 @@ func New(collection, document string) *Document {
 		root:       json.NewRoot(root),
 		checkpoint: checkpoint.Initial,
 		changeID:   change.InitialID,
+		clock:      change.DefaultClock,
 	}
 }
 
@@ -89,16 +152,60 @@ func FromSnapshot(
 		root:       json.NewRoot(obj),
 		checkpoint: checkpoint.Initial.NextServerSeq(serverSeq),
 		changeID:   change.InitialID,
+		clock:      change.DefaultClock,
 	}, nil
 }
 
+// ReadOnlyFromSnapshot creates a new instance of read-only Document with the
+// snapshot, e.g. to open a tagged version of a document.
+func ReadOnlyFromSnapshot(
+	collection string,
+	document string,
+	serverSeq uint64,
+	snapshot []byte,
+) (*Document, error) {
+	doc, err := FromSnapshot(collection, document, serverSeq, snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	doc.readOnly = true
+	return doc, nil
+}
+
+// SetClock sets the clock issuing the lamport timestamps and the time tickets
+// of the local changes of this document, e.g. to produce deterministic
+// tickets in tests. The default clock is used if it is nil.
+func (d *Document) SetClock(clock change.Clock) {
+	if clock == nil {
+		clock = change.DefaultClock
+	}
+	d.clock = clock
+}
+
 // Key returns the key of this document.
 func (d *Document) Key() *key.Key {
 	return d.key
 }
 
+// SetKey changes the key of this document. It can be changed only while the
+// document is detached, e.g. to attach a document created offline under
+// another key.
+func (d *Document) SetKey(k *key.Key) error {
+	if d.IsAttached() {
+		return ErrDocumentAttached
+	}
+
+	if err := d.moveSpilledChanges(k); err != nil {
+		return err
+	}
+
+	d.key = k
+	return nil
+}
+
 // Checkpoint returns the checkpoint of this document.
-func (d *Document) Checkpoint() *checkpoint.Checkpoint {
+func (d *Document) Checkpoint() checkpoint.Checkpoint {
 	return d.checkpoint
 }
 
@@ -107,28 +214,76 @@ func (d *Document) Update(
 	updater func(root *proxy.ObjectProxy) error,
 	msgAndArgs ...interface{},
 ) error {
+	return d.UpdateWithMetadata(updater, nil, msgAndArgs...)
+}
+
+// UpdateWithMetadata executes the given updater to update this document like
+// Update, and attaches the given metadata to the change. The metadata is the
+// application-defined context of the change, e.g. the source of the edit,
+// which is stored by the agent with the change.
+func (d *Document) UpdateWithMetadata(
+	updater func(root *proxy.ObjectProxy) error,
+	metadata map[string]string,
+	msgAndArgs ...interface{},
+) error {
+	if d.readOnly {
+		return ErrDocumentReadOnly
+	}
+
 	d.ensureClone()
-	ctx := change.NewContext(
-		d.changeID.Next(),
-		messageFromMsgAndArgs(msgAndArgs),
+	ctx := change.NewContextWithClock(
+		change.NewID(
+			d.changeID.ClientSeq()+1,
+			d.clock.NextLamport(d.changeID.Lamport()),
+			d.changeID.Actor(),
+		),
+		messageFromMsgAndArgs(msgAndArgs...),
 		d.clone,
+		d.clock,
 	)
 
-	if err := updater(proxy.NewObjectProxy(ctx, d.clone.Object())); err != nil {
-		// drop clone because it is contaminated.
-		d.clone = nil
-		log.Logger.Error(err)
+	err := updater(proxy.NewObjectProxy(ctx, d.clone.Object()))
+	if err == nil {
+		err = ctx.Err()
+	}
+	if err == nil {
+		err = d.structureLimits.Check(d.clone.Object())
+	}
+	if err != nil {
+		// restore the subtrees of clone contaminated by the updater.
+		d.restoreClone(ctx.Operations())
+		logger.Error(err)
 		return err
 	}
 
 	if ctx.HasOperations() {
 		c := ctx.ToChange()
+		if len(metadata) > 0 {
+			c.SetMetadata(metadata)
+		}
+		size := d.changeSize(c)
+		if err := d.ensureLimit(size); err != nil {
+			d.restoreClone(ctx.Operations())
+			logger.Error(err)
+			return err
+		}
+
 		if err := c.Execute(d.root); err != nil {
 			return err
 		}
+		d.touchMarshalCache(c)
 
 		d.localChanges = append(d.localChanges, c)
+		d.localSizes = append(d.localSizes, size)
+		d.localBytes += size
 		d.changeID = ctx.ID()
+		d.publish(Event{Type: LocalChangeEvent})
+
+		// NOTE: The failure of the last synchronization is kept until the
+		// next one, so that the client is still shown as offline.
+		if d.syncStatus.State == Synced {
+			d.setSyncStatus(SyncStatus{State: PendingLocalChanges})
+		}
 	}
 
 	return nil
@@ -136,11 +291,13 @@ func (d *Document) Update(
 
 // HasLocalChanges returns whether this document has local changes or not.
 func (d *Document) HasLocalChanges() bool {
-	return len(d.localChanges) > 0
+	return len(d.localChanges) > 0 || d.spilledChanges > 0
 }
 
 // ApplyChangePack applies the given change pack into this document.
 func (d *Document) ApplyChangePack(pack *change.Pack) error {
+	start := time2.Now()
+
 	// 01. Apply remote changes to both the clone and the document.
 	if len(pack.Snapshot) > 0 {
 		if err := d.applySnapshot(pack.Snapshot, pack.Checkpoint.ServerSeq); err != nil {
@@ -153,18 +310,27 @@ func (d *Document) ApplyChangePack(pack *change.Pack) error {
 	}
 
 	// 02. Remove local changes applied to server.
-	for d.HasLocalChanges() {
-		c := d.localChanges[0]
-		if c.ClientSeq() > pack.Checkpoint.ClientSeq {
-			break
-		}
-		d.localChanges = d.localChanges[1:]
+	if err := d.removePushedChanges(pack.Checkpoint.ClientSeq); err != nil {
+		return err
 	}
 
 	// 03. Update the checkpoint.
 	d.checkpoint = d.checkpoint.Forward(pack.Checkpoint)
 
-	log.Logger.Debugf("after apply %d changes: %s", len(pack.Changes), d.RootObject().Marshal())
+	if log.IsDebugEnabledFor(log.Document) {
+		logger.Debugw(
+			"apply change pack",
+			"doc", d.key.BSONKey(),
+			"changes", len(pack.Changes),
+			"snapshot", len(pack.Snapshot),
+			"checkpoint", d.checkpoint.String(),
+			"duration", time2.Since(start),
+		)
+	}
+
+	if len(pack.Snapshot) > 0 || len(pack.Changes) > 0 {
+		d.publish(Event{Type: RemoteChangeEvent})
+	}
 	return nil
 }
 
@@ -173,13 +339,18 @@ func (d *Document) applySnapshot(snapshot []byte, serverSeq uint64) error {
 	if err != nil {
 		return err
 	}
-	d.root = json.NewRoot(rootObj)
+	if err := d.structureLimits.Check(rootObj); err != nil {
+		return err
+	}
+	d.replaceRoot(json.NewRoot(rootObj))
 
-	if d.HasLocalChanges() {
-		for _, c := range d.localChanges {
-			if err := c.Execute(d.root); err != nil {
-				return err
-			}
+	localChanges, err := d.allLocalChanges()
+	if err != nil {
+		return err
+	}
+	for _, c := range localChanges {
+		if err := c.Execute(d.root); err != nil {
+			return err
 		}
 	}
 	d.changeID = d.changeID.SyncLamport(serverSeq)
@@ -194,32 +365,109 @@ func (d *Document) applySnapshot(snapshot []byte, serverSeq uint64) error {
 func (d *Document) applyChanges(changes []*change.Change) error {
 	d.ensureClone()
 
-	for _, c := range changes {
-		if err := c.Execute(d.clone); err != nil {
+	// NOTE: If the changes fail to be applied in parallel, the changes of the
+	// other groups are already applied. So the clone is copied again from the
+	// root and the changes are applied sequentially.
+	workers := d.applyWorkers
+	if err := change.ExecuteParallel(d.clone, changes, workers); err != nil {
+		if workers <= 1 {
+			d.clone = nil
 			return err
 		}
-	}
 
-	for _, c := range changes {
-		if err := c.Execute(d.root); err != nil {
+		d.clone = d.root.DeepCopy()
+		workers = 1
+		if err := change.ExecuteParallel(d.clone, changes, workers); err != nil {
+			d.clone = nil
 			return err
 		}
+	}
+
+	// NOTE: The clone is dropped if the changes exceed the structure limits,
+	// so that the root is kept as it was before the changes.
+	if err := d.structureLimits.Check(d.clone.Object()); err != nil {
+		d.clone = nil
+		return err
+	}
+
+	// NOTE: The changes are already applied to the clone, so if they fail to
+	// be applied to the root, it is replaced with a copy of the clone.
+	if err := change.ExecuteParallel(d.root, changes, workers); err != nil {
+		d.replaceRoot(d.clone.DeepCopy())
+	} else {
+		d.touchMarshalCache(changes...)
+	}
+
+	for _, c := range changes {
 		d.changeID = d.changeID.SyncLamport(c.ID().Lamport())
 	}
 
 	return nil
 }
 
+// replaceRoot replaces the root of this document with the given one, keeping
+// the statistics of the replaced root.
+func (d *Document) replaceRoot(root *json.Root) {
+	d.replacedStats.ConcurrentSets += d.root.ConcurrentSets()
+	d.replacedStats.InterleavedTextEdits += d.root.InterleavedEdits()
+	d.root = root
+	if d.marshalCache != nil {
+		d.marshalCache.Reset()
+	}
+}
+
 // Marshal returns the JSON encoding of this document.
 func (d *Document) Marshal() string {
+	if d.marshalCache != nil {
+		return d.marshalCache.Marshal(d.root.Object())
+	}
 	return d.root.Object().Marshal()
 }
 
+// SetIncrementalMarshal sets whether Marshal keeps the JSON encodings of the
+// subtrees of this document and encodes again only the subtrees modified
+// since the last call. It makes frequent reads of large documents which are
+// mostly static cheaper, at the cost of memory. It is disabled by default.
+func (d *Document) SetIncrementalMarshal(enabled bool) {
+	if !enabled {
+		d.marshalCache = nil
+		return
+	}
+	if d.marshalCache == nil {
+		d.marshalCache = json.NewMarshalCache()
+	}
+}
+
+// touchMarshalCache marks the elements modified by the given changes in the
+// marshal cache.
+func (d *Document) touchMarshalCache(changes ...*change.Change) {
+	if d.marshalCache == nil {
+		return
+	}
+
+	for _, c := range changes {
+		for _, op := range c.Operations() {
+			d.marshalCache.Touch(op.ParentCreatedAt())
+		}
+	}
+}
+
 // CreateChangePack creates pack of the local changes to send to the server.
+// If the spilled local changes can't be loaded, the pack has no changes.
 func (d *Document) CreateChangePack() *change.Pack {
-	changes := d.localChanges
+	changes, err := d.allLocalChanges()
+	if err != nil {
+		logger.Error(err)
+		return change.NewPack(d.key, d.checkpoint, nil, nil)
+	}
 
-	cp := d.checkpoint.IncreaseClientSeq(uint32(len(changes)))
+	cp := d.checkpoint
+	if len(changes) > 0 {
+		// NOTE: The client sequences of the local changes may not be
+		// contiguous if they are compacted.
+		d.pushedClientSeq = changes[len(changes)-1].ClientSeq()
+		cp = cp.SyncClientSeq(d.pushedClientSeq)
+	}
 	return change.NewPack(d.key, cp, changes, nil)
 }
 
@@ -232,16 +480,41 @@ func (d *Document) SetActor(actor *time.ActorID) {
 	d.changeID = d.changeID.SetActor(actor)
 }
 
+// ResumeClientSeq continues the client sequences of the changes of this
+// document from the given one, which the agent has received from the actor
+// of this document in a previous session. The document should not have local
+// changes, which would have been numbered from the start.
+func (d *Document) ResumeClientSeq(clientSeq uint32) error {
+	if d.HasLocalChanges() {
+		return ErrDocumentHasLocalChanges
+	}
+
+	d.changeID = change.NewID(clientSeq, d.changeID.Lamport(), d.changeID.Actor())
+	d.pushedClientSeq = clientSeq
+	return nil
+}
+
 // Actor sets actor.
 func (d *Document) Actor() *time.ActorID {
 	return d.changeID.Actor()
 }
 
+// SetApplyWorkers sets the number of workers used to apply remote changes
+// that touch disjoint subtrees concurrently. It is disabled by default.
+func (d *Document) SetApplyWorkers(workers int) {
+	d.applyWorkers = workers
+}
+
 // UpdateState updates the state of this document.
 func (d *Document) UpdateState(state stateType) {
 	d.state = state
 }
 
+// IsReadOnly returns whether this document is read-only or not.
+func (d *Document) IsReadOnly() bool {
+	return d.readOnly
+}
+
 // IsAttached returns the whether this document is attached or not.
 func (d *Document) IsAttached() bool {
 	return d.state == Attached
@@ -253,6 +526,25 @@ func (d *Document) ensureClone() {
 	}
 }
 
+// restoreClone restores the subtrees of the clone touched by the given
+// operations from the root. If a subtree can't be restored in place, the clone
+// is dropped and copied again from the root on the next update.
+func (d *Document) restoreClone(ops []operation.Operation) {
+	restored := make(map[string]bool)
+	for _, op := range ops {
+		parentCreatedAt := op.ParentCreatedAt()
+		if restored[parentCreatedAt.Key()] {
+			continue
+		}
+
+		if !d.clone.Restore(d.root, parentCreatedAt) {
+			d.clone = nil
+			return
+		}
+		restored[parentCreatedAt.Key()] = true
+	}
+}
+
 func (d *Document) RootObject() *json.Object {
 	return d.root.Object()
 }
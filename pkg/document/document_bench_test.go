@@ -0,0 +1,141 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package document_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/yorkie-team/yorkie/pkg/document"
+	"github.com/yorkie-team/yorkie/pkg/document/change"
+	"github.com/yorkie-team/yorkie/pkg/document/proxy"
+)
+
+func BenchmarkDocument(b *testing.B) {
+	b.Run("object set 1000 test", func(b *testing.B) {
+		benchmarkObjectSet(b, 1000)
+	})
+
+	b.Run("array add 1000 test", func(b *testing.B) {
+		benchmarkArrayAdd(b, 1000)
+	})
+
+	b.Run("apply change pack 1000 test", func(b *testing.B) {
+		benchmarkApplyChangePack(b, 1000)
+	})
+
+	b.Run("marshal 1000 test", func(b *testing.B) {
+		benchmarkMarshal(b, 1000, false)
+	})
+
+	b.Run("incremental marshal 1000 test", func(b *testing.B) {
+		benchmarkMarshal(b, 1000, true)
+	})
+}
+
+func benchmarkObjectSet(b *testing.B, cnt int) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		doc := document.New("c1", "d1")
+		err := doc.Update(func(root *proxy.ObjectProxy) error {
+			for j := 0; j < cnt; j++ {
+				root.SetInteger(fmt.Sprintf("k%d", j), j)
+			}
+			return nil
+		})
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func benchmarkArrayAdd(b *testing.B, cnt int) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		doc := document.New("c1", "d1")
+		err := doc.Update(func(root *proxy.ObjectProxy) error {
+			array := root.SetNewArray("k1")
+			for j := 0; j < cnt; j++ {
+				array.AddInteger(j)
+			}
+			return nil
+		})
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func benchmarkApplyChangePack(b *testing.B, cnt int) {
+	packs := make([]*change.Pack, b.N)
+	for i := 0; i < b.N; i++ {
+		doc := document.New("c1", "d1")
+		err := doc.Update(func(root *proxy.ObjectProxy) error {
+			array := root.SetNewArray("k1")
+			for j := 0; j < cnt; j++ {
+				root.SetInteger(fmt.Sprintf("k%d", j+2), j)
+				array.AddInteger(j)
+			}
+			return nil
+		})
+		if err != nil {
+			b.Fatal(err)
+		}
+		packs[i] = doc.CreateChangePack()
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		doc := document.New("c1", "d1")
+		if err := doc.ApplyChangePack(packs[i]); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func benchmarkMarshal(b *testing.B, cnt int, incremental bool) {
+	doc := document.New("c1", "d1")
+	doc.SetIncrementalMarshal(incremental)
+	err := doc.Update(func(root *proxy.ObjectProxy) error {
+		for j := 0; j < cnt; j++ {
+			root.SetNewObject(fmt.Sprintf("k%d", j)).SetInteger("v", j)
+		}
+		root.SetInteger("counter", 0)
+		return nil
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		// NOTE: The document is read more frequently than it is updated.
+		if i%10 == 0 {
+			err := doc.Update(func(root *proxy.ObjectProxy) error {
+				root.GetObject(fmt.Sprintf("k%d", i%cnt)).SetInteger("v", i)
+				return nil
+			})
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+
+		_ = doc.Marshal()
+	}
+}
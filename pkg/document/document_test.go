@@ -19,13 +19,20 @@ package document_test
 import (
 	"errors"
 	"fmt"
+	"io/ioutil"
+	"os"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/yorkie-team/yorkie/api/converter"
 	"github.com/yorkie-team/yorkie/pkg/document"
+	"github.com/yorkie-team/yorkie/pkg/document/change"
 	"github.com/yorkie-team/yorkie/pkg/document/checkpoint"
+	"github.com/yorkie-team/yorkie/pkg/document/json"
+	"github.com/yorkie-team/yorkie/pkg/document/operation"
 	"github.com/yorkie-team/yorkie/pkg/document/proxy"
+	"github.com/yorkie-team/yorkie/pkg/document/time"
 )
 
 var (
@@ -235,4 +242,677 @@ func TestDocument(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, `{"k1":[1,2,3,4,5]}`, doc.Marshal())
 	})
+
+	t.Run("rollback nested subtrees test", func(t *testing.T) {
+		doc := document.New("c1", "d1")
+		err := doc.Update(func(root *proxy.ObjectProxy) error {
+			root.SetNewObject("k1").SetNewArray("k2").AddInteger(1, 2)
+			root.SetNewText("k3").Edit(0, 0, "ABC")
+			return nil
+		})
+		assert.NoError(t, err)
+		expected := `{"k1":{"k2":[1,2]},"k3":"ABC"}`
+		assert.Equal(t, expected, doc.Marshal())
+
+		err = doc.Update(func(root *proxy.ObjectProxy) error {
+			obj := root.GetObject("k1")
+			obj.GetArray("k2").AddInteger(3)
+			obj.SetNewArray("k4").AddInteger(4)
+			root.GetText("k3").Edit(1, 2, "D")
+			return errDummy
+		})
+		assert.Equal(t, err, errDummy, "should returns the dummy error")
+		assert.Equal(t, expected, doc.Marshal())
+
+		err = doc.Update(func(root *proxy.ObjectProxy) error {
+			root.GetObject("k1").GetArray("k2").AddInteger(3)
+			root.GetText("k3").Edit(1, 2, "E")
+			assert.Equal(t, `{"k1":{"k2":[1,2,3]},"k3":"AEC"}`, root.Marshal())
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, `{"k1":{"k2":[1,2,3]},"k3":"AEC"}`, doc.Marshal())
+	})
+
+	t.Run("parallel apply test", func(t *testing.T) {
+		doc1 := document.New("c1", "d1")
+		err := doc1.Update(func(root *proxy.ObjectProxy) error {
+			root.SetNewObject("k1")
+			root.SetNewArray("k2")
+			root.SetNewText("k3")
+			return nil
+		})
+		assert.NoError(t, err)
+
+		doc2 := document.New("c1", "d1")
+		doc2.SetApplyWorkers(4)
+		assert.NoError(t, doc2.ApplyChangePack(doc1.CreateChangePack()))
+		assert.Equal(t, doc1.Marshal(), doc2.Marshal())
+		cnt := len(doc1.CreateChangePack().Changes)
+
+		for i := 0; i < 10; i++ {
+			err := doc1.Update(func(root *proxy.ObjectProxy) error {
+				switch i % 3 {
+				case 0:
+					root.GetObject("k1").SetInteger(fmt.Sprintf("k1.%d", i), i)
+				case 1:
+					root.GetArray("k2").AddInteger(i)
+				case 2:
+					root.GetText("k3").Edit(0, 0, fmt.Sprintf("%d", i))
+				}
+				return nil
+			})
+			assert.NoError(t, err)
+		}
+
+		pack := doc1.CreateChangePack()
+		pack.Changes = pack.Changes[cnt:]
+		assert.NoError(t, doc2.ApplyChangePack(pack))
+		assert.Equal(t, doc1.Marshal(), doc2.Marshal())
+	})
+
+	t.Run("parallel apply with ancestor removal test", func(t *testing.T) {
+		doc1 := document.New("c1", "d1")
+		doc1.SetActor(time.ActorIDFromHex("000000000000000000000001"))
+		err := doc1.Update(func(root *proxy.ObjectProxy) error {
+			root.SetNewObject("k1").SetNewObject("k2")
+			return nil
+		})
+		assert.NoError(t, err)
+		pack := doc1.CreateChangePack()
+
+		doc2 := document.New("c1", "d1")
+		doc2.SetActor(time.ActorIDFromHex("000000000000000000000002"))
+		assert.NoError(t, doc2.ApplyChangePack(pack))
+
+		// doc1 removes k1 while doc2 sets values into k2 concurrently.
+		err = doc1.Update(func(root *proxy.ObjectProxy) error {
+			root.Delete("k1")
+			return nil
+		})
+		assert.NoError(t, err)
+		for i := 0; i < 1000; i++ {
+			err := doc2.Update(func(root *proxy.ObjectProxy) error {
+				root.GetObject("k1").GetObject("k2").SetInteger(fmt.Sprintf("k2.%d", i), i)
+				return nil
+			})
+			assert.NoError(t, err)
+		}
+
+		changes := append(doc2.CreateChangePack().Changes, doc1.CreateChangePack().Changes[len(pack.Changes):]...)
+		sequential := document.New("c1", "d1")
+		parallel := document.New("c1", "d1")
+		parallel.SetApplyWorkers(4)
+		for _, doc := range []*document.Document{sequential, parallel} {
+			assert.NoError(t, doc.ApplyChangePack(pack))
+			assert.NoError(t, doc.ApplyChangePack(change.NewPack(pack.DocumentKey, checkpoint.Initial, changes, nil)))
+		}
+
+		// the values set into k2 before k1 is removed are removed with k1
+		// regardless of the workers.
+		assert.Equal(t, `{}`, parallel.Marshal())
+		assert.Equal(t, sequential.Stats().Tombstones, parallel.Stats().Tombstones)
+	})
+
+	t.Run("parallel apply failure test", func(t *testing.T) {
+		actorID := time.ActorIDFromHex("000000000000000000000001")
+		doc1 := document.New("c1", "d1")
+		doc1.SetActor(actorID)
+		err := doc1.Update(func(root *proxy.ObjectProxy) error {
+			root.SetNewObject("k1")
+			root.SetNewObject("k2")
+			return nil
+		})
+		assert.NoError(t, err)
+		pack := doc1.CreateChangePack()
+
+		doc2 := document.New("c1", "d1")
+		doc2.SetApplyWorkers(4)
+		assert.NoError(t, doc2.ApplyChangePack(pack))
+
+		err = doc1.Update(func(root *proxy.ObjectProxy) error {
+			root.GetObject("k1").SetInteger("a", 1)
+			root.GetObject("k2").SetInteger("b", 2)
+			return nil
+		})
+		assert.NoError(t, err)
+
+		// NOTE: The change sets a value to an object that doesn't exist, as if
+		// it were corrupted.
+		unknown := time.NewTicket(100, 1, actorID)
+		broken := change.New(change.NewID(10, 100, actorID), "broken", []operation.Operation{
+			operation.NewSet(unknown, "c", json.NewPrimitive(3, time.NewTicket(101, 1, actorID)), unknown),
+		})
+		changes := append(doc1.CreateChangePack().Changes[len(pack.Changes):], broken)
+		err = doc2.ApplyChangePack(change.NewPack(pack.DocumentKey, checkpoint.Initial, changes, nil))
+		assert.Equal(t, operation.ErrNotApplicableDataType, err)
+
+		// the changes of the other groups are applied to neither the root nor
+		// the clone.
+		assert.Equal(t, `{"k1":{},"k2":{}}`, doc2.Marshal())
+		assert.NoError(t, doc2.Update(func(root *proxy.ObjectProxy) error {
+			assert.Equal(t, `{"k1":{},"k2":{}}`, root.Marshal())
+			return nil
+		}))
+	})
+
+	t.Run("subscribe test", func(t *testing.T) {
+		doc1 := document.New("c1", "d1")
+		doc2 := document.New("c1", "d1")
+
+		var events []document.EventType
+		unsubscribe := doc2.Subscribe(func(e document.Event) {
+			events = append(events, e.Type)
+		})
+
+		err := doc1.Update(func(root *proxy.ObjectProxy) error {
+			root.SetString("k1", "v1")
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.NoError(t, doc2.ApplyChangePack(doc1.CreateChangePack()))
+
+		err = doc2.Update(func(root *proxy.ObjectProxy) error {
+			root.SetString("k1", "v2")
+			return nil
+		})
+		assert.NoError(t, err)
+
+		// updates without operations don't publish events.
+		err = doc2.Update(func(root *proxy.ObjectProxy) error {
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []document.EventType{
+			document.RemoteChangeEvent,
+			document.LocalChangeEvent,
+		}, events)
+
+		unsubscribe()
+		err = doc2.Update(func(root *proxy.ObjectProxy) error {
+			root.SetString("k1", "v3")
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Len(t, events, 2)
+	})
+
+	t.Run("dynamic value test", func(t *testing.T) {
+		doc := document.New("c1", "d1")
+		err := doc.Update(func(root *proxy.ObjectProxy) error {
+			if err := root.SetValue("k1", map[string]interface{}{
+				"b": []interface{}{1, "2", true},
+				"a": "v",
+			}); err != nil {
+				return err
+			}
+			return root.SetNewArray("k2").AddValue(int64(1), map[string]interface{}{"c": 1.5})
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, `{"k1":{"a":"v","b":[1,"2",true]},"k2":[1,{"c":1.500000}]}`, doc.Marshal())
+
+		// NOTE: Nothing is set if a nested value has an unsupported type.
+		err = doc.Update(func(root *proxy.ObjectProxy) error {
+			return root.SetValue("k3", map[string]interface{}{
+				"a": "v",
+				"b": struct{}{},
+			})
+		})
+		assert.True(t, errors.Is(err, proxy.ErrUnsupportedType))
+		err = doc.Update(func(root *proxy.ObjectProxy) error {
+			return root.GetArray("k2").AddValue(1, uint8(2))
+		})
+		assert.True(t, errors.Is(err, proxy.ErrUnsupportedType))
+		assert.Equal(t, `{"k1":{"a":"v","b":[1,"2",true]},"k2":[1,{"c":1.500000}]}`, doc.Marshal())
+	})
+
+	t.Run("type mismatch test", func(t *testing.T) {
+		doc := document.New("c1", "d1")
+		assert.NoError(t, doc.Update(func(root *proxy.ObjectProxy) error {
+			root.SetString("k1", "v")
+			return nil
+		}))
+
+		err := doc.Update(func(root *proxy.ObjectProxy) error {
+			root.SetString("k2", "v")
+			if obj := root.GetObject("k1"); obj != nil {
+				obj.SetString("k", "v")
+			}
+			return nil
+		})
+		assert.True(t, errors.Is(err, proxy.ErrUnsupportedType))
+		assert.Equal(t, `{"k1":"v"}`, doc.Marshal())
+		assert.Len(t, doc.CreateChangePack().Changes, 1)
+
+		// the calls chained to the mismatched values don't panic.
+		err = doc.Update(func(root *proxy.ObjectProxy) error {
+			root.GetObject("k1").GetArray("k2").AddInteger(1)
+			root.GetObject("k1").SetNewObject("k3").SetString("k4", "v")
+			root.GetText("k1").Edit(0, 0, "A")
+			return nil
+		})
+		assert.True(t, errors.Is(err, proxy.ErrUnsupportedType))
+		assert.Equal(t, `{"k1":"v"}`, doc.Marshal())
+		assert.Len(t, doc.CreateChangePack().Changes, 1)
+	})
+
+	t.Run("cyclic value test", func(t *testing.T) {
+		doc := document.New("c1", "d1")
+		cyclicMap := map[string]interface{}{"a": 1}
+		cyclicMap["self"] = cyclicMap
+		cyclicSlice := []interface{}{1, nil}
+		cyclicSlice[1] = cyclicSlice
+		shared := []interface{}{1}
+
+		err := doc.Update(func(root *proxy.ObjectProxy) error {
+			return root.SetValue("k1", cyclicMap)
+		})
+		assert.True(t, errors.Is(err, proxy.ErrUnsupportedType))
+		err = doc.Update(func(root *proxy.ObjectProxy) error {
+			return root.SetNewArray("k2").AddValue(cyclicSlice)
+		})
+		assert.True(t, errors.Is(err, proxy.ErrUnsupportedType))
+
+		// values referenced more than once are not cyclic.
+		err = doc.Update(func(root *proxy.ObjectProxy) error {
+			return root.SetValue("k3", map[string]interface{}{"a": shared, "b": shared})
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, `{"k3":{"a":[1],"b":[1]}}`, doc.Marshal())
+	})
+
+	t.Run("incremental marshal test", func(t *testing.T) {
+		doc := document.New("c1", "d1")
+		doc.SetIncrementalMarshal(true)
+		plain := document.New("c1", "d1")
+
+		updates := []func(root *proxy.ObjectProxy) error{
+			func(root *proxy.ObjectProxy) error {
+				root.SetNewObject("k1").SetString("k1.1", "v1").SetNewArray("k1.2").AddInteger(1, 2, 3)
+				root.SetNewText("k2").Edit(0, 0, "ABC")
+				return nil
+			},
+			func(root *proxy.ObjectProxy) error {
+				root.GetObject("k1").GetArray("k1.2").Delete(1)
+				return nil
+			},
+			func(root *proxy.ObjectProxy) error {
+				root.GetText("k2").Edit(1, 2, "D")
+				return nil
+			},
+			func(root *proxy.ObjectProxy) error {
+				arr := root.GetObject("k1").GetArray("k1.2")
+				arr.MoveBefore(arr.Get(0).CreatedAt(), arr.Get(1).CreatedAt())
+				root.Delete("k2")
+				return nil
+			},
+			func(root *proxy.ObjectProxy) error {
+				root.GetObject("k1").SetNewObject("k1.2").SetBool("k1.2.1", true)
+				return nil
+			},
+		}
+
+		for _, update := range updates {
+			assert.NoError(t, doc.Update(update))
+			assert.NoError(t, plain.Update(update))
+			assert.Equal(t, plain.Marshal(), doc.Marshal())
+			assert.Equal(t, plain.Marshal(), doc.Marshal())
+		}
+
+		// NOTE: Remote changes and snapshots are encoded again too.
+		remote := document.New("c1", "d1")
+		remote.SetIncrementalMarshal(true)
+		assert.Equal(t, "{}", remote.Marshal())
+		assert.NoError(t, remote.ApplyChangePack(doc.CreateChangePack()))
+		assert.Equal(t, doc.Marshal(), remote.Marshal())
+
+		snapshot, err := converter.ObjectToBytes(plain.RootObject())
+		assert.NoError(t, err)
+		assert.NoError(t, remote.ApplyChangePack(change.NewPack(
+			remote.Key(),
+			checkpoint.Initial.NextServerSeq(10),
+			nil,
+			snapshot,
+		)))
+		assert.Equal(t, plain.Marshal(), remote.Marshal())
+	})
+
+	t.Run("sync status test", func(t *testing.T) {
+		doc := document.New("c1", "d1")
+		assert.Equal(t, document.Synced, doc.SyncStatus().State)
+
+		var states []document.SyncState
+		doc.SubscribeSyncStatus(func(status document.SyncStatus) {
+			states = append(states, status.State)
+		})
+
+		err := doc.Update(func(root *proxy.ObjectProxy) error {
+			root.SetString("k1", "v1")
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, document.PendingLocalChanges, doc.SyncStatus().State)
+
+		doc.StartSync()
+		doc.FinishSync(errDummy)
+		assert.Equal(t, document.SyncStatus{State: document.SyncFailed, Err: errDummy}, doc.SyncStatus())
+
+		// the failure is kept until the next synchronization.
+		err = doc.Update(func(root *proxy.ObjectProxy) error {
+			root.SetString("k1", "v2")
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, document.SyncFailed, doc.SyncStatus().State)
+
+		doc.StartSync()
+		pack := doc.CreateChangePack()
+		assert.NoError(t, doc.ApplyChangePack(change.NewPack(pack.DocumentKey, pack.Checkpoint, nil, nil)))
+		doc.FinishSync(nil)
+		assert.Equal(t, document.SyncStatus{State: document.Synced}, doc.SyncStatus())
+
+		assert.Equal(t, []document.SyncState{
+			document.PendingLocalChanges,
+			document.Syncing,
+			document.SyncFailed,
+			document.Syncing,
+			document.Synced,
+		}, states)
+	})
+
+	t.Run("stats test", func(t *testing.T) {
+		doc1 := document.New("c1", "d1")
+		doc1.SetActor(time.ActorIDFromHex("000000000000000000000001"))
+		doc2 := document.New("c1", "d1")
+		doc2.SetActor(time.ActorIDFromHex("000000000000000000000002"))
+
+		// sync exchanges the local changes of the given documents only once.
+		sync := func(doc1, doc2 *document.Document) {
+			pack1, pack2 := doc1.CreateChangePack(), doc2.CreateChangePack()
+			assert.NoError(t, doc1.ApplyChangePack(change.NewPack(pack1.DocumentKey, pack1.Checkpoint, pack2.Changes, nil)))
+			assert.NoError(t, doc2.ApplyChangePack(change.NewPack(pack2.DocumentKey, pack2.Checkpoint, pack1.Changes, nil)))
+		}
+
+		err := doc1.Update(func(root *proxy.ObjectProxy) error {
+			root.SetString("k1", "v1")
+			root.SetNewText("k2").Edit(0, 0, "AB")
+			return nil
+		})
+		assert.NoError(t, err)
+		sync(doc1, doc2)
+		assert.Equal(t, document.Stats{}, doc2.Stats())
+
+		err = doc1.Update(func(root *proxy.ObjectProxy) error {
+			root.SetString("k1", "v2")
+			root.GetText("k2").Edit(1, 1, "1")
+			return nil
+		})
+		assert.NoError(t, err)
+		err = doc2.Update(func(root *proxy.ObjectProxy) error {
+			root.SetString("k1", "v3")
+			root.GetText("k2").Edit(0, 2, "2")
+			return nil
+		})
+		assert.NoError(t, err)
+		sync(doc1, doc2)
+		assert.Equal(t, doc1.Marshal(), doc2.Marshal())
+
+		// the concurrent sets and the text edits interleaved at the same range
+		// are counted on both sides. The values overwritten in "k1" are
+		// tombstones as well as the removed text nodes.
+		assert.Equal(t, document.Stats{
+			ConcurrentSets:       1,
+			InterleavedTextEdits: 1,
+			Tombstones:           4,
+		}, doc1.Stats())
+		assert.Equal(t, doc1.Stats(), doc2.Stats())
+	})
+
+	t.Run("clock test", func(t *testing.T) {
+		doc1 := document.New("c1", "d1")
+		doc1.SetActor(time.ActorIDFromHex("000000000000000000000001"))
+		doc2 := document.New("c1", "d1")
+		doc2.SetActor(time.ActorIDFromHex("000000000000000000000002"))
+
+		// the set of doc2 would win with the default clock as its actor is
+		// greater, but the clock of doc1 makes the set of doc1 later.
+		doc1.SetClock(stepClock(10))
+		err := doc1.Update(func(root *proxy.ObjectProxy) error {
+			root.SetString("k1", "v1")
+			return nil
+		})
+		assert.NoError(t, err)
+		err = doc2.Update(func(root *proxy.ObjectProxy) error {
+			root.SetString("k1", "v2")
+			return nil
+		})
+		assert.NoError(t, err)
+
+		pack1, pack2 := doc1.CreateChangePack(), doc2.CreateChangePack()
+		assert.Equal(t, uint64(10), pack1.Changes[0].ID().Lamport())
+		assert.Equal(t, uint64(1), pack2.Changes[0].ID().Lamport())
+		assert.NoError(t, doc1.ApplyChangePack(change.NewPack(pack1.DocumentKey, pack1.Checkpoint, pack2.Changes, nil)))
+		assert.NoError(t, doc2.ApplyChangePack(change.NewPack(pack2.DocumentKey, pack2.Checkpoint, pack1.Changes, nil)))
+		assert.Equal(t, `{"k1":"v1"}`, doc1.Marshal())
+		assert.Equal(t, doc1.Marshal(), doc2.Marshal())
+
+		// the default clock is used again if the clock is reset.
+		doc1.SetClock(nil)
+		err = doc1.Update(func(root *proxy.ObjectProxy) error {
+			root.SetString("k1", "v3")
+			return nil
+		})
+		assert.NoError(t, err)
+		pack1 = doc1.CreateChangePack()
+		assert.Equal(t, uint64(12), pack1.Changes[len(pack1.Changes)-1].ID().Lamport())
+	})
+}
+
+// stepClock is a change.Clock increasing the lamport timestamps by itself.
+type stepClock uint64
+
+func (c stepClock) NextLamport(lamport uint64) uint64 {
+	return lamport + uint64(c)
+}
+
+func (c stepClock) IssueTimeTicket(id change.ID, delimiter uint32) *time.Ticket {
+	return id.NewTimeTicket(delimiter)
+}
+
+func TestStructureLimits(t *testing.T) {
+	limits := document.StructureLimits{MaxDepth: 2, MaxChildren: 2, MaxKeyLength: 4}
+
+	t.Run("invalid limits test", func(t *testing.T) {
+		doc := document.New("c1", "d1")
+		err := doc.SetStructureLimits(document.StructureLimits{MaxDepth: -1})
+		assert.True(t, errors.Is(err, document.ErrInvalidStructureLimits))
+	})
+
+	t.Run("local update test", func(t *testing.T) {
+		doc := document.New("c1", "d1")
+		assert.NoError(t, doc.SetStructureLimits(limits))
+
+		assert.NoError(t, doc.Update(func(root *proxy.ObjectProxy) error {
+			root.SetNewObject("k1").SetInteger("k2", 1)
+			return nil
+		}))
+
+		var limitErr *document.StructureLimitError
+		err := doc.Update(func(root *proxy.ObjectProxy) error {
+			root.GetObject("k1").SetNewArray("k3").AddInteger(1)
+			return nil
+		})
+		assert.True(t, errors.As(err, &limitErr))
+		assert.Equal(t, document.StructureLimitError{Limit: "depth", Value: 3, Max: 2}, *limitErr)
+
+		err = doc.Update(func(root *proxy.ObjectProxy) error {
+			root.GetObject("k1").SetInteger("k3", 3).SetInteger("k4", 4)
+			return nil
+		})
+		assert.True(t, errors.As(err, &limitErr))
+		assert.Equal(t, document.StructureLimitError{Limit: "children", Value: 3, Max: 2}, *limitErr)
+
+		err = doc.Update(func(root *proxy.ObjectProxy) error {
+			root.SetInteger("k12345", 1)
+			return nil
+		})
+		assert.True(t, errors.Is(err, document.ErrStructureLimitExceeded))
+
+		// the rejected updates are not applied.
+		assert.Equal(t, `{"k1":{"k2":1}}`, doc.Marshal())
+		assert.NoError(t, doc.Update(func(root *proxy.ObjectProxy) error {
+			root.GetObject("k1").SetInteger("k3", 3)
+			return nil
+		}))
+		assert.Equal(t, `{"k1":{"k2":1,"k3":3}}`, doc.Marshal())
+	})
+
+	t.Run("remote changes test", func(t *testing.T) {
+		doc1 := document.New("c1", "d1")
+		doc2 := document.New("c1", "d1")
+		assert.NoError(t, doc2.SetStructureLimits(limits))
+
+		assert.NoError(t, doc1.Update(func(root *proxy.ObjectProxy) error {
+			root.SetNewArray("k1").AddInteger(1, 2, 3)
+			return nil
+		}))
+		pack := doc1.CreateChangePack()
+		err := doc2.ApplyChangePack(change.NewPack(pack.DocumentKey, checkpoint.Initial, pack.Changes, nil))
+		assert.True(t, errors.Is(err, document.ErrStructureLimitExceeded))
+		assert.Equal(t, "{}", doc2.Marshal())
+
+		snapshot, err := converter.ObjectToBytes(doc1.RootObject())
+		assert.NoError(t, err)
+		err = doc2.ApplyChangePack(change.NewPack(
+			pack.DocumentKey,
+			checkpoint.Initial.NextServerSeq(1),
+			nil,
+			snapshot,
+		))
+		assert.True(t, errors.Is(err, document.ErrStructureLimitExceeded))
+		assert.Equal(t, "{}", doc2.Marshal())
+
+		// the document can still be updated within the limits.
+		assert.NoError(t, doc2.Update(func(root *proxy.ObjectProxy) error {
+			root.SetNewArray("k1").AddInteger(1, 2)
+			return nil
+		}))
+		assert.Equal(t, `{"k1":[1,2]}`, doc2.Marshal())
+	})
+}
+
+func TestLocalChangesLimit(t *testing.T) {
+	update := func(doc *document.Document, i int) error {
+		return doc.Update(func(root *proxy.ObjectProxy) error {
+			root.SetInteger(fmt.Sprintf("k%d", i), i)
+			return nil
+		}, fmt.Sprintf("update %d", i))
+	}
+
+	// ack simulates the response of the agent that stored the given pack.
+	ack := func(doc *document.Document, pack *change.Pack) error {
+		return doc.ApplyChangePack(change.NewPack(pack.DocumentKey, pack.Checkpoint, nil, nil))
+	}
+
+	t.Run("reject policy test", func(t *testing.T) {
+		doc := document.New("c1", "d1")
+		assert.NoError(t, doc.SetLocalChangesLimit(document.LocalChangesLimit{MaxChanges: 2}))
+
+		assert.NoError(t, update(doc, 1))
+		assert.NoError(t, update(doc, 2))
+		assert.Equal(t, document.ErrLocalChangesLimitExceeded, update(doc, 3))
+		assert.Equal(t, `{"k1":1,"k2":2}`, doc.Marshal())
+		assert.Equal(t, 2, doc.LocalChangesStats().Changes)
+
+		assert.NoError(t, ack(doc, doc.CreateChangePack()))
+		assert.False(t, doc.HasLocalChanges())
+		assert.Equal(t, 0, doc.LocalChangesStats().Bytes)
+		assert.NoError(t, update(doc, 3))
+		assert.Equal(t, `{"k1":1,"k2":2,"k3":3}`, doc.Marshal())
+	})
+
+	t.Run("max bytes test", func(t *testing.T) {
+		doc := document.New("c1", "d1")
+		assert.NoError(t, update(doc, 1))
+		assert.Equal(t, 0, doc.LocalChangesStats().Bytes)
+
+		// the local changes are measured when MaxBytes is set.
+		assert.NoError(t, doc.SetLocalChangesLimit(document.LocalChangesLimit{MaxBytes: 1024}))
+		size := doc.LocalChangesStats().Bytes
+		assert.True(t, size > 0)
+		assert.NoError(t, update(doc, 2))
+		assert.True(t, doc.LocalChangesStats().Bytes > size)
+
+		assert.NoError(t, ack(doc, doc.CreateChangePack()))
+		assert.Equal(t, 0, doc.LocalChangesStats().Bytes)
+	})
+
+	t.Run("compact policy test", func(t *testing.T) {
+		doc := document.New("c1", "d1")
+		assert.NoError(t, doc.SetLocalChangesLimit(document.LocalChangesLimit{
+			MaxChanges: 2,
+			Policy:     document.CompactPolicy,
+		}))
+
+		for i := 0; i < 5; i++ {
+			assert.NoError(t, update(doc, i))
+		}
+		assert.Equal(t, 2, doc.LocalChangesStats().Changes)
+
+		pack := doc.CreateChangePack()
+		assert.Equal(t, uint32(5), pack.Checkpoint.ClientSeq)
+		assert.Equal(t, "update 0\nupdate 1\nupdate 2\nupdate 3", pack.Changes[0].Message())
+		assert.Nil(t, pack.Changes[0].Metadata())
+
+		other := document.New("c1", "d1")
+		assert.NoError(t, other.ApplyChangePack(pack))
+		assert.Equal(t, doc.Marshal(), other.Marshal())
+
+		// the changes included in a pack are not compacted.
+		assert.Equal(t, document.ErrLocalChangesLimitExceeded, update(doc, 5))
+		assert.NoError(t, ack(doc, pack))
+		assert.NoError(t, update(doc, 5))
+	})
+
+	t.Run("spill policy test", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "yorkie-changes")
+		assert.NoError(t, err)
+		defer func() {
+			assert.NoError(t, os.RemoveAll(dir))
+		}()
+
+		store, err := document.NewFileChangeStore(dir)
+		assert.NoError(t, err)
+
+		doc := document.New("c1", "d1")
+		assert.Error(t, doc.SetLocalChangesLimit(document.LocalChangesLimit{
+			MaxChanges: 2,
+			Policy:     document.SpillPolicy,
+		}))
+		assert.NoError(t, doc.SetLocalChangesLimit(document.LocalChangesLimit{
+			MaxChanges: 2,
+			Policy:     document.SpillPolicy,
+			Store:      store,
+		}))
+
+		for i := 0; i < 5; i++ {
+			assert.NoError(t, update(doc, i))
+		}
+		stats := doc.LocalChangesStats()
+		assert.Equal(t, 1, stats.Changes)
+		assert.Equal(t, 4, stats.SpilledChanges)
+		assert.True(t, stats.SpilledBytes > 0)
+
+		pack := doc.CreateChangePack()
+		assert.Len(t, pack.Changes, 5)
+		other := document.New("c1", "d1")
+		assert.NoError(t, other.ApplyChangePack(pack))
+		assert.Equal(t, doc.Marshal(), other.Marshal())
+
+		assert.NoError(t, ack(doc, pack))
+		assert.False(t, doc.HasLocalChanges())
+		batches, err := store.Load(doc.Key())
+		assert.NoError(t, err)
+		assert.Len(t, batches, 0)
+	})
 }
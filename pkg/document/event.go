@@ -0,0 +1,65 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package document
+
+// EventType represents the type of the event of a document.
+type EventType string
+
+const (
+	// LocalChangeEvent is published after the document is changed by
+	// Update.
+	LocalChangeEvent EventType = "local-change"
+
+	// RemoteChangeEvent is published after the changes or the snapshot of a
+	// change pack are applied to the document.
+	RemoteChangeEvent EventType = "remote-change"
+)
+
+// Event represents a change of a document.
+type Event struct {
+	Type EventType
+}
+
+// subscriber is a callback registered by Subscribe.
+type subscriber struct {
+	id int
+	fn func(Event)
+}
+
+// Subscribe registers the given callback, which is called after the document
+// is changed, and returns a function that unregisters it. Callbacks are called
+// in the goroutine that changed the document, in the order of registration.
+func (d *Document) Subscribe(fn func(Event)) func() {
+	d.subscriberSeq++
+	id := d.subscriberSeq
+	d.subscribers = append(d.subscribers, subscriber{id: id, fn: fn})
+
+	return func() {
+		for i, s := range d.subscribers {
+			if s.id == id {
+				d.subscribers = append(d.subscribers[:i:i], d.subscribers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+func (d *Document) publish(event Event) {
+	for _, s := range d.subscribers {
+		s.fn(event)
+	}
+}
@@ -0,0 +1,74 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package json
+
+const (
+	// minSlabSize is the size of the first chunk allocated by a slab.
+	minSlabSize = 2
+
+	// maxSlabSize is the maximum size of a chunk allocated by a slab.
+	maxSlabSize = 128
+)
+
+// nextSlabSize returns the size of the next chunk. Chunks grow geometrically
+// so that small containers don't waste memory while large ones amortize
+// allocations over many nodes.
+func nextSlabSize(prev int) int {
+	if prev < minSlabSize {
+		return minSlabSize
+	}
+	if prev*2 > maxSlabSize {
+		return maxSlabSize
+	}
+	return prev * 2
+}
+
+// rhtNodeSlab allocates RHTNodes in chunks. Nodes of CRDT containers live as
+// long as the container itself because removed nodes are kept as tombstones,
+// so chunks never need to be returned individually.
+type rhtNodeSlab struct {
+	size  int
+	nodes []RHTNode
+}
+
+func (s *rhtNodeSlab) alloc() *RHTNode {
+	if len(s.nodes) == 0 {
+		s.size = nextSlabSize(s.size)
+		s.nodes = make([]RHTNode, s.size)
+	}
+
+	node := &s.nodes[0]
+	s.nodes = s.nodes[1:]
+	return node
+}
+
+// rgaTreeListNodeSlab allocates RGATreeListNodes in chunks.
+type rgaTreeListNodeSlab struct {
+	size  int
+	nodes []RGATreeListNode
+}
+
+func (s *rgaTreeListNodeSlab) alloc() *RGATreeListNode {
+	if len(s.nodes) == 0 {
+		s.size = nextSlabSize(s.size)
+		s.nodes = make([]RGATreeListNode, s.size)
+	}
+
+	node := &s.nodes[0]
+	s.nodes = s.nodes[1:]
+	return node
+}
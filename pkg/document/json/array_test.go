@@ -36,4 +36,26 @@ func TestArray(t *testing.T) {
 		a.Add(json.NewPrimitive("3", time.InitialTicket))
 		assert.Equal(t, `["1","2","3"]`, a.Marshal())
 	})
+
+	t.Run("move test", func(t *testing.T) {
+		actorID := time.InitialActorID
+		a := json.NewArray(json.NewRGATreeList(), time.InitialTicket)
+
+		var tickets []*time.Ticket
+		for i := 1; i <= 3; i++ {
+			ticket := time.NewTicket(uint64(i), 0, actorID)
+			tickets = append(tickets, ticket)
+			a.Add(json.NewPrimitive(string(rune('0'+i)), ticket))
+		}
+		assert.Equal(t, `["1","2","3"]`, a.Marshal())
+
+		for i := 0; i < 10; i++ {
+			a.MoveAfter(tickets[2], tickets[0], time.NewTicket(uint64(10+2*i), 0, actorID))
+			assert.Equal(t, `["2","3","1"]`, a.Marshal())
+			a.MoveAfter(time.InitialTicket, tickets[0], time.NewTicket(uint64(11+2*i), 0, actorID))
+			assert.Equal(t, `["1","2","3"]`, a.Marshal())
+		}
+		assert.Equal(t, 3, a.Len())
+		assert.Len(t, a.RGANodes(), 3)
+	})
 }
@@ -0,0 +1,142 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package json
+
+import (
+	"sync"
+
+	"github.com/yorkie-team/yorkie/pkg/document/time"
+)
+
+// MarshalCache keeps the JSON encodings of the containers and texts of a root
+// object, so that the subtrees untouched since the last Marshal are not
+// encoded again. It makes frequent reads of large documents cheap, at the
+// cost of keeping the encodings in memory.
+type MarshalCache struct {
+	mu      sync.Mutex
+	touched map[string]bool
+	encoded map[string]string
+}
+
+// NewMarshalCache creates a new instance of MarshalCache.
+func NewMarshalCache() *MarshalCache {
+	return &MarshalCache{
+		touched: make(map[string]bool),
+		encoded: make(map[string]string),
+	}
+}
+
+// Touch marks the element of the given creation time as modified, so it and
+// its ancestors are encoded again in the next Marshal.
+func (c *MarshalCache) Touch(createdAt *time.Ticket) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.touched[createdAt.Key()] = true
+}
+
+// Reset drops all the encodings, e.g. when the root object is replaced.
+func (c *MarshalCache) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.touched = make(map[string]bool)
+	c.encoded = make(map[string]string)
+}
+
+// Marshal returns the JSON encoding of the given root object, which is the
+// same as the result of Object.Marshal.
+func (c *MarshalCache) Marshal(obj *Object) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.touched) == 0 {
+		if encoded, ok := c.encoded[obj.CreatedAt().Key()]; ok {
+			return encoded
+		}
+	}
+
+	dirty := make(map[string]bool)
+	c.markDirty(obj, dirty)
+
+	encoded := make(map[string]string)
+	result := c.marshal(obj, dirty, encoded)
+
+	c.touched = make(map[string]bool)
+	c.encoded = encoded
+	return result
+}
+
+// markDirty marks the given element as dirty if it or one of its descendants
+// has been touched, and returns whether it is dirty.
+func (c *MarshalCache) markDirty(elem Element, dirty map[string]bool) bool {
+	isDirty := c.touched[elem.CreatedAt().Key()]
+
+	switch elem := elem.(type) {
+	case *Object:
+		elem.ForEach(func(k string, member Element) bool {
+			if c.markDirty(member, dirty) {
+				isDirty = true
+			}
+			return true
+		})
+	case *Array:
+		for _, node := range elem.RGANodes() {
+			if !node.isRemoved() && c.markDirty(node.elem, dirty) {
+				isDirty = true
+			}
+		}
+	}
+
+	if isDirty {
+		dirty[elem.CreatedAt().Key()] = true
+	}
+	return isDirty
+}
+
+// marshal returns the JSON encoding of the given element, reusing the
+// encoding of the last Marshal if the element is not dirty. The encodings of
+// the containers and texts are kept in the given map.
+func (c *MarshalCache) marshal(elem Element, dirty map[string]bool, encoded map[string]string) string {
+	key := elem.CreatedAt().Key()
+	if !dirty[key] {
+		if result, ok := c.encoded[key]; ok {
+			encoded[key] = result
+			return result
+		}
+	}
+
+	marshalChild := func(child Element) string {
+		return c.marshal(child, dirty, encoded)
+	}
+
+	var result string
+	switch elem := elem.(type) {
+	case *Object:
+		result = elem.marshal(marshalChild)
+	case *Array:
+		result = elem.elements.marshal(marshalChild)
+	case *Text:
+		result = elem.Marshal()
+	default:
+		// NOTE: Primitives are cheap to encode, so they are not kept.
+		return elem.Marshal()
+	}
+
+	encoded[key] = result
+	return result
+}
@@ -41,9 +41,10 @@ func NewObject(memberNodes *RHTPriorityQueueMap, createdAt *time.Ticket) *Object
 	}
 }
 
-// Set sets the given element of the given key.
-func (o *Object) Set(k string, v Element) {
-	o.memberNodes.Set(k, v)
+// Set sets the given element of the given key. It returns the element that
+// was set to the key before, including removed one.
+func (o *Object) Set(k string, v Element) Element {
+	return o.memberNodes.Set(k, v)
 }
 
 // Members returns the member of this object as a map.
@@ -72,7 +73,7 @@ func (o *Object) Delete(k string, deletedAt *time.Ticket) Element {
 }
 
 func (o *Object) Descendants(descendants chan Element) {
-	for _, node := range o.memberNodes.AllNodes() {
+	o.memberNodes.ForEachNode(func(node *RHTNode) bool {
 		switch elem := node.elem.(type) {
 		case *Object:
 			elem.Descendants(descendants)
@@ -80,31 +81,41 @@ func (o *Object) Descendants(descendants chan Element) {
 			elem.Descendants(descendants)
 		}
 		descendants <- node.elem
-	}
+		return true
+	})
+}
+
+// ForEach calls the given function for each member of this object. The
+// iteration stops when the function returns false.
+func (o *Object) ForEach(fn func(k string, e Element) bool) {
+	o.memberNodes.ForEach(fn)
 }
 
 // Marshal returns the JSON encoding of this object.
 func (o *Object) Marshal() string {
-	members := o.memberNodes.Elements()
+	return o.marshal(Element.Marshal)
+}
 
-	size := len(members)
-	keys := make([]string, 0, size)
-	for k := range members {
-		keys = append(keys, k)
-	}
-	sort.Strings(keys)
+// marshal returns the JSON encoding of this object, encoding the members with
+// the given function.
+func (o *Object) marshal(marshalMember func(Element) string) string {
+	var members []RHTNode
+	o.memberNodes.ForEach(func(k string, e Element) bool {
+		members = append(members, RHTNode{key: k, elem: e})
+		return true
+	})
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].key < members[j].key
+	})
 
 	sb := strings.Builder{}
 	sb.WriteString("{")
 
-	idx := 0
-	for _, k := range keys {
-		value := members[k]
-		sb.WriteString(fmt.Sprintf("\"%s\":%s", k, value.Marshal()))
-		if size-1 != idx {
+	for idx, member := range members {
+		if idx > 0 {
 			sb.WriteString(",")
 		}
-		idx++
+		sb.WriteString(fmt.Sprintf("\"%s\":%s", member.key, marshalMember(member.elem)))
 	}
 	sb.WriteString("}")
 
@@ -115,9 +126,10 @@ func (o *Object) Marshal() string {
 func (o *Object) DeepCopy() Element {
 	members := NewRHT()
 
-	for _, node := range o.memberNodes.AllNodes() {
+	o.memberNodes.ForEachNode(func(node *RHTNode) bool {
 		members.Set(node.key, node.elem.DeepCopy())
-	}
+		return true
+	})
 
 	obj := NewObject(members, o.createdAt)
 	obj.removedAt = o.removedAt
@@ -153,6 +165,13 @@ func (o *Object) Remove(removedAt *time.Ticket) bool {
 	return false
 }
 
+// History returns the values set to the given key in the order they were
+// set, including the overwritten and removed ones. It is for debugging and
+// tools, e.g. to find who changed a field and when.
+func (o *Object) History(k string) []*RHTNode {
+	return o.memberNodes.History(k)
+}
+
 // RHTNodes returns the RHTPriorityQueueMap nodes.
 func (o *Object) RHTNodes() []*RHTNode {
 	return o.memberNodes.AllNodes()
@@ -219,3 +219,8 @@ func (p *Primitive) Remove(removedAt *time.Ticket) bool {
 func (p *Primitive) ValueType() ValueType {
 	return p.valueType
 }
+
+// Value returns the value of this primitive.
+func (p *Primitive) Value() interface{} {
+	return p.value
+}
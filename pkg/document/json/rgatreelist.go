@@ -20,7 +20,6 @@ import (
 	"strings"
 
 	"github.com/yorkie-team/yorkie/pkg/document/time"
-	"github.com/yorkie-team/yorkie/pkg/log"
 	"github.com/yorkie-team/yorkie/pkg/splay"
 )
 
@@ -32,31 +31,14 @@ type RGATreeListNode struct {
 	next *RGATreeListNode
 }
 
-func newRGATreeListNode(elem Element) *RGATreeListNode {
-	node := &RGATreeListNode{
-		prev: nil,
-		next: nil,
-		elem: elem,
-	}
+func newRGATreeListNode(slab *rgaTreeListNodeSlab, elem Element) *RGATreeListNode {
+	node := slab.alloc()
+	node.elem = elem
 	node.indexNode = splay.NewNode(node)
 
 	return node
 }
 
-func newRGATreeListNodeAfter(prev *RGATreeListNode, elem Element) *RGATreeListNode {
-	newNode := newRGATreeListNode(elem)
-	prevNext := prev.next
-
-	prev.next = newNode
-	newNode.prev = prev
-	newNode.next = prevNext
-	if prevNext != nil {
-		prevNext.prev = newNode
-	}
-
-	return prev.next
-}
-
 func (n *RGATreeListNode) Element() Element {
 	return n.elem
 }
@@ -82,28 +64,35 @@ type RGATreeList struct {
 	size               int
 	nodeMapByIndex     *splay.Tree
 	nodeMapByCreatedAt map[string]*RGATreeListNode
+	slab               rgaTreeListNodeSlab
 }
 
 // NewRGATreeList creates a new instance of RGATreeList.
 func NewRGATreeList() *RGATreeList {
 	dummyValue := NewPrimitive(0, time.InitialTicket)
 	dummyValue.Remove(time.InitialTicket)
-	dummyHead := newRGATreeListNode(dummyValue)
+	list := &RGATreeList{}
+	dummyHead := newRGATreeListNode(&list.slab, dummyValue)
 	nodeMapByIndex := splay.NewTree(dummyHead.indexNode)
 	nodeMapByCreatedAt := make(map[string]*RGATreeListNode)
 	nodeMapByCreatedAt[dummyHead.elem.CreatedAt().Key()] = dummyHead
 
-	return &RGATreeList{
-		dummyHead:          dummyHead,
-		last:               dummyHead,
-		size:               0,
-		nodeMapByIndex:     nodeMapByIndex,
-		nodeMapByCreatedAt: nodeMapByCreatedAt,
-	}
+	list.dummyHead = dummyHead
+	list.last = dummyHead
+	list.nodeMapByIndex = nodeMapByIndex
+	list.nodeMapByCreatedAt = nodeMapByCreatedAt
+
+	return list
 }
 
 // Marshal returns the JSON encoding of this RGATreeList.
 func (a *RGATreeList) Marshal() string {
+	return a.marshal(Element.Marshal)
+}
+
+// marshal returns the JSON encoding of this list, encoding the elements with
+// the given function.
+func (a *RGATreeList) marshal(marshalElement func(Element) string) string {
 	sb := strings.Builder{}
 	sb.WriteString("[")
 
@@ -114,7 +103,7 @@ func (a *RGATreeList) Marshal() string {
 		}
 
 		if !current.isRemoved() {
-			sb.WriteString(current.elem.Marshal())
+			sb.WriteString(marshalElement(current.elem))
 			if current != a.last {
 				sb.WriteString(",")
 			}
@@ -188,7 +177,7 @@ func (a *RGATreeList) Get(idx int) *RGATreeListNode {
 func (a *RGATreeList) DeleteByCreatedAt(createdAt *time.Ticket, deletedAt *time.Ticket) *RGATreeListNode {
 	node, ok := a.nodeMapByCreatedAt[createdAt.Key()]
 	if !ok {
-		log.Logger.Fatalf(
+		logger.Fatalf(
 			"fail to find the given createdAt: %s",
 			createdAt.Key(),
 		)
@@ -221,7 +210,7 @@ func (a *RGATreeList) Delete(idx int, deletedAt *time.Ticket) *RGATreeListNode {
 func (a *RGATreeList) MoveAfter(prevCreatedAt, createdAt, executedAt *time.Ticket) {
 	prevNode, ok := a.nodeMapByCreatedAt[prevCreatedAt.Key()]
 	if !ok {
-		log.Logger.Fatalf(
+		logger.Fatalf(
 			"fail to find the given prevCreatedAt: %s",
 			prevCreatedAt.Key(),
 		)
@@ -229,7 +218,7 @@ func (a *RGATreeList) MoveAfter(prevCreatedAt, createdAt, executedAt *time.Ticke
 
 	node, ok := a.nodeMapByCreatedAt[createdAt.Key()]
 	if !ok {
-		log.Logger.Fatalf(
+		logger.Fatalf(
 			"fail to find the given createdAt: %s",
 			createdAt.Key(),
 		)
@@ -237,7 +226,7 @@ func (a *RGATreeList) MoveAfter(prevCreatedAt, createdAt, executedAt *time.Ticke
 
 	if node.elem.UpdatedAt() == nil || executedAt.After(node.elem.UpdatedAt()) {
 		a.release(node)
-		a.insertAfter(prevNode, node.elem)
+		a.insertNodeAfter(prevNode, node)
 		node.elem.SetUpdatedAt(executedAt)
 	}
 }
@@ -245,7 +234,7 @@ func (a *RGATreeList) MoveAfter(prevCreatedAt, createdAt, executedAt *time.Ticke
 func (a *RGATreeList) FindPrevCreatedAt(createdAt *time.Ticket) *time.Ticket {
 	node, ok := a.nodeMapByCreatedAt[createdAt.Key()]
 	if !ok {
-		log.Logger.Fatalf(
+		logger.Fatalf(
 			"fail to find the given prevCreatedAt: %s",
 			createdAt.Key(),
 		)
@@ -264,7 +253,7 @@ func (a *RGATreeList) FindPrevCreatedAt(createdAt *time.Ticket) *time.Ticket {
 func (a *RGATreeList) findByCreatedAt(prevCreatedAt *time.Ticket, createdAt *time.Ticket) *RGATreeListNode {
 	node, ok := a.nodeMapByCreatedAt[prevCreatedAt.Key()]
 	if !ok {
-		log.Logger.Fatalf(
+		logger.Fatalf(
 			"fail to find the given prevCreatedAt: %s",
 			prevCreatedAt.Key(),
 		)
@@ -294,13 +283,24 @@ func (a *RGATreeList) release(node *RGATreeListNode) {
 }
 
 func (a *RGATreeList) insertAfter(prev *RGATreeListNode, element Element) {
-	node := newRGATreeListNodeAfter(prev, element)
+	a.insertNodeAfter(prev, newRGATreeListNode(&a.slab, element))
+}
+
+// insertNodeAfter links the given node after prev. Nodes released by moves are
+// inserted again with it, so that moves don't allocate nodes from the slab.
+func (a *RGATreeList) insertNodeAfter(prev *RGATreeListNode, node *RGATreeListNode) {
+	node.prev = prev
+	node.next = prev.next
+	if prev.next != nil {
+		prev.next.prev = node
+	}
+	prev.next = node
 	if prev == a.last {
 		a.last = node
 	}
 
 	a.nodeMapByIndex.InsertAfter(prev.indexNode, node.indexNode)
-	a.nodeMapByCreatedAt[element.CreatedAt().Key()] = node
+	a.nodeMapByCreatedAt[node.elem.CreatedAt().Key()] = node
 
 	a.size++
 }
@@ -17,8 +17,9 @@
 package json
 
 import (
+	"sort"
+
 	"github.com/yorkie-team/yorkie/pkg/document/time"
-	"github.com/yorkie-team/yorkie/pkg/log"
 	"github.com/yorkie-team/yorkie/pkg/pq"
 )
 
@@ -27,11 +28,11 @@ type RHTNode struct {
 	elem Element
 }
 
-func newRHTNode(key string, elem Element) *RHTNode {
-	return &RHTNode{
-		key:  key,
-		elem: elem,
-	}
+func newRHTNode(slab *rhtNodeSlab, key string, elem Element) *RHTNode {
+	node := slab.alloc()
+	node.key = key
+	node.elem = elem
+	return node
 }
 
 func (n *RHTNode) Remove(removedAt *time.Ticket) {
@@ -55,28 +56,75 @@ func (n *RHTNode) Element() Element {
 	return n.elem
 }
 
+// rhtEntry holds the nodes of a key. Almost all keys only ever have a single
+// node, so the node is kept inline and the priority queue is only created
+// when another node is set to the same key by concurrent editing.
+type rhtEntry struct {
+	node  *RHTNode
+	queue *pq.PriorityQueue
+}
+
+// peek returns the node with the highest priority.
+func (e *rhtEntry) peek() *RHTNode {
+	if e.queue != nil {
+		return e.queue.Peek().(*RHTNode)
+	}
+	return e.node
+}
+
+// push pushes the given node. It upgrades the entry to a queue on the
+// second node.
+func (e *rhtEntry) push(node *RHTNode) {
+	if e.queue == nil && e.node == nil {
+		e.node = node
+		return
+	}
+
+	if e.queue == nil {
+		e.queue = pq.NewPriorityQueue()
+		e.queue.Push(e.node)
+		e.node = nil
+	}
+	e.queue.Push(node)
+}
+
+// forEach calls the given function for each node of this entry.
+func (e *rhtEntry) forEach(fn func(node *RHTNode) bool) bool {
+	if e.queue == nil {
+		return fn(e.node)
+	}
+
+	stopped := false
+	e.queue.ForEach(func(value pq.Value) bool {
+		stopped = !fn(value.(*RHTNode))
+		return !stopped
+	})
+	return !stopped
+}
+
 // RHTPriorityQueueMap is replicated hash table.
 type RHTPriorityQueueMap struct {
-	nodeQueueMapByKey  map[string]*pq.PriorityQueue
+	entryMapByKey      map[string]rhtEntry
 	nodeMapByCreatedAt map[string]*RHTNode
+	slab               rhtNodeSlab
 }
 
 // NewRHT creates a new instance of RHTPriorityQueueMap.
 func NewRHT() *RHTPriorityQueueMap {
 	return &RHTPriorityQueueMap{
-		nodeQueueMapByKey:  make(map[string]*pq.PriorityQueue),
+		entryMapByKey:      make(map[string]rhtEntry),
 		nodeMapByCreatedAt: make(map[string]*RHTNode),
 	}
 }
 
 // Get returns the value of the given key.
 func (rht *RHTPriorityQueueMap) Get(key string) Element {
-	queue, ok := rht.nodeQueueMapByKey[key]
+	entry, ok := rht.entryMapByKey[key]
 	if !ok {
 		return nil
 	}
 
-	node := queue.Peek().(*RHTNode)
+	node := entry.peek()
 	if node.isRemoved() {
 		return nil
 	}
@@ -85,34 +133,39 @@ func (rht *RHTPriorityQueueMap) Get(key string) Element {
 
 // Has returns whether the element exists of the given key or not.
 func (rht *RHTPriorityQueueMap) Has(key string) bool {
-	queue, ok := rht.nodeQueueMapByKey[key]
+	entry, ok := rht.entryMapByKey[key]
 	if !ok {
 		return false
 	}
 
-	node := queue.Peek().(*RHTNode)
+	node := entry.peek()
 	return node != nil && !node.isRemoved()
 }
 
-// Set sets the value of the given key.
-func (rht *RHTPriorityQueueMap) Set(k string, v Element) {
-	if _, ok := rht.nodeQueueMapByKey[k]; !ok {
-		rht.nodeQueueMapByKey[k] = pq.NewPriorityQueue()
-	}
+// Set sets the value of the given key. It returns the element that had the
+// highest priority for the key before, including removed one.
+func (rht *RHTPriorityQueueMap) Set(k string, v Element) Element {
+	node := newRHTNode(&rht.slab, k, v)
 
-	node := newRHTNode(k, v)
-	rht.nodeQueueMapByKey[k].Push(node)
+	var prev Element
+	entry := rht.entryMapByKey[k]
+	if top := entry.peek(); top != nil {
+		prev = top.elem
+	}
+	entry.push(node)
+	rht.entryMapByKey[k] = entry
 	rht.nodeMapByCreatedAt[v.CreatedAt().Key()] = node
+	return prev
 }
 
 // Remove deletes the Element of the given key.
 func (rht *RHTPriorityQueueMap) Delete(k string, deletedAt *time.Ticket) Element {
-	queue, ok := rht.nodeQueueMapByKey[k]
+	entry, ok := rht.entryMapByKey[k]
 	if !ok {
 		return nil
 	}
 
-	node := queue.Peek().(*RHTNode)
+	node := entry.peek()
 	node.Remove(deletedAt)
 	return node.elem
 }
@@ -121,7 +174,7 @@ func (rht *RHTPriorityQueueMap) Delete(k string, deletedAt *time.Ticket) Element
 func (rht *RHTPriorityQueueMap) DeleteByCreatedAt(createdAt *time.Ticket, deletedAt *time.Ticket) Element {
 	node, ok := rht.nodeMapByCreatedAt[createdAt.Key()]
 	if !ok {
-		log.Logger.Warn("fail to find " + createdAt.Key())
+		logger.Warn("fail to find " + createdAt.Key())
 		return nil
 	}
 
@@ -129,27 +182,94 @@ func (rht *RHTPriorityQueueMap) DeleteByCreatedAt(createdAt *time.Ticket, delete
 	return node.elem
 }
 
+// History returns the nodes of the given key in the order they were created,
+// including the values overwritten or removed. The creation time of each
+// value tells who set the key and when, and its removal time tells who
+// removed it.
+func (rht *RHTPriorityQueueMap) History(key string) []*RHTNode {
+	entry, ok := rht.entryMapByKey[key]
+	if !ok {
+		return nil
+	}
+
+	var nodes []*RHTNode
+	entry.forEach(func(node *RHTNode) bool {
+		nodes = append(nodes, node)
+		return true
+	})
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[j].elem.CreatedAt().After(nodes[i].elem.CreatedAt())
+	})
+
+	return nodes
+}
+
 // Elements returns a map of elements because the map easy to use for loop.
-// TODO If we encounter performance issues, we need to replace this with other solution.
+// Use ForEach in hot paths to avoid building the map.
 func (rht *RHTPriorityQueueMap) Elements() map[string]Element {
 	members := make(map[string]Element)
-	for _, queue := range rht.nodeQueueMapByKey {
-		if node := queue.Peek().(*RHTNode); !node.isRemoved() {
-			members[node.key] = node.elem
+	rht.ForEach(func(k string, e Element) bool {
+		members[k] = e
+		return true
+	})
+
+	return members
+}
+
+// ForEach calls the given function for each element that is not removed. The
+// iteration stops when the function returns false.
+func (rht *RHTPriorityQueueMap) ForEach(fn func(k string, e Element) bool) {
+	for _, entry := range rht.entryMapByKey {
+		if node := entry.peek(); !node.isRemoved() {
+			if !fn(node.key, node.elem) {
+				return
+			}
 		}
 	}
+}
 
-	return members
+// ForEachNode calls the given function for each node including removed ones.
+// The iteration stops when the function returns false.
+func (rht *RHTPriorityQueueMap) ForEachNode(fn func(node *RHTNode) bool) {
+	for _, entry := range rht.entryMapByKey {
+		if !entry.forEach(fn) {
+			return
+		}
+	}
+}
+
+// forEachShadowedNode calls the given function for each node shadowed by
+// another node of the same key. Shadowed nodes are never visible again.
+func (rht *RHTPriorityQueueMap) forEachShadowedNode(fn func(node *RHTNode)) {
+	for _, entry := range rht.entryMapByKey {
+		top := entry.peek()
+		entry.forEach(func(node *RHTNode) bool {
+			if node != top {
+				fn(node)
+			}
+			return true
+		})
+	}
 }
 
 // AllNodes returns a map of elements because the map easy to use for loop.
+// The nodes are ordered by their keys, so that the snapshots of the same
+// document are encoded to the same bytes.
 // TODO If we encounter performance issues, we need to replace this with other solution.
 func (rht *RHTPriorityQueueMap) AllNodes() []*RHTNode {
+	keys := make([]string, 0, len(rht.entryMapByKey))
+	for k := range rht.entryMapByKey {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
 	var nodes []*RHTNode
-	for _, queue := range rht.nodeQueueMapByKey {
-		for _, value := range queue.Values() {
-			nodes = append(nodes, value.(*RHTNode))
-		}
+	for _, k := range keys {
+		entry := rht.entryMapByKey[k]
+		entry.forEach(func(node *RHTNode) bool {
+			nodes = append(nodes, node)
+			return true
+		})
 	}
 
 	return nodes
@@ -0,0 +1,77 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package json_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yorkie-team/yorkie/pkg/document/json"
+	"github.com/yorkie-team/yorkie/pkg/document/time"
+)
+
+func TestRHTPriorityQueueMap(t *testing.T) {
+	t.Run("concurrent set test", func(t *testing.T) {
+		rht := json.NewRHT()
+
+		first := time.NewTicket(1, 0, time.InitialActorID)
+		second := time.NewTicket(2, 0, time.InitialActorID)
+		third := time.NewTicket(3, 0, time.InitialActorID)
+
+		rht.Set("k1", json.NewPrimitive("v2", second))
+		assert.Equal(t, `"v2"`, rht.Get("k1").Marshal())
+
+		rht.Set("k1", json.NewPrimitive("v1", first))
+		assert.Equal(t, `"v2"`, rht.Get("k1").Marshal())
+
+		rht.Set("k1", json.NewPrimitive("v3", third))
+		assert.Equal(t, `"v3"`, rht.Get("k1").Marshal())
+		assert.Len(t, rht.AllNodes(), 3)
+
+		rht.Delete("k1", time.NewTicket(4, 0, time.InitialActorID))
+		assert.False(t, rht.Has("k1"))
+		assert.Len(t, rht.Elements(), 0)
+	})
+
+	t.Run("history test", func(t *testing.T) {
+		actor1 := time.ActorIDFromHex("000000000000000000000001")
+		actor2 := time.ActorIDFromHex("000000000000000000000002")
+
+		obj := json.NewObject(json.NewRHT(), time.InitialTicket)
+		assert.Len(t, obj.History("k1"), 0)
+
+		obj.Set("k1", json.NewPrimitive("v2", time.NewTicket(2, 0, actor2)))
+		obj.Set("k1", json.NewPrimitive("v1", time.NewTicket(1, 0, actor1)))
+		obj.Delete("k1", time.NewTicket(3, 0, actor1))
+		obj.Set("k1", json.NewPrimitive("v3", time.NewTicket(4, 0, actor2)))
+		obj.Set("k2", json.NewPrimitive("v4", time.NewTicket(5, 0, actor1)))
+
+		history := obj.History("k1")
+		assert.Len(t, history, 3)
+		assert.Equal(t, `"v1"`, history[0].Element().Marshal())
+		assert.Equal(t, actor1, history[0].Element().CreatedAt().ActorID())
+		assert.Nil(t, history[0].Element().RemovedAt())
+
+		assert.Equal(t, `"v2"`, history[1].Element().Marshal())
+		assert.Equal(t, actor2, history[1].Element().CreatedAt().ActorID())
+		assert.Equal(t, actor1, history[1].Element().RemovedAt().ActorID())
+
+		assert.Equal(t, `"v3"`, history[2].Element().Marshal())
+		assert.Equal(t, `"v3"`, obj.Get("k1").Marshal())
+	})
+}
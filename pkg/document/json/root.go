@@ -17,6 +17,9 @@
 package json
 
 import (
+	"sync"
+	"sync/atomic"
+
 	"github.com/yorkie-team/yorkie/pkg/document/time"
 )
 
@@ -27,28 +30,34 @@ import (
 // Every element has a unique time ticket at creation, which allows us to find
 // a particular element.
 type Root struct {
-	object                *Object
+	object *Object
+
+	// mu guards elementMapByCreatedAt and removedElementMapByCreatedAt,
+	// because independent changes can be applied to the root concurrently.
+	mu                    sync.RWMutex
 	elementMapByCreatedAt map[string]Element
+
+	// removedElementMapByCreatedAt is the registry of the removed elements
+	// which are kept as tombstones until they are garbage collected.
+	removedElementMapByCreatedAt map[string]Element
+
+	// concurrentSets and interleavedEdits count the conflicts resolved while
+	// applying changes. They are updated atomically for the same reason.
+	concurrentSets   int64
+	interleavedEdits int64
 }
 
 // NewRoot creates a new instance of Root.
 func NewRoot(root *Object) *Root {
-	elementMap := make(map[string]Element)
 	r := &Root{
-		object:                root,
-		elementMapByCreatedAt: elementMap,
+		object:                       root,
+		elementMapByCreatedAt:        make(map[string]Element),
+		removedElementMapByCreatedAt: make(map[string]Element),
 	}
 
 	r.RegisterElement(root)
-
-	descendants := make(chan Element)
-	go func() {
-		root.Descendants(descendants)
-		close(descendants)
-	}()
-	for descendant := range descendants {
-		r.RegisterElement(descendant)
-	}
+	forEachDescendant(root, r.RegisterElement)
+	r.registerShadowedElements(root)
 
 	return r
 }
@@ -60,15 +69,220 @@ func (r *Root) Object() *Object {
 
 // FindByCreatedAt returns the element of given creation time.
 func (r *Root) FindByCreatedAt(createdAt *time.Ticket) Element {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	return r.elementMapByCreatedAt[createdAt.Key()]
 }
 
-// RegisterElement registers the given element to hash table.
+// RegisterElement registers the given element to hash table. If the element
+// is already removed, it is registered as a removed element too.
 func (r *Root) RegisterElement(elem Element) {
-	r.elementMapByCreatedAt[elem.CreatedAt().Key()] = elem
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := elem.CreatedAt().Key()
+	r.elementMapByCreatedAt[key] = elem
+	if elem.RemovedAt() != nil {
+		r.registerRemovedElement(elem)
+	}
+}
+
+// DeregisterElement deregisters the given element from hash table.
+func (r *Root) DeregisterElement(elem Element) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := elem.CreatedAt().Key()
+	delete(r.elementMapByCreatedAt, key)
+	delete(r.removedElementMapByCreatedAt, key)
+}
+
+// RegisterRemovedElement registers the given element as a removed element
+// pending garbage collection, with its descendants removed together with it.
+// It is ignored if the element is not removed.
+func (r *Root) RegisterRemovedElement(elem Element) {
+	if elem == nil || elem.RemovedAt() == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.registerRemovedElement(elem)
+}
+
+// RegisterShadowedElement registers the given element shadowed by another
+// value of the same key as a removed element pending garbage collection. The
+// element is not marked as removed, but it is never visible again.
+func (r *Root) RegisterShadowedElement(elem Element) {
+	if elem == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.registerRemovedElement(elem)
+}
+
+// registerRemovedElement registers the given element and its descendants as
+// removed elements. The caller must hold the lock.
+func (r *Root) registerRemovedElement(elem Element) {
+	r.removedElementMapByCreatedAt[elem.CreatedAt().Key()] = elem
+	forEachDescendant(elem, func(descendant Element) {
+		r.removedElementMapByCreatedAt[descendant.CreatedAt().Key()] = descendant
+	})
+}
+
+// registerShadowedElements registers the values shadowed in the given element
+// and its descendants as removed elements.
+func (r *Root) registerShadowedElements(elem Element) {
+	register := func(elem Element) {
+		if obj, ok := elem.(*Object); ok {
+			obj.memberNodes.forEachShadowedNode(func(node *RHTNode) {
+				r.registerRemovedElement(node.elem)
+			})
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	register(elem)
+	forEachDescendant(elem, register)
+}
+
+// RemovedElements returns the removed elements pending garbage collection.
+func (r *Root) RemovedElements() []Element {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	elements := make([]Element, 0, len(r.removedElementMapByCreatedAt))
+	for _, elem := range r.removedElementMapByCreatedAt {
+		elements = append(elements, elem)
+	}
+	return elements
+}
+
+// RemovedElementLen returns the number of the removed elements pending
+// garbage collection.
+func (r *Root) RemovedElementLen() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return len(r.removedElementMapByCreatedAt)
+}
+
+// RecordConcurrentSet records a value set concurrently with the value of
+// another actor, which is resolved by last-writer-wins.
+func (r *Root) RecordConcurrentSet() {
+	atomic.AddInt64(&r.concurrentSets, 1)
+}
+
+// RecordInterleavedEdit records a text edit interleaved with concurrent
+// edits of other actors.
+func (r *Root) RecordInterleavedEdit() {
+	atomic.AddInt64(&r.interleavedEdits, 1)
+}
+
+// ConcurrentSets returns the number of values set concurrently with the values
+// of other actors.
+func (r *Root) ConcurrentSets() int64 {
+	return atomic.LoadInt64(&r.concurrentSets)
+}
+
+// InterleavedEdits returns the number of text edits interleaved with
+// concurrent edits of other actors.
+func (r *Root) InterleavedEdits() int64 {
+	return atomic.LoadInt64(&r.interleavedEdits)
+}
+
+// Tombstones returns the number of the removed elements and text nodes that
+// are still kept in this root.
+func (r *Root) Tombstones() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tombstones := len(r.removedElementMapByCreatedAt)
+	for _, elem := range r.elementMapByCreatedAt {
+		if text, ok := elem.(*Text); ok {
+			for _, node := range text.TextNodes() {
+				if node.RemovedAt() != nil {
+					tombstones++
+				}
+			}
+		}
+	}
+
+	return tombstones
+}
+
+// Restore replaces the contents of the element of the given creation time with
+// a deep copy of the same element in the given origin. The element keeps its
+// identity, so its ancestors don't have to be copied again. It returns false if
+// the element can't be restored in place.
+//
+// Elements that only exist in this root are skipped, because they are dropped
+// when the ancestor that they were created in is restored.
+func (r *Root) Restore(origin *Root, createdAt *time.Ticket) bool {
+	target := r.FindByCreatedAt(createdAt)
+	source := origin.FindByCreatedAt(createdAt)
+	if target == nil || source == nil {
+		return true
+	}
+
+	forEachDescendant(target, r.DeregisterElement)
+
+	switch elem := target.(type) {
+	case *Object:
+		src, ok := source.(*Object)
+		if !ok {
+			return false
+		}
+		elem.memberNodes = src.DeepCopy().(*Object).memberNodes
+	case *Array:
+		src, ok := source.(*Array)
+		if !ok {
+			return false
+		}
+		elem.elements = src.DeepCopy().(*Array).elements
+	case *Text:
+		src, ok := source.(*Text)
+		if !ok {
+			return false
+		}
+		copied := src.DeepCopy().(*Text)
+		elem.rgaTreeSplit = copied.rgaTreeSplit
+		elem.selectionMap = copied.selectionMap
+	default:
+		return false
+	}
+
+	forEachDescendant(target, r.RegisterElement)
+	r.registerShadowedElements(target)
+	return true
 }
 
 // DeepCopy copies itself deeply.
 func (r *Root) DeepCopy() *Root {
 	return NewRoot(r.object.DeepCopy().(*Object))
 }
+
+// forEachDescendant calls the given function for each descendant of the given
+// element.
+func forEachDescendant(elem Element, fn func(elem Element)) {
+	descendants := make(chan Element)
+	go func() {
+		switch container := elem.(type) {
+		case *Object:
+			container.Descendants(descendants)
+		case *Array:
+			container.Descendants(descendants)
+		}
+		close(descendants)
+	}()
+	for descendant := range descendants {
+		fn(descendant)
+	}
+}
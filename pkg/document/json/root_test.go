@@ -0,0 +1,88 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package json_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yorkie-team/yorkie/pkg/document/json"
+	"github.com/yorkie-team/yorkie/pkg/document/time"
+)
+
+func TestRoot(t *testing.T) {
+	ticket := func(lamport uint64) *time.Ticket {
+		return time.NewTicket(lamport, 0, time.InitialActorID)
+	}
+
+	t.Run("find by created at test", func(t *testing.T) {
+		obj := json.NewObject(json.NewRHT(), time.InitialTicket)
+		nested := json.NewObject(json.NewRHT(), ticket(1))
+		obj.Set("nested", nested)
+		nested.Set("k", json.NewPrimitive("v", ticket(2)))
+		root := json.NewRoot(obj)
+
+		assert.Equal(t, obj, root.FindByCreatedAt(time.InitialTicket))
+		assert.Equal(t, nested, root.FindByCreatedAt(ticket(1)))
+		assert.Equal(t, `"v"`, root.FindByCreatedAt(ticket(2)).Marshal())
+		assert.Nil(t, root.FindByCreatedAt(ticket(3)))
+	})
+
+	t.Run("removed element registry test", func(t *testing.T) {
+		obj := json.NewObject(json.NewRHT(), time.InitialTicket)
+		obj.Set("a", json.NewPrimitive("a", ticket(1)))
+		obj.Set("b", json.NewPrimitive("b", ticket(2)))
+		obj.Delete("a", ticket(3))
+		root := json.NewRoot(obj)
+		assert.Equal(t, 1, root.RemovedElementLen())
+
+		// NOTE: Elements not removed are ignored.
+		root.RegisterRemovedElement(root.FindByCreatedAt(ticket(2)))
+		assert.Equal(t, 1, root.RemovedElementLen())
+
+		removed := obj.Delete("b", ticket(4))
+		root.RegisterRemovedElement(removed)
+		assert.Equal(t, 2, root.RemovedElementLen())
+		assert.Equal(t, 2, root.Tombstones())
+
+		root.DeregisterElement(removed)
+		assert.Equal(t, 1, root.RemovedElementLen())
+		assert.Len(t, root.RemovedElements(), 1)
+		assert.Nil(t, root.FindByCreatedAt(ticket(2)))
+	})
+
+	t.Run("shadowed and descendant elements test", func(t *testing.T) {
+		obj := json.NewObject(json.NewRHT(), time.InitialTicket)
+		obj.Set("a", json.NewPrimitive("a1", ticket(1)))
+		obj.Set("a", json.NewPrimitive("a2", ticket(2)))
+		nested := json.NewObject(json.NewRHT(), ticket(3))
+		obj.Set("b", nested)
+		nested.Set("c", json.NewPrimitive("c", ticket(4)))
+		root := json.NewRoot(obj)
+
+		// the values overwritten by later values are registered.
+		assert.Equal(t, 1, root.RemovedElementLen())
+		root.RegisterShadowedElement(obj.Set("a", json.NewPrimitive("a3", ticket(5))))
+		assert.Equal(t, 2, root.RemovedElementLen())
+
+		// the descendants of removed containers are registered with them.
+		root.RegisterRemovedElement(obj.Delete("b", ticket(6)))
+		assert.Equal(t, 4, root.RemovedElementLen())
+		assert.Equal(t, 4, json.NewRoot(obj).RemovedElementLen())
+	})
+}
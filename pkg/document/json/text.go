@@ -27,6 +27,8 @@ import (
 	"github.com/yorkie-team/yorkie/pkg/splay"
 )
 
+var logger = log.Named(log.Document)
+
 var (
 	initialTextNodeID = NewTextNodeID(time.InitialTicket, 0)
 )
@@ -187,11 +189,17 @@ func (t *TextNode) setPrev(node *TextNode) {
 	node.next = t
 }
 
+// split splits this node at the given offset and returns the split node. The
+// split node keeps the removal of this node.
 func (t *TextNode) split(offset int) *TextNode {
-	return NewTextNode(
-		t.id.split(offset),
-		t.splitContent(offset),
-	)
+	node := &TextNode{
+		id:        t.id.split(offset),
+		value:     t.splitContent(offset),
+		removedAt: t.removedAt,
+	}
+	node.indexNode = splay.NewNode(node)
+
+	return node
 }
 
 func (t *TextNode) splitContent(offset int) string {
@@ -211,6 +219,14 @@ func (t *TextNode) annotatedString() string {
 	return fmt.Sprintf("%s %s", t.id.AnnotatedString(), t.value)
 }
 
+// removedConcurrently returns whether this node was removed by another actor
+// concurrently with the edit of the given time.
+func (t *TextNode) removedConcurrently(editedAt *time.Ticket) bool {
+	return t.removedAt != nil &&
+		t.removedAt.ActorIDHex() != editedAt.ActorIDHex() &&
+		editedAt.Lamport() <= t.removedAt.Lamport()
+}
+
 func (t *TextNode) Remove(removedAt *time.Ticket, latestCreatedAt *time.Ticket) bool {
 	if !t.createdAt().After(latestCreatedAt) &&
 		(t.removedAt == nil || removedAt.After(t.removedAt)) {
@@ -257,10 +273,35 @@ func (s *RGATreeSplit) findTextNodePos(index int) *TextNodePos {
 	}
 }
 
+// indexOf returns the index of the given position in the current text. A
+// position in removed text is mapped to the index where the text was. It
+// returns false if the position is not found.
+func (s *RGATreeSplit) indexOf(pos *TextNodePos) (int, bool) {
+	absoluteID := pos.getAbsoluteID()
+
+	index := 0
+	for node := s.initialHead; node != nil; node = node.next {
+		if node.id.hasSameCreatedAt(absoluteID) &&
+			node.id.offset <= absoluteID.offset &&
+			absoluteID.offset <= node.id.offset+node.contentLen() {
+			if node.removedAt == nil {
+				index += absoluteID.offset - node.id.offset
+			}
+			return index, true
+		}
+		index += node.Len()
+	}
+
+	return 0, false
+}
+
+// findTextNodeWithSplit splits the node at the given position and returns the
+// nodes on both sides of it. The nodes inserted concurrently after the position
+// are skipped, and it reports whether any was skipped.
 func (s *RGATreeSplit) findTextNodeWithSplit(
 	pos *TextNodePos,
 	updatedAt *time.Ticket,
-) (*TextNode, *TextNode) {
+) (*TextNode, *TextNode, bool) {
 	absoluteID := pos.getAbsoluteID()
 	node := s.findFloorTextNodePreferToLeft(absoluteID)
 
@@ -268,23 +309,25 @@ func (s *RGATreeSplit) findTextNodeWithSplit(
 
 	s.splitTextNode(node, relativeOffset)
 
+	skipped := false
 	for node.next != nil && node.next.createdAt().After(updatedAt) {
 		node = node.next
+		skipped = true
 	}
 
-	return node, node.next
+	return node, node.next, skipped
 }
 
 func (s *RGATreeSplit) findFloorTextNodePreferToLeft(id *TextNodeID) *TextNode {
 	node := s.findFloorTextNode(id)
 	if node == nil {
-		log.Logger.Error(s.AnnotatedString())
+		logger.Error(s.AnnotatedString())
 		panic("the node of the given id should be found")
 	}
 
 	if id.offset > 0 && node.id.offset == id.offset {
 		if node.insPrev == nil {
-			log.Logger.Error(s.AnnotatedString())
+			logger.Error(s.AnnotatedString())
 			panic("insPrev should be presence")
 		}
 		node = node.insPrev
@@ -295,7 +338,7 @@ func (s *RGATreeSplit) findFloorTextNodePreferToLeft(id *TextNodeID) *TextNode {
 
 func (s *RGATreeSplit) splitTextNode(node *TextNode, offset int) *TextNode {
 	if offset > node.contentLen() {
-		log.Logger.Error(s.AnnotatedString())
+		logger.Error(s.AnnotatedString())
 		panic("offset should be less than or equal to length")
 	}
 
@@ -359,20 +402,24 @@ func (s *RGATreeSplit) findFloorTextNode(id *TextNodeID) *TextNode {
 	return foundValue
 }
 
+// edit edits the nodes between the given positions. It also reports whether
+// the edit was interleaved with concurrent edits of other actors, i.e. it
+// inserted next to nodes inserted or removed concurrently, or left nodes
+// unknown to the editor undeleted.
 func (s *RGATreeSplit) edit(
 	from *TextNodePos,
 	to *TextNodePos,
 	latestCreatedAtMapByActor map[string]*time.Ticket,
 	content string,
 	editedAt *time.Ticket,
-) (*TextNodePos, map[string]*time.Ticket) {
+) (*TextNodePos, map[string]*time.Ticket, bool) {
 	// 01. split nodes with from and to
-	toLeft, toRight := s.findTextNodeWithSplit(to, editedAt)
-	fromLeft, fromRight := s.findTextNodeWithSplit(from, editedAt)
+	toLeft, toRight, _ := s.findTextNodeWithSplit(to, editedAt)
+	fromLeft, fromRight, skipped := s.findTextNodeWithSplit(from, editedAt)
 
 	// 02. delete between from and to
 	nodesToDelete := s.findBetween(fromRight, toRight)
-	latestCreatedAtMap := s.deleteNodes(nodesToDelete, latestCreatedAtMapByActor, editedAt)
+	latestCreatedAtMap, kept := s.deleteNodes(nodesToDelete, latestCreatedAtMapByActor, editedAt)
 
 	var caretID *TextNodeID
 	if toRight == nil {
@@ -388,7 +435,12 @@ func (s *RGATreeSplit) edit(
 		caretPos = NewTextNodePos(inserted.id, inserted.contentLen())
 	}
 
-	return caretPos, latestCreatedAtMap
+	interleaved := kept
+	if content != "" && (skipped || fromLeft.removedConcurrently(editedAt)) {
+		interleaved = true
+	}
+
+	return caretPos, latestCreatedAtMap, interleaved
 }
 
 func (s *RGATreeSplit) findBetween(from *TextNode, to *TextNode) []*TextNode {
@@ -405,8 +457,9 @@ func (s *RGATreeSplit) deleteNodes(
 	candidates []*TextNode,
 	latestCreatedAtMapByActor map[string]*time.Ticket,
 	editedAt *time.Ticket,
-) map[string]*time.Ticket {
+) (map[string]*time.Ticket, bool) {
 	createdAtMapByActor := make(map[string]*time.Ticket)
+	kept := false
 
 	for _, node := range candidates {
 		actorIDHex := node.createdAt().ActorIDHex()
@@ -431,10 +484,12 @@ func (s *RGATreeSplit) deleteNodes(
 			if latestCreatedAt == nil || createdAt.After(latestCreatedAt) {
 				createdAtMapByActor[actorIDHex] = createdAt
 			}
+		} else if node.removedAt == nil {
+			kept = true
 		}
 	}
 
-	return createdAtMapByActor
+	return createdAtMapByActor, kept
 }
 
 func (s *RGATreeSplit) marshal() string {
@@ -471,7 +526,7 @@ func (s *RGATreeSplit) AnnotatedString() string {
 	node := s.initialHead
 	for node != nil {
 		if node.id.offset > 0 && node.insPrev == nil {
-			log.Logger.Warn("insPrev should be presence")
+			logger.Warn("insPrev should be presence")
 		}
 
 		if node.removedAt != nil {
@@ -509,7 +564,7 @@ func newSelection(from, to *TextNodePos, updatedAt *time.Ticket) *Selection {
 // Text is an extended data type for the contents of a text editor.
 type Text struct {
 	rgaTreeSplit *RGATreeSplit
-	selectionMap map[string]*Selection
+	selectionMap map[time.ActorID]*Selection
 	createdAt    *time.Ticket
 	updatedAt    *time.Ticket
 	removedAt    *time.Ticket
@@ -519,11 +574,16 @@ type Text struct {
 func NewText(elements *RGATreeSplit, createdAt *time.Ticket) *Text {
 	return &Text{
 		rgaTreeSplit: elements,
-		selectionMap: make(map[string]*Selection),
+		selectionMap: make(map[time.ActorID]*Selection),
 		createdAt:    createdAt,
 	}
 }
 
+// String returns the content of this Text.
+func (t *Text) String() string {
+	return t.rgaTreeSplit.marshal()
+}
+
 func (t *Text) Marshal() string {
 	return fmt.Sprintf("\"%s\"", t.rgaTreeSplit.marshal())
 }
@@ -539,7 +599,7 @@ func (t *Text) DeepCopy() Element {
 		if insPrevID != nil {
 			insPrevNode := rgaTreeSplit.FindTextNode(insPrevID)
 			if insPrevNode == nil {
-				log.Logger.Warn("insPrevNode should be presence")
+				logger.Warn("insPrevNode should be presence")
 			}
 			current.SetInsPrev(insPrevNode)
 		}
@@ -577,31 +637,41 @@ func (t *Text) Remove(removedAt *time.Ticket) bool {
 	return false
 }
 
+// IndexOf returns the index of the given position in the current text. It
+// returns false if the position is not found.
+func (t *Text) IndexOf(pos *TextNodePos) (int, bool) {
+	return t.rgaTreeSplit.indexOf(pos)
+}
+
 // CreateRange returns pair of TextNodePos of the given integer offsets.
 func (t *Text) CreateRange(from, to int) (*TextNodePos, *TextNodePos) {
 	return t.rgaTreeSplit.createRange(from, to)
 }
 
+// Edit edits the given range with the given content. It also reports whether
+// the edit was interleaved with concurrent edits of other actors.
 func (t *Text) Edit(
 	from,
 	to *TextNodePos,
 	latestCreatedAtMapByActor map[string]*time.Ticket,
 	content string,
 	editedAt *time.Ticket,
-) (*TextNodePos, map[string]*time.Ticket) {
-	cursorPos, latestCreatedAtMapByActor := t.rgaTreeSplit.edit(
+) (*TextNodePos, map[string]*time.Ticket, bool) {
+	cursorPos, latestCreatedAtMapByActor, interleaved := t.rgaTreeSplit.edit(
 		from,
 		to,
 		latestCreatedAtMapByActor,
 		content,
 		editedAt,
 	)
-	log.Logger.Debugf(
-		"EDIT: '%s' edits %s",
-		editedAt.ActorID().String(),
-		t.rgaTreeSplit.AnnotatedString(),
-	)
-	return cursorPos, latestCreatedAtMapByActor
+	if log.IsDebugEnabledFor(log.Document) {
+		logger.Debugf(
+			"EDIT: '%s' edits %s",
+			editedAt.ActorID().String(),
+			t.rgaTreeSplit.AnnotatedString(),
+		)
+	}
+	return cursorPos, latestCreatedAtMapByActor, interleaved
 }
 
 func (t *Text) Select(
@@ -609,20 +679,22 @@ func (t *Text) Select(
 	to *TextNodePos,
 	updatedAt *time.Ticket,
 ) {
-	if _, ok := t.selectionMap[updatedAt.ActorIDHex()]; !ok {
-		t.selectionMap[updatedAt.ActorIDHex()] = newSelection(from, to, updatedAt)
+	if _, ok := t.selectionMap[*updatedAt.ActorID()]; !ok {
+		t.selectionMap[*updatedAt.ActorID()] = newSelection(from, to, updatedAt)
 		return
 	}
 
-	prevSelection := t.selectionMap[updatedAt.ActorIDHex()]
+	prevSelection := t.selectionMap[*updatedAt.ActorID()]
 	if updatedAt.After(prevSelection.updatedAt) {
-		log.Logger.Debugf(
-			"SELT: '%s' selects %s",
-			updatedAt.ActorID().String(),
-			t.rgaTreeSplit.AnnotatedString(),
-		)
+		if log.IsDebugEnabledFor(log.Document) {
+			logger.Debugf(
+				"SELT: '%s' selects %s",
+				updatedAt.ActorID().String(),
+				t.rgaTreeSplit.AnnotatedString(),
+			)
+		}
 
-		t.selectionMap[updatedAt.ActorIDHex()] = newSelection(from, to, updatedAt)
+		t.selectionMap[*updatedAt.ActorID()] = newSelection(from, to, updatedAt)
 	}
 }
 
@@ -18,11 +18,21 @@ package key
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 )
 
 const (
 	BSONSplitter = "$"
+
+	// PathSeparator separates the segments of the hierarchical path of a
+	// document, e.g. "workspace-42/designs/logo".
+	PathSeparator = "/"
+)
+
+var (
+	// ErrInvalidKey is returned when the key is not a valid document key.
+	ErrInvalidKey = errors.New("invalid document key")
 )
 
 type Key struct {
@@ -42,3 +52,97 @@ func FromBSONKey(bsonKey string) (*Key, error) {
 func (k *Key) BSONKey() string {
 	return k.Collection + BSONSplitter + k.Document
 }
+
+// Validate checks whether the key is a valid document key. The document of
+// the key is a path of segments separated by PathSeparator, and every segment
+// should be neither empty nor a relative segment such as "." and "..".
+func (k *Key) Validate() error {
+	if err := validateCollection(k.Collection); err != nil {
+		return err
+	}
+
+	return validatePath(k.Document)
+}
+
+// Segments returns the segments of the path of the document.
+func (k *Key) Segments() []string {
+	return strings.Split(k.Document, PathSeparator)
+}
+
+// Prefix is a prefix of the paths of the documents in a collection. The path
+// of a prefix is aligned to segments, so "workspace-4" does not match
+// "workspace-42/doc". An empty path matches all documents of the collection.
+type Prefix struct {
+	Collection string
+	Path       string
+}
+
+// NewPrefix creates a new prefix of the given collection and path. The
+// trailing PathSeparator of the path is optional.
+func NewPrefix(collection, path string) (*Prefix, error) {
+	p := &Prefix{
+		Collection: collection,
+		Path:       strings.TrimSuffix(path, PathSeparator),
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// Validate checks whether the prefix is a valid prefix of document keys.
+func (p *Prefix) Validate() error {
+	if err := validateCollection(p.Collection); err != nil {
+		return err
+	}
+	if p.Path == "" {
+		return nil
+	}
+
+	return validatePath(p.Path)
+}
+
+// Matches returns whether the given key is under the prefix.
+func (p *Prefix) Matches(k *Key) bool {
+	return strings.HasPrefix(k.BSONKey(), p.BSONKey())
+}
+
+// BSONKey returns the prefix of the BSON keys of the documents under the
+// prefix. It always ends with BSONSplitter or PathSeparator, so that the
+// prefix of a BSON key is aligned to segments.
+func (p *Prefix) BSONKey() string {
+	if p.Path == "" {
+		return p.Collection + BSONSplitter
+	}
+
+	return p.Collection + BSONSplitter + p.Path + PathSeparator
+}
+
+func (p *Prefix) String() string {
+	return p.BSONKey()
+}
+
+func validateCollection(collection string) error {
+	if collection == "" ||
+		strings.Contains(collection, BSONSplitter) ||
+		strings.Contains(collection, PathSeparator) {
+		return fmt.Errorf("collection %q: %w", collection, ErrInvalidKey)
+	}
+
+	return nil
+}
+
+func validatePath(path string) error {
+	if strings.Contains(path, BSONSplitter) {
+		return fmt.Errorf("document %q: %w", path, ErrInvalidKey)
+	}
+
+	for _, segment := range strings.Split(path, PathSeparator) {
+		if segment == "" || segment == "." || segment == ".." {
+			return fmt.Errorf("document %q: %w", path, ErrInvalidKey)
+		}
+	}
+
+	return nil
+}
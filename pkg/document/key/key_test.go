@@ -0,0 +1,80 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package key_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yorkie-team/yorkie/pkg/document/key"
+)
+
+func TestKey(t *testing.T) {
+	t.Run("validate test", func(t *testing.T) {
+		valid := []*key.Key{
+			{Collection: "c1", Document: "d1"},
+			{Collection: "c1", Document: "workspace-42/designs/logo"},
+			{Collection: "c1", Document: "TestKey/validate_test.v2"},
+		}
+		for _, k := range valid {
+			assert.NoError(t, k.Validate(), k.BSONKey())
+		}
+
+		invalid := []*key.Key{
+			{Collection: "", Document: "d1"},
+			{Collection: "c1", Document: ""},
+			{Collection: "c$1", Document: "d1"},
+			{Collection: "c/1", Document: "d1"},
+			{Collection: "c1", Document: "d$1"},
+			{Collection: "c1", Document: "/workspace-42"},
+			{Collection: "c1", Document: "workspace-42/"},
+			{Collection: "c1", Document: "workspace-42//logo"},
+			{Collection: "c1", Document: "workspace-42/../logo"},
+			{Collection: "c1", Document: "./logo"},
+		}
+		for _, k := range invalid {
+			assert.True(t, errors.Is(k.Validate(), key.ErrInvalidKey), k.BSONKey())
+		}
+
+		k := &key.Key{Collection: "c1", Document: "workspace-42/designs/logo"}
+		assert.Equal(t, []string{"workspace-42", "designs", "logo"}, k.Segments())
+	})
+
+	t.Run("prefix test", func(t *testing.T) {
+		p, err := key.NewPrefix("c1", "workspace-42/")
+		assert.NoError(t, err)
+		assert.Equal(t, "c1$workspace-42/", p.BSONKey())
+
+		assert.True(t, p.Matches(&key.Key{Collection: "c1", Document: "workspace-42/logo"}))
+		assert.True(t, p.Matches(&key.Key{Collection: "c1", Document: "workspace-42/designs/logo"}))
+		assert.False(t, p.Matches(&key.Key{Collection: "c1", Document: "workspace-42"}))
+		assert.False(t, p.Matches(&key.Key{Collection: "c1", Document: "workspace-420/logo"}))
+		assert.False(t, p.Matches(&key.Key{Collection: "c2", Document: "workspace-42/logo"}))
+
+		all, err := key.NewPrefix("c1", "")
+		assert.NoError(t, err)
+		assert.True(t, all.Matches(&key.Key{Collection: "c1", Document: "workspace-42/logo"}))
+		assert.False(t, all.Matches(&key.Key{Collection: "c10", Document: "workspace-42/logo"}))
+
+		_, err = key.NewPrefix("c1", "workspace-42//")
+		assert.True(t, errors.Is(err, key.ErrInvalidKey))
+		_, err = key.NewPrefix("", "workspace-42")
+		assert.True(t, errors.Is(err, key.ErrInvalidKey))
+	})
+}
@@ -0,0 +1,305 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package document
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/yorkie-team/yorkie/api/converter"
+	"github.com/yorkie-team/yorkie/pkg/document/change"
+	"github.com/yorkie-team/yorkie/pkg/document/key"
+	"github.com/yorkie-team/yorkie/pkg/document/operation"
+)
+
+var (
+	// ErrLocalChangesLimitExceeded is returned by Update when the local
+	// changes exceed the limit.
+	ErrLocalChangesLimitExceeded = errors.New("local changes limit exceeded")
+
+	errChangeStoreRequired = errors.New("change store required to spill local changes")
+)
+
+// LimitPolicy decides what happens when the local changes exceed the limit.
+type LimitPolicy int
+
+const (
+	// RejectPolicy rejects the updates exceeding the limit.
+	RejectPolicy LimitPolicy = iota
+
+	// CompactPolicy merges the local changes not pushed yet into a single
+	// change. Updates are rejected if the local changes still exceed the
+	// limit after the compaction.
+	CompactPolicy
+
+	// SpillPolicy moves the local changes from memory to the change store.
+	// They are loaded again when they are pushed.
+	SpillPolicy
+)
+
+// LocalChangesLimit is the limit of the local changes kept in memory until
+// they are pushed.
+type LocalChangesLimit struct {
+	// MaxChanges is the maximum number of the local changes. It is unlimited
+	// if it is zero.
+	MaxChanges int
+
+	// MaxBytes is the maximum size of the local changes in Protobuf format.
+	// It is unlimited if it is zero.
+	MaxBytes int
+
+	// Policy is the policy applied when the limit is exceeded.
+	Policy LimitPolicy
+
+	// Store is the store where the local changes are spilled. It is required
+	// by SpillPolicy.
+	Store ChangeStore
+}
+
+// ChangeStore is a local persistence store where the local changes are spilled
+// from memory.
+type ChangeStore interface {
+	// Append appends the given encoded changes of the given document.
+	Append(docKey *key.Key, changes []byte) error
+
+	// Load returns all the encoded changes of the given document in the
+	// order they were appended.
+	Load(docKey *key.Key) ([][]byte, error)
+
+	// Clear deletes all the encoded changes of the given document.
+	Clear(docKey *key.Key) error
+}
+
+// LocalChangesStats is the size of the local changes of a document. Bytes is
+// only measured if the limit of the local changes has MaxBytes.
+type LocalChangesStats struct {
+	Changes        int
+	Bytes          int
+	SpilledChanges int
+	SpilledBytes   int
+}
+
+// SetLocalChangesLimit sets the limit of the local changes kept in memory.
+func (d *Document) SetLocalChangesLimit(limit LocalChangesLimit) error {
+	if limit.Policy == SpillPolicy && limit.Store == nil {
+		return errChangeStoreRequired
+	}
+
+	d.limit = limit
+
+	// NOTE: The sizes of the local changes are measured again, because they
+	// are not measured without MaxBytes.
+	d.localBytes = 0
+	for i, c := range d.localChanges {
+		d.localSizes[i] = d.changeSize(c)
+		d.localBytes += d.localSizes[i]
+	}
+
+	return nil
+}
+
+// changeSize returns the size of the given change in Protobuf format. The
+// change is not encoded unless the limit has MaxBytes.
+func (d *Document) changeSize(c *change.Change) int {
+	if d.limit.MaxBytes == 0 {
+		return 0
+	}
+
+	return converter.ChangeSize(c)
+}
+
+// LocalChangesStats returns the size of the local changes not pushed yet.
+func (d *Document) LocalChangesStats() LocalChangesStats {
+	return LocalChangesStats{
+		Changes:        len(d.localChanges),
+		Bytes:          d.localBytes,
+		SpilledChanges: d.spilledChanges,
+		SpilledBytes:   d.spilledBytes,
+	}
+}
+
+// exceedsLimit returns whether the local changes exceed the limit if a change
+// of the given size is added.
+func (d *Document) exceedsLimit(size int) bool {
+	if d.limit.MaxChanges > 0 && len(d.localChanges)+1 > d.limit.MaxChanges {
+		return true
+	}
+
+	return d.limit.MaxBytes > 0 && d.localBytes+size > d.limit.MaxBytes
+}
+
+// ensureLimit applies the policy if the local changes exceed the limit when a
+// change of the given size is added.
+func (d *Document) ensureLimit(size int) error {
+	if !d.exceedsLimit(size) {
+		return nil
+	}
+
+	switch d.limit.Policy {
+	case CompactPolicy:
+		d.compactLocalChanges()
+		if d.exceedsLimit(size) {
+			return ErrLocalChangesLimitExceeded
+		}
+		return nil
+	case SpillPolicy:
+		return d.spillLocalChanges()
+	default:
+		return ErrLocalChangesLimitExceeded
+	}
+}
+
+// compactLocalChanges merges the local changes that have not been pushed into
+// a single change. The changes that have been pushed are kept as they are,
+// because the agent may have stored them even if the response was lost.
+func (d *Document) compactLocalChanges() {
+	idx := len(d.localChanges)
+	for idx > 0 && d.localChanges[idx-1].ClientSeq() > d.pushedClientSeq {
+		idx--
+	}
+
+	pending := d.localChanges[idx:]
+	if len(pending) < 2 {
+		return
+	}
+
+	var ops []operation.Operation
+	var messages []string
+	var metadata map[string]string
+	for _, c := range pending {
+		ops = append(ops, c.Operations()...)
+		if c.Message() != "" {
+			messages = append(messages, c.Message())
+		}
+		// NOTE: The values of the later changes take precedence.
+		for k, v := range c.Metadata() {
+			if metadata == nil {
+				metadata = make(map[string]string)
+			}
+			metadata[k] = v
+		}
+	}
+	for _, size := range d.localSizes[idx:] {
+		d.localBytes -= size
+	}
+
+	// NOTE: The merged change takes the ID of the last change, so that the
+	// sequences of the following changes continue from it.
+	merged := change.New(pending[len(pending)-1].ID(), strings.Join(messages, "\n"), ops)
+	merged.SetMetadata(metadata)
+	size := d.changeSize(merged)
+	d.localChanges = append(d.localChanges[:idx:idx], merged)
+	d.localSizes = append(d.localSizes[:idx:idx], size)
+	d.localBytes += size
+}
+
+// spillLocalChanges moves the local changes in memory to the change store.
+func (d *Document) spillLocalChanges() error {
+	if len(d.localChanges) == 0 {
+		return nil
+	}
+
+	bytes, err := converter.ChangesToBytes(d.localChanges)
+	if err != nil {
+		return err
+	}
+	if err := d.limit.Store.Append(d.key, bytes); err != nil {
+		return err
+	}
+
+	d.spilledChanges += len(d.localChanges)
+	d.spilledBytes += len(bytes)
+	d.spilledClientSeq = d.localChanges[len(d.localChanges)-1].ClientSeq()
+	d.localChanges = nil
+	d.localSizes = nil
+	d.localBytes = 0
+
+	return nil
+}
+
+// allLocalChanges returns the local changes including the spilled ones.
+func (d *Document) allLocalChanges() ([]*change.Change, error) {
+	if d.spilledChanges == 0 {
+		return d.localChanges, nil
+	}
+
+	batches, err := d.limit.Store.Load(d.key)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []*change.Change
+	for _, bytes := range batches {
+		batch, err := converter.BytesToChanges(bytes)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, batch...)
+	}
+
+	// NOTE: The actor may have been set after the changes were spilled.
+	actor := d.Actor()
+	for _, c := range changes {
+		c.SetActor(actor)
+	}
+
+	return append(changes, d.localChanges...), nil
+}
+
+// moveSpilledChanges moves the spilled local changes to the given key in the
+// change store.
+func (d *Document) moveSpilledChanges(k *key.Key) error {
+	if d.spilledChanges == 0 {
+		return nil
+	}
+
+	batches, err := d.limit.Store.Load(d.key)
+	if err != nil {
+		return err
+	}
+	for _, bytes := range batches {
+		if err := d.limit.Store.Append(k, bytes); err != nil {
+			return err
+		}
+	}
+
+	return d.limit.Store.Clear(d.key)
+}
+
+// removePushedChanges removes the local changes applied to the agent up to the
+// given client sequence.
+func (d *Document) removePushedChanges(clientSeq uint32) error {
+	if d.spilledChanges > 0 && d.spilledClientSeq <= clientSeq {
+		if err := d.limit.Store.Clear(d.key); err != nil {
+			return err
+		}
+		d.spilledChanges = 0
+		d.spilledBytes = 0
+	}
+
+	for len(d.localChanges) > 0 {
+		c := d.localChanges[0]
+		if c.ClientSeq() > clientSeq {
+			break
+		}
+		d.localChanges = d.localChanges[1:]
+		d.localBytes -= d.localSizes[0]
+		d.localSizes = d.localSizes[1:]
+	}
+
+	return nil
+}
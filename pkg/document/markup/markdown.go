@@ -0,0 +1,142 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package markup
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/yorkie-team/yorkie/pkg/document/proxy"
+)
+
+var (
+	headingPattern       = regexp.MustCompile(`^ {0,3}#{1,6}(\s+|$)`)
+	closingHashesPattern = regexp.MustCompile(`\s+#+\s*$`)
+	listItemPattern      = regexp.MustCompile(`^\s*([-+*]|\d{1,9}[.)])\s+`)
+	quotePattern         = regexp.MustCompile(`^ {0,3}>\s?`)
+	thematicBreakPattern = regexp.MustCompile(`^ {0,3}([-*_])(\s*([-*_]))+\s*$`)
+	fencePattern         = regexp.MustCompile("^ {0,3}(```|~~~)")
+
+	imagePattern    = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	linkPattern     = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	autolinkPattern = regexp.MustCompile(`<((?:https?|mailto):[^>\s]+)>`)
+	codeSpanPattern = regexp.MustCompile("`+([^`]*)`+")
+	emphasisPattern = regexp.MustCompile(`(\*\*|__|\*|_|~~)(\S(?:.*?\S)?)(\*\*|__|\*|_|~~)`)
+	escapePattern   = regexp.MustCompile(`\\([!-/:-@\[-` + "`" + `{-~])`)
+)
+
+// ImportMarkdown replaces the content of the given Text with the given
+// Markdown. Each paragraph, heading, list item and line of code blocks becomes
+// a line of the Text, and the inline markup is removed. It is the inverse of
+// TextToMarkdown.
+//
+// It should be called inside an Update, so that the edit is recorded as a
+// change of the document.
+func ImportMarkdown(text *proxy.TextProxy, src string) *proxy.TextProxy {
+	content := strings.Join(markdownToLines(src), "\n")
+	return text.Edit(0, utf8.RuneCountInString(text.String()), content)
+}
+
+// markdownToLines returns the lines of the plain text of the given Markdown.
+func markdownToLines(src string) []string {
+	var lines []string
+	var paragraph []string
+	flush := func() {
+		if len(paragraph) > 0 {
+			lines = append(lines, inlineToText(strings.Join(paragraph, " ")))
+			paragraph = nil
+		}
+	}
+
+	fence := ""
+	for _, line := range strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n") {
+		if fence != "" {
+			if strings.HasPrefix(strings.TrimSpace(line), fence) {
+				fence = ""
+				continue
+			}
+			lines = append(lines, line)
+			continue
+		}
+
+		if m := fencePattern.FindStringSubmatch(line); m != nil {
+			flush()
+			fence = m[1]
+			continue
+		}
+
+		line = quotePattern.ReplaceAllString(line, "")
+		switch {
+		case strings.TrimSpace(line) == "":
+			flush()
+		case thematicBreakPattern.MatchString(line):
+			flush()
+		case headingPattern.MatchString(line):
+			flush()
+			heading := headingPattern.ReplaceAllString(line, "")
+			heading = closingHashesPattern.ReplaceAllString(heading, "")
+			lines = append(lines, inlineToText(strings.TrimSpace(heading)))
+		case listItemPattern.MatchString(line):
+			flush()
+			paragraph = append(paragraph, listItemPattern.ReplaceAllString(line, ""))
+		case strings.HasSuffix(line, "  ") || strings.HasSuffix(line, `\`):
+			// NOTE: A hard line break ends the line.
+			paragraph = append(paragraph, strings.TrimSpace(strings.TrimSuffix(line, `\`)))
+			flush()
+		default:
+			paragraph = append(paragraph, strings.TrimSpace(line))
+		}
+	}
+	flush()
+
+	return lines
+}
+
+// inlineToText removes the inline markup of the given Markdown.
+func inlineToText(s string) string {
+	// NOTE: Escaped characters are replaced with placeholders first, so that
+	// they are not taken as markup.
+	var escaped []string
+	s = escapePattern.ReplaceAllStringFunc(s, func(m string) string {
+		escaped = append(escaped, m[1:])
+		return "\x00"
+	})
+
+	s = imagePattern.ReplaceAllString(s, "$1")
+	s = linkPattern.ReplaceAllString(s, "$1")
+	s = autolinkPattern.ReplaceAllString(s, "$1")
+	s = codeSpanPattern.ReplaceAllString(s, "$1")
+	for {
+		replaced := emphasisPattern.ReplaceAllStringFunc(s, func(m string) string {
+			sub := emphasisPattern.FindStringSubmatch(m)
+			if sub[1] != sub[3] {
+				return m
+			}
+			return sub[2]
+		})
+		if replaced == s {
+			break
+		}
+		s = replaced
+	}
+
+	for _, c := range escaped {
+		s = strings.Replace(s, "\x00", c, 1)
+	}
+	return s
+}
@@ -0,0 +1,94 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package markup converts the contents of Text elements to and from markup
+// languages such as HTML and Markdown.
+//
+// TODO: Text doesn't have attributes and embeds yet, so the contents are
+//  converted as plain paragraphs and the styles of imported Markdown are
+//  dropped. They should be converted once a rich text type is introduced.
+package markup
+
+import (
+	"html"
+	"strings"
+
+	"github.com/yorkie-team/yorkie/pkg/document/json"
+)
+
+// markdownEscaper escapes the characters that have a meaning in Markdown.
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"`", "\\`",
+	`*`, `\*`,
+	`_`, `\_`,
+	`{`, `\{`,
+	`}`, `\}`,
+	`[`, `\[`,
+	`]`, `\]`,
+	`(`, `\(`,
+	`)`, `\)`,
+	`#`, `\#`,
+	`+`, `\+`,
+	`-`, `\-`,
+	`.`, `\.`,
+	`!`, `\!`,
+	`|`, `\|`,
+	`<`, `\<`,
+	`>`, `\>`,
+)
+
+// TextToHTML converts the content of the given Text to HTML. Each line becomes
+// a paragraph, and the content is escaped, so the result is safe to embed in
+// other HTML documents.
+func TextToHTML(text *json.Text) string {
+	var b strings.Builder
+	for _, line := range lines(text) {
+		b.WriteString("<p>")
+		if line == "" {
+			b.WriteString("<br>")
+		} else {
+			b.WriteString(html.EscapeString(line))
+		}
+		b.WriteString("</p>")
+	}
+	return b.String()
+}
+
+// TextToMarkdown converts the content of the given Text to Markdown. Each line
+// becomes a paragraph, and the characters that have a meaning in Markdown are
+// escaped. Empty lines are dropped, because Markdown can't express empty
+// paragraphs.
+func TextToMarkdown(text *json.Text) string {
+	var paragraphs []string
+	for _, line := range lines(text) {
+		if line == "" {
+			continue
+		}
+		paragraphs = append(paragraphs, markdownEscaper.Replace(line))
+	}
+	return strings.Join(paragraphs, "\n\n")
+}
+
+// lines returns the lines of the content of the given Text.
+func lines(text *json.Text) []string {
+	content := text.String()
+	if content == "" {
+		return nil
+	}
+
+	return strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+}
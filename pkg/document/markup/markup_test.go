@@ -0,0 +1,106 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package markup_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yorkie-team/yorkie/pkg/document"
+	"github.com/yorkie-team/yorkie/pkg/document/json"
+	"github.com/yorkie-team/yorkie/pkg/document/markup"
+	"github.com/yorkie-team/yorkie/pkg/document/proxy"
+)
+
+func newText(t *testing.T, content string) *json.Text {
+	doc := document.New("c1", "d1")
+	err := doc.Update(func(root *proxy.ObjectProxy) error {
+		root.SetNewText("k1").Edit(0, 0, content)
+		return nil
+	})
+	assert.NoError(t, err)
+
+	return doc.RootObject().Get("k1").(*json.Text)
+}
+
+func TestMarkup(t *testing.T) {
+	t.Run("html test", func(t *testing.T) {
+		assert.Equal(t, "", markup.TextToHTML(newText(t, "")))
+		assert.Equal(
+			t,
+			"<p>Hello &lt;b&gt;world&lt;/b&gt; &amp; co</p><p><br></p><p>bye</p>",
+			markup.TextToHTML(newText(t, "Hello <b>world</b> & co\n\nbye\n")),
+		)
+	})
+
+	t.Run("markdown test", func(t *testing.T) {
+		assert.Equal(t, "", markup.TextToMarkdown(newText(t, "")))
+		assert.Equal(
+			t,
+			"\\# not a heading, \\*not emphasis\\*\n\n1\\. not a list",
+			markup.TextToMarkdown(newText(t, "# not a heading, *not emphasis*\n\n1. not a list")),
+		)
+	})
+
+	t.Run("markdown import test", func(t *testing.T) {
+		doc := document.New("c1", "d1")
+		err := doc.Update(func(root *proxy.ObjectProxy) error {
+			text := root.SetNewText("k1").Edit(0, 0, "old content")
+			markup.ImportMarkdown(text, strings.Join([]string{
+				"# Title #",
+				"",
+				"Some **bold** and _italic_ text",
+				"with a [link](https://yorkie.dev) and `code`.",
+				"",
+				"- item 1",
+				"- item \\*2\\*",
+				"",
+				"> quoted  ",
+				"---",
+				"```",
+				"**kept**",
+				"```",
+			}, "\n"))
+			return nil
+		})
+		assert.NoError(t, err)
+
+		text := doc.RootObject().Get("k1").(*json.Text)
+		assert.Equal(t, strings.Join([]string{
+			"Title",
+			"Some bold and italic text with a link and code.",
+			"item 1",
+			"item *2*",
+			"quoted",
+			"**kept**",
+		}, "\n"), text.String())
+	})
+
+	t.Run("markdown round trip test", func(t *testing.T) {
+		content := "# not a heading, *not emphasis*\n1. not a list"
+
+		doc := document.New("c1", "d1")
+		err := doc.Update(func(root *proxy.ObjectProxy) error {
+			markup.ImportMarkdown(root.SetNewText("k1"), markup.TextToMarkdown(newText(t, content)))
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, content, doc.RootObject().Get("k1").(*json.Text).String())
+	})
+}
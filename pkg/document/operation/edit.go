@@ -55,7 +55,10 @@ func (e *Edit) Execute(root *json.Root) error {
 		return ErrNotApplicableDataType
 	}
 
-	obj.Edit(e.from, e.to, e.latestCreatedAtMapByActor, e.content, e.executedAt)
+	_, _, interleaved := obj.Edit(e.from, e.to, e.latestCreatedAtMapByActor, e.content, e.executedAt)
+	if interleaved {
+		root.RecordInterleavedEdit()
+	}
 	return nil
 }
 
@@ -44,9 +44,9 @@ func (o *Remove) Execute(root *json.Root) error {
 
 	switch obj := parent.(type) {
 	case *json.Object:
-		_ = obj.DeleteByCreatedAt(o.createdAt, o.executedAt)
+		root.RegisterRemovedElement(obj.DeleteByCreatedAt(o.createdAt, o.executedAt))
 	case *json.Array:
-		_ = obj.DeleteByCreatedAt(o.createdAt, o.executedAt)
+		root.RegisterRemovedElement(obj.DeleteByCreatedAt(o.createdAt, o.executedAt))
 	default:
 		return ErrNotApplicableDataType
 	}
@@ -51,11 +51,32 @@ func (o *Set) Execute(root *json.Root) error {
 	}
 
 	value := o.value.DeepCopy()
-	obj.Set(o.key, value)
+	prev := obj.Set(o.key, value)
+	if prev != nil && isConcurrent(prev.CreatedAt(), value.CreatedAt()) {
+		root.RecordConcurrentSet()
+	}
+
 	root.RegisterElement(value)
+
+	// NOTE: The value with the lower priority, the previous one or the given
+	// one, is shadowed by the other and never visible again.
+	if prev != nil {
+		if value.CreatedAt().After(prev.CreatedAt()) {
+			root.RegisterShadowedElement(prev)
+		} else {
+			root.RegisterShadowedElement(value)
+		}
+	}
 	return nil
 }
 
+// isConcurrent returns whether the given value was set without knowing the
+// previous value of another actor. Values set after the previous value is
+// known always have a greater lamport.
+func isConcurrent(prev *time.Ticket, value *time.Ticket) bool {
+	return prev.ActorIDHex() != value.ActorIDHex() && value.Lamport() <= prev.Lamport()
+}
+
 func (o *Set) ParentCreatedAt() *time.Ticket {
 	return o.parentCreatedAt
 }
@@ -17,6 +17,7 @@
 package proxy
 
 import (
+	"fmt"
 	time2 "time"
 
 	"github.com/yorkie-team/yorkie/pkg/document/change"
@@ -26,6 +27,8 @@ import (
 	"github.com/yorkie-team/yorkie/pkg/log"
 )
 
+var logger = log.Named(log.Document)
+
 type ArrayProxy struct {
 	*json.Array
 	context *change.Context
@@ -116,6 +119,67 @@ func (p *ArrayProxy) AddNewArray() *ArrayProxy {
 	return v.(*ArrayProxy)
 }
 
+// AddNewObject adds a new object at the end of the array.
+func (p *ArrayProxy) AddNewObject() *ObjectProxy {
+	v := p.addInternal(func(ticket *time.Ticket) json.Element {
+		return NewObjectProxy(p.context, json.NewObject(json.NewRHT(), ticket))
+	})
+
+	return v.(*ObjectProxy)
+}
+
+// AddValue adds the given values at the end of the array. Unlike the other
+// builders, it takes values of dynamic types: bool, int, int64, float64,
+// string, []byte, time.Time, map[string]interface{} and []interface{}. It
+// returns ErrUnsupportedType without adding anything if a value, including
+// the nested ones, has another type.
+func (p *ArrayProxy) AddValue(values ...interface{}) error {
+	for _, value := range values {
+		if err := validateValue(value); err != nil {
+			return err
+		}
+	}
+
+	for _, value := range values {
+		switch value := value.(type) {
+		case map[string]interface{}:
+			p.AddNewObject().setMembers(value)
+		case []interface{}:
+			_ = p.AddNewArray().AddValue(value...)
+		default:
+			p.addInternal(func(ticket *time.Ticket) json.Element {
+				return json.NewPrimitive(value, ticket)
+			})
+		}
+	}
+
+	return nil
+}
+
+// GetObject returns the object at the given index. If the element is not an
+// object, ErrUnsupportedType is recorded to the context so that the update
+// fails. Once the update has failed, a proxy discarding its changes is
+// returned instead of nil, so that the calls chained to it don't panic.
+func (p *ArrayProxy) GetObject(idx int) *ObjectProxy {
+	elem := p.Get(idx)
+	if elem == nil {
+		if p.context.Err() != nil {
+			return newNoopObjectProxy(p.context)
+		}
+		return nil
+	}
+
+	switch elem := elem.(type) {
+	case *json.Object:
+		return NewObjectProxy(p.context, elem)
+	case *ObjectProxy:
+		return elem
+	default:
+		p.context.SetError(fmt.Errorf("%T: %w", elem, ErrUnsupportedType))
+		return newNoopObjectProxy(p.context)
+	}
+}
+
 // MoveBefore moves the given element to its new position before the given next element.
 func (p *ArrayProxy) MoveBefore(nextCreatedAt, createdAt *time.Ticket) {
 	p.moveBeforeInternal(nextCreatedAt, createdAt)
@@ -131,7 +195,7 @@ func (p *ArrayProxy) InsertIntegerAfter(index int, v int) *ArrayProxy {
 
 func (p *ArrayProxy) Delete(idx int) json.Element {
 	if p.Len() <= idx {
-		log.Logger.Warnf("the given index is out of bound: %d", idx)
+		logger.Warnf("the given index is out of bound: %d", idx)
 		return nil
 	}
 
@@ -142,6 +206,7 @@ func (p *ArrayProxy) Delete(idx int) json.Element {
 		deleted.CreatedAt(),
 		ticket,
 	))
+	p.context.RegisterRemovedElement(deleted)
 
 	return deleted
 }
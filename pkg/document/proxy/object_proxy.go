@@ -17,6 +17,8 @@
 package proxy
 
 import (
+	"fmt"
+	"sort"
 	time2 "time"
 
 	"github.com/yorkie-team/yorkie/pkg/document/change"
@@ -117,6 +119,30 @@ func (p *ObjectProxy) SetDate(k string, v time2.Time) *ObjectProxy {
 	return p
 }
 
+// SetValue sets the given value of the given key. Unlike the other builders,
+// it takes a value of dynamic types: bool, int, int64, float64, string,
+// []byte, time.Time, map[string]interface{} and []interface{}. It returns
+// ErrUnsupportedType without setting anything if the value, including the
+// nested ones, has another type.
+func (p *ObjectProxy) SetValue(k string, v interface{}) error {
+	if err := validateValue(v); err != nil {
+		return fmt.Errorf("%s: %w", k, err)
+	}
+
+	switch v := v.(type) {
+	case map[string]interface{}:
+		p.SetNewObject(k).setMembers(v)
+	case []interface{}:
+		_ = p.SetNewArray(k).AddValue(v...)
+	default:
+		p.setInternal(k, func(ticket *time.Ticket) json.Element {
+			return json.NewPrimitive(v, ticket)
+		})
+	}
+
+	return nil
+}
+
 func (p *ObjectProxy) Delete(k string) json.Element {
 	if !p.Object.Has(k) {
 		return nil
@@ -129,12 +155,20 @@ func (p *ObjectProxy) Delete(k string) json.Element {
 		deleted.CreatedAt(),
 		ticket,
 	))
+	p.context.RegisterRemovedElement(deleted)
 	return deleted
 }
 
+// GetObject returns the object of the given key. If the value is not an object,
+// ErrUnsupportedType is recorded to the context so that the update fails. Once
+// the update has failed, a proxy discarding its changes is returned instead of
+// nil, so that the calls chained to it don't panic.
 func (p *ObjectProxy) GetObject(k string) *ObjectProxy {
 	elem := p.Object.Get(k)
 	if elem == nil {
+		if p.context.Err() != nil {
+			return newNoopObjectProxy(p.context)
+		}
 		return nil
 	}
 
@@ -144,13 +178,21 @@ func (p *ObjectProxy) GetObject(k string) *ObjectProxy {
 	case *ObjectProxy:
 		return elem
 	default:
-		panic("unsupported type")
+		p.context.SetError(fmt.Errorf("%T: %w", elem, ErrUnsupportedType))
+		return newNoopObjectProxy(p.context)
 	}
 }
 
+// GetArray returns the array of the given key. If the value is not an array,
+// ErrUnsupportedType is recorded to the context so that the update fails. Once
+// the update has failed, a proxy discarding its changes is returned instead of
+// nil, so that the calls chained to it don't panic.
 func (p *ObjectProxy) GetArray(k string) *ArrayProxy {
 	elem := p.Object.Get(k)
 	if elem == nil {
+		if p.context.Err() != nil {
+			return newNoopArrayProxy(p.context)
+		}
 		return nil
 	}
 
@@ -160,13 +202,21 @@ func (p *ObjectProxy) GetArray(k string) *ArrayProxy {
 	case *ArrayProxy:
 		return elem
 	default:
-		panic("unsupported type")
+		p.context.SetError(fmt.Errorf("%T: %w", elem, ErrUnsupportedType))
+		return newNoopArrayProxy(p.context)
 	}
 }
 
+// GetText returns the text of the given key. If the value is not a text,
+// ErrUnsupportedType is recorded to the context so that the update fails. Once
+// the update has failed, a proxy discarding its changes is returned instead of
+// nil, so that the calls chained to it don't panic.
 func (p *ObjectProxy) GetText(k string) *TextProxy {
 	elem := p.Object.Get(k)
 	if elem == nil {
+		if p.context.Err() != nil {
+			return newNoopTextProxy(p.context)
+		}
 		return nil
 	}
 
@@ -176,7 +226,8 @@ func (p *ObjectProxy) GetText(k string) *TextProxy {
 	case *TextProxy:
 		return elem
 	default:
-		panic("unsupported type")
+		p.context.SetError(fmt.Errorf("%T: %w", elem, ErrUnsupportedType))
+		return newNoopTextProxy(p.context)
 	}
 }
 
@@ -195,8 +246,23 @@ func (p *ObjectProxy) setInternal(
 		ticket,
 	))
 
-	p.Set(k, value)
+	prev := p.Set(k, value)
 	p.context.RegisterElement(value)
+	p.context.RegisterShadowedElement(prev)
 
 	return proxy
 }
+
+// setMembers sets the given members of validated values in the order of their
+// keys, so that the operations are deterministic.
+func (p *ObjectProxy) setMembers(members map[string]interface{}) {
+	keys := make([]string, 0, len(members))
+	for k := range members {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		_ = p.SetValue(k, members[k])
+	}
+}
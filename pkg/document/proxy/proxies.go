@@ -17,9 +17,99 @@
 package proxy
 
 import (
+	"errors"
+	"fmt"
+	"reflect"
+	time2 "time"
+
+	"github.com/yorkie-team/yorkie/pkg/document/change"
 	"github.com/yorkie-team/yorkie/pkg/document/json"
+	"github.com/yorkie-team/yorkie/pkg/document/time"
 )
 
+// ErrUnsupportedType is returned when a value of an unsupported type is given
+// to a proxy, or when an element is accessed as a type other than its own.
+var ErrUnsupportedType = errors.New("unsupported type")
+
+// validateValue returns ErrUnsupportedType if the given value or one of its
+// nested values can't be stored in a document. Maps and slices containing
+// themselves are rejected too, because they can't be stored either.
+func validateValue(v interface{}) error {
+	return validateValueInternal(v, make(map[visitKey]bool))
+}
+
+// visitKey identifies a map or a slice being validated.
+type visitKey struct {
+	ptr uintptr
+	len int
+}
+
+func validateValueInternal(v interface{}, visiting map[visitKey]bool) error {
+	switch v := v.(type) {
+	case bool, int, int64, float64, string, []byte, time2.Time:
+		return nil
+	case map[string]interface{}:
+		key := visitKey{ptr: reflect.ValueOf(v).Pointer()}
+		if visiting[key] {
+			return fmt.Errorf("cyclic %T: %w", v, ErrUnsupportedType)
+		}
+		visiting[key] = true
+		defer delete(visiting, key)
+
+		for k, member := range v {
+			if err := validateValueInternal(member, visiting); err != nil {
+				return fmt.Errorf("%s: %w", k, err)
+			}
+		}
+		return nil
+	case []interface{}:
+		key := visitKey{ptr: reflect.ValueOf(v).Pointer(), len: len(v)}
+		if visiting[key] {
+			return fmt.Errorf("cyclic %T: %w", v, ErrUnsupportedType)
+		}
+		visiting[key] = true
+		defer delete(visiting, key)
+
+		for i, elem := range v {
+			if err := validateValueInternal(elem, visiting); err != nil {
+				return fmt.Errorf("%d: %w", i, err)
+			}
+		}
+		return nil
+	}
+
+	return fmt.Errorf("%T: %w", v, ErrUnsupportedType)
+}
+
+// newNoopContext returns a context detached from the document, failed with
+// the error of the given context. The proxies on it are returned instead of
+// nil once the update has failed, so that the calls chained to them don't
+// panic, and the changes made through them are discarded.
+func newNoopContext(ctx *change.Context) *change.Context {
+	noop := change.NewContext(
+		ctx.ID(),
+		"",
+		json.NewRoot(json.NewObject(json.NewRHT(), time.InitialTicket)),
+	)
+	noop.SetError(ctx.Err())
+	return noop
+}
+
+func newNoopObjectProxy(ctx *change.Context) *ObjectProxy {
+	noop := newNoopContext(ctx)
+	return NewObjectProxy(noop, json.NewObject(json.NewRHT(), noop.IssueTimeTicket()))
+}
+
+func newNoopArrayProxy(ctx *change.Context) *ArrayProxy {
+	noop := newNoopContext(ctx)
+	return NewArrayProxy(noop, json.NewArray(json.NewRGATreeList(), noop.IssueTimeTicket()))
+}
+
+func newNoopTextProxy(ctx *change.Context) *TextProxy {
+	noop := newNoopContext(ctx)
+	return NewTextProxy(noop, json.NewText(json.NewRGATreeSplit(), noop.IssueTimeTicket()))
+}
+
 func toOriginal(elem json.Element) json.Element {
 	switch elem := elem.(type) {
 	case *ObjectProxy:
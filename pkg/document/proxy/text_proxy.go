@@ -40,13 +40,15 @@ func (p *TextProxy) Edit(from, to int, content string) *TextProxy {
 		panic("from should be less than or equal to to")
 	}
 	fromPos, toPos := p.Text.CreateRange(from, to)
-	log.Logger.Debugf(
-		"EDIT: f:%d->%s, t:%d->%s c:%s",
-		from, fromPos.AnnotatedString(), to, toPos.AnnotatedString(), content,
-	)
+	if log.IsDebugEnabledFor(log.Document) {
+		logger.Debugf(
+			"EDIT: f:%d->%s, t:%d->%s c:%s",
+			from, fromPos.AnnotatedString(), to, toPos.AnnotatedString(), content,
+		)
+	}
 
 	ticket := p.context.IssueTimeTicket()
-	_, maxCreationMapByActor := p.Text.Edit(
+	_, maxCreationMapByActor, _ := p.Text.Edit(
 		fromPos,
 		toPos,
 		nil,
@@ -0,0 +1,55 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package document
+
+// Stats is the statistics of the conflicts resolved in a document. It helps
+// to explain why an edit of a user was overwritten by another user.
+type Stats struct {
+	// ConcurrentSets is the number of values set concurrently with the values
+	// of other actors to the same key. Only one of them remains by
+	// last-writer-wins.
+	ConcurrentSets int64
+
+	// InterleavedTextEdits is the number of text edits interleaved with
+	// concurrent edits of other actors, e.g. text inserted into a range
+	// deleted concurrently, or a deletion of a range in which other text was
+	// inserted concurrently.
+	InterleavedTextEdits int64
+
+	// Tombstones is the number of the removed elements and text nodes still
+	// kept in the document.
+	//
+	// TODO: The tombstones are never purged yet, because neither documents
+	//  nor the agent collect garbage: the agent doesn't track the tickets that
+	//  all replicas have seen, below which the tombstones could be purged
+	//  safely. Once the garbage collection is introduced, it should return
+	//  the purged tombstones, the bytes freed and its duration, count them
+	//  here and report them to the stats of the agent, so that operators can
+	//  verify it keeps up. Until then, RemovedElementLen of json.Root tells
+	//  the removed elements pending the collection.
+	Tombstones int
+}
+
+// Stats returns the statistics of the conflicts resolved while applying the
+// remote changes to this document.
+func (d *Document) Stats() Stats {
+	return Stats{
+		ConcurrentSets:       d.replacedStats.ConcurrentSets + d.root.ConcurrentSets(),
+		InterleavedTextEdits: d.replacedStats.InterleavedTextEdits + d.root.InterleavedEdits(),
+		Tombstones:           d.root.Tombstones(),
+	}
+}
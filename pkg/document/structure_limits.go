@@ -0,0 +1,143 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package document
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/yorkie-team/yorkie/pkg/document/json"
+)
+
+var (
+	// ErrStructureLimitExceeded is returned when the changes make the
+	// structure of a document exceed its limits.
+	ErrStructureLimitExceeded = errors.New("structure limit exceeded")
+
+	// ErrInvalidStructureLimits is returned when the structure limits are
+	// invalid.
+	ErrInvalidStructureLimits = errors.New("invalid structure limits")
+)
+
+// StructureLimits is the limits of the structure of a document, which
+// protect the replicas from pathological or malicious shapes of documents.
+// The zero values of the fields disable the limits.
+//
+// NOTE: Checking the limits walks the whole document on each update, so it
+// costs more for larger documents.
+type StructureLimits struct {
+	// MaxDepth is the maximum depth of the elements. The members of the root
+	// object are at depth 1.
+	MaxDepth int
+
+	// MaxChildren is the maximum number of the elements in an object or an
+	// array, not counting the removed ones.
+	MaxChildren int
+
+	// MaxKeyLength is the maximum length of the keys of objects in bytes.
+	MaxKeyLength int
+}
+
+// StructureLimitError is returned when the changes make the structure of a
+// document exceed a limit. It wraps ErrStructureLimitExceeded.
+type StructureLimitError struct {
+	// Limit is the name of the limit, "depth", "children" or "key length".
+	Limit string
+	Value int
+	Max   int
+}
+
+// Error returns the description of the exceeded limit.
+func (e *StructureLimitError) Error() string {
+	return fmt.Sprintf("%s %d above %d: %s", e.Limit, e.Value, e.Max, ErrStructureLimitExceeded.Error())
+}
+
+// Unwrap returns ErrStructureLimitExceeded.
+func (e *StructureLimitError) Unwrap() error {
+	return ErrStructureLimitExceeded
+}
+
+// Validate validates these limits.
+func (l StructureLimits) Validate() error {
+	if l.MaxDepth < 0 || l.MaxChildren < 0 || l.MaxKeyLength < 0 {
+		return fmt.Errorf("negative limit: %w", ErrInvalidStructureLimits)
+	}
+	return nil
+}
+
+// IsZero returns whether all the limits are disabled.
+func (l StructureLimits) IsZero() bool {
+	return l == StructureLimits{}
+}
+
+// Check checks the structure of the given root object against these limits.
+func (l StructureLimits) Check(root *json.Object) error {
+	if l.IsZero() {
+		return nil
+	}
+	return l.check(root, 0)
+}
+
+func (l StructureLimits) check(elem json.Element, depth int) error {
+	if l.MaxDepth > 0 && depth > l.MaxDepth {
+		return &StructureLimitError{Limit: "depth", Value: depth, Max: l.MaxDepth}
+	}
+
+	switch elem := elem.(type) {
+	case *json.Object:
+		var err error
+		children := 0
+		elem.ForEach(func(k string, e json.Element) bool {
+			children++
+			if l.MaxKeyLength > 0 && len(k) > l.MaxKeyLength {
+				err = &StructureLimitError{Limit: "key length", Value: len(k), Max: l.MaxKeyLength}
+				return false
+			}
+			err = l.check(e, depth+1)
+			return err == nil
+		})
+		if err != nil {
+			return err
+		}
+		if l.MaxChildren > 0 && children > l.MaxChildren {
+			return &StructureLimitError{Limit: "children", Value: children, Max: l.MaxChildren}
+		}
+	case *json.Array:
+		if l.MaxChildren > 0 && elem.Len() > l.MaxChildren {
+			return &StructureLimitError{Limit: "children", Value: elem.Len(), Max: l.MaxChildren}
+		}
+		for _, e := range elem.Elements() {
+			if err := l.check(e, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// SetStructureLimits sets the limits of the structure of this document. The
+// local updates and the remote changes or snapshots exceeding them are
+// rejected with a StructureLimitError, keeping the document as it was.
+func (d *Document) SetStructureLimits(limits StructureLimits) error {
+	if err := limits.Validate(); err != nil {
+		return err
+	}
+
+	d.structureLimits = limits
+	return nil
+}
@@ -0,0 +1,123 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package document
+
+// SyncState represents the state of the synchronization of a document with
+// the agent.
+type SyncState int
+
+const (
+	// Synced means that all the local changes have been pushed to the agent.
+	Synced SyncState = iota
+
+	// PendingLocalChanges means that the document has local changes not
+	// pushed yet.
+	PendingLocalChanges
+
+	// Syncing means that the document is being synchronized with the agent.
+	Syncing
+
+	// SyncFailed means that the last synchronization failed, e.g. the client
+	// is offline.
+	SyncFailed
+)
+
+// String returns the name of this state.
+func (s SyncState) String() string {
+	switch s {
+	case Synced:
+		return "synced"
+	case PendingLocalChanges:
+		return "pending-local-changes"
+	case Syncing:
+		return "syncing"
+	case SyncFailed:
+		return "sync-failed"
+	default:
+		return "unknown"
+	}
+}
+
+// SyncStatus is the status of the synchronization of a document.
+type SyncStatus struct {
+	State SyncState
+
+	// Err is the cause of the failure if the state is SyncFailed.
+	Err error
+}
+
+// syncStatusSubscriber is a callback registered by SubscribeSyncStatus.
+type syncStatusSubscriber struct {
+	id int
+	fn func(SyncStatus)
+}
+
+// SyncStatus returns the status of the synchronization of this document.
+func (d *Document) SyncStatus() SyncStatus {
+	return d.syncStatus
+}
+
+// StartSync marks this document as being synchronized. It is called by the
+// client before it pushes the local changes.
+func (d *Document) StartSync() {
+	d.setSyncStatus(SyncStatus{State: Syncing})
+}
+
+// FinishSync marks the synchronization of this document as finished with the
+// given error. It is called by the client after it applies the response of
+// the agent.
+func (d *Document) FinishSync(err error) {
+	switch {
+	case err != nil:
+		d.setSyncStatus(SyncStatus{State: SyncFailed, Err: err})
+	case d.HasLocalChanges():
+		d.setSyncStatus(SyncStatus{State: PendingLocalChanges})
+	default:
+		d.setSyncStatus(SyncStatus{State: Synced})
+	}
+}
+
+// SubscribeSyncStatus registers the given callback, which is called after the
+// status of the synchronization is changed, and returns a function that
+// unregisters it. Failures are always notified even if the state is the same.
+func (d *Document) SubscribeSyncStatus(fn func(SyncStatus)) func() {
+	d.subscriberSeq++
+	id := d.subscriberSeq
+	d.syncStatusSubscribers = append(d.syncStatusSubscribers, syncStatusSubscriber{id: id, fn: fn})
+
+	return func() {
+		for i, s := range d.syncStatusSubscribers {
+			if s.id == id {
+				d.syncStatusSubscribers = append(d.syncStatusSubscribers[:i:i], d.syncStatusSubscribers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// setSyncStatus sets the status of the synchronization and notifies the
+// subscribers if it is changed.
+func (d *Document) setSyncStatus(status SyncStatus) {
+	if status.Err == nil && d.syncStatus.Err == nil && d.syncStatus.State == status.State {
+		return
+	}
+
+	d.syncStatus = status
+	for _, s := range d.syncStatusSubscribers {
+		s.fn(status)
+	}
+}
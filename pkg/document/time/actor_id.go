@@ -18,12 +18,21 @@ package time
 
 import (
 	"bytes"
+	"crypto/subtle"
 	"encoding/hex"
+	"errors"
+	"fmt"
 	"math"
 )
 
+// ErrInvalidActorID is returned when the given string is not an ActorID.
+var ErrInvalidActorID = errors.New("invalid actorID")
+
 const actorIDSize = 12
 
+// actorIDHexLength is the length of ActorIDs in hexadecimal.
+const actorIDHexLength = actorIDSize * 2
+
 var (
 	InitialActorID = &ActorID{}
 	MaxActorID     = &ActorID{
@@ -42,19 +51,37 @@ var (
 	}
 )
 
+// ActorID is the ID of an actor, e.g. a client editing documents. It is a
+// fixed-size array, so it can be compared with == and used as a map key
+// directly.
 type ActorID [actorIDSize]byte
 
+// ParseActorID returns the ActorID of the given string of exactly 24
+// hexadecimal digits. It returns ErrInvalidActorID if the string is not.
+func ParseActorID(str string) (ActorID, error) {
+	actorID := ActorID{}
+	if len(str) != actorIDHexLength {
+		return actorID, fmt.Errorf("%q: %w", str, ErrInvalidActorID)
+	}
+
+	if _, err := hex.Decode(actorID[:], []byte(str)); err != nil {
+		return ActorID{}, fmt.Errorf("%q: %w", str, ErrInvalidActorID)
+	}
+	return actorID, nil
+}
+
+// ActorIDFromHex returns the ActorID of the given hexadecimal string. It
+// returns nil if the string is empty, and panics if the string is not an
+// ActorID. ParseActorID should be used for strings from untrusted sources.
 func ActorIDFromHex(str string) *ActorID {
 	if str == "" {
 		return nil
 	}
 
-	actorID := ActorID{}
-	decoded, err := hex.DecodeString(str)
+	actorID, err := ParseActorID(str)
 	if err != nil {
 		panic("fail to decode hex")
 	}
-	copy(actorID[:], decoded[:actorIDSize])
 	return &actorID
 }
 
@@ -66,6 +93,13 @@ func (id *ActorID) String() string {
 	return hex.EncodeToString(id[:])
 }
 
+// Equal returns whether the ActorID is equal to the given one. It takes the
+// same time regardless of where they differ, so it can be used to compare
+// the IDs of clients from untrusted sources.
+func (id ActorID) Equal(other ActorID) bool {
+	return subtle.ConstantTimeCompare(id[:], other[:]) == 1
+}
+
 func (id *ActorID) Compare(other *ActorID) int {
 	if id == nil || other == nil {
 		panic("actorID cannot be null")
@@ -0,0 +1,70 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package time_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yorkie-team/yorkie/pkg/document/time"
+)
+
+func TestActorID(t *testing.T) {
+	t.Run("parse test", func(t *testing.T) {
+		id, err := time.ParseActorID("0123456789abcdef01234567")
+		assert.NoError(t, err)
+		assert.Equal(t, "0123456789abcdef01234567", id.String())
+		assert.Equal(t, *time.ActorIDFromHex("0123456789abcdef01234567"), id)
+
+		for _, str := range []string{
+			"",
+			"0123456789abcdef0123456",
+			"0123456789abcdef0123456789",
+			"0123456789abcdef0123456z",
+			"123e4567e89b12d3a456426614174000",
+		} {
+			_, err := time.ParseActorID(str)
+			assert.True(t, errors.Is(err, time.ErrInvalidActorID), str)
+		}
+
+		assert.Panics(t, func() {
+			time.ActorIDFromHex("0123")
+		})
+	})
+
+	t.Run("equal test", func(t *testing.T) {
+		id1, err := time.ParseActorID("0123456789abcdef01234567")
+		assert.NoError(t, err)
+		id2, err := time.ParseActorID("0123456789abcdef01234567")
+		assert.NoError(t, err)
+		id3, err := time.ParseActorID("0123456789abcdef01234568")
+		assert.NoError(t, err)
+
+		assert.True(t, id1.Equal(id2))
+		assert.False(t, id1.Equal(id3))
+		assert.True(t, id1 == id2)
+
+		counts := map[time.ActorID]int{}
+		counts[id1]++
+		counts[id2]++
+		counts[id3]++
+		assert.Equal(t, 2, counts[id1])
+		assert.Equal(t, 1, counts[id3])
+	})
+}
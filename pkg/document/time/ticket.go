@@ -17,8 +17,11 @@
 package time
 
 import (
+	"encoding/hex"
 	"fmt"
 	"math"
+	"strconv"
+	"sync/atomic"
 )
 
 var (
@@ -40,6 +43,10 @@ type Ticket struct {
 	lamport   uint64
 	delimiter uint32
 	actorID   *ActorID
+
+	// key memoizes the result of Key. It is stored atomically because tickets
+	// such as InitialTicket are shared between goroutines.
+	key atomic.Value
 }
 
 func NewTicket(
@@ -66,14 +73,27 @@ func (t *Ticket) AnnotatedString() string {
 	)
 }
 
+// Key returns the string of the ticket used as the key of maps, e.g.
+// "1:2:0123456789abcdef01234567". It is computed only once.
 func (t *Ticket) Key() string {
-	if t.actorID == nil {
-		return fmt.Sprintf("%d:%d:", t.lamport, t.delimiter)
+	if key, ok := t.key.Load().(string); ok {
+		return key
 	}
 
-	return fmt.Sprintf(
-		"%d:%d:%s", t.lamport, t.delimiter, t.actorID.String(),
-	)
+	buf := make([]byte, 0, 2*20+2+actorIDHexLength)
+	buf = strconv.AppendUint(buf, t.lamport, 10)
+	buf = append(buf, ':')
+	buf = strconv.AppendUint(buf, uint64(t.delimiter), 10)
+	buf = append(buf, ':')
+	if t.actorID != nil {
+		var actorHex [actorIDHexLength]byte
+		hex.Encode(actorHex[:], t.actorID[:])
+		buf = append(buf, actorHex[:]...)
+	}
+
+	key := string(buf)
+	t.key.Store(key)
+	return key
 }
 
 func (t *Ticket) Lamport() uint64 {
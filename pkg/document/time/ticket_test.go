@@ -0,0 +1,57 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package time_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yorkie-team/yorkie/pkg/document/time"
+)
+
+func TestTicket(t *testing.T) {
+	t.Run("key test", func(t *testing.T) {
+		actorID := time.ActorIDFromHex("0123456789abcdef01234567")
+		ticket := time.NewTicket(1, 2, actorID)
+		assert.Equal(t, "1:2:0123456789abcdef01234567", ticket.Key())
+		assert.Equal(t, "1:2:0123456789abcdef01234567", ticket.Key())
+
+		assert.Equal(t, "3:4:", time.NewTicket(3, 4, nil).Key())
+		assert.Equal(t, "1:2:ffffffffffffffffffffffff", ticket.SetActorID(time.MaxActorID).Key())
+	})
+
+	t.Run("concurrent key test", func(t *testing.T) {
+		ticket := time.NewTicket(1, 2, time.MaxActorID)
+
+		wg := sync.WaitGroup{}
+		keys := make([]string, 10)
+		for i := range keys {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				keys[i] = ticket.Key()
+			}(i)
+		}
+		wg.Wait()
+
+		for _, key := range keys {
+			assert.Equal(t, "1:2:ffffffffffffffffffffffff", key)
+		}
+	})
+}
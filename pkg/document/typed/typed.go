@@ -0,0 +1,376 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package typed provides typed accessors of the members of proxies. It is the
+// runtime of the bindings generated by `yorkie bindgen`, which wrap proxies
+// with the fields of application structs instead of string keys:
+//
+//	board := NewBoardProxy(root)
+//	board.Columns().At(0).Title().Set("x")
+package typed
+
+import (
+	time2 "time"
+
+	"github.com/yorkie-team/yorkie/pkg/document/json"
+	"github.com/yorkie-team/yorkie/pkg/document/proxy"
+)
+
+// Object returns the object of the given key, creating it if it doesn't
+// exist.
+func Object(obj *proxy.ObjectProxy, key string) *proxy.ObjectProxy {
+	if o := obj.GetObject(key); o != nil {
+		return o
+	}
+	return obj.SetNewObject(key)
+}
+
+// Array returns the array of the given key, creating it if it doesn't exist.
+func Array(obj *proxy.ObjectProxy, key string) *proxy.ArrayProxy {
+	if a := obj.GetArray(key); a != nil {
+		return a
+	}
+	return obj.SetNewArray(key)
+}
+
+// Text returns the text of the given key, creating it if it doesn't exist.
+func Text(obj *proxy.ObjectProxy, key string) *proxy.TextProxy {
+	if t := obj.GetText(key); t != nil {
+		return t
+	}
+	return obj.SetNewText(key)
+}
+
+// field is a primitive member of an object.
+type field struct {
+	obj *proxy.ObjectProxy
+	key string
+}
+
+// Has returns whether the field is set.
+func (f field) Has() bool {
+	return f.obj.Has(f.key)
+}
+
+// Delete deletes the field.
+func (f field) Delete() {
+	f.obj.Delete(f.key)
+}
+
+func (f field) value() interface{} {
+	if p, ok := f.obj.Get(f.key).(*json.Primitive); ok {
+		return p.Value()
+	}
+	return nil
+}
+
+// Bool is a boolean field of an object.
+type Bool struct{ field }
+
+// NewBool creates a new instance of Bool.
+func NewBool(obj *proxy.ObjectProxy, key string) Bool {
+	return Bool{field{obj: obj, key: key}}
+}
+
+// Get returns the value of the field, or false if it is not set.
+func (f Bool) Get() bool {
+	v, _ := f.value().(bool)
+	return v
+}
+
+// Set sets the value of the field.
+func (f Bool) Set(v bool) {
+	f.obj.SetBool(f.key, v)
+}
+
+// Integer is an integer field of an object.
+type Integer struct{ field }
+
+// NewInteger creates a new instance of Integer.
+func NewInteger(obj *proxy.ObjectProxy, key string) Integer {
+	return Integer{field{obj: obj, key: key}}
+}
+
+// Get returns the value of the field, or zero if it is not set.
+func (f Integer) Get() int {
+	v, _ := f.value().(int)
+	return v
+}
+
+// Set sets the value of the field.
+func (f Integer) Set(v int) {
+	f.obj.SetInteger(f.key, v)
+}
+
+// Long is a 64-bit integer field of an object.
+type Long struct{ field }
+
+// NewLong creates a new instance of Long.
+func NewLong(obj *proxy.ObjectProxy, key string) Long {
+	return Long{field{obj: obj, key: key}}
+}
+
+// Get returns the value of the field, or zero if it is not set.
+func (f Long) Get() int64 {
+	v, _ := f.value().(int64)
+	return v
+}
+
+// Set sets the value of the field.
+func (f Long) Set(v int64) {
+	f.obj.SetLong(f.key, v)
+}
+
+// Double is a floating point field of an object.
+type Double struct{ field }
+
+// NewDouble creates a new instance of Double.
+func NewDouble(obj *proxy.ObjectProxy, key string) Double {
+	return Double{field{obj: obj, key: key}}
+}
+
+// Get returns the value of the field, or zero if it is not set.
+func (f Double) Get() float64 {
+	v, _ := f.value().(float64)
+	return v
+}
+
+// Set sets the value of the field.
+func (f Double) Set(v float64) {
+	f.obj.SetDouble(f.key, v)
+}
+
+// String is a string field of an object.
+type String struct{ field }
+
+// NewString creates a new instance of String.
+func NewString(obj *proxy.ObjectProxy, key string) String {
+	return String{field{obj: obj, key: key}}
+}
+
+// Get returns the value of the field, or an empty string if it is not set.
+func (f String) Get() string {
+	v, _ := f.value().(string)
+	return v
+}
+
+// Set sets the value of the field.
+func (f String) Set(v string) {
+	f.obj.SetString(f.key, v)
+}
+
+// Bytes is a bytes field of an object.
+type Bytes struct{ field }
+
+// NewBytes creates a new instance of Bytes.
+func NewBytes(obj *proxy.ObjectProxy, key string) Bytes {
+	return Bytes{field{obj: obj, key: key}}
+}
+
+// Get returns the value of the field, or nil if it is not set.
+func (f Bytes) Get() []byte {
+	v, _ := f.value().([]byte)
+	return v
+}
+
+// Set sets the value of the field.
+func (f Bytes) Set(v []byte) {
+	f.obj.SetBytes(f.key, v)
+}
+
+// Date is a date field of an object.
+type Date struct{ field }
+
+// NewDate creates a new instance of Date.
+func NewDate(obj *proxy.ObjectProxy, key string) Date {
+	return Date{field{obj: obj, key: key}}
+}
+
+// Get returns the value of the field, or the zero time if it is not set.
+func (f Date) Get() time2.Time {
+	v, _ := f.value().(time2.Time)
+	return v
+}
+
+// Set sets the value of the field.
+func (f Date) Set(v time2.Time) {
+	f.obj.SetDate(f.key, v)
+}
+
+// list is an array of primitives.
+type list struct {
+	arr *proxy.ArrayProxy
+}
+
+// Len returns the length of the list.
+func (l list) Len() int {
+	return l.arr.Len()
+}
+
+// Delete deletes the element at the given index.
+func (l list) Delete(idx int) {
+	l.arr.Delete(idx)
+}
+
+// Array returns the underlying array proxy.
+func (l list) Array() *proxy.ArrayProxy {
+	return l.arr
+}
+
+func (l list) value(idx int) interface{} {
+	if idx < 0 || idx >= l.arr.Len() {
+		return nil
+	}
+	if p, ok := l.arr.Get(idx).(*json.Primitive); ok {
+		return p.Value()
+	}
+	return nil
+}
+
+// BoolList is a list of booleans.
+type BoolList struct{ list }
+
+// NewBoolList creates a new instance of BoolList.
+func NewBoolList(arr *proxy.ArrayProxy) BoolList {
+	return BoolList{list{arr: arr}}
+}
+
+// At returns the element at the given index, or false if it is out of range.
+func (l BoolList) At(idx int) bool {
+	v, _ := l.value(idx).(bool)
+	return v
+}
+
+// Add adds the given values at the end of the list.
+func (l BoolList) Add(values ...bool) {
+	l.arr.AddBool(values...)
+}
+
+// IntegerList is a list of integers.
+type IntegerList struct{ list }
+
+// NewIntegerList creates a new instance of IntegerList.
+func NewIntegerList(arr *proxy.ArrayProxy) IntegerList {
+	return IntegerList{list{arr: arr}}
+}
+
+// At returns the element at the given index, or zero if it is out of range.
+func (l IntegerList) At(idx int) int {
+	v, _ := l.value(idx).(int)
+	return v
+}
+
+// Add adds the given values at the end of the list.
+func (l IntegerList) Add(values ...int) {
+	l.arr.AddInteger(values...)
+}
+
+// LongList is a list of 64-bit integers.
+type LongList struct{ list }
+
+// NewLongList creates a new instance of LongList.
+func NewLongList(arr *proxy.ArrayProxy) LongList {
+	return LongList{list{arr: arr}}
+}
+
+// At returns the element at the given index, or zero if it is out of range.
+func (l LongList) At(idx int) int64 {
+	v, _ := l.value(idx).(int64)
+	return v
+}
+
+// Add adds the given values at the end of the list.
+func (l LongList) Add(values ...int64) {
+	l.arr.AddLong(values...)
+}
+
+// DoubleList is a list of floating point numbers.
+type DoubleList struct{ list }
+
+// NewDoubleList creates a new instance of DoubleList.
+func NewDoubleList(arr *proxy.ArrayProxy) DoubleList {
+	return DoubleList{list{arr: arr}}
+}
+
+// At returns the element at the given index, or zero if it is out of range.
+func (l DoubleList) At(idx int) float64 {
+	v, _ := l.value(idx).(float64)
+	return v
+}
+
+// Add adds the given values at the end of the list.
+func (l DoubleList) Add(values ...float64) {
+	l.arr.AddDouble(values...)
+}
+
+// StringList is a list of strings.
+type StringList struct{ list }
+
+// NewStringList creates a new instance of StringList.
+func NewStringList(arr *proxy.ArrayProxy) StringList {
+	return StringList{list{arr: arr}}
+}
+
+// At returns the element at the given index, or an empty string if it is out
+// of range.
+func (l StringList) At(idx int) string {
+	v, _ := l.value(idx).(string)
+	return v
+}
+
+// Add adds the given values at the end of the list.
+func (l StringList) Add(values ...string) {
+	l.arr.AddString(values...)
+}
+
+// BytesList is a list of bytes.
+type BytesList struct{ list }
+
+// NewBytesList creates a new instance of BytesList.
+func NewBytesList(arr *proxy.ArrayProxy) BytesList {
+	return BytesList{list{arr: arr}}
+}
+
+// At returns the element at the given index, or nil if it is out of range.
+func (l BytesList) At(idx int) []byte {
+	v, _ := l.value(idx).([]byte)
+	return v
+}
+
+// Add adds the given values at the end of the list.
+func (l BytesList) Add(values ...[]byte) {
+	l.arr.AddBytes(values...)
+}
+
+// DateList is a list of dates.
+type DateList struct{ list }
+
+// NewDateList creates a new instance of DateList.
+func NewDateList(arr *proxy.ArrayProxy) DateList {
+	return DateList{list{arr: arr}}
+}
+
+// At returns the element at the given index, or the zero time if it is out
+// of range.
+func (l DateList) At(idx int) time2.Time {
+	v, _ := l.value(idx).(time2.Time)
+	return v
+}
+
+// Add adds the given values at the end of the list.
+func (l DateList) Add(values ...time2.Time) {
+	l.arr.AddDate(values...)
+}
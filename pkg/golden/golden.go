@@ -0,0 +1,260 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package golden generates the canonical test vectors of the wire format,
+// which are consumed by the test suites of the SDKs to verify that they are
+// compatible with each other. Each vector has a change pack in Protobuf
+// format, the snapshot of the document after applying it and the expected
+// result of Marshal.
+//
+// The vectors are usually generated with:
+//
+//	go run github.com/yorkie-team/yorkie golden -o testdata
+package golden
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	time2 "time"
+
+	"github.com/yorkie-team/yorkie/api/converter"
+	api "github.com/yorkie-team/yorkie/api/yorkie/v1"
+	"github.com/yorkie-team/yorkie/pkg/document"
+	"github.com/yorkie-team/yorkie/pkg/document/change"
+	"github.com/yorkie-team/yorkie/pkg/document/proxy"
+	"github.com/yorkie-team/yorkie/pkg/document/time"
+)
+
+const (
+	collection = "golden"
+
+	actorA = "000000000000000000000001"
+	actorB = "000000000000000000000002"
+)
+
+// ErrMismatch is returned when the result of a vector doesn't match the
+// expected one.
+var ErrMismatch = errors.New("mismatch")
+
+// date is the fixed date used by the vectors.
+var date = time2.Date(2020, 9, 1, 0, 0, 0, 0, time2.UTC)
+
+// Vector is a test vector of the wire format.
+type Vector struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+
+	// ChangePack is the change pack in Protobuf format. It is base64-encoded
+	// in JSON.
+	ChangePack []byte `json:"changePack"`
+
+	// Snapshot is the root of the document after applying the change pack in
+	// Protobuf format. It is base64-encoded in JSON.
+	Snapshot []byte `json:"snapshot"`
+
+	// Marshal is the expected result of Marshal after applying the change
+	// pack or the snapshot.
+	Marshal string `json:"marshal"`
+}
+
+// scenario builds the change pack of a vector.
+type scenario struct {
+	name        string
+	description string
+	build       func() (*change.Pack, error)
+}
+
+var scenarios = []scenario{{
+	name:        "object",
+	description: "sets primitives of all the types and nested objects",
+	build: func() (*change.Pack, error) {
+		return update("object", actorA, func(root *proxy.ObjectProxy) error {
+			root.SetNewObject("primitives").
+				SetBool("bool", true).
+				SetInteger("integer", 2147483647).
+				SetLong("long", 9223372036854775807).
+				SetDouble("double", 1.79).
+				SetString("string", "yorkie").
+				SetBytes("bytes", []byte{65, 66}).
+				SetDate("date", date)
+			root.SetNewObject("nested").SetNewObject("object").SetString("key", "value")
+			root.SetString("removed", "value")
+			root.Delete("removed")
+			return nil
+		})
+	},
+}, {
+	name:        "array",
+	description: "adds, moves and removes elements of an array",
+	build: func() (*change.Pack, error) {
+		return update("array", actorA, func(root *proxy.ObjectProxy) error {
+			arr := root.SetNewArray("array").AddInteger(0, 1, 2, 3)
+			arr.AddNewArray().AddString("nested")
+			arr.Delete(1)
+			arr.MoveBefore(arr.Get(0).CreatedAt(), arr.Get(2).CreatedAt())
+			return nil
+		})
+	},
+}, {
+	name:        "text",
+	description: "edits a text including multibyte characters",
+	build: func() (*change.Pack, error) {
+		return update("text", actorA, func(root *proxy.ObjectProxy) error {
+			root.SetNewText("text").
+				Edit(0, 0, "ㅎ").
+				Edit(0, 1, "하").
+				Edit(0, 1, "한").
+				Edit(1, 1, "글").
+				Edit(2, 2, " yorkie").
+				Edit(3, 5, "")
+			return nil
+		})
+	},
+}, {
+	name:        "concurrent",
+	description: "applies the concurrent changes of two actors",
+	build: func() (*change.Pack, error) {
+		packA, err := update("concurrent", actorA, func(root *proxy.ObjectProxy) error {
+			root.SetString("key", "a")
+			root.SetNewText("text").Edit(0, 0, "aaa")
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		packB, err := update("concurrent", actorB, func(root *proxy.ObjectProxy) error {
+			root.SetString("key", "b")
+			root.SetNewArray("array").AddString("b")
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return change.NewPack(
+			packA.DocumentKey,
+			packA.Checkpoint,
+			append(packA.Changes, packB.Changes...),
+			nil,
+		), nil
+	},
+}}
+
+// Generate returns the test vectors.
+func Generate() ([]*Vector, error) {
+	var vectors []*Vector
+	for _, s := range scenarios {
+		pack, err := s.build()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", s.name, err)
+		}
+
+		vector, err := newVector(s, pack)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", s.name, err)
+		}
+		vectors = append(vectors, vector)
+	}
+
+	return vectors, nil
+}
+
+// Verify checks that the change pack and the snapshot of the vector are
+// decoded to the expected document.
+func (v *Vector) Verify() error {
+	pbPack := &api.ChangePack{}
+	if err := pbPack.Unmarshal(v.ChangePack); err != nil {
+		return err
+	}
+
+	pack, err := converter.FromChangePack(pbPack)
+	if err != nil {
+		return err
+	}
+
+	doc := document.New(collection, v.Name)
+	if err := doc.ApplyChangePack(pack); err != nil {
+		return err
+	}
+	if doc.Marshal() != v.Marshal {
+		return fmt.Errorf("change pack of %s: %s: %w", v.Name, doc.Marshal(), ErrMismatch)
+	}
+
+	root, err := converter.BytesToObject(v.Snapshot)
+	if err != nil {
+		return err
+	}
+	if root.Marshal() != v.Marshal {
+		return fmt.Errorf("snapshot of %s: %s: %w", v.Name, root.Marshal(), ErrMismatch)
+	}
+
+	return nil
+}
+
+// FileName returns the name of the golden file of the vector.
+func (v *Vector) FileName() string {
+	return v.Name + ".json"
+}
+
+// Encode returns the content of the golden file of the vector.
+func (v *Vector) Encode() ([]byte, error) {
+	bytes, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(bytes, '\n'), nil
+}
+
+// newVector returns the vector of the given change pack.
+func newVector(s scenario, pack *change.Pack) (*Vector, error) {
+	pbPack, err := converter.ToChangePack(pack).Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	doc := document.New(collection, s.name)
+	if err := doc.ApplyChangePack(pack); err != nil {
+		return nil, err
+	}
+
+	snapshot, err := converter.ObjectToBytes(doc.RootObject())
+	if err != nil {
+		return nil, err
+	}
+
+	return &Vector{
+		Name:        s.name,
+		Description: s.description,
+		ChangePack:  pbPack,
+		Snapshot:    snapshot,
+		Marshal:     doc.Marshal(),
+	}, nil
+}
+
+// update returns the change pack of the given updater made by the given
+// actor on a new document.
+func update(name, actor string, updater func(root *proxy.ObjectProxy) error) (*change.Pack, error) {
+	doc := document.New(collection, name)
+	doc.SetActor(time.ActorIDFromHex(actor))
+	if err := doc.Update(updater); err != nil {
+		return nil, err
+	}
+
+	return doc.CreateChangePack(), nil
+}
@@ -0,0 +1,56 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package golden_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yorkie-team/yorkie/pkg/golden"
+)
+
+func TestGolden(t *testing.T) {
+	t.Run("generate test", func(t *testing.T) {
+		vectors, err := golden.Generate()
+		assert.NoError(t, err)
+
+		for _, vector := range vectors {
+			expected, err := ioutil.ReadFile(filepath.Join("testdata", vector.FileName()))
+			assert.NoError(t, err)
+
+			actual, err := vector.Encode()
+			assert.NoError(t, err)
+			assert.Equal(t, string(expected), string(actual), "regenerate the vectors if the wire format is changed intentionally")
+		}
+	})
+
+	t.Run("verify test", func(t *testing.T) {
+		vectors, err := golden.Generate()
+		assert.NoError(t, err)
+		assert.NotEmpty(t, vectors)
+
+		for _, vector := range vectors {
+			assert.NoError(t, vector.Verify())
+		}
+
+		vectors[0].Marshal = "{}"
+		assert.Error(t, vectors[0].Verify())
+	})
+}
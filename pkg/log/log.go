@@ -14,17 +14,315 @@
  * limitations under the License.
  */
 
+// Package log provides the loggers of Yorkie. Each component, e.g. document
+// or rpc, has its own named logger whose level can be changed at runtime,
+// and all loggers write to the output configured with Configure.
 package log
 
 import (
+	"errors"
+	"fmt"
 	"os"
+	"sync"
+	"sync/atomic"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
+// The components of Yorkie having their own loggers.
+const (
+	Document = "document"
+	Client   = "client"
+	RPC      = "rpc"
+	DB       = "db"
+	Agent    = "agent"
+)
+
+// The formats of logs.
+const (
+	ConsoleFormat = "console"
+	JSONFormat    = "json"
+)
+
+var (
+	// ErrUnknownFormat is returned when the format of logs is unknown.
+	ErrUnknownFormat = errors.New("unknown log format")
+
+	// ErrUnknownLevel is returned when the level of logs is unknown.
+	ErrUnknownLevel = errors.New("unknown log level")
+)
+
+// Logger is the logger of no component.
+//
+// Deprecated: Use the logger of the component returned by Named instead.
 var Logger *zap.SugaredLogger
-var rawLogger *zap.Logger
+
+// Config is the configuration of the loggers.
+type Config struct {
+	// Level is the default level of the loggers: debug, info, warn or
+	// error. It is info if empty.
+	Level string `json:"Level"`
+
+	// Components is the levels of the loggers of the components which
+	// differ from the default level, e.g. {"db": "debug"}.
+	Components map[string]string `json:"Components"`
+
+	// Format is the format of logs: console or json. It is console if empty.
+	Format string `json:"Format"`
+
+	// Output is the path of the file to write logs to. Logs are written to
+	// the standard output if it is empty or "stdout", and to the standard
+	// error if it is "stderr".
+	Output string `json:"Output"`
+}
+
+// factory creates the loggers of the components and keeps their levels, so
+// that they can be changed at runtime.
+type factory struct {
+	mu      sync.RWMutex
+	level   zap.AtomicLevel
+	levels  map[string]zap.AtomicLevel
+	pinned  map[string]bool
+	loggers map[string]*zap.SugaredLogger
+
+	// sink is the zapcore.Core writing the entries of all loggers in the
+	// configured format and output.
+	sink atomic.Value
+}
+
+var defaultFactory = newFactory()
+
+func newFactory() *factory {
+	f := &factory{
+		level:   zap.NewAtomicLevelAt(zap.InfoLevel),
+		levels:  make(map[string]zap.AtomicLevel),
+		pinned:  make(map[string]bool),
+		loggers: make(map[string]*zap.SugaredLogger),
+	}
+	f.sink.Store(newSink(ConsoleFormat, zapcore.AddSync(os.Stdout)))
+	return f
+}
+
+// Configure applies the given configuration to all loggers, including the
+// loggers created before. The levels set by SetLevel are reset.
+func Configure(conf *Config) error {
+	return defaultFactory.configure(conf)
+}
+
+// Named returns the logger of the given component.
+func Named(component string) *zap.SugaredLogger {
+	return defaultFactory.named(component)
+}
+
+// SetLevel changes the level of the logger of the given component at runtime.
+// If the component is empty, it changes the default level, which is applied
+// to the components without their own levels.
+func SetLevel(component, level string) error {
+	l, err := parseLevel(level)
+	if err != nil {
+		return err
+	}
+
+	defaultFactory.setLevel(component, l)
+	return nil
+}
+
+// IsDebugEnabled returns whether the debug level is enabled or not. Callers
+// in hot paths should check this before building expensive debug messages.
+func IsDebugEnabled() bool {
+	return defaultFactory.level.Enabled(zap.DebugLevel)
+}
+
+// IsDebugEnabledFor returns whether the debug level is enabled for the logger
+// of the given component.
+func IsDebugEnabledFor(component string) bool {
+	return defaultFactory.levelOf(component).Enabled(zap.DebugLevel)
+}
+
+func (f *factory) configure(conf *Config) error {
+	var output zapcore.WriteSyncer
+	switch conf.Output {
+	case "", "stdout":
+		output = zapcore.AddSync(os.Stdout)
+	case "stderr":
+		output = zapcore.AddSync(os.Stderr)
+	default:
+		file, err := os.OpenFile(conf.Output, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			return err
+		}
+		output = zapcore.AddSync(file)
+	}
+
+	format := conf.Format
+	if format == "" {
+		format = ConsoleFormat
+	}
+	if format != ConsoleFormat && format != JSONFormat {
+		return fmt.Errorf("%s: %w", format, ErrUnknownFormat)
+	}
+
+	level := zap.InfoLevel
+	if conf.Level != "" {
+		l, err := parseLevel(conf.Level)
+		if err != nil {
+			return err
+		}
+		level = l
+	}
+	levels := make(map[string]zapcore.Level)
+	for component, componentLevel := range conf.Components {
+		l, err := parseLevel(componentLevel)
+		if err != nil {
+			return fmt.Errorf("%s: %w", component, err)
+		}
+		levels[component] = l
+	}
+
+	f.sink.Store(newSink(format, output))
+	f.mu.Lock()
+	f.pinned = make(map[string]bool)
+	f.mu.Unlock()
+	f.setLevel("", level)
+	for component, l := range levels {
+		f.setLevel(component, l)
+	}
+	return nil
+}
+
+func (f *factory) named(component string) *zap.SugaredLogger {
+	f.mu.RLock()
+	logger, ok := f.loggers[component]
+	f.mu.RUnlock()
+	if ok {
+		return logger
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if logger, ok := f.loggers[component]; ok {
+		return logger
+	}
+
+	raw := zap.New(
+		&componentCore{level: f.levelOfLocked(component), sink: &f.sink},
+		zap.AddStacktrace(zap.ErrorLevel),
+	)
+	if component != "" {
+		raw = raw.Named(component)
+	}
+	logger = raw.Sugar()
+	f.loggers[component] = logger
+	return logger
+}
+
+func (f *factory) setLevel(component string, level zapcore.Level) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if component == "" {
+		f.level.SetLevel(level)
+		for c, l := range f.levels {
+			if !f.pinned[c] {
+				l.SetLevel(level)
+			}
+		}
+		return
+	}
+
+	f.levelOfLocked(component).SetLevel(level)
+	f.pinned[component] = true
+}
+
+func (f *factory) levelOf(component string) zap.AtomicLevel {
+	if component == "" {
+		return f.level
+	}
+
+	f.mu.RLock()
+	l, ok := f.levels[component]
+	f.mu.RUnlock()
+	if ok {
+		return l
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.levelOfLocked(component)
+}
+
+func (f *factory) levelOfLocked(component string) zap.AtomicLevel {
+	if component == "" {
+		return f.level
+	}
+
+	l, ok := f.levels[component]
+	if !ok {
+		l = zap.NewAtomicLevelAt(f.level.Level())
+		f.levels[component] = l
+	}
+	return l
+}
+
+// componentCore is the zapcore.Core of the logger of a component. It filters
+// the entries by the level of the component and writes them to the current
+// sink of the factory.
+type componentCore struct {
+	level  zap.AtomicLevel
+	sink   *atomic.Value
+	fields []zapcore.Field
+}
+
+func (c *componentCore) Enabled(level zapcore.Level) bool {
+	return c.level.Enabled(level)
+}
+
+func (c *componentCore) With(fields []zapcore.Field) zapcore.Core {
+	return &componentCore{
+		level:  c.level,
+		sink:   c.sink,
+		fields: append(append([]zapcore.Field(nil), c.fields...), fields...),
+	}
+}
+
+func (c *componentCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c *componentCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	sink := c.sink.Load().(zapcore.Core)
+	if len(c.fields) > 0 {
+		sink = sink.With(c.fields)
+	}
+	return sink.Write(entry, fields)
+}
+
+func (c *componentCore) Sync() error {
+	return c.sink.Load().(zapcore.Core).Sync()
+}
+
+func newSink(format string, output zapcore.WriteSyncer) zapcore.Core {
+	var encoder zapcore.Encoder
+	if format == JSONFormat {
+		encoder = zapcore.NewJSONEncoder(encoderConfig())
+	} else {
+		encoder = zapcore.NewConsoleEncoder(humanEncoderConfig())
+	}
+
+	return zapcore.NewCore(encoder, output, zap.DebugLevel)
+}
+
+func parseLevel(level string) (zapcore.Level, error) {
+	var l zapcore.Level
+	if err := l.UnmarshalText([]byte(level)); err != nil {
+		return l, fmt.Errorf("%s: %w", level, ErrUnknownLevel)
+	}
+	return l, nil
+}
 
 func encoderConfig() zapcore.EncoderConfig {
 	return zapcore.EncoderConfig{
@@ -51,13 +349,5 @@ func humanEncoderConfig() zapcore.EncoderConfig {
 }
 
 func init() {
-	rawLogger = zap.New(zapcore.NewTee(
-		zapcore.NewCore(
-			zapcore.NewConsoleEncoder(humanEncoderConfig()),
-			zapcore.AddSync(os.Stdout),
-			zap.InfoLevel,
-		),
-	), zap.AddStacktrace(zap.ErrorLevel))
-
-	Logger = rawLogger.Sugar()
+	Logger = Named("")
 }
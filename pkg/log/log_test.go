@@ -0,0 +1,121 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package log_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yorkie-team/yorkie/pkg/log"
+)
+
+type entry struct {
+	Level   string `json:"L"`
+	Name    string `json:"N"`
+	Message string `json:"M"`
+}
+
+func readEntries(t *testing.T, path string) []entry {
+	bytes, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+
+	var entries []entry
+	for _, line := range strings.Split(strings.TrimSpace(string(bytes)), "\n") {
+		if line == "" {
+			continue
+		}
+		var e entry
+		assert.NoError(t, json.Unmarshal([]byte(line), &e))
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+func TestLog(t *testing.T) {
+	dir, err := ioutil.TempDir("", "log")
+	assert.NoError(t, err)
+	defer func() {
+		assert.NoError(t, log.Configure(&log.Config{}))
+		_ = os.RemoveAll(dir)
+	}()
+
+	t.Run("component level test", func(t *testing.T) {
+		path := filepath.Join(dir, "level.log")
+		assert.NoError(t, log.Configure(&log.Config{
+			Level:      "warn",
+			Components: map[string]string{log.DB: "debug"},
+			Format:     log.JSONFormat,
+			Output:     path,
+		}))
+
+		log.Named(log.DB).Debug("db debug")
+		log.Named(log.RPC).Info("rpc info")
+		log.Named(log.RPC).Warn("rpc warn")
+
+		entries := readEntries(t, path)
+		assert.Equal(t, []entry{
+			{Level: "debug", Name: log.DB, Message: "db debug"},
+			{Level: "warn", Name: log.RPC, Message: "rpc warn"},
+		}, entries)
+		assert.True(t, log.IsDebugEnabledFor(log.DB))
+		assert.False(t, log.IsDebugEnabledFor(log.RPC))
+		assert.False(t, log.IsDebugEnabled())
+	})
+
+	t.Run("runtime level change test", func(t *testing.T) {
+		path := filepath.Join(dir, "runtime.log")
+		assert.NoError(t, log.Configure(&log.Config{
+			Format: log.JSONFormat,
+			Output: path,
+		}))
+
+		logger := log.Named(log.Document)
+		logger.Debug("before")
+		assert.NoError(t, log.SetLevel(log.Document, "debug"))
+		logger.Debug("after")
+
+		// NOTE: Changing the default level does not affect the components
+		// having their own levels.
+		assert.NoError(t, log.SetLevel("", "error"))
+		logger.Debug("pinned")
+		log.Named(log.Client).Warn("default")
+
+		entries := readEntries(t, path)
+		assert.Equal(t, []entry{
+			{Level: "debug", Name: log.Document, Message: "after"},
+			{Level: "debug", Name: log.Document, Message: "pinned"},
+		}, entries)
+	})
+
+	t.Run("invalid config test", func(t *testing.T) {
+		err := log.Configure(&log.Config{Format: "xml"})
+		assert.True(t, errors.Is(err, log.ErrUnknownFormat))
+
+		err = log.Configure(&log.Config{Level: "verbose"})
+		assert.True(t, errors.Is(err, log.ErrUnknownLevel))
+
+		err = log.SetLevel(log.RPC, "verbose")
+		assert.True(t, errors.Is(err, log.ErrUnknownLevel))
+	})
+}
@@ -14,12 +14,22 @@
  * limitations under the License.
  */
 
+// Package pq provides a priority queue of values ordered by their Less.
+//
+// NOTE: The module supports Go 1.13, so the queue can't be parameterized by
+// the type of its values. Values are usually pointers, e.g. *json.RHTNode,
+// which are stored in interfaces without allocations, and callers confine the
+// type assertions to a single typed wrapper such as json.rhtEntry.
 package pq
 
 import "container/heap"
 
+// PriorityQueue is a queue of values in the order of their priorities. The
+// values should be comparable, e.g. pointers, since they are indexed to be
+// removed or updated.
 type PriorityQueue struct {
 	queue *internalQueue
+	items map[Value]*Item
 }
 
 func NewPriorityQueue() *PriorityQueue {
@@ -28,6 +38,7 @@ func NewPriorityQueue() *PriorityQueue {
 
 	return &PriorityQueue{
 		queue: pq,
+		items: make(map[Value]*Item),
 	}
 }
 
@@ -36,12 +47,51 @@ func (pq *PriorityQueue) Peek() Value {
 }
 
 func (pq *PriorityQueue) Pop() Value {
-	return heap.Pop(pq.queue).(*Item).value
+	item := heap.Pop(pq.queue).(*Item)
+	delete(pq.items, item.value)
+	return item.value
 }
 
+// Push pushes the given value. If the value is already in the queue, its
+// position is updated instead.
 func (pq *PriorityQueue) Push(value Value) {
+	if pq.Update(value) {
+		return
+	}
+
 	item := NewItem(value)
 	heap.Push(pq.queue, item)
+	pq.items[value] = item
+}
+
+// Remove removes the given value from the queue. It returns false if the
+// value is not in the queue.
+func (pq *PriorityQueue) Remove(value Value) bool {
+	item, ok := pq.items[value]
+	if !ok {
+		return false
+	}
+
+	heap.Remove(pq.queue, item.index)
+	delete(pq.items, value)
+	return true
+}
+
+// Update restores the order of the queue after the priority of the given
+// value has changed. It returns false if the value is not in the queue.
+func (pq *PriorityQueue) Update(value Value) bool {
+	item, ok := pq.items[value]
+	if !ok {
+		return false
+	}
+
+	heap.Fix(pq.queue, item.index)
+	return true
+}
+
+// Len returns the number of the values in the queue.
+func (pq *PriorityQueue) Len() int {
+	return pq.queue.Len()
 }
 
 func (pq *PriorityQueue) Values() []Value {
@@ -52,6 +102,16 @@ func (pq *PriorityQueue) Values() []Value {
 	return values
 }
 
+// ForEach calls the given function for each value without allocating a slice.
+// The iteration stops when the function returns false.
+func (pq *PriorityQueue) ForEach(fn func(value Value) bool) {
+	for _, item := range *pq.queue {
+		if !fn(item.value) {
+			return
+		}
+	}
+}
+
 type Value interface {
 	Less(other Value) bool
 }
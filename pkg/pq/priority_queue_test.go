@@ -0,0 +1,93 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pq_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yorkie-team/yorkie/pkg/pq"
+)
+
+type testValue struct {
+	priority int
+}
+
+func (v *testValue) Less(other pq.Value) bool {
+	return v.priority < other.(*testValue).priority
+}
+
+func popAll(queue *pq.PriorityQueue) []int {
+	var priorities []int
+	for queue.Len() > 0 {
+		priorities = append(priorities, queue.Pop().(*testValue).priority)
+	}
+	return priorities
+}
+
+func TestPriorityQueue(t *testing.T) {
+	t.Run("push/pop test", func(t *testing.T) {
+		queue := pq.NewPriorityQueue()
+		for _, priority := range []int{5, 1, 4, 2, 3} {
+			queue.Push(&testValue{priority: priority})
+		}
+
+		assert.Equal(t, 5, queue.Len())
+		assert.Equal(t, 1, queue.Peek().(*testValue).priority)
+		assert.Equal(t, []int{1, 2, 3, 4, 5}, popAll(queue))
+	})
+
+	t.Run("remove test", func(t *testing.T) {
+		queue := pq.NewPriorityQueue()
+		values := make(map[int]*testValue)
+		for _, priority := range []int{5, 1, 4, 2, 3} {
+			values[priority] = &testValue{priority: priority}
+			queue.Push(values[priority])
+		}
+
+		assert.True(t, queue.Remove(values[1]))
+		assert.True(t, queue.Remove(values[4]))
+		assert.False(t, queue.Remove(values[4]))
+		assert.False(t, queue.Remove(&testValue{priority: 2}))
+
+		assert.Equal(t, 3, queue.Len())
+		assert.Equal(t, []int{2, 3, 5}, popAll(queue))
+		assert.False(t, queue.Remove(values[2]))
+	})
+
+	t.Run("update test", func(t *testing.T) {
+		queue := pq.NewPriorityQueue()
+		values := make(map[int]*testValue)
+		for _, priority := range []int{5, 1, 4, 2, 3} {
+			values[priority] = &testValue{priority: priority}
+			queue.Push(values[priority])
+		}
+
+		values[5].priority = 0
+		assert.True(t, queue.Update(values[5]))
+		values[1].priority = 6
+		assert.True(t, queue.Update(values[1]))
+		assert.False(t, queue.Update(&testValue{priority: 7}))
+
+		values[3].priority = 10
+		queue.Push(values[3])
+		assert.Equal(t, 5, queue.Len())
+
+		assert.Equal(t, []int{0, 2, 4, 6, 10}, popAll(queue))
+	})
+}
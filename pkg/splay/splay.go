@@ -23,6 +23,8 @@ import (
 	"github.com/yorkie-team/yorkie/pkg/log"
 )
 
+var logger = log.Named(log.Document)
+
 // Value is an interface that represents the value of Node.
 // User can extend this interface to use custom value in Node.
 type Value interface {
@@ -186,7 +188,7 @@ func (t *Tree) Find(index int) (*Node, int) {
 	}
 
 	if index > node.value.Len() {
-		log.Logger.Fatalf(
+		logger.Fatalf(
 			"out of bound of text index: node.length %d, pos %d",
 			node.value.Len(),
 			index,
@@ -253,6 +255,12 @@ func (t *Tree) Delete(node *Node) {
 	} else {
 		t.root = rightTree.root
 	}
+
+	// NOTE: The links of the deleted node are cleared so that it can be
+	// inserted again.
+	node.left = nil
+	node.right = nil
+	node.parent = nil
 }
 
 func (t *Tree) rotateLeft(pivot *Node) {
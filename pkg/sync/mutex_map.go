@@ -64,3 +64,13 @@ func (m *MutexMap) Lock(key string) error {
 
 	return nil
 }
+
+// Len returns the number of keys currently locked.
+func (m *MutexMap) Len() int {
+	size := 0
+	m.mutexMap.Range(func(_, _ interface{}) bool {
+		size++
+		return true
+	})
+	return size
+}
@@ -37,6 +37,8 @@ const (
 	MongoPingTimeoutSec       = 5
 
 	SnapshotThreshold = 10
+	SnapshotChunkSize = 16 * 1024
+	AdminToken        = "test-admin-token"
 
 	Collection = "test-collection"
 )
@@ -56,10 +58,13 @@ func TestDBName() string {
 func TestYorkie() *yorkie.Yorkie {
 	y, err := yorkie.New(&yorkie.Config{
 		RPC: &rpc.Config{
-			Port: RPCPort,
+			Port:              RPCPort,
+			SnapshotChunkSize: SnapshotChunkSize,
+			AdminToken:        AdminToken,
 		},
 		Backend: &backend.Config{
 			SnapshotThreshold: SnapshotThreshold,
+			ChangeSummaries:   true,
 		},
 		Mongo: &mongo.Config{
 			ConnectionURI:        MongoConnectionURI,
@@ -0,0 +1,82 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package auth
+
+import (
+	"github.com/yorkie-team/yorkie/pkg/document/change"
+	"github.com/yorkie-team/yorkie/pkg/document/operation"
+)
+
+// Access is the level of access granted to a document.
+type Access string
+
+const (
+	// ReadWrite allows the holder to read and edit the document.
+	ReadWrite Access = "read-write"
+
+	// CommentOnly allows the holder to read the document and to push
+	// selections, but not to edit the contents of the document.
+	CommentOnly Access = "comment-only"
+
+	// ReadOnly allows the holder to read the document only.
+	ReadOnly Access = "read-only"
+)
+
+// Valid returns whether this access is a known access level or not.
+func (a Access) Valid() bool {
+	return a == ReadWrite || a == CommentOnly || a == ReadOnly
+}
+
+// Min returns the more restrictive access of this and the given access.
+func (a Access) Min(other Access) Access {
+	if a.rank() < other.rank() {
+		return a
+	}
+	return other
+}
+
+// Permits returns whether the changes of the given pack can be pushed with
+// this access.
+func (a Access) Permits(pack *change.Pack) bool {
+	switch a {
+	case ReadWrite:
+		return true
+	case ReadOnly:
+		return !pack.HasChanges()
+	case CommentOnly:
+		for _, c := range pack.Changes {
+			for _, op := range c.Operations() {
+				if _, ok := op.(*operation.Select); !ok {
+					return false
+				}
+			}
+		}
+		return true
+	}
+
+	return false
+}
+
+func (a Access) rank() int {
+	switch a {
+	case ReadWrite:
+		return 2
+	case CommentOnly:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,59 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package auth
+
+// ACL is the access control list of a document. The members are identified
+// by the subjects of their access tokens, e.g. user IDs, because the keys of
+// clients are chosen by the clients themselves and prove nothing.
+//
+// A document without ACL can be accessed by every client, while a document
+// with ACL can't be accessed by clients without a subject.
+type ACL struct {
+	Owner   string   `bson:"owner"`
+	Writers []string `bson:"writers"`
+	Readers []string `bson:"readers"`
+}
+
+// AccessOf returns the access granted to the given subject. The second return
+// value is false if the subject is not allowed to access the document at all.
+func (acl *ACL) AccessOf(subject string) (Access, bool) {
+	if acl == nil {
+		return ReadWrite, true
+	}
+
+	if subject == "" {
+		return "", false
+	}
+
+	if acl.Owner == subject {
+		return ReadWrite, true
+	}
+
+	for _, writer := range acl.Writers {
+		if writer == subject {
+			return ReadWrite, true
+		}
+	}
+
+	for _, reader := range acl.Readers {
+		if reader == subject {
+			return ReadOnly, true
+		}
+	}
+
+	return "", false
+}
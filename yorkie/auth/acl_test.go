@@ -0,0 +1,65 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package auth_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yorkie-team/yorkie/yorkie/auth"
+)
+
+func TestACL(t *testing.T) {
+	t.Run("access of members test", func(t *testing.T) {
+		acl := &auth.ACL{
+			Owner:   "owner",
+			Writers: []string{"writer"},
+			Readers: []string{"reader"},
+		}
+
+		access, ok := acl.AccessOf("owner")
+		assert.True(t, ok)
+		assert.Equal(t, auth.ReadWrite, access)
+
+		access, ok = acl.AccessOf("writer")
+		assert.True(t, ok)
+		assert.Equal(t, auth.ReadWrite, access)
+
+		access, ok = acl.AccessOf("reader")
+		assert.True(t, ok)
+		assert.Equal(t, auth.ReadOnly, access)
+
+		_, ok = acl.AccessOf("stranger")
+		assert.False(t, ok)
+
+		_, ok = (&auth.ACL{}).AccessOf("")
+		assert.False(t, ok)
+	})
+
+	t.Run("nil ACL test", func(t *testing.T) {
+		var acl *auth.ACL
+		access, ok := acl.AccessOf("anyone")
+		assert.True(t, ok)
+		assert.Equal(t, auth.ReadWrite, access)
+	})
+
+	t.Run("min access test", func(t *testing.T) {
+		assert.Equal(t, auth.ReadOnly, auth.ReadWrite.Min(auth.ReadOnly))
+		assert.Equal(t, auth.CommentOnly, auth.CommentOnly.Min(auth.ReadWrite))
+	})
+}
@@ -0,0 +1,170 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/yorkie-team/yorkie/pkg/document/key"
+)
+
+var (
+	// ErrInvalidToken is returned when the token is malformed or the
+	// signature does not match.
+	ErrInvalidToken = errors.New("invalid access token")
+
+	// ErrTokenExpired is returned when the token has expired.
+	ErrTokenExpired = errors.New("access token expired")
+
+	// ErrUnknownAccess is returned when the token has an unknown access level.
+	ErrUnknownAccess = errors.New("unknown access level")
+)
+
+// Claims is the payload of an access token.
+type Claims struct {
+	// Subject is the principal the token is issued to, such as a user ID.
+	// Unlike the keys of clients, which are chosen by the clients themselves,
+	// it is vouched for by the issuer, so the ACLs of documents are matched
+	// against it.
+	Subject string `json:"sub,omitempty"`
+
+	DocumentKey string `json:"doc"`
+	Access      Access `json:"acc"`
+	ExpiresAt   int64  `json:"exp"`
+
+	// Prefix is true if DocumentKey is the prefix of the keys of the
+	// documents which the token grants access to.
+	Prefix bool `json:"pfx,omitempty"`
+}
+
+// Expired returns whether the claims are expired at the given time.
+func (c *Claims) Expired(now time.Time) bool {
+	return c.ExpiresAt != 0 && now.Unix() >= c.ExpiresAt
+}
+
+// Covers returns whether the claims grant access to the given document.
+func (c *Claims) Covers(docKey *key.Key) bool {
+	if c.Prefix {
+		return strings.HasPrefix(docKey.BSONKey(), c.DocumentKey)
+	}
+	return c.DocumentKey == docKey.BSONKey()
+}
+
+// CoversPrefix returns whether the claims grant access to all documents under
+// the given prefix.
+func (c *Claims) CoversPrefix(prefix *key.Prefix) bool {
+	return c.Prefix && strings.HasPrefix(prefix.BSONKey(), c.DocumentKey)
+}
+
+// IssueToken creates a token that grants the given access to the document of
+// the given key to the given subject until expiresAt. The subject can be empty
+// for anonymous tokens. If expiresAt is zero, the token never expires.
+//
+// The token is signed with HMAC-SHA256 using the given secret, so any party
+// sharing the secret with the agent can issue tokens without contacting it.
+func IssueToken(
+	secret []byte,
+	subject string,
+	docKey *key.Key,
+	access Access,
+	expiresAt time.Time,
+) (string, error) {
+	return issue(secret, Claims{
+		Subject:     subject,
+		DocumentKey: docKey.BSONKey(),
+		Access:      access,
+	}, expiresAt)
+}
+
+// IssuePrefixToken creates a token that grants the given access to all
+// documents under the given prefix to the given subject until expiresAt, e.g.
+// all documents of a workspace. If expiresAt is zero, the token never expires.
+func IssuePrefixToken(
+	secret []byte,
+	subject string,
+	prefix *key.Prefix,
+	access Access,
+	expiresAt time.Time,
+) (string, error) {
+	return issue(secret, Claims{
+		Subject:     subject,
+		DocumentKey: prefix.BSONKey(),
+		Access:      access,
+		Prefix:      true,
+	}, expiresAt)
+}
+
+func issue(secret []byte, claims Claims, expiresAt time.Time) (string, error) {
+	if !claims.Access.Valid() {
+		return "", ErrUnknownAccess
+	}
+
+	if !expiresAt.IsZero() {
+		claims.ExpiresAt = expiresAt.Unix()
+	}
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + sign(secret, encoded), nil
+}
+
+// VerifyToken verifies the signature and the expiration of the given token
+// and returns its claims.
+func VerifyToken(secret []byte, token string, now time.Time) (*Claims, error) {
+	splits := strings.Split(token, ".")
+	if len(splits) != 2 {
+		return nil, ErrInvalidToken
+	}
+
+	if !hmac.Equal([]byte(sign(secret, splits[0])), []byte(splits[1])) {
+		return nil, ErrInvalidToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(splits[0])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	claims := &Claims{}
+	if err := json.Unmarshal(payload, claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+	if !claims.Access.Valid() {
+		return nil, ErrUnknownAccess
+	}
+	if claims.Expired(now) {
+		return nil, ErrTokenExpired
+	}
+
+	return claims, nil
+}
+
+func sign(secret []byte, payload string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
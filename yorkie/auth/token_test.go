@@ -0,0 +1,90 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package auth_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yorkie-team/yorkie/pkg/document/key"
+	"github.com/yorkie-team/yorkie/yorkie/auth"
+)
+
+func TestToken(t *testing.T) {
+	secret := []byte("secret")
+	docKey := &key.Key{Collection: "c1", Document: "d1"}
+
+	t.Run("issue/verify test", func(t *testing.T) {
+		token, err := auth.IssueToken(secret, "user-1", docKey, auth.ReadOnly, time.Now().Add(time.Hour))
+		assert.NoError(t, err)
+
+		claims, err := auth.VerifyToken(secret, token, time.Now())
+		assert.NoError(t, err)
+		assert.Equal(t, auth.ReadOnly, claims.Access)
+		assert.Equal(t, "user-1", claims.Subject)
+		assert.True(t, claims.Covers(docKey))
+		assert.False(t, claims.Covers(&key.Key{Collection: "c1", Document: "d2"}))
+	})
+
+	t.Run("invalid token test", func(t *testing.T) {
+		token, err := auth.IssueToken(secret, "user-1", docKey, auth.ReadWrite, time.Time{})
+		assert.NoError(t, err)
+
+		_, err = auth.VerifyToken([]byte("other"), token, time.Now())
+		assert.Equal(t, auth.ErrInvalidToken, err)
+
+		_, err = auth.VerifyToken(secret, "malformed", time.Now())
+		assert.Equal(t, auth.ErrInvalidToken, err)
+
+		_, err = auth.IssueToken(secret, "user-1", docKey, auth.Access("admin"), time.Time{})
+		assert.Equal(t, auth.ErrUnknownAccess, err)
+	})
+
+	t.Run("expired token test", func(t *testing.T) {
+		token, err := auth.IssueToken(secret, "user-1", docKey, auth.CommentOnly, time.Now().Add(-time.Second))
+		assert.NoError(t, err)
+
+		_, err = auth.VerifyToken(secret, token, time.Now())
+		assert.Equal(t, auth.ErrTokenExpired, err)
+	})
+	t.Run("prefix token test", func(t *testing.T) {
+		prefix, err := key.NewPrefix("c1", "workspace-42")
+		assert.NoError(t, err)
+		token, err := auth.IssuePrefixToken(secret, "", prefix, auth.ReadWrite, time.Time{})
+		assert.NoError(t, err)
+
+		claims, err := auth.VerifyToken(secret, token, time.Now())
+		assert.NoError(t, err)
+		assert.True(t, claims.Covers(&key.Key{Collection: "c1", Document: "workspace-42/d1"}))
+		assert.False(t, claims.Covers(&key.Key{Collection: "c1", Document: "workspace-420/d1"}))
+		assert.False(t, claims.Covers(docKey))
+
+		sub, err := key.NewPrefix("c1", "workspace-42/designs")
+		assert.NoError(t, err)
+		assert.True(t, claims.CoversPrefix(prefix))
+		assert.True(t, claims.CoversPrefix(sub))
+		assert.False(t, claims.CoversPrefix(&key.Prefix{Collection: "c1"}))
+
+		token, err = auth.IssueToken(secret, "user-1", docKey, auth.ReadWrite, time.Time{})
+		assert.NoError(t, err)
+		claims, err = auth.VerifyToken(secret, token, time.Now())
+		assert.NoError(t, err)
+		assert.False(t, claims.CoversPrefix(&key.Prefix{Collection: "c1"}))
+	})
+}
@@ -17,51 +17,307 @@
 package backend
 
 import (
+	"context"
+	"encoding/json"
+	time2 "time"
+
+	"github.com/google/uuid"
+
+	"github.com/yorkie-team/yorkie/api/converter"
 	"github.com/yorkie-team/yorkie/pkg/document/time"
+	"github.com/yorkie-team/yorkie/pkg/log"
 	"github.com/yorkie-team/yorkie/pkg/sync"
+	"github.com/yorkie-team/yorkie/yorkie/backend/database"
+	// The embedded driver is registered to be selectable by configuration.
+	_ "github.com/yorkie-team/yorkie/yorkie/backend/embedded"
+	"github.com/yorkie-team/yorkie/yorkie/backend/encryption"
 	"github.com/yorkie-team/yorkie/yorkie/backend/mongo"
+	"github.com/yorkie-team/yorkie/yorkie/broadcast"
+	"github.com/yorkie-team/yorkie/yorkie/election"
+	"github.com/yorkie-team/yorkie/yorkie/presence"
 	"github.com/yorkie-team/yorkie/yorkie/pubsub"
+	"github.com/yorkie-team/yorkie/yorkie/stats"
+	"github.com/yorkie-team/yorkie/yorkie/validation"
 )
 
+var logger = log.Named(log.Agent)
+
 type Config struct {
 	// SnapshotThreshold is the threshold that determines if changes should be
 	// sent with snapshot when the number of changes is greater than this value.
 	SnapshotThreshold uint64 `json:"SnapshotThreshold"`
+
+	// UseChangeStreams determines whether watch events are generated from
+	// MongoDB change streams instead of in-process pub/sub. It lets agents
+	// sharing a replica set see changes pushed through any of them.
+	UseChangeStreams bool `json:"UseChangeStreams"`
+
+	// ChangeSummaries determines whether the watch events of the pushed
+	// changes carry the paths changed by them, so that clients can skip
+	// pulling the changes of the paths they don't use. It costs loading the
+	// document on each push. The events from change streams and the events
+	// of encrypted documents don't carry them.
+	ChangeSummaries bool `json:"ChangeSummaries"`
+
+	// Database is the name of the database driver used as the storage, e.g.
+	// "mongo" or "embedded". The MongoDB driver is used if it is empty.
+	Database string `json:"Database"`
+
+	// DatabaseOptions is the driver specific configuration of the database. It
+	// is not used by the MongoDB driver, which is configured by the Mongo
+	// section of the configuration.
+	DatabaseOptions json.RawMessage `json:"DatabaseOptions"`
+
+	// Encryption is the configuration of the master keys encrypting the
+	// payloads of changes and snapshots at rest. Payloads are stored in
+	// plaintext if it is nil.
+	Encryption *encryption.Config `json:"Encryption"`
+
+	// LeaderElection determines whether the agents sharing the database elect
+	// a leader to run the singleton background jobs such as backups and
+	// housekeeping. The database should support leases.
+	LeaderElection bool `json:"LeaderElection"`
+
+	// LeaseDurationSec is the duration of the lease of the leader. If the
+	// leader stops, another agent takes over after the lease expires. It is
+	// 15 seconds if it is zero.
+	LeaseDurationSec time2.Duration `json:"LeaseDurationSec"`
+
+	// PresenceTTLSec is the period after which the peers that attached the
+	// documents but are neither watching nor synchronizing them are expired,
+	// e.g. the clients that crashed. It is 60 seconds if it is zero.
+	PresenceTTLSec time2.Duration `json:"PresenceTTLSec"`
+
+	// SharePresence determines whether the peers of the documents are shared
+	// with the other agents sharing the database, so that the peers connected
+	// to any agent are listed. The database should support sharing peers.
+	SharePresence bool `json:"SharePresence"`
+
+	// Profiles is the configuration of the documents of each collection,
+	// keyed by the name of the collection.
+	Profiles map[string]*Profile `json:"Profiles"`
+
+	// ValidationWebhook is the configuration of the webhook validating the
+	// changes pushed by clients. Changes are not validated by a webhook if it
+	// is nil.
+	ValidationWebhook *validation.WebhookConfig `json:"ValidationWebhook"`
+
+	// Validators are the validators of the changes pushed by clients, which
+	// run before the webhook in order.
+	Validators []validation.Validator `json:"-"`
+
+	// SkipUnappliableChanges determines whether the changes that can't be
+	// applied to their documents are skipped when snapshots are created.
+	// They are quarantined in either case, but snapshots are not created
+	// past them unless they are skipped.
+	SkipUnappliableChanges bool `json:"SkipUnappliableChanges"`
+
+	// DeadLetterWebhookURL is the URL that the changes that can't be applied
+	// are posted to as JSON when they are quarantined. No alert is sent if it
+	// is empty.
+	DeadLetterWebhookURL string `json:"DeadLetterWebhookURL"`
+
+	// DocumentLimits is the configuration of the limits of the documents.
+	// Documents are not limited if it is nil.
+	DocumentLimits *DocumentLimits `json:"DocumentLimits"`
 }
 
+// leaderLeaseName is the name of the lease of the leader running the
+// background jobs.
+const leaderLeaseName = "background-jobs"
+
 // Backend manages Yorkie's remote states such as data store, distributed lock
 // and etc.
 type Backend struct {
-	Config   *Config
-	Mongo    *mongo.Client
-	mutexMap *sync.MutexMap
-	pubSub   *pubsub.PubSub
+	Config     *Config
+	DB         database.Database
+	Stats      *stats.Stats
+	Presence   *presence.Registry
+	Broadcasts *broadcast.Tracker
+	mutexMap   *sync.MutexMap
+	pubSub     *pubsub.PubSub
+	encoders   *snapshotEncoders
+
+	// stopChangeStream stops watching the change stream if it is used.
+	stopChangeStream context.CancelFunc
+
+	// elector elects the leader if leader election is enabled.
+	elector *election.Elector
+
+	// presenceKeeper expires the peers and shares them with other agents.
+	presenceKeeper *presenceKeeper
+
+	// validators validate the changes pushed by clients.
+	validators []validation.Validator
+
+	// softLimited is the set of the documents above the soft limits.
+	softLimited *softLimitedDocs
 }
 
-// New creates a new instance of Backend.
+// New creates a new instance of Backend with the database of the configured
+// driver.
 func New(conf *Config, mongoConf *mongo.Config) (*Backend, error) {
-	client, err := mongo.NewClient(mongoConf)
+	var db database.Database
+	var err error
+	if conf.Database == "" || conf.Database == mongo.DriverName {
+		db, err = mongo.NewClient(mongoConf)
+	} else {
+		db, err = database.Open(conf.Database, conf.DatabaseOptions)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	return &Backend{
-		Config:   conf,
-		Mongo:    client,
-		mutexMap: sync.NewMutexMap(),
-		pubSub:   pubsub.NewPubSub(),
-	}, nil
+	be, err := NewWithDatabase(conf, db)
+	if err != nil {
+		if err := db.Close(); err != nil {
+			logger.Error(err)
+		}
+		return nil, err
+	}
+
+	return be, nil
+}
+
+// NewWithDatabase creates a new instance of Backend with the given database.
+func NewWithDatabase(conf *Config, db database.Database) (*Backend, error) {
+	if err := validateProfiles(conf); err != nil {
+		return nil, err
+	}
+
+	if conf.DocumentLimits != nil {
+		if err := conf.DocumentLimits.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	if conf.Encryption != nil {
+		if err := useEncryption(conf.Encryption, db); err != nil {
+			return nil, err
+		}
+	}
+
+	registry := presence.NewRegistry(conf.PresenceTTLSec * time2.Second)
+	be := &Backend{
+		Config:     conf,
+		DB:         db,
+		Stats:      stats.New(),
+		Presence:   registry,
+		Broadcasts: broadcast.NewTracker(broadcast.DefaultCapacity),
+		mutexMap:   sync.NewMutexMap(),
+		pubSub:     pubsub.NewPubSub(),
+		encoders:   newSnapshotEncoders(),
+		validators: conf.Validators,
+		softLimited: &softLimitedDocs{
+			keys: make(map[string]bool),
+		},
+	}
+	if conf.ValidationWebhook != nil {
+		be.validators = append(be.validators, validation.NewWebhook(conf.ValidationWebhook))
+	}
+
+	// NOTE: The ID identifies this agent among the agents sharing the
+	// database, e.g. as the holder of the lease of the leader.
+	agentID := uuid.New().String()
+	be.presenceKeeper = &presenceKeeper{
+		agentID:  agentID,
+		ttl:      registry.TTL(),
+		registry: registry,
+	}
+	if conf.SharePresence {
+		sharer, ok := db.(database.PeerSharer)
+		if !ok {
+			return nil, database.ErrPeerSharingNotSupported
+		}
+		be.presenceKeeper.sharer = sharer
+	}
+
+	if conf.UseChangeStreams {
+		if err := be.watchChangeStream(); err != nil {
+			return nil, err
+		}
+	}
+
+	if conf.LeaderElection {
+		leaser, ok := db.(database.Leaser)
+		if !ok {
+			be.stopWatchingChangeStream()
+			return nil, database.ErrLeasesNotSupported
+		}
+
+		be.elector = election.New(
+			leaser,
+			leaderLeaseName,
+			agentID,
+			conf.LeaseDurationSec*time2.Second,
+		)
+		be.elector.Start()
+	}
+
+	be.presenceKeeper.start()
+
+	return be, nil
+}
+
+// useEncryption makes the given database encrypt the payloads with the
+// master keys of the given configuration.
+func useEncryption(conf *encryption.Config, db database.Database) error {
+	encryptable, ok := db.(database.Encryptable)
+	if !ok {
+		return database.ErrEncryptionNotSupported
+	}
+
+	provider, err := encryption.NewLocalKeyProvider(conf)
+	if err != nil {
+		return err
+	}
+
+	envelope, err := encryption.NewEnvelope(provider)
+	if err != nil {
+		return err
+	}
+
+	encryptable.UseCipher(envelope)
+	return nil
 }
 
 // Close closes all resources of this instance.
 func (b *Backend) Close() error {
-	if err := b.Mongo.Close(); err != nil {
+	if b.elector != nil {
+		b.elector.Stop()
+	}
+
+	b.stopWatchingChangeStream()
+	b.presenceKeeper.stop()
+
+	if err := b.DB.Close(); err != nil {
 		return err
 	}
 
 	return nil
 }
 
+// IsLeader returns whether this agent should run the singleton background
+// jobs. It is always true if leader election is disabled.
+func (b *Backend) IsLeader() bool {
+	if b.elector == nil {
+		return true
+	}
+
+	return b.elector.IsLeader()
+}
+
+// Validators returns the validators of the changes pushed by clients.
+func (b *Backend) Validators() []validation.Validator {
+	return b.validators
+}
+
+func (b *Backend) stopWatchingChangeStream() {
+	if b.stopChangeStream != nil {
+		b.stopChangeStream()
+	}
+}
+
 func (b *Backend) Lock(k string) error {
 	return b.mutexMap.Lock(k)
 }
@@ -78,6 +334,79 @@ func (b *Backend) Unsubscribe(topics []string, subscription *pubsub.Subscription
 	b.pubSub.Unsubscribe(topics, subscription)
 }
 
+// SubscribeWithPrefixes subscribes to the given topics and all topics
+// starting with the given prefixes.
+func (b *Backend) SubscribeWithPrefixes(
+	actor *time.ActorID,
+	topics []string,
+	prefixes []string,
+) (*pubsub.Subscription, error) {
+	return b.pubSub.SubscribeWithPrefixes(actor, topics, prefixes)
+}
+
+// UnsubscribeWithPrefixes unsubscribes the given topics and prefixes.
+func (b *Backend) UnsubscribeWithPrefixes(
+	topics []string,
+	prefixes []string,
+	subscription *pubsub.Subscription,
+) {
+	b.pubSub.UnsubscribeWithPrefixes(topics, prefixes, subscription)
+}
+
+// Publish publishes the given event to the subscribers of the given topic.
+// Document change events are ignored when change streams are used, because
+// they are published from the change stream instead.
 func (b *Backend) Publish(actor *time.ActorID, topic string, event pubsub.Event) {
+	if b.Config.UseChangeStreams && event.Type == pubsub.DocumentChangeEvent {
+		return
+	}
+
 	b.pubSub.Publish(actor, topic, event)
 }
+
+// watchChangeStream starts publishing document change events from the change
+// stream of the changes stored by any agent.
+func (b *Backend) watchChangeStream() error {
+	watcher, ok := b.DB.(database.ChangeWatcher)
+	if !ok {
+		return database.ErrChangeStreamsNotSupported
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := watcher.WatchChanges(ctx, func(bsonDocKey string, actor *time.ActorID) {
+		b.pubSub.Publish(actor, bsonDocKey, pubsub.Event{
+			Type:  pubsub.DocumentChangeEvent,
+			Value: bsonDocKey,
+		})
+	}, func(err error) {
+		b.Stats.ChangeStreamErrors.Inc()
+	}); err != nil {
+		cancel()
+		return err
+	}
+
+	b.stopChangeStream = cancel
+	return nil
+}
+
+// Peers returns the peers of the given document connected to this agent and,
+// if presence sharing is enabled, to the other agents.
+func (b *Backend) Peers(ctx context.Context, bsonDocKey string) ([]*presence.Peer, error) {
+	return b.presenceKeeper.peers(ctx, bsonDocKey)
+}
+
+// SnapshotEncoder returns the snapshot encoder of the given document whose
+// last snapshot is of the given server sequence.
+func (b *Backend) SnapshotEncoder(docID string, serverSeq uint64) *converter.SnapshotEncoder {
+	return b.encoders.get(docID, serverSeq)
+}
+
+// Locks returns the number of locks currently held.
+func (b *Backend) Locks() int {
+	return b.mutexMap.Len()
+}
+
+// SubscriptionSize returns the number of topics and subscriptions.
+func (b *Backend) SubscriptionSize() (int, int) {
+	return b.pubSub.Size()
+}
@@ -0,0 +1,437 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package database defines the interface of the storage that the agent uses
+// to persist clients, documents, changes, snapshots and checkpoints.
+//
+// Storage backends implement Database and register themselves with Register,
+// usually in an init function of their package, so that the agent can open
+// them by name from its configuration:
+//
+//	func init() {
+//		database.Register("dynamodb", func(conf json.RawMessage) (database.Database, error) {
+//			...
+//		})
+//	}
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	time2 "time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/yorkie-team/yorkie/pkg/document/change"
+	"github.com/yorkie-team/yorkie/pkg/document/time"
+	"github.com/yorkie-team/yorkie/yorkie/auth"
+	"github.com/yorkie-team/yorkie/yorkie/labels"
+	"github.com/yorkie-team/yorkie/yorkie/stats"
+	"github.com/yorkie-team/yorkie/yorkie/types"
+)
+
+var (
+	// ErrClientNotFound is returned when the client could not be found.
+	ErrClientNotFound = errors.New("fail to find the client")
+
+	// ErrDocumentNotFound is returned when the document could not be found.
+	ErrDocumentNotFound = errors.New("fail to find the document")
+
+	// ErrStagedSnapshotNotFound is returned when the staged snapshot could not
+	// be found or has expired.
+	ErrStagedSnapshotNotFound = errors.New("fail to find the staged snapshot")
+
+	// ErrTagNotFound is returned when the tag could not be found.
+	ErrTagNotFound = errors.New("fail to find the tag")
+
+	// ErrTagAlreadyExists is returned when the document already has a tag of
+	// the same name.
+	ErrTagAlreadyExists = errors.New("the tag already exists")
+
+	// ErrConflict is returned when the document has been updated by another
+	// agent since it was read.
+	ErrConflict = errors.New("the document has been updated concurrently")
+
+	// ErrChangeStreamsNotSupported is returned when changes are watched on a
+	// database that can't notify them.
+	ErrChangeStreamsNotSupported = errors.New("the database does not support watching changes")
+
+	// ErrEncryptionNotSupported is returned when encryption is enabled on a
+	// database that can't encrypt the payloads.
+	ErrEncryptionNotSupported = errors.New("the database does not support encryption")
+
+	// ErrUnknownDriver is returned when the driver is not registered.
+	ErrUnknownDriver = errors.New("unknown database driver")
+
+	// ErrLeasesNotSupported is returned when leader election is enabled on a
+	// database that can't grant leases.
+	ErrLeasesNotSupported = errors.New("the database does not support leases")
+
+	// ErrPeerSharingNotSupported is returned when presence sharing is enabled
+	// on a database that can't share the peers of documents.
+	ErrPeerSharingNotSupported = errors.New("the database does not support sharing peers")
+)
+
+// Database is the storage of the agent.
+type Database interface {
+	// Close closes all resources of this database.
+	Close() error
+
+	// ActivateClient activates the client of the given key, creating it if it
+	// doesn't exist.
+	ActivateClient(ctx context.Context, key string) (*types.ClientInfo, error)
+
+	// DeactivateClient deactivates the client of the given ID.
+	DeactivateClient(ctx context.Context, clientID string) (*types.ClientInfo, error)
+
+	// FindClientInfoByID finds the client of the given ID. It returns
+	// ErrClientNotFound if the client doesn't exist.
+	FindClientInfoByID(ctx context.Context, clientID string) (*types.ClientInfo, error)
+
+	// FindClientInfoByKey finds the client of the given key. It returns
+	// ErrClientNotFound if the client doesn't exist.
+	FindClientInfoByKey(ctx context.Context, key string) (*types.ClientInfo, error)
+
+	// UpdateClientInfoAfterPushPull updates the checkpoint of the given
+	// document in the client after a push-pull.
+	UpdateClientInfoAfterPushPull(
+		ctx context.Context,
+		clientInfo *types.ClientInfo,
+		docInfo *types.DocInfo,
+	) error
+
+	// UpdateClientInfoSession updates the session token and the documents of
+	// the given client.
+	UpdateClientInfoSession(ctx context.Context, clientInfo *types.ClientInfo) error
+
+	// FindDocInfoByID finds the document of the given ID. It returns
+	// ErrDocumentNotFound if the document doesn't exist.
+	FindDocInfoByID(ctx context.Context, docID primitive.ObjectID) (*types.DocInfo, error)
+
+	// FindDocInfoByKey finds the document of the given key, creating it if it
+	// doesn't exist and createDocIfNotExist is true. It returns
+	// ErrDocumentNotFound if the document doesn't exist.
+	FindDocInfoByKey(
+		ctx context.Context,
+		clientInfo *types.ClientInfo,
+		bsonDocKey string,
+		createDocIfNotExist bool,
+	) (*types.DocInfo, error)
+
+	// FindDocInfos returns all documents.
+	FindDocInfos(ctx context.Context) ([]*types.DocInfo, error)
+
+	// FindDocInfosByKeyPrefix returns up to the given number of documents
+	// whose BSON keys start with the given prefix and whose labels match the
+	// given selector in the order of their keys.
+	FindDocInfosByKeyPrefix(
+		ctx context.Context,
+		bsonKeyPrefix string,
+		selector labels.Selector,
+		limit int,
+	) ([]*types.DocInfo, error)
+
+	// FindInactiveDocInfos returns up to the given number of documents that
+	// are not attached to any activated client and have not been updated
+	// since the given time. Documents are skipped if the given filter is not
+	// nil and returns false for them.
+	FindInactiveDocInfos(
+		ctx context.Context,
+		updatedBefore time2.Time,
+		filter func(docInfo *types.DocInfo) bool,
+		limit int,
+	) ([]*types.DocInfo, error)
+
+	// PurgeDocInfo deletes the given document with its changes and
+	// snapshots, and removes it from the clients. It returns false without
+	// deleting anything if the document has been updated since it was read
+	// or is attached to an activated client.
+	PurgeDocInfo(ctx context.Context, docInfo *types.DocInfo) (bool, error)
+
+	// UpdateDocInfo updates the server sequence and the encryption mode of
+	// the given document.
+	UpdateDocInfo(ctx context.Context, docInfo *types.DocInfo) error
+
+	// UpdateDocInfoAfterPushPull updates the given document like
+	// UpdateDocInfo after a push-pull, only if its server sequence in the
+	// database is still the given initial one. It returns ErrConflict
+	// otherwise, so that the changes of another agent are not overwritten.
+	UpdateDocInfoAfterPushPull(
+		ctx context.Context,
+		docInfo *types.DocInfo,
+		initialServerSeq uint64,
+	) error
+
+	// UpdateDocACL updates the ACL of the document of the given key.
+	UpdateDocACL(ctx context.Context, bsonDocKey string, acl *auth.ACL) (*types.DocInfo, error)
+
+	// UpdateDocLabels replaces the labels of the document of the given key.
+	UpdateDocLabels(ctx context.Context, bsonDocKey string, l labels.Labels) (*types.DocInfo, error)
+
+	// UpdateDocBranch updates the branch information of the given document.
+	UpdateDocBranch(ctx context.Context, docID primitive.ObjectID, branch *types.BranchInfo) error
+
+	// CreateChangeInfos stores the given changes of the given document.
+	CreateChangeInfos(ctx context.Context, docID primitive.ObjectID, changes []*change.Change) error
+
+	// FindChangeInfosBetweenServerSeqs returns the changes of the given
+	// document between the given server sequences, inclusive.
+	FindChangeInfosBetweenServerSeqs(
+		ctx context.Context,
+		docID primitive.ObjectID,
+		from uint64,
+		to uint64,
+	) ([]*change.Change, error)
+
+	// PruneChangeInfos deletes the changes of the given document up to the
+	// given server sequence and returns the number of deleted changes.
+	PruneChangeInfos(ctx context.Context, docID primitive.ObjectID, serverSeq uint64) (int64, error)
+
+	// CreateSnapshotInfo stores the given snapshot of the given document.
+	CreateSnapshotInfo(
+		ctx context.Context,
+		docID primitive.ObjectID,
+		serverSeq uint64,
+		snapshot []byte,
+	) error
+
+	// FindLastSnapshotInfo returns the last snapshot of the given document.
+	// It returns an empty snapshot if the document has no snapshot.
+	FindLastSnapshotInfo(ctx context.Context, docID primitive.ObjectID) (*types.SnapshotInfo, error)
+
+	// CreateTagInfo tags the given snapshot of the given document at the
+	// given server sequence with the given name. It returns
+	// ErrTagAlreadyExists if the document already has a tag of the name.
+	CreateTagInfo(
+		ctx context.Context,
+		docID primitive.ObjectID,
+		name string,
+		serverSeq uint64,
+		snapshot []byte,
+	) (*types.TagInfo, error)
+
+	// FindTagInfos returns the tags of the given document in the order of
+	// their server sequences. The snapshots of the tags are not returned.
+	FindTagInfos(ctx context.Context, docID primitive.ObjectID) ([]*types.TagInfo, error)
+
+	// FindTagInfoByName returns the tag of the given name of the given
+	// document with its snapshot.
+	FindTagInfoByName(ctx context.Context, docID primitive.ObjectID, name string) (*types.TagInfo, error)
+
+	// CreateDeadLetterInfo quarantines the given change of the given document
+	// which could not be applied for the given cause. It returns false if the
+	// change has already been quarantined.
+	CreateDeadLetterInfo(
+		ctx context.Context,
+		docID primitive.ObjectID,
+		c *change.Change,
+		cause string,
+	) (bool, error)
+
+	// FindDeadLetterInfos returns the quarantined changes of the given
+	// document in the order of their server sequences.
+	FindDeadLetterInfos(ctx context.Context, docID primitive.ObjectID) ([]*types.DeadLetterInfo, error)
+
+	// CountActivatedClients returns the number of activated clients.
+	CountActivatedClients(ctx context.Context) (int64, error)
+
+	// CountAttachedDocuments returns the number of documents attached to
+	// activated clients.
+	CountAttachedDocuments(ctx context.Context) (int64, error)
+
+	// WithTransaction runs the given function atomically if the database
+	// supports transactions. The context passed to the function must be used
+	// for the operations in it.
+	WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// ChangeWatcher is implemented by databases that can notify the changes
+// stored by other agents sharing the database.
+type ChangeWatcher interface {
+	// WatchChanges calls the given function for each stored change until the
+	// given context is done. If watching fails, onErr is called with the
+	// error and watching is resumed from the last change.
+	WatchChanges(
+		ctx context.Context,
+		fn func(bsonDocKey string, actor *time.ActorID),
+		onErr func(err error),
+	) error
+}
+
+// Leaser is implemented by databases that can grant leases to the agents
+// sharing the database, which are used to elect the leader of them.
+type Leaser interface {
+	// TryLease acquires the lease of the given name for the given holder, or
+	// renews it if the holder already has it, so that it expires after the
+	// given duration. It returns false if another holder has the lease and
+	// it has not expired.
+	TryLease(ctx context.Context, name, holder string, ttl time2.Duration) (bool, error)
+
+	// ReleaseLease releases the lease of the given name if the given holder
+	// has it, so that another holder can acquire it without waiting for it
+	// to expire.
+	ReleaseLease(ctx context.Context, name, holder string) error
+}
+
+// PeerSharer is implemented by databases that can share the peers of the
+// documents between the agents sharing the database.
+type PeerSharer interface {
+	// ReplacePeers replaces the peers registered by the given agent with the
+	// given ones.
+	ReplacePeers(ctx context.Context, agentID string, peers []*types.PeerInfo) error
+
+	// FindPeers returns the peers of the document of the given key registered
+	// by the agents other than the given one since the given time.
+	FindPeers(
+		ctx context.Context,
+		bsonDocKey string,
+		agentID string,
+		since time2.Time,
+	) ([]*types.PeerInfo, error)
+}
+
+// LatencyReporter is implemented by databases that record the latency of
+// their operations.
+type LatencyReporter interface {
+	// Latency returns the latency recorder of the operations.
+	Latency() *stats.Latency
+}
+
+// Cipher encrypts and decrypts the payloads of changes and snapshots.
+type Cipher interface {
+	// Encrypt encrypts the given payload.
+	Encrypt(plaintext []byte) ([]byte, error)
+
+	// Decrypt decrypts the given payload. Payloads that are not encrypted
+	// must be returned as they are.
+	Decrypt(payload []byte) ([]byte, error)
+}
+
+// Encryptable is implemented by databases that can encrypt the payloads of
+// changes and snapshots before storing them.
+type Encryptable interface {
+	// UseCipher sets the cipher of the payloads. It must be called before
+	// the database is used.
+	UseCipher(cipher Cipher)
+}
+
+// EncryptPayloads encrypts each of the given payloads with the given cipher.
+// The payloads are returned as they are if the cipher is nil.
+func EncryptPayloads(cipher Cipher, payloads [][]byte) ([][]byte, error) {
+	if cipher == nil {
+		return payloads, nil
+	}
+	return transformPayloads(payloads, cipher.Encrypt)
+}
+
+// DecryptPayloads decrypts each of the given payloads with the given cipher.
+// The payloads are returned as they are if the cipher is nil.
+func DecryptPayloads(cipher Cipher, payloads [][]byte) ([][]byte, error) {
+	if cipher == nil {
+		return payloads, nil
+	}
+	return transformPayloads(payloads, cipher.Decrypt)
+}
+
+func transformPayloads(
+	payloads [][]byte,
+	fn func(payload []byte) ([]byte, error),
+) ([][]byte, error) {
+	transformed := make([][]byte, 0, len(payloads))
+	for _, payload := range payloads {
+		p, err := fn(payload)
+		if err != nil {
+			return nil, err
+		}
+		transformed = append(transformed, p)
+	}
+
+	return transformed, nil
+}
+
+// Factory creates a Database from the given driver specific configuration.
+type Factory func(conf json.RawMessage) (Database, error)
+
+var (
+	factoriesMu sync.RWMutex
+	factories   = make(map[string]Factory)
+)
+
+// Register makes a database available by the given driver name. It panics if
+// Register is called twice with the same name or if the factory is nil.
+func Register(driver string, factory Factory) {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+
+	if factory == nil {
+		panic("database: Register factory is nil")
+	}
+	if _, dup := factories[driver]; dup {
+		panic("database: Register called twice for driver " + driver)
+	}
+	factories[driver] = factory
+}
+
+// Open opens a database of the given driver with the given configuration.
+func Open(driver string, conf json.RawMessage) (Database, error) {
+	factoriesMu.RLock()
+	factory, ok := factories[driver]
+	factoriesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", driver, ErrUnknownDriver)
+	}
+
+	return factory(conf)
+}
+
+// Drivers returns a sorted list of the names of the registered drivers.
+func Drivers() []string {
+	factoriesMu.RLock()
+	defer factoriesMu.RUnlock()
+
+	var drivers []string
+	for driver := range factories {
+		drivers = append(drivers, driver)
+	}
+	sort.Strings(drivers)
+	return drivers
+}
+
+// SnapshotStager is implemented by databases that can keep the snapshots
+// omitted from change packs, so that the clients can fetch them from any of
+// the agents sharing the database.
+type SnapshotStager interface {
+	// StageSnapshot keeps the given snapshot for the given client until the
+	// given time, and returns its ID.
+	StageSnapshot(
+		ctx context.Context,
+		clientID string,
+		snapshot []byte,
+		expiresAt time2.Time,
+	) (string, error)
+
+	// FindStagedSnapshot returns the snapshot of the given ID kept for the
+	// given client. It returns ErrStagedSnapshotNotFound if the snapshot
+	// doesn't exist or has expired.
+	FindStagedSnapshot(ctx context.Context, clientID string, id string) ([]byte, error)
+
+	// DeleteStagedSnapshot deletes the snapshot of the given ID.
+	DeleteStagedSnapshot(ctx context.Context, id string) error
+}
@@ -0,0 +1,55 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yorkie-team/yorkie/yorkie/backend/database"
+)
+
+var errDummy = errors.New("dummy error")
+
+func TestRegistration(t *testing.T) {
+	t.Run("register and open test", func(t *testing.T) {
+		var received json.RawMessage
+		database.Register("dummy", func(conf json.RawMessage) (database.Database, error) {
+			received = conf
+			return nil, errDummy
+		})
+		assert.Contains(t, database.Drivers(), "dummy")
+
+		_, err := database.Open("dummy", json.RawMessage(`{"k":"v"}`))
+		assert.Equal(t, errDummy, err)
+		assert.Equal(t, `{"k":"v"}`, string(received))
+
+		assert.Panics(t, func() {
+			database.Register("dummy", func(conf json.RawMessage) (database.Database, error) {
+				return nil, nil
+			})
+		})
+	})
+
+	t.Run("unknown driver test", func(t *testing.T) {
+		_, err := database.Open("unknown", nil)
+		assert.True(t, errors.Is(err, database.ErrUnknownDriver))
+	})
+}
@@ -0,0 +1,1105 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package embedded provides a database stored in a single local file, so
+// that the agent can run as a self-contained binary without an external
+// database, e.g. for desktop applications and small installations.
+//
+// The whole state is kept in memory and every write is appended to a journal
+// file, which is replayed and compacted when the database is opened. It is
+// meant for a single agent; the file must not be shared by several agents.
+//
+// SQLite and Bolt are not used: SQLite requires cgo, which gets in the way of
+// cross-compiling a self-contained binary, and both would only serve as a
+// durable log here because queries are answered from the in-memory indexes.
+package embedded
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/yorkie-team/yorkie/pkg/document/change"
+	"github.com/yorkie-team/yorkie/yorkie/auth"
+	"github.com/yorkie-team/yorkie/yorkie/backend/database"
+	"github.com/yorkie-team/yorkie/yorkie/labels"
+	"github.com/yorkie-team/yorkie/yorkie/types"
+)
+
+// DriverName is the name of the embedded driver registered to the database
+// package.
+const DriverName = "embedded"
+
+// ErrPathRequired is returned when the path of the database file is empty.
+var ErrPathRequired = errors.New("the path of the database file is required")
+
+var (
+	_ database.Database    = (*DB)(nil)
+	_ database.Encryptable = (*DB)(nil)
+)
+
+func init() {
+	database.Register(DriverName, func(raw json.RawMessage) (database.Database, error) {
+		conf := &Config{}
+		if len(raw) > 0 {
+			if err := json.Unmarshal(raw, conf); err != nil {
+				logger.Error(err)
+				return nil, err
+			}
+		}
+
+		return Open(conf)
+	})
+}
+
+// Config is the configuration for opening a DB instance.
+type Config struct {
+	// Path is the path of the database file.
+	Path string `json:"Path"`
+
+	// NoSync skips flushing the file to the disk after each write. It is
+	// faster, but the last writes can be lost on a power failure.
+	NoSync bool `json:"NoSync"`
+}
+
+type txKey struct{}
+
+// tx collects the records written in a transaction.
+type tx struct {
+	records []*record
+}
+
+// DB is a database stored in a single local file.
+type DB struct {
+	// txMu serializes transactions.
+	txMu sync.Mutex
+
+	mu      sync.RWMutex
+	journal *journal
+
+	// cipher encrypts the payloads of changes and snapshots if it is set.
+	cipher database.Cipher
+
+	clients       map[primitive.ObjectID]*types.ClientInfo
+	clientIDByKey map[string]primitive.ObjectID
+	docs          map[primitive.ObjectID]*types.DocInfo
+	docIDByKey    map[string]primitive.ObjectID
+	changes       map[primitive.ObjectID][]*types.ChangeInfo
+	snapshots     map[primitive.ObjectID]*types.SnapshotInfo
+	tags          map[primitive.ObjectID][]*types.TagInfo
+	deadLetters   map[primitive.ObjectID][]*types.DeadLetterInfo
+}
+
+// Open opens the database of the given configuration, creating the file if it
+// doesn't exist.
+func Open(conf *Config) (*DB, error) {
+	if conf.Path == "" {
+		return nil, ErrPathRequired
+	}
+
+	db := &DB{
+		clients:       make(map[primitive.ObjectID]*types.ClientInfo),
+		clientIDByKey: make(map[string]primitive.ObjectID),
+		docs:          make(map[primitive.ObjectID]*types.DocInfo),
+		docIDByKey:    make(map[string]primitive.ObjectID),
+		changes:       make(map[primitive.ObjectID][]*types.ChangeInfo),
+		snapshots:     make(map[primitive.ObjectID]*types.SnapshotInfo),
+		tags:          make(map[primitive.ObjectID][]*types.TagInfo),
+		deadLetters:   make(map[primitive.ObjectID][]*types.DeadLetterInfo),
+	}
+
+	j, err := openJournal(conf.Path, !conf.NoSync, db.apply)
+	if err != nil {
+		return nil, err
+	}
+	if err := j.close(); err != nil {
+		return nil, err
+	}
+
+	// NOTE: The journal is compacted on every open, so it only grows with
+	// the writes done since the agent started.
+	if db.journal, err = rewriteJournal(conf.Path, !conf.NoSync, db.records()); err != nil {
+		return nil, err
+	}
+
+	logger.Infof("opened, path: %s", conf.Path)
+
+	return db, nil
+}
+
+// Close closes the file of this database.
+func (db *DB) Close() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	return db.journal.close()
+}
+
+// UseCipher sets the cipher of the payloads of changes and snapshots.
+func (db *DB) UseCipher(cipher database.Cipher) {
+	db.cipher = cipher
+}
+
+// ActivateClient activates the client of the given key, creating it if it
+// doesn't exist.
+func (db *DB) ActivateClient(ctx context.Context, key string) (*types.ClientInfo, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	now := time.Now()
+	var clientInfo *types.ClientInfo
+	if id, ok := db.clientIDByKey[key]; ok {
+		clientInfo = copyClientInfo(db.clients[id])
+	} else {
+		clientInfo = &types.ClientInfo{
+			ID:        primitive.NewObjectID(),
+			Key:       key,
+			CreatedAt: now,
+		}
+	}
+	clientInfo.Status = types.ClientActivated
+	clientInfo.UpdatedAt = now
+
+	if err := db.write(ctx, &record{Type: recordClient, Client: clientInfo}); err != nil {
+		return nil, err
+	}
+
+	return copyClientInfo(clientInfo), nil
+}
+
+// DeactivateClient deactivates the client of the given ID.
+func (db *DB) DeactivateClient(ctx context.Context, clientID string) (*types.ClientInfo, error) {
+	id, err := primitive.ObjectIDFromHex(clientID)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	stored, ok := db.clients[id]
+	if !ok {
+		return nil, database.ErrClientNotFound
+	}
+
+	clientInfo := copyClientInfo(stored)
+	clientInfo.Status = types.ClientDeactivated
+	clientInfo.UpdatedAt = time.Now()
+	if err := db.write(ctx, &record{Type: recordClient, Client: clientInfo}); err != nil {
+		return nil, err
+	}
+
+	return copyClientInfo(clientInfo), nil
+}
+
+// FindClientInfoByID finds the client of the given ID.
+func (db *DB) FindClientInfoByID(ctx context.Context, clientID string) (*types.ClientInfo, error) {
+	id, err := primitive.ObjectIDFromHex(clientID)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	clientInfo, ok := db.clients[id]
+	if !ok {
+		return nil, database.ErrClientNotFound
+	}
+
+	return copyClientInfo(clientInfo), nil
+}
+
+// FindClientInfoByKey finds the client of the given key.
+func (db *DB) FindClientInfoByKey(ctx context.Context, key string) (*types.ClientInfo, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	id, ok := db.clientIDByKey[key]
+	if !ok {
+		return nil, database.ErrClientNotFound
+	}
+
+	return copyClientInfo(db.clients[id]), nil
+}
+
+// UpdateClientInfoAfterPushPull updates the checkpoint of the given document
+// in the client after a push-pull.
+func (db *DB) UpdateClientInfoAfterPushPull(
+	ctx context.Context,
+	clientInfo *types.ClientInfo,
+	docInfo *types.DocInfo,
+) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	id, ok := db.clientIDByKey[clientInfo.Key]
+	if !ok {
+		return database.ErrClientNotFound
+	}
+
+	stored := copyClientInfo(db.clients[id])
+	if stored.Documents == nil {
+		stored.Documents = make(map[string]*types.ClientDocInfo)
+	}
+	if docClientInfo := clientInfo.Documents[docInfo.ID.Hex()]; docClientInfo != nil {
+		info := *docClientInfo
+		stored.Documents[docInfo.ID.Hex()] = &info
+	} else {
+		delete(stored.Documents, docInfo.ID.Hex())
+	}
+	stored.UpdatedAt = clientInfo.UpdatedAt
+
+	return db.write(ctx, &record{Type: recordClient, Client: stored})
+}
+
+// UpdateClientInfoSession updates the session token and the documents of the
+// given client.
+func (db *DB) UpdateClientInfoSession(ctx context.Context, clientInfo *types.ClientInfo) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	stored, ok := db.clients[clientInfo.ID]
+	if !ok {
+		return database.ErrClientNotFound
+	}
+
+	updated := copyClientInfo(stored)
+	updated.SessionToken = clientInfo.SessionToken
+	updated.Documents = copyClientInfo(clientInfo).Documents
+	updated.UpdatedAt = clientInfo.UpdatedAt
+	return db.write(ctx, &record{Type: recordClient, Client: updated})
+}
+
+// FindDocInfoByID finds the document of the given ID.
+func (db *DB) FindDocInfoByID(ctx context.Context, docID primitive.ObjectID) (*types.DocInfo, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	docInfo, ok := db.docs[docID]
+	if !ok {
+		return nil, database.ErrDocumentNotFound
+	}
+
+	return copyDocInfo(docInfo), nil
+}
+
+// FindDocInfoByKey finds the document of the given key, creating it if it
+// doesn't exist and createDocIfNotExist is true.
+func (db *DB) FindDocInfoByKey(
+	ctx context.Context,
+	clientInfo *types.ClientInfo,
+	bsonDocKey string,
+	createDocIfNotExist bool,
+) (*types.DocInfo, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	now := time.Now()
+	var docInfo *types.DocInfo
+	if id, ok := db.docIDByKey[bsonDocKey]; ok {
+		docInfo = copyDocInfo(db.docs[id])
+	} else if createDocIfNotExist {
+		docInfo = &types.DocInfo{
+			ID:        primitive.NewObjectID(),
+			Key:       bsonDocKey,
+			Owner:     clientInfo.ID,
+			CreatedAt: now,
+		}
+	} else {
+		return nil, database.ErrDocumentNotFound
+	}
+	docInfo.AccessedAt = now
+
+	if err := db.write(ctx, &record{Type: recordDoc, Doc: docInfo}); err != nil {
+		return nil, err
+	}
+
+	return copyDocInfo(docInfo), nil
+}
+
+// FindDocInfos returns all documents.
+func (db *DB) FindDocInfos(ctx context.Context) ([]*types.DocInfo, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	var docInfos []*types.DocInfo
+	for _, docInfo := range db.docs {
+		docInfos = append(docInfos, copyDocInfo(docInfo))
+	}
+
+	return docInfos, nil
+}
+
+// FindDocInfosByKeyPrefix returns up to the given number of documents whose
+// BSON keys start with the given prefix and whose labels match the given
+// selector in the order of their keys.
+func (db *DB) FindDocInfosByKeyPrefix(
+	ctx context.Context,
+	bsonKeyPrefix string,
+	selector labels.Selector,
+	limit int,
+) ([]*types.DocInfo, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	var keys []string
+	for bsonKey, id := range db.docIDByKey {
+		if strings.HasPrefix(bsonKey, bsonKeyPrefix) && selector.Matches(db.docs[id].Labels) {
+			keys = append(keys, bsonKey)
+		}
+	}
+	sort.Strings(keys)
+	if len(keys) > limit {
+		keys = keys[:limit]
+	}
+
+	var docInfos []*types.DocInfo
+	for _, bsonKey := range keys {
+		docInfos = append(docInfos, copyDocInfo(db.docs[db.docIDByKey[bsonKey]]))
+	}
+
+	return docInfos, nil
+}
+
+// FindInactiveDocInfos returns up to the given number of documents that are
+// not attached to any activated client and have not been updated since the
+// given time. Documents are skipped if the given filter is not nil and
+// returns false for them.
+func (db *DB) FindInactiveDocInfos(
+	ctx context.Context,
+	updatedBefore time.Time,
+	filter func(docInfo *types.DocInfo) bool,
+	limit int,
+) ([]*types.DocInfo, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	var docInfos []*types.DocInfo
+	for _, docInfo := range db.docs {
+		if len(docInfos) >= limit {
+			break
+		}
+
+		updatedAt := docInfo.UpdatedAt
+		if updatedAt.IsZero() {
+			updatedAt = docInfo.CreatedAt
+		}
+		if !updatedAt.Before(updatedBefore) || db.isAttached(docInfo.ID) {
+			continue
+		}
+		if filter != nil && !filter(docInfo) {
+			continue
+		}
+
+		docInfos = append(docInfos, copyDocInfo(docInfo))
+	}
+
+	return docInfos, nil
+}
+
+// PurgeDocInfo deletes the given document with its changes and snapshots,
+// and removes it from the clients. It returns false without deleting anything
+// if the document has been updated since it was read or is attached to an
+// activated client.
+func (db *DB) PurgeDocInfo(ctx context.Context, docInfo *types.DocInfo) (bool, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	stored, ok := db.docs[docInfo.ID]
+	if !ok || stored.ServerSeq != docInfo.ServerSeq || db.isAttached(docInfo.ID) {
+		return false, nil
+	}
+
+	if err := db.write(ctx, &record{Type: recordPurge, DocID: docInfo.ID}); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// isAttached returns whether the given document is attached to an activated
+// client. The caller must hold the lock.
+func (db *DB) isAttached(docID primitive.ObjectID) bool {
+	hexDocID := docID.Hex()
+	for _, clientInfo := range db.clients {
+		if clientInfo.Status != types.ClientActivated {
+			continue
+		}
+		if docInfo, ok := clientInfo.Documents[hexDocID]; ok && docInfo.Status == types.DocumentAttached {
+			return true
+		}
+	}
+
+	return false
+}
+
+// UpdateDocInfo updates the server sequence of the given document.
+func (db *DB) UpdateDocInfo(ctx context.Context, docInfo *types.DocInfo) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	stored, ok := db.docs[docInfo.ID]
+	if !ok {
+		return database.ErrDocumentNotFound
+	}
+
+	updated := copyDocInfo(stored)
+	updated.ServerSeq = docInfo.ServerSeq
+	updated.Encrypted = docInfo.Encrypted
+	updated.UpdatedAt = time.Now()
+
+	return db.write(ctx, &record{Type: recordDoc, Doc: updated})
+}
+
+// UpdateDocInfoAfterPushPull updates the given document after a push-pull
+// only if its server sequence is still the given initial one. It returns
+// ErrConflict otherwise.
+func (db *DB) UpdateDocInfoAfterPushPull(
+	ctx context.Context,
+	docInfo *types.DocInfo,
+	initialServerSeq uint64,
+) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	stored, ok := db.docs[docInfo.ID]
+	if !ok || stored.ServerSeq != initialServerSeq {
+		return database.ErrConflict
+	}
+
+	updated := copyDocInfo(stored)
+	updated.ServerSeq = docInfo.ServerSeq
+	updated.Encrypted = docInfo.Encrypted
+	updated.UpdatedAt = time.Now()
+
+	return db.write(ctx, &record{Type: recordDoc, Doc: updated})
+}
+
+// UpdateDocACL updates the ACL of the document of the given key.
+func (db *DB) UpdateDocACL(
+	ctx context.Context,
+	bsonDocKey string,
+	acl *auth.ACL,
+) (*types.DocInfo, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	id, ok := db.docIDByKey[bsonDocKey]
+	if !ok {
+		return nil, database.ErrDocumentNotFound
+	}
+
+	docInfo := copyDocInfo(db.docs[id])
+	docInfo.ACL = copyACL(acl)
+	docInfo.UpdatedAt = time.Now()
+	if err := db.write(ctx, &record{Type: recordDoc, Doc: docInfo}); err != nil {
+		return nil, err
+	}
+
+	return copyDocInfo(docInfo), nil
+}
+
+// UpdateDocLabels replaces the labels of the document of the given key.
+func (db *DB) UpdateDocLabels(
+	ctx context.Context,
+	bsonDocKey string,
+	l labels.Labels,
+) (*types.DocInfo, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	id, ok := db.docIDByKey[bsonDocKey]
+	if !ok {
+		return nil, database.ErrDocumentNotFound
+	}
+
+	docInfo := copyDocInfo(db.docs[id])
+	docInfo.Labels = copyLabels(l)
+	docInfo.UpdatedAt = time.Now()
+	if err := db.write(ctx, &record{Type: recordDoc, Doc: docInfo}); err != nil {
+		return nil, err
+	}
+
+	return copyDocInfo(docInfo), nil
+}
+
+// UpdateDocBranch updates the branch information of the given document.
+func (db *DB) UpdateDocBranch(
+	ctx context.Context,
+	docID primitive.ObjectID,
+	branch *types.BranchInfo,
+) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	stored, ok := db.docs[docID]
+	if !ok {
+		return database.ErrDocumentNotFound
+	}
+
+	updated := copyDocInfo(stored)
+	if branch != nil {
+		branchCopy := *branch
+		updated.Branch = &branchCopy
+	} else {
+		updated.Branch = nil
+	}
+
+	return db.write(ctx, &record{Type: recordDoc, Doc: updated})
+}
+
+// CreateChangeInfos stores the given changes of the given document.
+func (db *DB) CreateChangeInfos(
+	ctx context.Context,
+	docID primitive.ObjectID,
+	changes []*change.Change,
+) error {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	var infos []*types.ChangeInfo
+	for _, c := range changes {
+		info := types.NewChangeInfo(docID, c)
+		operations, err := database.EncryptPayloads(db.cipher, info.Operations)
+		if err != nil {
+			return err
+		}
+		info.Operations = operations
+		infos = append(infos, info)
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	return db.write(ctx, &record{Type: recordChanges, DocID: docID, Changes: infos})
+}
+
+// FindChangeInfosBetweenServerSeqs returns the changes of the given document
+// between the given server sequences, inclusive.
+func (db *DB) FindChangeInfosBetweenServerSeqs(
+	ctx context.Context,
+	docID primitive.ObjectID,
+	from uint64,
+	to uint64,
+) ([]*change.Change, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	infos := db.changes[docID]
+	var changes []*change.Change
+	for i := searchChangeInfo(infos, from); i < len(infos) && infos[i].ServerSeq <= to; i++ {
+		info := *infos[i]
+		operations, err := database.DecryptPayloads(db.cipher, info.Operations)
+		if err != nil {
+			return nil, err
+		}
+		info.Operations = operations
+
+		c, err := info.ToChange()
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, c)
+	}
+
+	return changes, nil
+}
+
+// PruneChangeInfos deletes the changes of the given document up to the given
+// server sequence and returns the number of deleted changes.
+func (db *DB) PruneChangeInfos(
+	ctx context.Context,
+	docID primitive.ObjectID,
+	serverSeq uint64,
+) (int64, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	count := int64(searchChangeInfo(db.changes[docID], serverSeq+1))
+	if err := db.write(ctx, &record{
+		Type:      recordPrune,
+		DocID:     docID,
+		ServerSeq: serverSeq,
+	}); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// CreateSnapshotInfo stores the given snapshot of the given document. Only
+// the last snapshot of each document is kept.
+func (db *DB) CreateSnapshotInfo(
+	ctx context.Context,
+	docID primitive.ObjectID,
+	serverSeq uint64,
+	snapshot []byte,
+) error {
+	// NOTE: The snapshot is kept in memory, so it is copied as the caller can
+	// reuse the buffer.
+	if db.cipher != nil {
+		encrypted, err := db.cipher.Encrypt(snapshot)
+		if err != nil {
+			return err
+		}
+		snapshot = encrypted
+	} else {
+		snapshot = append([]byte(nil), snapshot...)
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	return db.write(ctx, &record{Type: recordSnapshot, Snapshot: &types.SnapshotInfo{
+		ID:        primitive.NewObjectID(),
+		DocID:     docID,
+		ServerSeq: serverSeq,
+		Snapshot:  snapshot,
+		CreatedAt: time.Now(),
+	}})
+}
+
+// FindLastSnapshotInfo returns the last snapshot of the given document.
+func (db *DB) FindLastSnapshotInfo(
+	ctx context.Context,
+	docID primitive.ObjectID,
+) (*types.SnapshotInfo, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	snapshotInfo, ok := db.snapshots[docID]
+	if !ok {
+		return &types.SnapshotInfo{}, nil
+	}
+
+	info := *snapshotInfo
+	if db.cipher != nil {
+		snapshot, err := db.cipher.Decrypt(info.Snapshot)
+		if err != nil {
+			return nil, err
+		}
+		info.Snapshot = snapshot
+	}
+
+	return &info, nil
+}
+
+// CreateTagInfo tags the given snapshot of the given document at the given
+// server sequence with the given name.
+func (db *DB) CreateTagInfo(
+	ctx context.Context,
+	docID primitive.ObjectID,
+	name string,
+	serverSeq uint64,
+	snapshot []byte,
+) (*types.TagInfo, error) {
+	stored := append([]byte(nil), snapshot...)
+	if db.cipher != nil {
+		encrypted, err := db.cipher.Encrypt(snapshot)
+		if err != nil {
+			return nil, err
+		}
+		stored = encrypted
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for _, tagInfo := range db.tags[docID] {
+		if tagInfo.Name == name {
+			return nil, database.ErrTagAlreadyExists
+		}
+	}
+
+	tagInfo := &types.TagInfo{
+		ID:        primitive.NewObjectID(),
+		DocID:     docID,
+		Name:      name,
+		ServerSeq: serverSeq,
+		Snapshot:  stored,
+		CreatedAt: time.Now(),
+	}
+	if err := db.write(ctx, &record{Type: recordTag, Tag: tagInfo}); err != nil {
+		return nil, err
+	}
+
+	info := *tagInfo
+	info.Snapshot = snapshot
+	return &info, nil
+}
+
+// FindTagInfos returns the tags of the given document without their
+// snapshots.
+func (db *DB) FindTagInfos(ctx context.Context, docID primitive.ObjectID) ([]*types.TagInfo, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	var tagInfos []*types.TagInfo
+	for _, tagInfo := range db.tags[docID] {
+		info := *tagInfo
+		info.Snapshot = nil
+		tagInfos = append(tagInfos, &info)
+	}
+
+	sort.SliceStable(tagInfos, func(i, j int) bool {
+		return tagInfos[i].ServerSeq < tagInfos[j].ServerSeq
+	})
+
+	return tagInfos, nil
+}
+
+// FindTagInfoByName returns the tag of the given name of the given document.
+func (db *DB) FindTagInfoByName(
+	ctx context.Context,
+	docID primitive.ObjectID,
+	name string,
+) (*types.TagInfo, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	for _, tagInfo := range db.tags[docID] {
+		if tagInfo.Name != name {
+			continue
+		}
+
+		info := *tagInfo
+		if db.cipher != nil {
+			snapshot, err := db.cipher.Decrypt(info.Snapshot)
+			if err != nil {
+				return nil, err
+			}
+			info.Snapshot = snapshot
+		}
+		return &info, nil
+	}
+
+	return nil, database.ErrTagNotFound
+}
+
+// CreateDeadLetterInfo quarantines the given change of the given document
+// which could not be applied for the given cause.
+func (db *DB) CreateDeadLetterInfo(
+	ctx context.Context,
+	docID primitive.ObjectID,
+	c *change.Change,
+	cause string,
+) (bool, error) {
+	changeInfo := types.NewChangeInfo(docID, c)
+	operations, err := database.EncryptPayloads(db.cipher, changeInfo.Operations)
+	if err != nil {
+		return false, err
+	}
+	changeInfo.Operations = operations
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for _, deadLetterInfo := range db.deadLetters[docID] {
+		if deadLetterInfo.ServerSeq == c.ServerSeq() {
+			return false, nil
+		}
+	}
+
+	deadLetterInfo := &types.DeadLetterInfo{
+		ID:        primitive.NewObjectID(),
+		DocID:     docID,
+		ServerSeq: c.ServerSeq(),
+		Change:    changeInfo,
+		Cause:     cause,
+		CreatedAt: time.Now(),
+	}
+	if err := db.write(ctx, &record{Type: recordDeadLetter, DeadLetter: deadLetterInfo}); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// FindDeadLetterInfos returns the quarantined changes of the given document.
+func (db *DB) FindDeadLetterInfos(
+	ctx context.Context,
+	docID primitive.ObjectID,
+) ([]*types.DeadLetterInfo, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	var deadLetterInfos []*types.DeadLetterInfo
+	for _, deadLetterInfo := range db.deadLetters[docID] {
+		changeInfo := *deadLetterInfo.Change
+		operations, err := database.DecryptPayloads(db.cipher, changeInfo.Operations)
+		if err != nil {
+			return nil, err
+		}
+		changeInfo.Operations = operations
+
+		info := *deadLetterInfo
+		info.Change = &changeInfo
+		deadLetterInfos = append(deadLetterInfos, &info)
+	}
+
+	sort.SliceStable(deadLetterInfos, func(i, j int) bool {
+		return deadLetterInfos[i].ServerSeq < deadLetterInfos[j].ServerSeq
+	})
+
+	return deadLetterInfos, nil
+}
+
+// CountActivatedClients returns the number of activated clients.
+func (db *DB) CountActivatedClients(ctx context.Context) (int64, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	var count int64
+	for _, clientInfo := range db.clients {
+		if clientInfo.Status == types.ClientActivated {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// CountAttachedDocuments returns the number of documents attached to the
+// activated clients.
+func (db *DB) CountAttachedDocuments(ctx context.Context) (int64, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	var count int64
+	for _, clientInfo := range db.clients {
+		if clientInfo.Status != types.ClientActivated {
+			continue
+		}
+		for _, docInfo := range clientInfo.Documents {
+			if docInfo.Status == types.DocumentAttached {
+				count++
+			}
+		}
+	}
+
+	return count, nil
+}
+
+// WithTransaction runs the given function exclusively with other
+// transactions. The writes of the function are journaled as a single record,
+// so that they survive a crash all together or not at all. Like MongoDB
+// without transactions, the writes done before an error are not rolled back.
+func (db *DB) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*tx); ok {
+		return fn(ctx)
+	}
+
+	db.txMu.Lock()
+	defer db.txMu.Unlock()
+
+	t := &tx{}
+	fnErr := fn(context.WithValue(ctx, txKey{}, t))
+
+	if len(t.records) > 0 {
+		db.mu.Lock()
+		err := db.journal.append(&record{Type: recordBatch, Records: t.records})
+		db.mu.Unlock()
+		if err != nil {
+			return err
+		}
+	}
+
+	return fnErr
+}
+
+// write journals the given record and applies it to the state. If the context
+// is in a transaction, the record is journaled when the transaction ends.
+// The caller must hold the write lock.
+func (db *DB) write(ctx context.Context, rec *record) error {
+	if t, ok := ctx.Value(txKey{}).(*tx); ok {
+		t.records = append(t.records, rec)
+	} else if err := db.journal.append(rec); err != nil {
+		return err
+	}
+
+	return db.apply(rec)
+}
+
+// apply applies the given record to the state.
+func (db *DB) apply(rec *record) error {
+	switch rec.Type {
+	case recordClient:
+		db.clients[rec.Client.ID] = rec.Client
+		db.clientIDByKey[rec.Client.Key] = rec.Client.ID
+	case recordDoc:
+		db.docs[rec.Doc.ID] = rec.Doc
+		db.docIDByKey[rec.Doc.Key] = rec.Doc.ID
+	case recordChanges:
+		infos := db.changes[rec.DocID]
+		for _, info := range rec.Changes {
+			i := searchChangeInfo(infos, info.ServerSeq)
+			if i < len(infos) && infos[i].ServerSeq == info.ServerSeq {
+				infos[i] = info
+				continue
+			}
+			infos = append(infos, nil)
+			copy(infos[i+1:], infos[i:])
+			infos[i] = info
+		}
+		db.changes[rec.DocID] = infos
+	case recordSnapshot:
+		last, ok := db.snapshots[rec.Snapshot.DocID]
+		if !ok || last.ServerSeq <= rec.Snapshot.ServerSeq {
+			db.snapshots[rec.Snapshot.DocID] = rec.Snapshot
+		}
+	case recordTag:
+		db.tags[rec.Tag.DocID] = append(db.tags[rec.Tag.DocID], rec.Tag)
+	case recordDeadLetter:
+		docID := rec.DeadLetter.DocID
+		db.deadLetters[docID] = append(db.deadLetters[docID], rec.DeadLetter)
+	case recordPrune:
+		if docInfo, ok := db.docs[rec.DocID]; ok && docInfo.PrunedServerSeq < rec.ServerSeq {
+			docInfo = copyDocInfo(docInfo)
+			docInfo.PrunedServerSeq = rec.ServerSeq
+			db.docs[rec.DocID] = docInfo
+		}
+
+		infos := db.changes[rec.DocID]
+		remaining := infos[searchChangeInfo(infos, rec.ServerSeq+1):]
+		if len(remaining) == 0 {
+			delete(db.changes, rec.DocID)
+		} else {
+			db.changes[rec.DocID] = append([]*types.ChangeInfo(nil), remaining...)
+		}
+	case recordPurge:
+		if docInfo, ok := db.docs[rec.DocID]; ok {
+			delete(db.docIDByKey, docInfo.Key)
+			delete(db.docs, rec.DocID)
+		}
+		delete(db.changes, rec.DocID)
+		delete(db.snapshots, rec.DocID)
+		delete(db.tags, rec.DocID)
+		delete(db.deadLetters, rec.DocID)
+
+		hexDocID := rec.DocID.Hex()
+		for id, clientInfo := range db.clients {
+			if _, ok := clientInfo.Documents[hexDocID]; ok {
+				clientInfo = copyClientInfo(clientInfo)
+				delete(clientInfo.Documents, hexDocID)
+				db.clients[id] = clientInfo
+			}
+		}
+	case recordBatch:
+		for _, r := range rec.Records {
+			if err := db.apply(r); err != nil {
+				return err
+			}
+		}
+	default:
+		logger.Warnf("unknown record type: %s", rec.Type)
+	}
+
+	return nil
+}
+
+// records returns the records that rebuild the current state.
+func (db *DB) records() []*record {
+	var recs []*record
+	for _, clientInfo := range db.clients {
+		recs = append(recs, &record{Type: recordClient, Client: clientInfo})
+	}
+	for _, docInfo := range db.docs {
+		recs = append(recs, &record{Type: recordDoc, Doc: docInfo})
+	}
+	for docID, infos := range db.changes {
+		recs = append(recs, &record{Type: recordChanges, DocID: docID, Changes: infos})
+	}
+	for _, snapshotInfo := range db.snapshots {
+		recs = append(recs, &record{Type: recordSnapshot, Snapshot: snapshotInfo})
+	}
+	for _, tagInfos := range db.tags {
+		for _, tagInfo := range tagInfos {
+			recs = append(recs, &record{Type: recordTag, Tag: tagInfo})
+		}
+	}
+	for _, deadLetterInfos := range db.deadLetters {
+		for _, deadLetterInfo := range deadLetterInfos {
+			recs = append(recs, &record{Type: recordDeadLetter, DeadLetter: deadLetterInfo})
+		}
+	}
+
+	return recs
+}
+
+// searchChangeInfo returns the index of the first change whose server
+// sequence is not less than the given one.
+func searchChangeInfo(infos []*types.ChangeInfo, serverSeq uint64) int {
+	return sort.Search(len(infos), func(i int) bool {
+		return infos[i].ServerSeq >= serverSeq
+	})
+}
+
+func copyClientInfo(clientInfo *types.ClientInfo) *types.ClientInfo {
+	info := *clientInfo
+	if clientInfo.Documents != nil {
+		info.Documents = make(map[string]*types.ClientDocInfo, len(clientInfo.Documents))
+		for docID, docInfo := range clientInfo.Documents {
+			docInfoCopy := *docInfo
+			info.Documents[docID] = &docInfoCopy
+		}
+	}
+	return &info
+}
+
+func copyDocInfo(docInfo *types.DocInfo) *types.DocInfo {
+	info := *docInfo
+	info.ACL = copyACL(docInfo.ACL)
+	info.Labels = copyLabels(docInfo.Labels)
+	if docInfo.Branch != nil {
+		branch := *docInfo.Branch
+		info.Branch = &branch
+	}
+	return &info
+}
+
+func copyLabels(l labels.Labels) labels.Labels {
+	if len(l) == 0 {
+		return nil
+	}
+
+	copied := make(labels.Labels)
+	for k, v := range l {
+		copied[k] = v
+	}
+	return copied
+}
+
+func copyACL(acl *auth.ACL) *auth.ACL {
+	if acl == nil {
+		return nil
+	}
+
+	return &auth.ACL{
+		Owner:   acl.Owner,
+		Writers: append([]string(nil), acl.Writers...),
+		Readers: append([]string(nil), acl.Readers...),
+	}
+}
@@ -0,0 +1,398 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package embedded_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yorkie-team/yorkie/pkg/document"
+	"github.com/yorkie-team/yorkie/pkg/document/proxy"
+	"github.com/yorkie-team/yorkie/yorkie/auth"
+	"github.com/yorkie-team/yorkie/yorkie/backend/database"
+	"github.com/yorkie-team/yorkie/yorkie/backend/embedded"
+	"github.com/yorkie-team/yorkie/yorkie/backend/encryption"
+	"github.com/yorkie-team/yorkie/yorkie/labels"
+	"github.com/yorkie-team/yorkie/yorkie/types"
+)
+
+var errDummy = errors.New("dummy error")
+
+func TestDB(t *testing.T) {
+	ctx := context.Background()
+
+	dir, err := ioutil.TempDir("", "yorkie-embedded")
+	assert.NoError(t, err)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	conf := &embedded.Config{Path: filepath.Join(dir, "yorkie.db")}
+
+	t.Run("persistence test", func(t *testing.T) {
+		db, err := embedded.Open(conf)
+		assert.NoError(t, err)
+
+		clientInfo, err := db.ActivateClient(ctx, "client")
+		assert.NoError(t, err)
+		docInfo, err := db.FindDocInfoByKey(ctx, clientInfo, "c$d", true)
+		assert.NoError(t, err)
+
+		doc := document.New("c", "d")
+		for i := 0; i < 3; i++ {
+			assert.NoError(t, doc.Update(func(root *proxy.ObjectProxy) error {
+				root.SetString("k", "v")
+				return nil
+			}))
+		}
+		changes := doc.CreateChangePack().Changes
+		for _, c := range changes {
+			c.SetServerSeq(docInfo.IncreaseServerSeq())
+		}
+		assert.NoError(t, db.CreateChangeInfos(ctx, docInfo.ID, changes))
+		assert.NoError(t, db.UpdateDocInfo(ctx, docInfo))
+		assert.NoError(t, db.CreateSnapshotInfo(ctx, docInfo.ID, 2, []byte{1}))
+
+		pruned, err := db.PruneChangeInfos(ctx, docInfo.ID, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), pruned)
+		assert.NoError(t, db.Close())
+
+		db, err = embedded.Open(conf)
+		assert.NoError(t, err)
+		defer func() {
+			assert.NoError(t, db.Close())
+		}()
+
+		found, err := db.FindDocInfoByKey(ctx, clientInfo, "c$d", false)
+		assert.NoError(t, err)
+		assert.Equal(t, docInfo.ID, found.ID)
+		assert.Equal(t, uint64(3), found.ServerSeq)
+		assert.Equal(t, uint64(1), found.PrunedServerSeq)
+
+		stored, err := db.FindChangeInfosBetweenServerSeqs(ctx, docInfo.ID, 0, 3)
+		assert.NoError(t, err)
+		assert.Len(t, stored, 2)
+		assert.Equal(t, uint64(2), stored[0].ServerSeq())
+
+		snapshotInfo, err := db.FindLastSnapshotInfo(ctx, docInfo.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte{1}, snapshotInfo.Snapshot)
+
+		_, err = db.FindDocInfoByKey(ctx, clientInfo, "c$unknown", false)
+		assert.Equal(t, database.ErrDocumentNotFound, err)
+	})
+
+	t.Run("client test", func(t *testing.T) {
+		db, err := embedded.Open(conf)
+		assert.NoError(t, err)
+		defer func() {
+			assert.NoError(t, db.Close())
+		}()
+
+		clientInfo, err := db.ActivateClient(ctx, "client")
+		assert.NoError(t, err)
+		docInfo, err := db.FindDocInfoByKey(ctx, clientInfo, "c$d", false)
+		assert.NoError(t, err)
+
+		assert.NoError(t, clientInfo.AttachDocument(docInfo.ID, auth.ReadWrite))
+		assert.NoError(t, db.UpdateClientInfoAfterPushPull(ctx, clientInfo, docInfo))
+		count, err := db.CountAttachedDocuments(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+
+		deactivated, err := db.DeactivateClient(ctx, clientInfo.ID.Hex())
+		assert.NoError(t, err)
+		assert.Equal(t, types.ClientDeactivated, deactivated.Status)
+		count, err = db.CountActivatedClients(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), count)
+
+		_, err = db.FindClientInfoByID(ctx, "0123456789abcdef01234567")
+		assert.Equal(t, database.ErrClientNotFound, err)
+	})
+
+	t.Run("transaction test", func(t *testing.T) {
+		db, err := embedded.Open(conf)
+		assert.NoError(t, err)
+
+		err = db.WithTransaction(ctx, func(ctx context.Context) error {
+			if _, err := db.ActivateClient(ctx, "tx-client"); err != nil {
+				return err
+			}
+			return errDummy
+		})
+		assert.Equal(t, errDummy, err)
+		assert.NoError(t, db.Close())
+
+		db, err = embedded.Open(conf)
+		assert.NoError(t, err)
+		defer func() {
+			assert.NoError(t, db.Close())
+		}()
+
+		count, err := db.CountActivatedClients(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("encryption test", func(t *testing.T) {
+		provider, err := encryption.NewLocalKeyProvider(&encryption.Config{
+			KeyID: "k1",
+			Keys:  map[string]string{"k1": base64.StdEncoding.EncodeToString(make([]byte, 32))},
+		})
+		assert.NoError(t, err)
+		envelope, err := encryption.NewEnvelope(provider)
+		assert.NoError(t, err)
+
+		db, err := embedded.Open(conf)
+		assert.NoError(t, err)
+		db.UseCipher(envelope)
+
+		clientInfo, err := db.ActivateClient(ctx, "client")
+		assert.NoError(t, err)
+		docInfo, err := db.FindDocInfoByKey(ctx, clientInfo, "c$encrypted", true)
+		assert.NoError(t, err)
+
+		plaintext := []byte("plaintext snapshot")
+		assert.NoError(t, db.CreateSnapshotInfo(ctx, docInfo.ID, 1, plaintext))
+		snapshotInfo, err := db.FindLastSnapshotInfo(ctx, docInfo.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, plaintext, snapshotInfo.Snapshot)
+		assert.NoError(t, db.Close())
+
+		data, err := ioutil.ReadFile(conf.Path)
+		assert.NoError(t, err)
+		assert.False(t, bytes.Contains(data, plaintext))
+	})
+
+	t.Run("purge test", func(t *testing.T) {
+		db, err := embedded.Open(conf)
+		assert.NoError(t, err)
+
+		clientInfo, err := db.ActivateClient(ctx, "purge-client")
+		assert.NoError(t, err)
+		docInfo, err := db.FindDocInfoByKey(ctx, clientInfo, "c$purge", true)
+		assert.NoError(t, err)
+		assert.NoError(t, db.CreateSnapshotInfo(ctx, docInfo.ID, 1, []byte{1}))
+		assert.NoError(t, clientInfo.AttachDocument(docInfo.ID, auth.ReadWrite))
+		assert.NoError(t, db.UpdateClientInfoAfterPushPull(ctx, clientInfo, docInfo))
+
+		future := time.Now().Add(time.Hour)
+		inactive, err := db.FindInactiveDocInfos(ctx, future, nil, 100)
+		assert.NoError(t, err)
+		for _, info := range inactive {
+			assert.NotEqual(t, docInfo.ID, info.ID)
+		}
+		purged, err := db.PurgeDocInfo(ctx, docInfo)
+		assert.NoError(t, err)
+		assert.False(t, purged)
+
+		assert.NoError(t, clientInfo.DetachDocument(docInfo.ID))
+		assert.NoError(t, db.UpdateClientInfoAfterPushPull(ctx, clientInfo, docInfo))
+		inactive, err = db.FindInactiveDocInfos(ctx, time.Now().Add(-time.Hour), nil, 100)
+		assert.NoError(t, err)
+		assert.Len(t, inactive, 0)
+
+		purged, err = db.PurgeDocInfo(ctx, docInfo)
+		assert.NoError(t, err)
+		assert.True(t, purged)
+		assert.NoError(t, db.Close())
+
+		db, err = embedded.Open(conf)
+		assert.NoError(t, err)
+		defer func() {
+			assert.NoError(t, db.Close())
+		}()
+
+		_, err = db.FindDocInfoByKey(ctx, clientInfo, "c$purge", false)
+		assert.Equal(t, database.ErrDocumentNotFound, err)
+		snapshotInfo, err := db.FindLastSnapshotInfo(ctx, docInfo.ID)
+		assert.NoError(t, err)
+		assert.Nil(t, snapshotInfo.Snapshot)
+		found, err := db.FindClientInfoByID(ctx, clientInfo.ID.Hex())
+		assert.NoError(t, err)
+		assert.NotContains(t, found.Documents, docInfo.ID.Hex())
+	})
+
+	t.Run("tag test", func(t *testing.T) {
+		db, err := embedded.Open(conf)
+		assert.NoError(t, err)
+
+		clientInfo, err := db.ActivateClient(ctx, "tag-client")
+		assert.NoError(t, err)
+		docInfo, err := db.FindDocInfoByKey(ctx, clientInfo, "c$tag", true)
+		assert.NoError(t, err)
+
+		_, err = db.CreateTagInfo(ctx, docInfo.ID, "v2", 2, []byte{2})
+		assert.NoError(t, err)
+		_, err = db.CreateTagInfo(ctx, docInfo.ID, "v1", 1, []byte{1})
+		assert.NoError(t, err)
+		_, err = db.CreateTagInfo(ctx, docInfo.ID, "v1", 3, []byte{3})
+		assert.Equal(t, database.ErrTagAlreadyExists, err)
+		assert.NoError(t, db.Close())
+
+		db, err = embedded.Open(conf)
+		assert.NoError(t, err)
+		defer func() {
+			assert.NoError(t, db.Close())
+		}()
+
+		tagInfos, err := db.FindTagInfos(ctx, docInfo.ID)
+		assert.NoError(t, err)
+		assert.Len(t, tagInfos, 2)
+		assert.Equal(t, "v1", tagInfos[0].Name)
+		assert.Equal(t, "v2", tagInfos[1].Name)
+		assert.Nil(t, tagInfos[0].Snapshot)
+
+		tagInfo, err := db.FindTagInfoByName(ctx, docInfo.ID, "v2")
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(2), tagInfo.ServerSeq)
+		assert.Equal(t, []byte{2}, tagInfo.Snapshot)
+
+		_, err = db.FindTagInfoByName(ctx, docInfo.ID, "v3")
+		assert.Equal(t, database.ErrTagNotFound, err)
+	})
+
+	t.Run("dead letter test", func(t *testing.T) {
+		db, err := embedded.Open(conf)
+		assert.NoError(t, err)
+
+		clientInfo, err := db.ActivateClient(ctx, "dead-letter-client")
+		assert.NoError(t, err)
+		docInfo, err := db.FindDocInfoByKey(ctx, clientInfo, "c$dead-letter", true)
+		assert.NoError(t, err)
+
+		doc := document.New("c", "dead-letter")
+		assert.NoError(t, doc.Update(func(root *proxy.ObjectProxy) error {
+			root.SetString("k", "v")
+			return nil
+		}, "broken"))
+		c := doc.CreateChangePack().Changes[0]
+		c.SetServerSeq(3)
+
+		created, err := db.CreateDeadLetterInfo(ctx, docInfo.ID, c, "not applicable")
+		assert.NoError(t, err)
+		assert.True(t, created)
+		created, err = db.CreateDeadLetterInfo(ctx, docInfo.ID, c, "not applicable")
+		assert.NoError(t, err)
+		assert.False(t, created)
+		assert.NoError(t, db.Close())
+
+		db, err = embedded.Open(conf)
+		assert.NoError(t, err)
+		defer func() {
+			assert.NoError(t, db.Close())
+		}()
+
+		deadLetterInfos, err := db.FindDeadLetterInfos(ctx, docInfo.ID)
+		assert.NoError(t, err)
+		assert.Len(t, deadLetterInfos, 1)
+		assert.Equal(t, uint64(3), deadLetterInfos[0].ServerSeq)
+		assert.Equal(t, "not applicable", deadLetterInfos[0].Cause)
+		assert.Equal(t, "broken", deadLetterInfos[0].Change.Message)
+	})
+
+	t.Run("find doc infos by key prefix test", func(t *testing.T) {
+		db, err := embedded.Open(conf)
+		assert.NoError(t, err)
+		defer func() {
+			assert.NoError(t, db.Close())
+		}()
+
+		clientInfo, err := db.ActivateClient(ctx, "prefix-client")
+		assert.NoError(t, err)
+		for _, bsonKey := range []string{
+			"p$workspace-42/b",
+			"p$workspace-42/a",
+			"p$workspace-42/designs/logo",
+			"p$workspace-420/a",
+			"q$workspace-42/a",
+		} {
+			_, err := db.FindDocInfoByKey(ctx, clientInfo, bsonKey, true)
+			assert.NoError(t, err)
+		}
+
+		docInfos, err := db.FindDocInfosByKeyPrefix(ctx, "p$workspace-42/", nil, 10)
+		assert.NoError(t, err)
+		var keys []string
+		for _, docInfo := range docInfos {
+			keys = append(keys, docInfo.Key)
+		}
+		assert.Equal(t, []string{
+			"p$workspace-42/a",
+			"p$workspace-42/b",
+			"p$workspace-42/designs/logo",
+		}, keys)
+
+		docInfos, err = db.FindDocInfosByKeyPrefix(ctx, "p$", nil, 2)
+		assert.NoError(t, err)
+		assert.Len(t, docInfos, 2)
+	})
+
+	t.Run("labels test", func(t *testing.T) {
+		db, err := embedded.Open(conf)
+		assert.NoError(t, err)
+
+		clientInfo, err := db.ActivateClient(ctx, "labels-client")
+		assert.NoError(t, err)
+		for _, bsonKey := range []string{"l$a", "l$b"} {
+			_, err := db.FindDocInfoByKey(ctx, clientInfo, bsonKey, true)
+			assert.NoError(t, err)
+		}
+
+		docInfo, err := db.UpdateDocLabels(ctx, "l$a", labels.Labels{"team": "design"})
+		assert.NoError(t, err)
+		assert.Equal(t, labels.Labels{"team": "design"}, docInfo.Labels)
+		_, err = db.UpdateDocLabels(ctx, "l$c", labels.Labels{"team": "design"})
+		assert.Equal(t, database.ErrDocumentNotFound, err)
+		assert.NoError(t, db.Close())
+
+		db, err = embedded.Open(conf)
+		assert.NoError(t, err)
+		defer func() {
+			assert.NoError(t, db.Close())
+		}()
+
+		selector, err := labels.ParseSelector("team=design")
+		assert.NoError(t, err)
+		docInfos, err := db.FindDocInfosByKeyPrefix(ctx, "l$", selector, 10)
+		assert.NoError(t, err)
+		assert.Len(t, docInfos, 1)
+		assert.Equal(t, "l$a", docInfos[0].Key)
+
+		selector, err = labels.ParseSelector("!team")
+		assert.NoError(t, err)
+		docInfos, err = db.FindDocInfosByKeyPrefix(ctx, "l$", selector, 10)
+		assert.NoError(t, err)
+		assert.Len(t, docInfos, 1)
+		assert.Equal(t, "l$b", docInfos[0].Key)
+	})
+
+	t.Run("open by driver name test", func(t *testing.T) {
+		_, err := database.Open(embedded.DriverName, nil)
+		assert.Equal(t, embedded.ErrPathRequired, err)
+	})
+}
@@ -0,0 +1,254 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package embedded
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/yorkie-team/yorkie/pkg/log"
+	"github.com/yorkie-team/yorkie/yorkie/types"
+)
+
+var logger = log.Named(log.DB)
+
+// maxRecordSize is the maximum size of a record in the journal. It guards
+// against allocating a huge buffer for a corrupted length.
+const maxRecordSize = 256 * 1024 * 1024
+
+var errCorruptedRecord = errors.New("corrupted record")
+
+type recordType string
+
+const (
+	recordClient     recordType = "client"
+	recordDoc        recordType = "doc"
+	recordChanges    recordType = "changes"
+	recordSnapshot   recordType = "snapshot"
+	recordTag        recordType = "tag"
+	recordDeadLetter recordType = "dead_letter"
+	recordPrune      recordType = "prune"
+	recordPurge      recordType = "purge"
+	recordBatch      recordType = "batch"
+)
+
+// record is an entry of the journal. Each record describes a single write,
+// and the state of the database is rebuilt by applying the records in order.
+type record struct {
+	Type       recordType            `bson:"type"`
+	Client     *types.ClientInfo     `bson:"client,omitempty"`
+	Doc        *types.DocInfo        `bson:"doc,omitempty"`
+	Changes    []*types.ChangeInfo   `bson:"changes,omitempty"`
+	Snapshot   *types.SnapshotInfo   `bson:"snapshot,omitempty"`
+	Tag        *types.TagInfo        `bson:"tag,omitempty"`
+	DeadLetter *types.DeadLetterInfo `bson:"dead_letter,omitempty"`
+	DocID      primitive.ObjectID    `bson:"doc_id,omitempty"`
+	ServerSeq  uint64                `bson:"server_seq,omitempty"`
+	Records    []*record             `bson:"records,omitempty"`
+}
+
+// journal is an append-only file of BSON encoded records. BSON documents are
+// prefixed with their length, so records are simply written one after
+// another.
+type journal struct {
+	file *os.File
+	sync bool
+
+	// offset is the offset after the last record written successfully.
+	offset int64
+
+	// err is the error of the failed rollback of a write, after which the
+	// journal can't be appended anymore.
+	err error
+}
+
+// openJournal opens the journal of the given path and calls the given
+// function for each record in it. A partially written record at the end of
+// the file, left by a crash, is discarded.
+func openJournal(path string, sync bool, fn func(rec *record) error) (*journal, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	offset, err := replay(file, fn)
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	if err := file.Truncate(offset); err != nil {
+		logger.Error(err)
+		_ = file.Close()
+		return nil, err
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		logger.Error(err)
+		_ = file.Close()
+		return nil, err
+	}
+
+	return &journal{file: file, sync: sync, offset: offset}, nil
+}
+
+// replay calls the given function for each complete record of the given file
+// and returns the offset after the last one.
+func replay(file *os.File, fn func(rec *record) error) (int64, error) {
+	reader := bufio.NewReader(file)
+
+	var offset int64
+	for {
+		data, err := readRecord(reader)
+		if err == io.EOF {
+			return offset, nil
+		}
+		if err == io.ErrUnexpectedEOF || err == errCorruptedRecord {
+			logger.Warnf("discard the journal after offset %d: %v", offset, err)
+			return offset, nil
+		}
+		if err != nil {
+			logger.Error(err)
+			return 0, err
+		}
+
+		rec := &record{}
+		if err := bson.Unmarshal(data, rec); err != nil {
+			logger.Warnf("discard the journal after offset %d: %v", offset, err)
+			return offset, nil
+		}
+		if err := fn(rec); err != nil {
+			return 0, err
+		}
+
+		offset += int64(len(data))
+	}
+}
+
+func readRecord(reader *bufio.Reader) ([]byte, error) {
+	header, err := reader.Peek(4)
+	if err == io.EOF && len(header) == 0 {
+		return nil, io.EOF
+	}
+	if err != nil {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	size := binary.LittleEndian.Uint32(header)
+	if size < 5 || size > maxRecordSize {
+		return nil, errCorruptedRecord
+	}
+
+	data := make([]byte, size)
+	if _, err := io.ReadFull(reader, data); err != nil {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	return data, nil
+}
+
+// append writes the given records to the end of the journal. If the write
+// fails, the journal is truncated back to the offset before it, so that a
+// partially written record doesn't hide the records appended after it on
+// replay.
+func (j *journal) append(recs ...*record) error {
+	if j.err != nil {
+		return j.err
+	}
+
+	var data []byte
+	for _, rec := range recs {
+		encoded, err := bson.Marshal(rec)
+		if err != nil {
+			logger.Error(err)
+			return err
+		}
+		data = append(data, encoded...)
+	}
+
+	if _, err := j.file.Write(data); err != nil {
+		logger.Error(err)
+		j.rollback()
+		return err
+	}
+
+	if j.sync {
+		if err := j.file.Sync(); err != nil {
+			logger.Error(err)
+			j.rollback()
+			return err
+		}
+	}
+
+	j.offset += int64(len(data))
+	return nil
+}
+
+// rollback truncates the journal to the offset after the last record written
+// successfully. If it fails, the journal is broken and refuses later writes.
+func (j *journal) rollback() {
+	if err := j.file.Truncate(j.offset); err != nil {
+		logger.Error(err)
+		j.err = err
+		return
+	}
+	if _, err := j.file.Seek(j.offset, io.SeekStart); err != nil {
+		logger.Error(err)
+		j.err = err
+	}
+}
+
+func (j *journal) close() error {
+	if err := j.file.Close(); err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+// rewriteJournal atomically replaces the journal of the given path with a new
+// one containing only the given records, and opens it to append records.
+func rewriteJournal(path string, sync bool, recs []*record) (*journal, error) {
+	tmpPath := path + ".tmp"
+	file, err := os.OpenFile(tmpPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	j := &journal{file: file, sync: true}
+	if err := j.append(recs...); err != nil {
+		_ = j.close()
+		return nil, err
+	}
+	j.sync = sync
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		logger.Error(err)
+		_ = j.close()
+		return nil, err
+	}
+
+	return j, nil
+}
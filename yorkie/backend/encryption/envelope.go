@@ -0,0 +1,224 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package encryption provides envelope encryption of the payloads stored in
+// the database. Payloads are encrypted with AES-GCM by a data key, and the
+// data key is encrypted by a master key of a KeyProvider and stored with
+// each payload.
+package encryption
+
+import (
+	"bytes"
+	"crypto/aes"
+	gocipher "crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"sync"
+
+	"github.com/yorkie-team/yorkie/pkg/log"
+)
+
+var logger = log.Named(log.DB)
+
+// dataKeySize is the size of AES-256 keys.
+const dataKeySize = 32
+
+// magic prefixes encrypted payloads. Its first byte is zero, which is never
+// the first byte of a protobuf message, so payloads stored before enabling
+// encryption are told apart and read as they are.
+var magic = []byte{0x00, 'Y', 'E', 1}
+
+// ErrInvalidPayload is returned when an encrypted payload is malformed.
+var ErrInvalidPayload = errors.New("invalid encrypted payload")
+
+// cipher seals and opens data with AES-GCM. The nonce is prepended to the
+// sealed data.
+type cipher struct {
+	aead gocipher.AEAD
+}
+
+func newCipher(key []byte) (*cipher, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	aead, err := gocipher.NewGCM(block)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	return &cipher{aead: aead}, nil
+}
+
+func (c *cipher) seal(plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, c.aead.NonceSize(), c.aead.NonceSize()+len(plaintext)+c.aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	return c.aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func (c *cipher) open(sealed []byte) ([]byte, error) {
+	if len(sealed) < c.aead.NonceSize() {
+		return nil, ErrInvalidPayload
+	}
+
+	nonce := sealed[:c.aead.NonceSize()]
+	plaintext, err := c.aead.Open(nil, nonce, sealed[c.aead.NonceSize():], nil)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	return plaintext, nil
+}
+
+// Envelope encrypts payloads with a data key generated when it is created, and
+// decrypts payloads encrypted with any data key of the KeyProvider. It is
+// safe for concurrent use.
+//
+// The layout of an encrypted payload is:
+//
+//	magic(4) | key ID length(1) | key ID | wrapped key length(2) |
+//	wrapped key | nonce | ciphertext
+type Envelope struct {
+	provider KeyProvider
+	header   []byte
+	cipher   *cipher
+
+	mu      sync.Mutex
+	ciphers map[string]*cipher
+}
+
+// NewEnvelope creates a new instance of Envelope with a new data key wrapped
+// by the given KeyProvider.
+func NewEnvelope(provider KeyProvider) (*Envelope, error) {
+	dataKey := make([]byte, dataKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	wrapped, err := provider.WrapKey(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	keyID := provider.KeyID()
+	if len(keyID) > 0xff || len(wrapped) > 0xffff {
+		return nil, ErrInvalidPayload
+	}
+
+	c, err := newCipher(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	header := append([]byte(nil), magic...)
+	header = append(header, byte(len(keyID)))
+	header = append(header, keyID...)
+	header = append(header, 0, 0)
+	binary.BigEndian.PutUint16(header[len(header)-2:], uint16(len(wrapped)))
+	header = append(header, wrapped...)
+
+	return &Envelope{
+		provider: provider,
+		header:   header,
+		cipher:   c,
+		ciphers:  map[string]*cipher{string(wrapped): c},
+	}, nil
+}
+
+// Encrypt encrypts the given payload.
+func (e *Envelope) Encrypt(plaintext []byte) ([]byte, error) {
+	sealed, err := e.cipher.seal(plaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := make([]byte, 0, len(e.header)+len(sealed))
+	payload = append(payload, e.header...)
+	return append(payload, sealed...), nil
+}
+
+// Decrypt decrypts the given payload. Payloads that are not encrypted are
+// returned as they are.
+func (e *Envelope) Decrypt(payload []byte) ([]byte, error) {
+	if !IsEncrypted(payload) {
+		return payload, nil
+	}
+
+	rest := payload[len(magic):]
+	if len(rest) < 1 {
+		return nil, ErrInvalidPayload
+	}
+	keyIDLen := int(rest[0])
+	rest = rest[1:]
+	if len(rest) < keyIDLen+2 {
+		return nil, ErrInvalidPayload
+	}
+	keyID := string(rest[:keyIDLen])
+	rest = rest[keyIDLen:]
+
+	wrappedLen := int(binary.BigEndian.Uint16(rest))
+	rest = rest[2:]
+	if len(rest) < wrappedLen {
+		return nil, ErrInvalidPayload
+	}
+	wrapped := rest[:wrappedLen]
+
+	c, err := e.dataCipher(keyID, wrapped)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.open(rest[wrappedLen:])
+}
+
+// dataCipher returns the cipher of the given wrapped data key, unwrapping it
+// with the KeyProvider at the first use.
+func (e *Envelope) dataCipher(keyID string, wrapped []byte) (*cipher, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if c, ok := e.ciphers[string(wrapped)]; ok {
+		return c, nil
+	}
+
+	dataKey, err := e.provider.UnwrapKey(keyID, wrapped)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := newCipher(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	e.ciphers[string(wrapped)] = c
+
+	return c, nil
+}
+
+// IsEncrypted returns whether the given payload is encrypted by an Envelope.
+func IsEncrypted(payload []byte) bool {
+	return bytes.HasPrefix(payload, magic)
+}
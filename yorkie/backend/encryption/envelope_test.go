@@ -0,0 +1,111 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package encryption_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yorkie-team/yorkie/yorkie/backend/encryption"
+)
+
+func newKey(b byte) string {
+	return base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{b}, 32))
+}
+
+func TestEnvelope(t *testing.T) {
+	t.Run("encrypt and decrypt test", func(t *testing.T) {
+		provider, err := encryption.NewLocalKeyProvider(&encryption.Config{
+			KeyID: "k1",
+			Keys:  map[string]string{"k1": newKey(1)},
+		})
+		assert.NoError(t, err)
+		envelope, err := encryption.NewEnvelope(provider)
+		assert.NoError(t, err)
+
+		plaintext := []byte("payload")
+		payload, err := envelope.Encrypt(plaintext)
+		assert.NoError(t, err)
+		assert.True(t, encryption.IsEncrypted(payload))
+		assert.False(t, bytes.Contains(payload, plaintext))
+
+		decrypted, err := envelope.Decrypt(payload)
+		assert.NoError(t, err)
+		assert.Equal(t, plaintext, decrypted)
+
+		decrypted, err = envelope.Decrypt(plaintext)
+		assert.NoError(t, err)
+		assert.Equal(t, plaintext, decrypted)
+
+		payload[len(payload)-1] ^= 0xff
+		_, err = envelope.Decrypt(payload)
+		assert.Error(t, err)
+	})
+
+	t.Run("key rotation test", func(t *testing.T) {
+		oldProvider, err := encryption.NewLocalKeyProvider(&encryption.Config{
+			KeyID: "k1",
+			Keys:  map[string]string{"k1": newKey(1)},
+		})
+		assert.NoError(t, err)
+		oldEnvelope, err := encryption.NewEnvelope(oldProvider)
+		assert.NoError(t, err)
+		payload, err := oldEnvelope.Encrypt([]byte("payload"))
+		assert.NoError(t, err)
+
+		provider, err := encryption.NewLocalKeyProvider(&encryption.Config{
+			KeyID: "k2",
+			Keys:  map[string]string{"k1": newKey(1), "k2": newKey(2)},
+		})
+		assert.NoError(t, err)
+		envelope, err := encryption.NewEnvelope(provider)
+		assert.NoError(t, err)
+
+		decrypted, err := envelope.Decrypt(payload)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("payload"), decrypted)
+
+		provider, err = encryption.NewLocalKeyProvider(&encryption.Config{
+			KeyID: "k2",
+			Keys:  map[string]string{"k2": newKey(2)},
+		})
+		assert.NoError(t, err)
+		envelope, err = encryption.NewEnvelope(provider)
+		assert.NoError(t, err)
+
+		_, err = envelope.Decrypt(payload)
+		assert.True(t, errors.Is(err, encryption.ErrKeyNotFound))
+	})
+
+	t.Run("invalid config test", func(t *testing.T) {
+		_, err := encryption.NewLocalKeyProvider(&encryption.Config{
+			KeyID: "k1",
+			Keys:  map[string]string{"k1": base64.StdEncoding.EncodeToString([]byte("short"))},
+		})
+		assert.True(t, errors.Is(err, encryption.ErrInvalidKey))
+
+		_, err = encryption.NewLocalKeyProvider(&encryption.Config{
+			KeyID: "unknown",
+			Keys:  map[string]string{"k1": newKey(1)},
+		})
+		assert.True(t, errors.Is(err, encryption.ErrKeyNotFound))
+	})
+}
@@ -0,0 +1,113 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package encryption
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrKeyNotFound is returned when the master key of the given ID is not
+	// provided.
+	ErrKeyNotFound = errors.New("fail to find the master key")
+
+	// ErrInvalidKey is returned when a master key is not a 256-bit key.
+	ErrInvalidKey = errors.New("the master key must be 32 bytes")
+)
+
+// KeyProvider wraps and unwraps data keys with master keys. A key management
+// service can be used by implementing this interface.
+type KeyProvider interface {
+	// KeyID returns the ID of the master key used to wrap new data keys.
+	KeyID() string
+
+	// WrapKey encrypts the given data key with the current master key.
+	WrapKey(dataKey []byte) ([]byte, error)
+
+	// UnwrapKey decrypts the given data key with the master key of the given
+	// ID.
+	UnwrapKey(keyID string, wrapped []byte) ([]byte, error)
+}
+
+// Config is the configuration of the local key provider.
+type Config struct {
+	// KeyID is the ID of the master key used to encrypt new data.
+	KeyID string `json:"KeyID"`
+
+	// Keys are the base64 encoded 256-bit master keys by their IDs. Retired
+	// keys must be kept to decrypt the data encrypted with them.
+	Keys map[string]string `json:"Keys"`
+}
+
+// LocalKeyProvider is a KeyProvider with master keys given by the
+// configuration.
+type LocalKeyProvider struct {
+	keyID string
+	keys  map[string]*cipher
+}
+
+// NewLocalKeyProvider creates a new instance of LocalKeyProvider.
+func NewLocalKeyProvider(conf *Config) (*LocalKeyProvider, error) {
+	keys := make(map[string]*cipher)
+	for id, encoded := range conf.Keys {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+		if len(key) != dataKeySize {
+			return nil, fmt.Errorf("%s: %w", id, ErrInvalidKey)
+		}
+
+		c, err := newCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		keys[id] = c
+	}
+
+	if _, ok := keys[conf.KeyID]; !ok {
+		return nil, fmt.Errorf("%s: %w", conf.KeyID, ErrKeyNotFound)
+	}
+
+	return &LocalKeyProvider{
+		keyID: conf.KeyID,
+		keys:  keys,
+	}, nil
+}
+
+// KeyID returns the ID of the master key used to wrap new data keys.
+func (p *LocalKeyProvider) KeyID() string {
+	return p.keyID
+}
+
+// WrapKey encrypts the given data key with the current master key.
+func (p *LocalKeyProvider) WrapKey(dataKey []byte) ([]byte, error) {
+	return p.keys[p.keyID].seal(dataKey)
+}
+
+// UnwrapKey decrypts the given data key with the master key of the given ID.
+func (p *LocalKeyProvider) UnwrapKey(keyID string, wrapped []byte) ([]byte, error) {
+	c, ok := p.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", keyID, ErrKeyNotFound)
+	}
+
+	return c.open(wrapped)
+}
@@ -0,0 +1,108 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrInvalidDocumentLimits is returned when the limits of documents are
+// invalid.
+var ErrInvalidDocumentLimits = errors.New("invalid document limits")
+
+// DocumentLimits is the configuration of the limits of the documents. The
+// size of a document is the size of its snapshot in bytes, and the elements
+// of a document are the elements not removed from it. The zero values of the
+// fields disable the limits.
+type DocumentLimits struct {
+	// SoftSize is the size of the documents above which the operators are
+	// warned.
+	SoftSize int `json:"SoftSize"`
+
+	// HardSize is the size of the documents above which the changes pushed
+	// by clients are rejected.
+	HardSize int `json:"HardSize"`
+
+	// SoftElements is the number of elements of the documents above which the
+	// operators are warned.
+	SoftElements int `json:"SoftElements"`
+
+	// HardElements is the number of elements of the documents above which the
+	// changes pushed by clients are rejected.
+	HardElements int `json:"HardElements"`
+
+	// MaxDepth, MaxChildren and MaxKeyLength are the limits of the structure
+	// of the documents, which are the same as document.StructureLimits. The
+	// changes pushed by clients exceeding them are rejected.
+	MaxDepth     int `json:"MaxDepth"`
+	MaxChildren  int `json:"MaxChildren"`
+	MaxKeyLength int `json:"MaxKeyLength"`
+
+	// WebhookURL is the URL that the warnings are posted to as JSON. No
+	// warning is posted if it is empty.
+	WebhookURL string `json:"WebhookURL"`
+}
+
+// Validate validates these limits.
+func (l *DocumentLimits) Validate() error {
+	if l.SoftSize < 0 || l.HardSize < 0 || l.SoftElements < 0 || l.HardElements < 0 ||
+		l.MaxDepth < 0 || l.MaxChildren < 0 || l.MaxKeyLength < 0 {
+		return fmt.Errorf("negative limit: %w", ErrInvalidDocumentLimits)
+	}
+
+	if l.SoftSize > 0 && l.HardSize > 0 && l.SoftSize > l.HardSize {
+		return fmt.Errorf("soft size %d above hard size %d: %w", l.SoftSize, l.HardSize, ErrInvalidDocumentLimits)
+	}
+
+	if l.SoftElements > 0 && l.HardElements > 0 && l.SoftElements > l.HardElements {
+		return fmt.Errorf(
+			"soft elements %d above hard elements %d: %w",
+			l.SoftElements,
+			l.HardElements,
+			ErrInvalidDocumentLimits,
+		)
+	}
+
+	return nil
+}
+
+// softLimitedDocs is the set of the documents above the soft limits, so that
+// the operators are warned once until the documents go below the limits.
+type softLimitedDocs struct {
+	mu   sync.Mutex
+	keys map[string]bool
+}
+
+// MarkSoftLimited marks whether the document of the given key is above the
+// soft limits. It returns true if the document has just gone above them.
+func (b *Backend) MarkSoftLimited(bsonDocKey string, limited bool) bool {
+	b.softLimited.mu.Lock()
+	defer b.softLimited.mu.Unlock()
+
+	if !limited {
+		delete(b.softLimited.keys, bsonDocKey)
+		return false
+	}
+
+	if b.softLimited.keys[bsonDocKey] {
+		return false
+	}
+	b.softLimited.keys[bsonDocKey] = true
+	return true
+}
@@ -0,0 +1,227 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mongo
+
+import (
+	"context"
+	"errors"
+	time2 "time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/yorkie-team/yorkie/pkg/document/time"
+	"github.com/yorkie-team/yorkie/yorkie/backend/database"
+	"github.com/yorkie-team/yorkie/yorkie/types"
+)
+
+var (
+	// ErrChangeStreamsNotSupported is returned when change streams are
+	// requested from a deployment that doesn't support them, such as a
+	// standalone server.
+	ErrChangeStreamsNotSupported = database.ErrChangeStreamsNotSupported
+
+	errChangeStreamClosed = errors.New("change stream closed")
+)
+
+const (
+	// maxCachedDocKeys is the maximum number of the keys of documents cached
+	// by a change stream.
+	maxCachedDocKeys = 10000
+
+	// minReopenBackoff and maxReopenBackoff bound the delay before a failed
+	// change stream is reopened.
+	minReopenBackoff = 100 * time2.Millisecond
+	maxReopenBackoff = 10 * time2.Second
+)
+
+// changeStreamHistoryLost is the code of the error returned when the change
+// stream can't be resumed because the oplog no longer has the resume token.
+const changeStreamHistoryLost = 286
+
+// changeEvent is the event of a change stream on the changes collection.
+type changeEvent struct {
+	FullDocument struct {
+		DocID primitive.ObjectID `bson:"doc_id"`
+		Actor primitive.ObjectID `bson:"actor"`
+	} `bson:"fullDocument"`
+}
+
+// WatchChanges opens a change stream on the changes collection to watch the
+// changes stored by any agent. The stream is consumed in a new goroutine until
+// the given context is done, and the given function is called with the key of
+// the document and the actor of each inserted change.
+//
+// If the stream fails, onErr is called with the error and the stream is
+// reopened after a backoff, resuming after the last consumed change.
+func (c *Client) WatchChanges(
+	ctx context.Context,
+	fn func(bsonDocKey string, actor *time.ActorID),
+	onErr func(err error),
+) error {
+	if !c.transactional {
+		return ErrChangeStreamsNotSupported
+	}
+
+	stream, err := c.openChangeStream(ctx, nil)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	go c.consumeChanges(ctx, stream, fn, onErr)
+	return nil
+}
+
+// openChangeStream opens a change stream on the changes collection. If the
+// given resume token is not nil, the stream starts after it.
+func (c *Client) openChangeStream(
+	ctx context.Context,
+	resumeToken bson.Raw,
+) (*mongo.ChangeStream, error) {
+	opts := options.ChangeStream()
+	if resumeToken != nil {
+		opts.SetResumeAfter(resumeToken)
+	}
+
+	var stream *mongo.ChangeStream
+	if err := c.withCollection(ColChanges, func(col *mongo.Collection) error {
+		var err error
+		stream, err = col.Watch(ctx, mongo.Pipeline{
+			bson.D{{Key: "$match", Value: bson.M{"operationType": "insert"}}},
+		}, opts)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return stream, nil
+}
+
+// consumeChanges consumes the given change stream until the given context is
+// done. The stream is reopened whenever it fails.
+func (c *Client) consumeChanges(
+	ctx context.Context,
+	stream *mongo.ChangeStream,
+	fn func(bsonDocKey string, actor *time.ActorID),
+	onErr func(err error),
+) {
+	keyByDocID := make(map[primitive.ObjectID]string)
+	var resumeToken bson.Raw
+	backoff := minReopenBackoff
+
+	for {
+		if token := stream.ResumeToken(); token != nil {
+			resumeToken = token
+		}
+
+		for stream.Next(ctx) {
+			backoff = minReopenBackoff
+			resumeToken = stream.ResumeToken()
+
+			event := changeEvent{}
+			if err := stream.Decode(&event); err != nil {
+				logger.Error(err)
+				continue
+			}
+
+			docID := event.FullDocument.DocID
+			bsonDocKey, ok := keyByDocID[docID]
+			if !ok {
+				docInfo, err := c.findDocInfoByID(ctx, docID)
+				if err != nil {
+					logger.Error(err)
+					continue
+				}
+				bsonDocKey = docInfo.Key
+
+				// NOTE: The keys of documents never change, so the cache is
+				// simply dropped when it is full.
+				if len(keyByDocID) >= maxCachedDocKeys {
+					keyByDocID = make(map[primitive.ObjectID]string)
+				}
+				keyByDocID[docID] = bsonDocKey
+			}
+
+			actor := time.ActorID{}
+			copy(actor[:], event.FullDocument.Actor[:])
+			fn(bsonDocKey, &actor)
+		}
+
+		err := stream.Err()
+		if closeErr := stream.Close(context.Background()); closeErr != nil {
+			logger.Error(closeErr)
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			err = errChangeStreamClosed
+		}
+
+		for {
+			logger.Error(err)
+			onErr(err)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time2.After(backoff):
+			}
+			if backoff *= 2; backoff > maxReopenBackoff {
+				backoff = maxReopenBackoff
+			}
+
+			stream, err = c.openChangeStream(ctx, resumeToken)
+			if err == nil {
+				break
+			}
+
+			// NOTE: If the oplog no longer has the resume token, the changes
+			// since then are lost and the stream starts over from now.
+			var cmdErr mongo.CommandError
+			if errors.As(err, &cmdErr) && cmdErr.Code == changeStreamHistoryLost {
+				resumeToken = nil
+			}
+		}
+	}
+}
+
+// findDocInfoByID finds the document info of the given ID.
+func (c *Client) findDocInfoByID(
+	ctx context.Context,
+	docID primitive.ObjectID,
+) (*types.DocInfo, error) {
+	docInfo := types.DocInfo{}
+	if err := c.withCollection(ColDocInfos, func(col *mongo.Collection) error {
+		result := col.FindOne(ctx, bson.M{"_id": docID})
+		if result.Err() == mongo.ErrNoDocuments {
+			return ErrDocumentNotFound
+		}
+		if result.Err() != nil {
+			return result.Err()
+		}
+
+		return result.Decode(&docInfo)
+	}); err != nil {
+		return nil, err
+	}
+
+	return &docInfo, nil
+}
@@ -18,7 +18,8 @@ package mongo
 
 import (
 	"context"
-	"errors"
+	"encoding/json"
+	"regexp"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -27,32 +28,75 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/mongo/readpref"
 
-	"github.com/yorkie-team/yorkie/api/converter"
-	"github.com/yorkie-team/yorkie/pkg/document"
 	"github.com/yorkie-team/yorkie/pkg/document/change"
 	"github.com/yorkie-team/yorkie/pkg/log"
+	"github.com/yorkie-team/yorkie/yorkie/auth"
+	"github.com/yorkie-team/yorkie/yorkie/backend/database"
+	"github.com/yorkie-team/yorkie/yorkie/labels"
+	"github.com/yorkie-team/yorkie/yorkie/stats"
 	"github.com/yorkie-team/yorkie/yorkie/types"
 )
 
+var logger = log.Named(log.DB)
+
+// DriverName is the name of the MongoDB driver registered to the database
+// package.
+const DriverName = "mongo"
+
 var (
 	// ErrClientNotFound is returned when the client could not be found.
-	ErrClientNotFound = errors.New("fail to find the client")
+	ErrClientNotFound = database.ErrClientNotFound
 
 	// ErrDocumentNotFound is returned when the document could not be found.
-	ErrDocumentNotFound = errors.New("fail to find the document")
+	ErrDocumentNotFound = database.ErrDocumentNotFound
 )
 
+var (
+	_ database.Database        = (*Client)(nil)
+	_ database.ChangeWatcher   = (*Client)(nil)
+	_ database.LatencyReporter = (*Client)(nil)
+	_ database.Encryptable     = (*Client)(nil)
+	_ database.Leaser          = (*Client)(nil)
+	_ database.PeerSharer      = (*Client)(nil)
+	_ database.SnapshotStager  = (*Client)(nil)
+)
+
+func init() {
+	database.Register(DriverName, func(raw json.RawMessage) (database.Database, error) {
+		conf := &Config{}
+		if err := json.Unmarshal(raw, conf); err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+
+		return NewClient(conf)
+	})
+}
+
 // Config is the configuration for creating a Client instance.
 type Config struct {
 	ConnectionTimeoutSec time.Duration `json:"ConnectionTimeoutSec"`
 	ConnectionURI        string        `json:"ConnectionURI"`
 	YorkieDatabase       string        `json:"YorkieDatabase"`
 	PingTimeoutSec       time.Duration `json:"PingTimeoutSec"`
+
+	// Sharded determines whether the changes and snapshots are sharded by
+	// the document. If it is true, the collections are sharded when
+	// connected to mongos.
+	Sharded bool `json:"Sharded"`
 }
 
 type Client struct {
-	config *Config
-	client *mongo.Client
+	config  *Config
+	client  *mongo.Client
+	latency *stats.Latency
+
+	// transactional is whether the deployment supports multi-document
+	// transactions.
+	transactional bool
+
+	// cipher encrypts the payloads of changes and snapshots if it is set.
+	cipher database.Cipher
 }
 
 func NewClient(conf *Config) (*Client, error) {
@@ -67,7 +111,7 @@ func NewClient(conf *Config) (*Client, error) {
 		options.Client().ApplyURI(conf.ConnectionURI),
 	)
 	if err != nil {
-		log.Logger.Error(err)
+		logger.Error(err)
 		return nil, err
 	}
 
@@ -75,32 +119,55 @@ func NewClient(conf *Config) (*Client, error) {
 	defer cancel()
 
 	if err := client.Ping(ctxPing, readpref.Primary()); err != nil {
-		log.Logger.Errorf("fail to connect to %s in %d sec", conf.ConnectionURI, conf.PingTimeoutSec)
+		logger.Errorf("fail to connect to %s in %d sec", conf.ConnectionURI, conf.PingTimeoutSec)
 		return nil, err
 	}
 
-	if err := ensureIndexes(ctx, client.Database(conf.YorkieDatabase)); err != nil {
-		log.Logger.Error(err)
+	if err := ensureIndexes(ctx, client.Database(conf.YorkieDatabase), conf.Sharded); err != nil {
+		logger.Error(err)
 		return nil, err
 	}
 
-	log.Logger.Infof("connected, URI: %s, DB: %s", conf.ConnectionURI, conf.YorkieDatabase)
+	if conf.Sharded {
+		if err := ensureSharding(ctx, client, conf.YorkieDatabase); err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+	}
+
+	transactional, err := supportsTransactions(ctx, client)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	if !transactional {
+		logger.Warn("transactions are not supported, push-pull is not atomic")
+	}
+
+	logger.Infof("connected, URI: %s, DB: %s", conf.ConnectionURI, conf.YorkieDatabase)
 
 	return &Client{
-		config: conf,
-		client: client,
+		config:        conf,
+		client:        client,
+		latency:       stats.NewLatency(),
+		transactional: transactional,
 	}, nil
 }
 
 func (c *Client) Close() error {
 	if err := c.client.Disconnect(context.Background()); err != nil {
-		log.Logger.Error(err)
+		logger.Error(err)
 		return err
 	}
 
 	return nil
 }
 
+// UseCipher sets the cipher of the payloads of changes and snapshots.
+func (c *Client) UseCipher(cipher database.Cipher) {
+	c.cipher = cipher
+}
+
 func (c *Client) ActivateClient(ctx context.Context, key string) (*types.ClientInfo, error) {
 	clientInfo := types.ClientInfo{}
 	if err := c.withCollection(ColClientInfos, func(col *mongo.Collection) error {
@@ -114,7 +181,7 @@ func (c *Client) ActivateClient(ctx context.Context, key string) (*types.ClientI
 			},
 		}, options.Update().SetUpsert(true))
 		if err != nil {
-			log.Logger.Error(err)
+			logger.Error(err)
 			return err
 		}
 
@@ -134,7 +201,7 @@ func (c *Client) ActivateClient(ctx context.Context, key string) (*types.ClientI
 		}
 
 		if err := result.Decode(&clientInfo); err != nil {
-			log.Logger.Error(err)
+			logger.Error(err)
 			return err
 		}
 
@@ -151,7 +218,7 @@ func (c *Client) DeactivateClient(ctx context.Context, clientID string) (*types.
 	if err := c.withCollection(ColClientInfos, func(col *mongo.Collection) error {
 		id, err := primitive.ObjectIDFromHex(clientID)
 		if err != nil {
-			log.Logger.Error(err)
+			logger.Error(err)
 			return err
 		}
 		res := col.FindOneAndUpdate(ctx, bson.M{
@@ -165,11 +232,11 @@ func (c *Client) DeactivateClient(ctx context.Context, clientID string) (*types.
 
 		if err := res.Decode(&clientInfo); err != nil {
 			if err == mongo.ErrNoDocuments {
-				log.Logger.Error(err)
+				logger.Error(err)
 				return ErrClientNotFound
 			}
 
-			log.Logger.Error(err)
+			logger.Error(err)
 			return err
 		}
 		return nil
@@ -186,7 +253,7 @@ func (c *Client) FindClientInfoByID(ctx context.Context, clientID string) (*type
 	if err := c.withCollection(ColClientInfos, func(col *mongo.Collection) error {
 		id, err := primitive.ObjectIDFromHex(clientID)
 		if err != nil {
-			log.Logger.Error(err)
+			logger.Error(err)
 			return err
 		}
 		result := col.FindOne(ctx, bson.M{
@@ -195,10 +262,35 @@ func (c *Client) FindClientInfoByID(ctx context.Context, clientID string) (*type
 
 		if err := result.Decode(&client); err != nil {
 			if err == mongo.ErrNoDocuments {
-				log.Logger.Error(result.Err())
+				logger.Error(result.Err())
+				return ErrClientNotFound
+			}
+			logger.Error(err)
+			return err
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return &client, nil
+}
+
+// FindClientInfoByKey finds the client of the given key.
+func (c *Client) FindClientInfoByKey(ctx context.Context, key string) (*types.ClientInfo, error) {
+	var client types.ClientInfo
+
+	if err := c.withCollection(ColClientInfos, func(col *mongo.Collection) error {
+		result := col.FindOne(ctx, bson.M{
+			"key": key,
+		})
+
+		if err := result.Decode(&client); err != nil {
+			if err == mongo.ErrNoDocuments {
 				return ErrClientNotFound
 			}
-			log.Logger.Error(err)
+			logger.Error(err)
 			return err
 		}
 
@@ -227,10 +319,37 @@ func (c *Client) UpdateClientInfoAfterPushPull(
 
 		if result.Err() != nil {
 			if result.Err() == mongo.ErrNoDocuments {
-				log.Logger.Error(result.Err())
+				logger.Error(result.Err())
+				return ErrClientNotFound
+			}
+			logger.Error(result.Err())
+			return result.Err()
+		}
+
+		return nil
+	})
+}
+
+// UpdateClientInfoSession updates the session token and the documents of the
+// given client.
+func (c *Client) UpdateClientInfoSession(ctx context.Context, clientInfo *types.ClientInfo) error {
+	return c.withCollection(ColClientInfos, func(col *mongo.Collection) error {
+		result := col.FindOneAndUpdate(ctx, bson.M{
+			"_id": clientInfo.ID,
+		}, bson.M{
+			"$set": bson.M{
+				"session_token": clientInfo.SessionToken,
+				"documents":     clientInfo.Documents,
+				"updated_at":    clientInfo.UpdatedAt,
+			},
+		})
+
+		if result.Err() != nil {
+			if result.Err() == mongo.ErrNoDocuments {
+				logger.Error(result.Err())
 				return ErrClientNotFound
 			}
-			log.Logger.Error(result.Err())
+			logger.Error(result.Err())
 			return result.Err()
 		}
 
@@ -238,6 +357,31 @@ func (c *Client) UpdateClientInfoAfterPushPull(
 	})
 }
 
+// FindDocInfoByID finds the document of the given ID.
+func (c *Client) FindDocInfoByID(ctx context.Context, docID primitive.ObjectID) (*types.DocInfo, error) {
+	var docInfo types.DocInfo
+
+	if err := c.withCollection(ColDocInfos, func(col *mongo.Collection) error {
+		result := col.FindOne(ctx, bson.M{
+			"_id": docID,
+		})
+
+		if err := result.Decode(&docInfo); err != nil {
+			if err == mongo.ErrNoDocuments {
+				return ErrDocumentNotFound
+			}
+			logger.Error(err)
+			return err
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return &docInfo, nil
+}
+
 func (c *Client) FindDocInfoByKey(
 	ctx context.Context,
 	clientInfo *types.ClientInfo,
@@ -256,7 +400,7 @@ func (c *Client) FindDocInfoByKey(
 			},
 		}, options.Update().SetUpsert(createDocIfNotExist))
 		if err != nil {
-			log.Logger.Error(err)
+			logger.Error(err)
 			return err
 		}
 
@@ -275,17 +419,320 @@ func (c *Client) FindDocInfoByKey(
 				"key": bsonDocKey,
 			})
 			if result.Err() == mongo.ErrNoDocuments {
-				log.Logger.Error(err)
+				logger.Error(err)
 				return ErrDocumentNotFound
 			}
 			if result.Err() != nil {
-				log.Logger.Error(result.Err())
+				logger.Error(result.Err())
 				return result.Err()
 			}
 		}
 
 		if err := result.Decode(&docInfo); err != nil {
-			log.Logger.Error(err)
+			logger.Error(err)
+			return err
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return &docInfo, nil
+}
+
+// FindDocInfos returns all documents.
+func (c *Client) FindDocInfos(ctx context.Context) ([]*types.DocInfo, error) {
+	var docInfos []*types.DocInfo
+
+	if err := c.withCollection(ColDocInfos, func(col *mongo.Collection) error {
+		cursor, err := col.Find(ctx, bson.M{})
+		if err != nil {
+			logger.Error(err)
+			return err
+		}
+
+		if err := cursor.All(ctx, &docInfos); err != nil {
+			logger.Error(err)
+			return err
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return docInfos, nil
+}
+
+// FindDocInfosByKeyPrefix returns up to the given number of documents whose
+// BSON keys start with the given prefix and whose labels match the given
+// selector in the order of their keys.
+func (c *Client) FindDocInfosByKeyPrefix(
+	ctx context.Context,
+	bsonKeyPrefix string,
+	selector labels.Selector,
+	limit int,
+) ([]*types.DocInfo, error) {
+	var docInfos []*types.DocInfo
+
+	if err := c.withCollection(ColDocInfos, func(col *mongo.Collection) error {
+		// NOTE: An anchored regex without options can use the index of keys.
+		filter := bson.A{bson.M{
+			"key": bson.M{
+				"$regex": "^" + regexp.QuoteMeta(bsonKeyPrefix),
+			},
+		}}
+		for _, r := range selector {
+			filter = append(filter, toLabelFilter(r))
+		}
+
+		cursor, err := col.Find(ctx, bson.M{
+			"$and": filter,
+		}, options.Find().SetSort(bson.M{"key": 1}).SetLimit(int64(limit)))
+		if err != nil {
+			logger.Error(err)
+			return err
+		}
+
+		if err := cursor.All(ctx, &docInfos); err != nil {
+			logger.Error(err)
+			return err
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return docInfos, nil
+}
+
+// FindInactiveDocInfos returns up to the given number of documents that are
+// not attached to any activated client and have not been updated since the
+// given time.
+func (c *Client) FindInactiveDocInfos(
+	ctx context.Context,
+	updatedBefore time.Time,
+	filter func(docInfo *types.DocInfo) bool,
+	limit int,
+) ([]*types.DocInfo, error) {
+	var docInfos []*types.DocInfo
+
+	if err := c.withCollection(ColDocInfos, func(col *mongo.Collection) error {
+		cursor, err := col.Find(ctx, bson.M{
+			"$or": bson.A{
+				bson.M{"updated_at": bson.M{"$lt": updatedBefore}},
+				bson.M{
+					"updated_at": bson.M{"$exists": false},
+					"created_at": bson.M{"$lt": updatedBefore},
+				},
+			},
+		})
+		if err != nil {
+			logger.Error(err)
+			return err
+		}
+
+		defer func() {
+			if err := cursor.Close(ctx); err != nil {
+				logger.Error(err)
+			}
+		}()
+
+		for len(docInfos) < limit && cursor.Next(ctx) {
+			docInfo := &types.DocInfo{}
+			if err := cursor.Decode(docInfo); err != nil {
+				logger.Error(err)
+				return err
+			}
+
+			if filter != nil && !filter(docInfo) {
+				continue
+			}
+
+			attached, err := c.isDocumentAttached(ctx, docInfo.ID)
+			if err != nil {
+				return err
+			}
+			if !attached {
+				docInfos = append(docInfos, docInfo)
+			}
+		}
+
+		if cursor.Err() != nil {
+			logger.Error(cursor.Err())
+			return cursor.Err()
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return docInfos, nil
+}
+
+// PurgeDocInfo deletes the given document with its changes and snapshots,
+// and removes it from the clients. It returns false without deleting anything
+// if the document has been updated since it was read or is attached to an
+// activated client.
+func (c *Client) PurgeDocInfo(ctx context.Context, docInfo *types.DocInfo) (bool, error) {
+	attached, err := c.isDocumentAttached(ctx, docInfo.ID)
+	if err != nil {
+		return false, err
+	}
+	if attached {
+		return false, nil
+	}
+
+	var deleted bool
+	if err := c.withCollection(ColDocInfos, func(col *mongo.Collection) error {
+		res, err := col.DeleteOne(ctx, bson.M{
+			"_id":        docInfo.ID,
+			"server_seq": docInfo.ServerSeq,
+		})
+		if err != nil {
+			logger.Error(err)
+			return err
+		}
+
+		deleted = res.DeletedCount > 0
+		return nil
+	}); err != nil {
+		return false, err
+	}
+	if !deleted {
+		return false, nil
+	}
+
+	for _, colName := range []string{ColChanges, ColSnapshots} {
+		if err := c.withCollection(colName, func(col *mongo.Collection) error {
+			if _, err := col.DeleteMany(ctx, c.docFilter(docInfo.ID)); err != nil {
+				logger.Error(err)
+				return err
+			}
+
+			return nil
+		}); err != nil {
+			return false, err
+		}
+	}
+
+	for _, colName := range []string{ColTags, ColDeadLetters} {
+		if err := c.withCollection(colName, func(col *mongo.Collection) error {
+			if _, err := col.DeleteMany(ctx, bson.M{"doc_id": docInfo.ID}); err != nil {
+				logger.Error(err)
+				return err
+			}
+
+			return nil
+		}); err != nil {
+			return false, err
+		}
+	}
+
+	if err := c.withCollection(ColClientInfos, func(col *mongo.Collection) error {
+		field := "documents." + docInfo.ID.Hex()
+		if _, err := col.UpdateMany(ctx, bson.M{
+			field: bson.M{"$exists": true},
+		}, bson.M{
+			"$unset": bson.M{field: ""},
+		}); err != nil {
+			logger.Error(err)
+			return err
+		}
+
+		return nil
+	}); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// isDocumentAttached returns whether the given document is attached to an
+// activated client.
+func (c *Client) isDocumentAttached(ctx context.Context, docID primitive.ObjectID) (bool, error) {
+	var attached bool
+	if err := c.withCollection(ColClientInfos, func(col *mongo.Collection) error {
+		n, err := col.CountDocuments(ctx, bson.M{
+			"status":                               types.ClientActivated,
+			"documents." + docID.Hex() + ".status": types.DocumentAttached,
+		}, options.Count().SetLimit(1))
+		if err != nil {
+			logger.Error(err)
+			return err
+		}
+
+		attached = n > 0
+		return nil
+	}); err != nil {
+		return false, err
+	}
+
+	return attached, nil
+}
+
+// UpdateDocACL updates the ACL of the document of the given key.
+func (c *Client) UpdateDocACL(
+	ctx context.Context,
+	bsonDocKey string,
+	acl *auth.ACL,
+) (*types.DocInfo, error) {
+	docInfo := types.DocInfo{}
+
+	if err := c.withCollection(ColDocInfos, func(col *mongo.Collection) error {
+		result := col.FindOneAndUpdate(ctx, bson.M{
+			"key": bsonDocKey,
+		}, bson.M{
+			"$set": bson.M{
+				"acl":        acl,
+				"updated_at": time.Now(),
+			},
+		}, options.FindOneAndUpdate().SetReturnDocument(options.After))
+
+		if err := result.Decode(&docInfo); err != nil {
+			if err == mongo.ErrNoDocuments {
+				logger.Error(err)
+				return ErrDocumentNotFound
+			}
+			logger.Error(err)
+			return err
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return &docInfo, nil
+}
+
+// UpdateDocLabels replaces the labels of the document of the given key.
+func (c *Client) UpdateDocLabels(
+	ctx context.Context,
+	bsonDocKey string,
+	l labels.Labels,
+) (*types.DocInfo, error) {
+	docInfo := types.DocInfo{}
+
+	if err := c.withCollection(ColDocInfos, func(col *mongo.Collection) error {
+		result := col.FindOneAndUpdate(ctx, bson.M{
+			"key": bsonDocKey,
+		}, bson.M{
+			"$set": bson.M{
+				"labels":     l,
+				"updated_at": time.Now(),
+			},
+		}, options.FindOneAndUpdate().SetReturnDocument(options.After))
+
+		if err := result.Decode(&docInfo); err != nil {
+			if err == mongo.ErrNoDocuments {
+				logger.Error(err)
+				return ErrDocumentNotFound
+			}
+			logger.Error(err)
 			return err
 		}
 
@@ -297,6 +744,32 @@ func (c *Client) FindDocInfoByKey(
 	return &docInfo, nil
 }
 
+// UpdateDocBranch updates the branch information of the given document.
+func (c *Client) UpdateDocBranch(
+	ctx context.Context,
+	docID primitive.ObjectID,
+	branch *types.BranchInfo,
+) error {
+	return c.withCollection(ColDocInfos, func(col *mongo.Collection) error {
+		res, err := col.UpdateOne(ctx, bson.M{
+			"_id": docID,
+		}, bson.M{
+			"$set": bson.M{
+				"branch": branch,
+			},
+		})
+		if err != nil {
+			logger.Error(err)
+			return err
+		}
+		if res.MatchedCount == 0 {
+			return ErrDocumentNotFound
+		}
+
+		return nil
+	})
+}
+
 func (c *Client) CreateChangeInfos(
 	ctx context.Context,
 	docID primitive.ObjectID,
@@ -306,25 +779,39 @@ func (c *Client) CreateChangeInfos(
 		return nil
 	}
 
-	return c.withCollection(ColChanges, func(col *mongo.Collection) error {
-		var modelChanges []mongo.WriteModel
+	var modelChanges []mongo.WriteModel
+	for _, ch := range changes {
+		operations, err := database.EncryptPayloads(
+			c.cipher,
+			types.EncodeOperation(ch.Operations()),
+		)
+		if err != nil {
+			return err
+		}
 
-		for _, c := range changes {
-			modelChanges = append(modelChanges, mongo.NewUpdateOneModel().SetFilter(bson.M{
-				"doc_id":     docID,
-				"server_seq": c.ServerSeq(),
-			}).SetUpdate(bson.M{"$set": bson.M{
-				"actor":      types.EncodeActorID(c.ID().Actor()),
-				"client_seq": c.ID().ClientSeq(),
-				"lamport":    c.ID().Lamport(),
-				"message":    c.Message(),
-				"operations": types.EncodeOperation(c.Operations()),
-			}}).SetUpsert(true))
+		var userID, userName string
+		if user := ch.User(); user != nil {
+			userID, userName = user.ID, user.Name
 		}
 
+		filter := c.docFilter(docID)
+		filter["server_seq"] = ch.ServerSeq()
+		modelChanges = append(modelChanges, mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(bson.M{"$set": bson.M{
+			"actor":      types.EncodeActorID(ch.ID().Actor()),
+			"client_seq": ch.ID().ClientSeq(),
+			"lamport":    ch.ID().Lamport(),
+			"message":    ch.Message(),
+			"operations": operations,
+			"user_id":    userID,
+			"user_name":  userName,
+			"metadata":   ch.Metadata(),
+		}}).SetUpsert(true))
+	}
+
+	return c.withCollection(ColChanges, func(col *mongo.Collection) error {
 		_, err := col.BulkWrite(ctx, modelChanges, options.BulkWrite().SetOrdered(true))
 		if err != nil {
-			log.Logger.Error(err)
+			logger.Error(err)
 			return err
 		}
 
@@ -332,24 +819,71 @@ func (c *Client) CreateChangeInfos(
 	})
 }
 
+// PruneChangeInfos deletes the changes of the given document up to the given
+// server sequence and returns the number of deleted changes.
+func (c *Client) PruneChangeInfos(
+	ctx context.Context,
+	docID primitive.ObjectID,
+	serverSeq uint64,
+) (int64, error) {
+	// NOTE: pruned_server_seq is raised before deleting the changes, so that
+	// clients behind it receive a snapshot even if the deletion is interrupted.
+	if err := c.withCollection(ColDocInfos, func(col *mongo.Collection) error {
+		if _, err := col.UpdateOne(ctx, bson.M{
+			"_id": docID,
+		}, bson.M{
+			"$max": bson.M{
+				"pruned_server_seq": serverSeq,
+			},
+		}); err != nil {
+			logger.Error(err)
+			return err
+		}
+
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+
+	var deletedCount int64
+	if err := c.withCollection(ColChanges, func(col *mongo.Collection) error {
+		filter := c.docFilter(docID)
+		filter["server_seq"] = bson.M{
+			"$lte": serverSeq,
+		}
+		res, err := col.DeleteMany(ctx, filter)
+		if err != nil {
+			logger.Error(err)
+			return err
+		}
+
+		deletedCount = res.DeletedCount
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+
+	return deletedCount, nil
+}
+
 func (c *Client) CreateSnapshotInfo(
 	ctx context.Context,
 	docID primitive.ObjectID,
-	doc *document.Document,
+	serverSeq uint64,
+	snapshot []byte,
 ) error {
-	snapshot, err := converter.ObjectToBytes(doc.RootObject())
+	snapshot, err := c.encrypt(snapshot)
 	if err != nil {
 		return err
 	}
 
 	return c.withCollection(ColSnapshots, func(col *mongo.Collection) error {
-		if _, err := col.InsertOne(ctx, bson.M{
-			"doc_id":     docID,
-			"server_seq": doc.Checkpoint().ServerSeq,
-			"snapshot":   snapshot,
-			"created_at": time.Now(),
-		}); err != nil {
-			log.Logger.Error(err)
+		snapshotInfo := c.docFilter(docID)
+		snapshotInfo["server_seq"] = serverSeq
+		snapshotInfo["snapshot"] = snapshot
+		snapshotInfo["created_at"] = time.Now()
+		if _, err := col.InsertOne(ctx, snapshotInfo); err != nil {
+			logger.Error(err)
 			return err
 		}
 
@@ -368,20 +902,53 @@ func (c *Client) UpdateDocInfo(
 		}, bson.M{
 			"$set": bson.M{
 				"server_seq": docInfo.ServerSeq,
+				"encrypted":  docInfo.Encrypted,
 				"updated_at": now,
 			},
 		})
 
 		if err != nil {
 			if err == mongo.ErrNoDocuments {
-				log.Logger.Error(err)
+				logger.Error(err)
 				return ErrDocumentNotFound
 			}
 
-			log.Logger.Error(err)
+			logger.Error(err)
+			return err
+		}
+
+		return nil
+	})
+}
+
+// UpdateDocInfoAfterPushPull updates the given document after a push-pull
+// only if its server sequence is still the given initial one. It returns
+// ErrConflict otherwise.
+func (c *Client) UpdateDocInfoAfterPushPull(
+	ctx context.Context,
+	docInfo *types.DocInfo,
+	initialServerSeq uint64,
+) error {
+	return c.withCollection(ColDocInfos, func(col *mongo.Collection) error {
+		res, err := col.UpdateOne(ctx, bson.M{
+			"_id":        docInfo.ID,
+			"server_seq": initialServerSeq,
+		}, bson.M{
+			"$set": bson.M{
+				"server_seq": docInfo.ServerSeq,
+				"encrypted":  docInfo.Encrypted,
+				"updated_at": time.Now(),
+			},
+		})
+		if err != nil {
+			logger.Error(err)
 			return err
 		}
 
+		if res.MatchedCount == 0 {
+			return database.ErrConflict
+		}
+
 		return nil
 	})
 }
@@ -395,30 +962,35 @@ func (c *Client) FindChangeInfosBetweenServerSeqs(
 	var changes []*change.Change
 
 	if err := c.withCollection(ColChanges, func(col *mongo.Collection) error {
-		cursor, err := col.Find(ctx, bson.M{
-			"doc_id": docID,
-			"server_seq": bson.M{
-				"$gte": from,
-				"$lte": to,
-			},
-		}, options.Find())
+		filter := c.docFilter(docID)
+		filter["server_seq"] = bson.M{
+			"$gte": from,
+			"$lte": to,
+		}
+		cursor, err := col.Find(ctx, filter, options.Find())
 		if err != nil {
-			log.Logger.Error(err)
+			logger.Error(err)
 			return err
 		}
 
 		defer func() {
 			if err := cursor.Close(ctx); err != nil {
-				log.Logger.Error(err)
+				logger.Error(err)
 			}
 		}()
 
 		for cursor.Next(ctx) {
 			var changeInfo types.ChangeInfo
 			if err := cursor.Decode(&changeInfo); err != nil {
-				log.Logger.Error(err)
+				logger.Error(err)
+				return err
+			}
+
+			operations, err := database.DecryptPayloads(c.cipher, changeInfo.Operations)
+			if err != nil {
 				return err
 			}
+			changeInfo.Operations = operations
 
 			c, err := changeInfo.ToChange()
 			if err != nil {
@@ -428,7 +1000,7 @@ func (c *Client) FindChangeInfosBetweenServerSeqs(
 		}
 
 		if cursor.Err() != nil {
-			log.Logger.Error(cursor.Err())
+			logger.Error(cursor.Err())
 			return cursor.Err()
 		}
 
@@ -440,10 +1012,90 @@ func (c *Client) FindChangeInfosBetweenServerSeqs(
 	return changes, nil
 }
 
+// Latency returns the latency of the recent requests to MongoDB.
+func (c *Client) Latency() *stats.Latency {
+	return c.latency
+}
+
+// CountActivatedClients returns the number of activated clients.
+func (c *Client) CountActivatedClients(ctx context.Context) (int64, error) {
+	var count int64
+	if err := c.withCollection(ColClientInfos, func(col *mongo.Collection) error {
+		n, err := col.CountDocuments(ctx, bson.M{
+			"status": types.ClientActivated,
+		})
+		if err != nil {
+			logger.Error(err)
+			return err
+		}
+
+		count = n
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// CountAttachedDocuments returns the number of documents attached to the
+// activated clients.
+func (c *Client) CountAttachedDocuments(ctx context.Context) (int64, error) {
+	var count int64
+	if err := c.withCollection(ColClientInfos, func(col *mongo.Collection) error {
+		cursor, err := col.Aggregate(ctx, mongo.Pipeline{
+			{{Key: "$match", Value: bson.M{"status": types.ClientActivated}}},
+			{{Key: "$project", Value: bson.M{
+				"documents": bson.M{"$objectToArray": "$documents"},
+			}}},
+			{{Key: "$unwind", Value: "$documents"}},
+			{{Key: "$match", Value: bson.M{"documents.v.status": types.DocumentAttached}}},
+			{{Key: "$count", Value: "count"}},
+		})
+		if err != nil {
+			logger.Error(err)
+			return err
+		}
+
+		defer func() {
+			if err := cursor.Close(ctx); err != nil {
+				logger.Error(err)
+			}
+		}()
+
+		if cursor.Next(ctx) {
+			var result struct {
+				Count int64 `bson:"count"`
+			}
+			if err := cursor.Decode(&result); err != nil {
+				logger.Error(err)
+				return err
+			}
+			count = result.Count
+		}
+
+		if cursor.Err() != nil {
+			logger.Error(cursor.Err())
+			return cursor.Err()
+		}
+
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
 func (c *Client) withCollection(
 	collection string,
 	callback func(collection *mongo.Collection) error,
 ) error {
+	start := time.Now()
+	defer func() {
+		c.latency.Record(time.Since(start))
+	}()
+
 	col := c.client.Database(c.config.YorkieDatabase).Collection(collection)
 	return callback(col)
 }
@@ -455,9 +1107,7 @@ func (c *Client) FindLastSnapshotInfo(
 	snapshotInfo := &types.SnapshotInfo{}
 
 	if err := c.withCollection(ColSnapshots, func(col *mongo.Collection) error {
-		result := col.FindOne(ctx, bson.M{
-			"doc_id": docID,
-		}, options.FindOne().SetSort(bson.M{
+		result := col.FindOne(ctx, c.docFilter(docID), options.FindOne().SetSort(bson.M{
 			"server_seq": -1,
 		}))
 
@@ -466,15 +1116,21 @@ func (c *Client) FindLastSnapshotInfo(
 		}
 
 		if result.Err() != nil {
-			log.Logger.Error(result.Err())
+			logger.Error(result.Err())
 			return result.Err()
 		}
 
 		if err := result.Decode(&snapshotInfo); err != nil {
-			log.Logger.Error(err)
+			logger.Error(err)
 			return err
 		}
 
+		snapshot, err := c.decrypt(snapshotInfo.Snapshot)
+		if err != nil {
+			return err
+		}
+		snapshotInfo.Snapshot = snapshot
+
 		return nil
 	}); err != nil && err != mongo.ErrNoDocuments {
 		return nil, err
@@ -482,3 +1138,35 @@ func (c *Client) FindLastSnapshotInfo(
 
 	return snapshotInfo, nil
 }
+
+// encrypt encrypts the given payload if a cipher is set.
+func (c *Client) encrypt(payload []byte) ([]byte, error) {
+	if c.cipher == nil {
+		return payload, nil
+	}
+	return c.cipher.Encrypt(payload)
+}
+
+// decrypt decrypts the given payload if a cipher is set.
+func (c *Client) decrypt(payload []byte) ([]byte, error) {
+	if c.cipher == nil {
+		return payload, nil
+	}
+	return c.cipher.Decrypt(payload)
+}
+
+// toLabelFilter converts the given requirement of a label selector to the
+// filter of documents. Labels are stored as the fields of "labels".
+func toLabelFilter(r labels.Requirement) bson.M {
+	field := "labels." + r.Key
+	switch r.Operator {
+	case labels.Equals:
+		return bson.M{field: r.Value}
+	case labels.NotEquals:
+		return bson.M{field: bson.M{"$ne": r.Value}}
+	case labels.Exists:
+		return bson.M{field: bson.M{"$exists": true}}
+	default:
+		return bson.M{field: bson.M{"$exists": false}}
+	}
+}
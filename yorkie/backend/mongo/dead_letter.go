@@ -0,0 +1,111 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/yorkie-team/yorkie/pkg/document/change"
+	"github.com/yorkie-team/yorkie/yorkie/backend/database"
+	"github.com/yorkie-team/yorkie/yorkie/types"
+)
+
+// CreateDeadLetterInfo quarantines the given change of the given document
+// which could not be applied for the given cause.
+func (c *Client) CreateDeadLetterInfo(
+	ctx context.Context,
+	docID primitive.ObjectID,
+	ch *change.Change,
+	cause string,
+) (bool, error) {
+	changeInfo := types.NewChangeInfo(docID, ch)
+	operations, err := database.EncryptPayloads(c.cipher, changeInfo.Operations)
+	if err != nil {
+		return false, err
+	}
+	changeInfo.Operations = operations
+
+	created := false
+	if err := c.withCollection(ColDeadLetters, func(col *mongo.Collection) error {
+		if _, err := col.InsertOne(ctx, &types.DeadLetterInfo{
+			ID:        primitive.NewObjectID(),
+			DocID:     docID,
+			ServerSeq: ch.ServerSeq(),
+			Change:    changeInfo,
+			Cause:     cause,
+			CreatedAt: time.Now(),
+		}); err != nil {
+			if isDuplicateKeyError(err) {
+				return nil
+			}
+			logger.Error(err)
+			return err
+		}
+
+		created = true
+		return nil
+	}); err != nil {
+		return false, err
+	}
+
+	return created, nil
+}
+
+// FindDeadLetterInfos returns the quarantined changes of the given document.
+func (c *Client) FindDeadLetterInfos(
+	ctx context.Context,
+	docID primitive.ObjectID,
+) ([]*types.DeadLetterInfo, error) {
+	var deadLetterInfos []*types.DeadLetterInfo
+
+	if err := c.withCollection(ColDeadLetters, func(col *mongo.Collection) error {
+		cursor, err := col.Find(ctx, bson.M{
+			"doc_id": docID,
+		}, options.Find().SetSort(bson.M{
+			"server_seq": 1,
+		}))
+		if err != nil {
+			logger.Error(err)
+			return err
+		}
+
+		if err := cursor.All(ctx, &deadLetterInfos); err != nil {
+			logger.Error(err)
+			return err
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	for _, info := range deadLetterInfos {
+		operations, err := database.DecryptPayloads(c.cipher, info.Change.Operations)
+		if err != nil {
+			return nil, err
+		}
+		info.Change.Operations = operations
+	}
+
+	return deadLetterInfos, nil
+}
@@ -22,8 +22,6 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/x/bsonx"
-
-	"github.com/yorkie-team/yorkie/pkg/log"
 )
 
 var (
@@ -37,6 +35,9 @@ var (
 	idxDocInfos = []mongo.IndexModel{{
 		Keys:    bsonx.Doc{{Key: "key", Value: bsonx.Int32(1)}},
 		Options: options.Index().SetUnique(true),
+	}, {
+		// NOTE: updated_at is used by housekeeping to find inactive documents.
+		Keys: bsonx.Doc{{Key: "updated_at", Value: bsonx.Int32(1)}},
 	}}
 
 	ColChanges = "changes"
@@ -56,14 +57,71 @@ var (
 		},
 		Options: options.Index().SetUnique(true),
 	}}
+
+	ColTags = "tags"
+	idxTags = []mongo.IndexModel{{
+		Keys: bsonx.Doc{
+			{Key: "doc_id", Value: bsonx.Int32(1)},
+			{Key: "name", Value: bsonx.Int32(1)},
+		},
+		Options: options.Index().SetUnique(true),
+	}}
+
+	ColDeadLetters = "dead_letters"
+	idxDeadLetters = []mongo.IndexModel{{
+		Keys: bsonx.Doc{
+			{Key: "doc_id", Value: bsonx.Int32(1)},
+			{Key: "server_seq", Value: bsonx.Int32(1)},
+		},
+		Options: options.Index().SetUnique(true),
+	}}
+
+	ColPeers = "peers"
+	idxPeers = []mongo.IndexModel{{
+		Keys: bsonx.Doc{
+			{Key: "agent_id", Value: bsonx.Int32(1)},
+			{Key: "doc_key", Value: bsonx.Int32(1)},
+			{Key: "client_id", Value: bsonx.Int32(1)},
+		},
+		Options: options.Index().SetUnique(true),
+	}, {
+		Keys: bsonx.Doc{{Key: "doc_key", Value: bsonx.Int32(1)}},
+	}}
+
+	ColStagedSnapshots = "staged_snapshots"
+	idxStagedSnapshots = []mongo.IndexModel{{
+		// NOTE: The snapshots are deleted by MongoDB once they expire.
+		Keys:    bsonx.Doc{{Key: "expires_at", Value: bsonx.Int32(1)}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	}}
+
+	// idxShardedDocChanges is the index of the changes and snapshots when
+	// sharding is enabled. A unique index of a sharded collection must be
+	// prefixed by the shard key, so it replaces the index on doc_id and
+	// server_seq, and it also serves as the index of the shard key on each
+	// shard. See shardKey for the choice of the key.
+	idxShardedDocChanges = []mongo.IndexModel{{
+		Keys: bsonx.Doc{
+			{Key: "shard_prefix", Value: bsonx.Int32(1)},
+			{Key: "doc_id", Value: bsonx.Int32(1)},
+			{Key: "server_seq", Value: bsonx.Int32(1)},
+		},
+		Options: options.Index().SetUnique(true),
+	}}
 )
 
-func ensureIndexes(ctx context.Context, db *mongo.Database) error {
+// ensureIndexes creates the indexes of the collections. If sharded is true,
+// the changes and snapshots are indexed by the shard key instead.
+//
+// NOTE: Sharding can't be enabled for the existing changes and snapshots,
+// which have neither the shard prefix nor an index compatible with the shard
+// key. They should be migrated to new collections before enabling it.
+func ensureIndexes(ctx context.Context, db *mongo.Database, sharded bool) error {
 	if _, err := db.Collection(ColClientInfos).Indexes().CreateMany(
 		ctx,
 		idxClientInfos,
 	); err != nil {
-		log.Logger.Error(err)
+		logger.Error(err)
 		return err
 	}
 
@@ -71,23 +129,60 @@ func ensureIndexes(ctx context.Context, db *mongo.Database) error {
 		ctx,
 		idxDocInfos,
 	); err != nil {
-		log.Logger.Error(err)
+		logger.Error(err)
 		return err
 	}
 
+	changesIndexes, snapshotsIndexes := idxChanges, idxSnapshots
+	if sharded {
+		changesIndexes, snapshotsIndexes = idxShardedDocChanges, idxShardedDocChanges
+	}
+
 	if _, err := db.Collection(ColChanges).Indexes().CreateMany(
 		ctx,
-		idxChanges,
+		changesIndexes,
 	); err != nil {
-		log.Logger.Error(err)
+		logger.Error(err)
 		return err
 	}
 
 	if _, err := db.Collection(ColSnapshots).Indexes().CreateMany(
 		ctx,
-		idxSnapshots,
+		snapshotsIndexes,
+	); err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	if _, err := db.Collection(ColTags).Indexes().CreateMany(
+		ctx,
+		idxTags,
+	); err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	if _, err := db.Collection(ColDeadLetters).Indexes().CreateMany(
+		ctx,
+		idxDeadLetters,
+	); err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	if _, err := db.Collection(ColPeers).Indexes().CreateMany(
+		ctx,
+		idxPeers,
+	); err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	if _, err := db.Collection(ColStagedSnapshots).Indexes().CreateMany(
+		ctx,
+		idxStagedSnapshots,
 	); err != nil {
-		log.Logger.Error(err)
+		logger.Error(err)
 		return err
 	}
 
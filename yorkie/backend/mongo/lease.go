@@ -0,0 +1,104 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ColLeases is the collection of the leases used to elect the leader of the
+// agents.
+var ColLeases = "leases"
+
+// duplicateKeyErrorCode is the code of the error of MongoDB returned when a
+// document violates a unique index.
+const duplicateKeyErrorCode = 11000
+
+// TryLease acquires the lease of the given name for the given holder, or
+// renews it if the holder already has it.
+//
+// NOTE: The expiration is decided by the clock of each agent, so the clocks
+// of the agents should be synchronized within a fraction of the duration.
+func (c *Client) TryLease(ctx context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	acquired := false
+	if err := c.withCollection(ColLeases, func(col *mongo.Collection) error {
+		now := time.Now()
+		_, err := col.UpdateOne(ctx, bson.M{
+			"_id": name,
+			"$or": bson.A{
+				bson.M{"holder": holder},
+				bson.M{"expires_at": bson.M{"$lt": now}},
+			},
+		}, bson.M{
+			"$set": bson.M{
+				"holder":     holder,
+				"expires_at": now.Add(ttl),
+			},
+		}, options.Update().SetUpsert(true))
+		if err != nil {
+			// NOTE: The upsert fails with the duplicate key error if another
+			// holder has the lease.
+			if isDuplicateKeyError(err) {
+				return nil
+			}
+			logger.Error(err)
+			return err
+		}
+
+		acquired = true
+		return nil
+	}); err != nil {
+		return false, err
+	}
+
+	return acquired, nil
+}
+
+// ReleaseLease releases the lease of the given name if the given holder has
+// it.
+func (c *Client) ReleaseLease(ctx context.Context, name, holder string) error {
+	return c.withCollection(ColLeases, func(col *mongo.Collection) error {
+		if _, err := col.DeleteOne(ctx, bson.M{
+			"_id":    name,
+			"holder": holder,
+		}); err != nil {
+			logger.Error(err)
+			return err
+		}
+
+		return nil
+	})
+}
+
+func isDuplicateKeyError(err error) bool {
+	switch err := err.(type) {
+	case mongo.WriteException:
+		for _, writeErr := range err.WriteErrors {
+			if writeErr.Code == duplicateKeyErrorCode {
+				return true
+			}
+		}
+	case mongo.CommandError:
+		return err.Code == duplicateKeyErrorCode
+	}
+	return false
+}
@@ -0,0 +1,102 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/yorkie-team/yorkie/yorkie/types"
+)
+
+// ReplacePeers replaces the peers registered by the given agent with the
+// given ones.
+func (c *Client) ReplacePeers(ctx context.Context, agentID string, peers []*types.PeerInfo) error {
+	return c.withCollection(ColPeers, func(col *mongo.Collection) error {
+		// NOTE: The peers are upserted before the stale ones are deleted, so
+		// that the other agents never see the peers of this agent vanish.
+		replacedAt := time.Now()
+		if len(peers) > 0 {
+			var models []mongo.WriteModel
+			for _, peer := range peers {
+				models = append(models, mongo.NewUpdateOneModel().SetFilter(bson.M{
+					"agent_id":  agentID,
+					"doc_key":   peer.DocKey,
+					"client_id": peer.ClientID,
+				}).SetUpdate(bson.M{
+					"$set": bson.M{
+						"presence":    peer.Presence,
+						"watching":    peer.Watching,
+						"last_seen":   peer.LastSeen,
+						"replaced_at": replacedAt,
+					},
+				}).SetUpsert(true))
+			}
+			if _, err := col.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false)); err != nil {
+				logger.Error(err)
+				return err
+			}
+		}
+
+		if _, err := col.DeleteMany(ctx, bson.M{
+			"agent_id":    agentID,
+			"replaced_at": bson.M{"$lt": replacedAt},
+		}); err != nil {
+			logger.Error(err)
+			return err
+		}
+
+		return nil
+	})
+}
+
+// FindPeers returns the peers of the document of the given key registered by
+// the agents other than the given one since the given time.
+func (c *Client) FindPeers(
+	ctx context.Context,
+	bsonDocKey string,
+	agentID string,
+	since time.Time,
+) ([]*types.PeerInfo, error) {
+	var peers []*types.PeerInfo
+	if err := c.withCollection(ColPeers, func(col *mongo.Collection) error {
+		cursor, err := col.Find(ctx, bson.M{
+			"doc_key":     bsonDocKey,
+			"agent_id":    bson.M{"$ne": agentID},
+			"replaced_at": bson.M{"$gte": since},
+		})
+		if err != nil {
+			logger.Error(err)
+			return err
+		}
+
+		if err := cursor.All(ctx, &peers); err != nil {
+			logger.Error(err)
+			return err
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return peers, nil
+}
@@ -0,0 +1,137 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mongo
+
+import (
+	"context"
+	"hash/fnv"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// shardPrefixBuckets is the number of hashed prefixes. It bounds the number
+// of chunks the changes and snapshots can be split into, so it should be
+// much larger than the number of shards.
+const shardPrefixBuckets = 4096
+
+// codeAlreadyInitialized is the code of the error returned by older servers
+// when sharding is already enabled for the database.
+const codeAlreadyInitialized = 23
+
+// shardedCollections are the collections sharded by the document. Clients and
+// documents are small per document and stay on the primary shard.
+var shardedCollections = []string{ColChanges, ColSnapshots}
+
+// shardKey is the shard key of the sharded collections.
+//
+// Document IDs are ObjectIDs, which grow monotonically, so a ranged shard key
+// on doc_id would send the writes of all new documents to the last chunk. The
+// hashed prefix spreads the documents evenly across the chunks, while
+// doc_id and server_seq keep the changes of a document in a single chunk, so
+// that the queries of a document are routed to a single shard.
+var shardKey = bson.D{
+	{Key: "shard_prefix", Value: 1},
+	{Key: "doc_id", Value: 1},
+	{Key: "server_seq", Value: 1},
+}
+
+// shardPrefix returns the hashed prefix of the given document.
+func shardPrefix(docID primitive.ObjectID) int32 {
+	h := fnv.New32a()
+	_, _ = h.Write(docID[:])
+	return int32(h.Sum32() % shardPrefixBuckets)
+}
+
+// docFilter returns the filter of the changes and snapshots of the given
+// document. It includes the shard key prefix if sharding is enabled, so that
+// the query is routed to the shard of the document.
+func (c *Client) docFilter(docID primitive.ObjectID) bson.M {
+	if !c.config.Sharded {
+		return bson.M{"doc_id": docID}
+	}
+
+	return bson.M{
+		"shard_prefix": shardPrefix(docID),
+		"doc_id":       docID,
+	}
+}
+
+// ensureSharding enables sharding for the database and shards the changes and
+// snapshots by shardKey. It is skipped if the deployment is not a sharded
+// cluster, because the indexes are already compatible with the shard key and
+// the collections can be sharded later.
+func ensureSharding(ctx context.Context, client *mongo.Client, dbName string) error {
+	result, err := isMaster(ctx, client)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+	if result.Msg != "isdbgrid" {
+		logger.Warn("sharding is enabled, but not connected to mongos")
+		return nil
+	}
+
+	admin := client.Database("admin")
+	if err := admin.RunCommand(ctx, bson.D{
+		{Key: "enableSharding", Value: dbName},
+	}).Err(); err != nil {
+		if cmdErr, ok := err.(mongo.CommandError); !ok || cmdErr.Code != codeAlreadyInitialized {
+			logger.Error(err)
+			return err
+		}
+	}
+
+	for _, col := range shardedCollections {
+		ns := dbName + "." + col
+		sharded, err := isSharded(ctx, client, ns)
+		if err != nil {
+			return err
+		}
+		if sharded {
+			continue
+		}
+
+		if err := admin.RunCommand(ctx, bson.D{
+			{Key: "shardCollection", Value: ns},
+			{Key: "key", Value: shardKey},
+			{Key: "unique", Value: true},
+		}).Err(); err != nil {
+			logger.Error(err)
+			return err
+		}
+
+		logger.Infof("sharded %s", ns)
+	}
+
+	return nil
+}
+
+// isSharded returns whether the collection of the given namespace is sharded.
+func isSharded(ctx context.Context, client *mongo.Client, ns string) (bool, error) {
+	count, err := client.Database("config").Collection("collections").CountDocuments(ctx, bson.M{
+		"_id":     ns,
+		"dropped": bson.M{"$ne": true},
+	})
+	if err != nil {
+		logger.Error(err)
+		return false, err
+	}
+
+	return count > 0, nil
+}
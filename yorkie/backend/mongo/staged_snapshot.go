@@ -0,0 +1,108 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/yorkie-team/yorkie/yorkie/backend/database"
+)
+
+// StageSnapshot keeps the given snapshot for the given client until the given
+// time, and returns its ID.
+func (c *Client) StageSnapshot(
+	ctx context.Context,
+	clientID string,
+	snapshot []byte,
+	expiresAt time.Time,
+) (string, error) {
+	id := primitive.NewObjectID()
+	if err := c.withCollection(ColStagedSnapshots, func(col *mongo.Collection) error {
+		if _, err := col.InsertOne(ctx, bson.M{
+			"_id":        id,
+			"client_id":  clientID,
+			"snapshot":   snapshot,
+			"expires_at": expiresAt,
+		}); err != nil {
+			logger.Error(err)
+			return err
+		}
+
+		return nil
+	}); err != nil {
+		return "", err
+	}
+
+	return id.Hex(), nil
+}
+
+// FindStagedSnapshot returns the snapshot of the given ID kept for the given
+// client.
+func (c *Client) FindStagedSnapshot(ctx context.Context, clientID string, id string) ([]byte, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, database.ErrStagedSnapshotNotFound
+	}
+
+	var staged struct {
+		Snapshot []byte `bson:"snapshot"`
+	}
+	if err := c.withCollection(ColStagedSnapshots, func(col *mongo.Collection) error {
+		// NOTE: MongoDB deletes the expired snapshots periodically, so they
+		// are filtered out until then.
+		result := col.FindOne(ctx, bson.M{
+			"_id":        objectID,
+			"client_id":  clientID,
+			"expires_at": bson.M{"$gt": time.Now()},
+		})
+		if err := result.Decode(&staged); err != nil {
+			if err == mongo.ErrNoDocuments {
+				return database.ErrStagedSnapshotNotFound
+			}
+			logger.Error(err)
+			return err
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return staged.Snapshot, nil
+}
+
+// DeleteStagedSnapshot deletes the snapshot of the given ID.
+func (c *Client) DeleteStagedSnapshot(ctx context.Context, id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return database.ErrStagedSnapshotNotFound
+	}
+
+	return c.withCollection(ColStagedSnapshots, func(col *mongo.Collection) error {
+		if _, err := col.DeleteOne(ctx, bson.M{"_id": objectID}); err != nil {
+			logger.Error(err)
+			return err
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,143 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/yorkie-team/yorkie/yorkie/backend/database"
+	"github.com/yorkie-team/yorkie/yorkie/types"
+)
+
+// CreateTagInfo tags the given snapshot of the given document at the given
+// server sequence with the given name.
+func (c *Client) CreateTagInfo(
+	ctx context.Context,
+	docID primitive.ObjectID,
+	name string,
+	serverSeq uint64,
+	snapshot []byte,
+) (*types.TagInfo, error) {
+	encrypted, err := c.encrypt(snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	tagInfo := &types.TagInfo{
+		ID:        primitive.NewObjectID(),
+		DocID:     docID,
+		Name:      name,
+		ServerSeq: serverSeq,
+		Snapshot:  encrypted,
+		CreatedAt: time.Now(),
+	}
+
+	if err := c.withCollection(ColTags, func(col *mongo.Collection) error {
+		if _, err := col.InsertOne(ctx, tagInfo); err != nil {
+			if isDuplicateKeyError(err) {
+				return database.ErrTagAlreadyExists
+			}
+			logger.Error(err)
+			return err
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	tagInfo.Snapshot = snapshot
+	return tagInfo, nil
+}
+
+// FindTagInfos returns the tags of the given document without their
+// snapshots.
+func (c *Client) FindTagInfos(ctx context.Context, docID primitive.ObjectID) ([]*types.TagInfo, error) {
+	var tagInfos []*types.TagInfo
+
+	if err := c.withCollection(ColTags, func(col *mongo.Collection) error {
+		cursor, err := col.Find(ctx, bson.M{
+			"doc_id": docID,
+		}, options.Find().SetSort(bson.D{
+			{Key: "server_seq", Value: 1},
+			{Key: "created_at", Value: 1},
+		}).SetProjection(bson.M{
+			"snapshot": 0,
+		}))
+		if err != nil {
+			logger.Error(err)
+			return err
+		}
+
+		if err := cursor.All(ctx, &tagInfos); err != nil {
+			logger.Error(err)
+			return err
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return tagInfos, nil
+}
+
+// FindTagInfoByName returns the tag of the given name of the given document.
+func (c *Client) FindTagInfoByName(
+	ctx context.Context,
+	docID primitive.ObjectID,
+	name string,
+) (*types.TagInfo, error) {
+	tagInfo := &types.TagInfo{}
+
+	if err := c.withCollection(ColTags, func(col *mongo.Collection) error {
+		result := col.FindOne(ctx, bson.M{
+			"doc_id": docID,
+			"name":   name,
+		})
+		if result.Err() == mongo.ErrNoDocuments {
+			return database.ErrTagNotFound
+		}
+		if result.Err() != nil {
+			logger.Error(result.Err())
+			return result.Err()
+		}
+
+		if err := result.Decode(tagInfo); err != nil {
+			logger.Error(err)
+			return err
+		}
+
+		snapshot, err := c.decrypt(tagInfo.Snapshot)
+		if err != nil {
+			return err
+		}
+		tagInfo.Snapshot = snapshot
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return tagInfo, nil
+}
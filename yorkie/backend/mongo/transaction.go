@@ -0,0 +1,85 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mongo
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// isMasterResult is the result of the isMaster command.
+type isMasterResult struct {
+	SetName string `bson:"setName"`
+	Msg     string `bson:"msg"`
+}
+
+// isMaster runs the isMaster command, which describes the role of the
+// connected server.
+func isMaster(ctx context.Context, client *mongo.Client) (*isMasterResult, error) {
+	result := &isMasterResult{}
+	if err := client.Database("admin").RunCommand(
+		ctx,
+		bson.D{{Key: "isMaster", Value: 1}},
+	).Decode(result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// supportsTransactions returns whether the connected deployment supports
+// multi-document transactions. Transactions are only available on replica set
+// members and mongos, not on standalone servers.
+func supportsTransactions(ctx context.Context, client *mongo.Client) (bool, error) {
+	result, err := isMaster(ctx, client)
+	if err != nil {
+		return false, err
+	}
+
+	return result.SetName != "" || result.Msg == "isdbgrid", nil
+}
+
+// WithTransaction runs the given function in a multi-document transaction.
+// The context passed to the function must be used for the operations that
+// belong to the transaction. On standalone servers, which don't support
+// transactions, the function is run without a transaction.
+func (c *Client) WithTransaction(
+	ctx context.Context,
+	fn func(ctx context.Context) error,
+) error {
+	if !c.transactional {
+		return fn(ctx)
+	}
+
+	session, err := c.client.StartSession()
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	if _, err := session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sessCtx)
+	}); err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,145 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/yorkie-team/yorkie/yorkie/backend/database"
+	"github.com/yorkie-team/yorkie/yorkie/presence"
+	"github.com/yorkie-team/yorkie/yorkie/types"
+)
+
+// presenceKeeper expires the peers of the registry of this agent and shares
+// them with the other agents periodically.
+type presenceKeeper struct {
+	agentID  string
+	ttl      time.Duration
+	registry *presence.Registry
+
+	// sharer shares the peers through the database if presence sharing is
+	// enabled.
+	sharer database.PeerSharer
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// start starts expiring and sharing the peers at half the TTL, so that the
+// shared peers are refreshed before the other agents consider them gone.
+func (k *presenceKeeper) start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	k.cancel = cancel
+	k.done = make(chan struct{})
+
+	go func() {
+		defer close(k.done)
+
+		ticker := time.NewTicker(k.ttl / 2)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				k.registry.Expire()
+				if err := k.share(ctx); err != nil && ctx.Err() == nil {
+					logger.Error(err)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// stop stops the keeper and waits for it to finish.
+func (k *presenceKeeper) stop() {
+	if k.cancel == nil {
+		return
+	}
+
+	k.cancel()
+	<-k.done
+}
+
+// share replaces the peers of this agent in the database with the peers of
+// the registry.
+func (k *presenceKeeper) share(ctx context.Context) error {
+	if k.sharer == nil {
+		return nil
+	}
+
+	var infos []*types.PeerInfo
+	for docKey, peers := range k.registry.All() {
+		for _, peer := range peers {
+			infos = append(infos, &types.PeerInfo{
+				AgentID:  k.agentID,
+				DocKey:   docKey,
+				ClientID: peer.ClientID,
+				Presence: peer.Presence,
+				Watching: peer.Watching,
+				LastSeen: peer.LastSeen,
+			})
+		}
+	}
+
+	return k.sharer.ReplacePeers(ctx, k.agentID, infos)
+}
+
+// peers returns the peers of the given document connected to any agent. The
+// peers connected to the other agents are seen once they are shared, so they
+// can be listed up to half the TTL late.
+func (k *presenceKeeper) peers(ctx context.Context, docKey string) ([]*presence.Peer, error) {
+	peers := k.registry.Peers(docKey)
+	if k.sharer == nil {
+		return peers, nil
+	}
+
+	infos, err := k.sharer.FindPeers(ctx, docKey, k.agentID, time.Now().Add(-k.ttl))
+	if err != nil {
+		return nil, err
+	}
+
+	// NOTE: A client can be connected to several agents, e.g. attaching the
+	// document through one and watching it through another.
+	byClientID := make(map[string]*presence.Peer, len(peers))
+	for _, peer := range peers {
+		byClientID[peer.ClientID] = peer
+	}
+	for _, info := range infos {
+		peer, ok := byClientID[info.ClientID]
+		if !ok {
+			peer = &presence.Peer{ClientID: info.ClientID}
+			byClientID[info.ClientID] = peer
+			peers = append(peers, peer)
+		}
+
+		peer.Watching = peer.Watching || info.Watching
+		if info.LastSeen.After(peer.LastSeen) {
+			peer.Presence = info.Presence
+			peer.LastSeen = info.LastSeen
+		}
+	}
+
+	sort.Slice(peers, func(i, j int) bool {
+		return peers[i].ClientID < peers[j].ClientID
+	})
+
+	return peers, nil
+}
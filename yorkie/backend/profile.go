@@ -0,0 +1,89 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"errors"
+	"fmt"
+	time2 "time"
+
+	"github.com/yorkie-team/yorkie/yorkie/auth"
+)
+
+// ErrInvalidProfile is returned when a profile of a collection is invalid.
+var ErrInvalidProfile = errors.New("invalid profile")
+
+// Profile is the configuration of the documents of a collection. Its fields
+// override the configuration of the agent for the collection, and the zero
+// values of them leave the configuration of the agent as it is.
+type Profile struct {
+	// SnapshotThreshold is the threshold that determines if changes should be
+	// sent with snapshot.
+	SnapshotThreshold uint64 `json:"SnapshotThreshold"`
+
+	// DocumentTTLSec is the period after which inactive documents are purged
+	// by housekeeping. The documents are never purged if it is zero.
+	DocumentTTLSec *time2.Duration `json:"DocumentTTLSec"`
+
+	// AnonymousAccess is the access granted to the clients without access
+	// tokens, e.g. "read-only" for public documents. Access tokens are
+	// required if it is empty. It is used only if access tokens are verified.
+	AnonymousAccess auth.Access `json:"AnonymousAccess"`
+}
+
+// Validate validates this profile.
+func (p *Profile) Validate() error {
+	if p.AnonymousAccess != "" && !p.AnonymousAccess.Valid() {
+		return fmt.Errorf("anonymous access %q: %w", p.AnonymousAccess, ErrInvalidProfile)
+	}
+
+	return nil
+}
+
+// ProfileOf returns the profile of the given collection. It returns an empty
+// profile if the collection has no profile.
+func (c *Config) ProfileOf(collection string) *Profile {
+	if profile, ok := c.Profiles[collection]; ok && profile != nil {
+		return profile
+	}
+
+	return &Profile{}
+}
+
+// SnapshotThresholdOf returns the snapshot threshold of the given collection.
+func (c *Config) SnapshotThresholdOf(collection string) uint64 {
+	if threshold := c.ProfileOf(collection).SnapshotThreshold; threshold != 0 {
+		return threshold
+	}
+
+	return c.SnapshotThreshold
+}
+
+// validateProfiles validates the profiles of the given configuration.
+func validateProfiles(conf *Config) error {
+	for collection, profile := range conf.Profiles {
+		if profile == nil {
+			continue
+		}
+
+		if err := profile.Validate(); err != nil {
+			return fmt.Errorf("collection %s: %w", collection, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"sync"
+
+	"github.com/yorkie-team/yorkie/api/converter"
+)
+
+// maxSnapshotEncoders is the maximum number of documents whose snapshot
+// encoders are kept in memory.
+const maxSnapshotEncoders = 1000
+
+// snapshotEncoders keeps the snapshot encoders of recently snapshotted
+// documents to reuse the encoded subtrees of their last snapshots.
+type snapshotEncoders struct {
+	mu       sync.Mutex
+	encoders map[string]*converter.SnapshotEncoder
+}
+
+func newSnapshotEncoders() *snapshotEncoders {
+	return &snapshotEncoders{
+		encoders: make(map[string]*converter.SnapshotEncoder),
+	}
+}
+
+// get returns the encoder of the given document whose last snapshot is of the
+// given server sequence. If the encoder is missing or stale, for example
+// because another agent stored a snapshot in between, a new one is returned.
+func (s *snapshotEncoders) get(docID string, serverSeq uint64) *converter.SnapshotEncoder {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	encoder, ok := s.encoders[docID]
+	if ok && encoder.ServerSeq() == serverSeq {
+		return encoder
+	}
+
+	encoder = converter.NewSnapshotEncoder()
+	if !ok && len(s.encoders) >= maxSnapshotEncoders {
+		for k := range s.encoders {
+			delete(s.encoders, k)
+			break
+		}
+	}
+	s.encoders[docID] = encoder
+
+	return encoder
+}
@@ -0,0 +1,479 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package backup exports the snapshots of documents to a Store periodically
+// and restores them, so that operators can recover the documents as of the
+// time of a backup.
+package backup
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/yorkie-team/yorkie/pkg/document/change"
+	"github.com/yorkie-team/yorkie/pkg/log"
+	"github.com/yorkie-team/yorkie/yorkie/auth"
+	"github.com/yorkie-team/yorkie/yorkie/backend"
+	"github.com/yorkie-team/yorkie/yorkie/packs"
+	"github.com/yorkie-team/yorkie/yorkie/types"
+)
+
+var logger = log.Named(log.Agent)
+
+const (
+	namePrefix        = "backup-"
+	archiveNamePrefix = "archive-"
+	nameSuffix        = ".bson"
+	nameLayout        = "20060102T150405.000Z"
+
+	formatVersion = 1
+)
+
+// ErrInvalidBackup is returned when a backup can't be decoded.
+var ErrInvalidBackup = errors.New("invalid backup")
+
+// Config is the configuration for creating a Manager instance.
+type Config struct {
+	// Dir is the directory where the backups are stored.
+	Dir string `json:"Dir"`
+
+	// IntervalSec is the interval between scheduled backups. Backups are
+	// not scheduled if it is zero.
+	IntervalSec time.Duration `json:"IntervalSec"`
+
+	// Retention is the number of the latest backups to keep. All backups
+	// are kept if it is zero.
+	Retention int `json:"Retention"`
+
+	// IncludeChanges determines whether the changes not yet pruned are
+	// exported with the snapshots.
+	IncludeChanges bool `json:"IncludeChanges"`
+}
+
+// header is the first record of a backup.
+type header struct {
+	Version        int       `bson:"version"`
+	CreatedAt      time.Time `bson:"created_at"`
+	IncludeChanges bool      `bson:"include_changes"`
+}
+
+// entry is the record of a document in a backup.
+type entry struct {
+	Key               string              `bson:"key"`
+	Owner             primitive.ObjectID  `bson:"owner"`
+	ACL               *auth.ACL           `bson:"acl"`
+	Encrypted         bool                `bson:"encrypted"`
+	ServerSeq         uint64              `bson:"server_seq"`
+	PrunedServerSeq   uint64              `bson:"pruned_server_seq"`
+	SnapshotServerSeq uint64              `bson:"snapshot_server_seq"`
+	Snapshot          []byte              `bson:"snapshot"`
+	Changes           []*types.ChangeInfo `bson:"changes"`
+}
+
+// Manager takes backups of the documents of a backend.
+type Manager struct {
+	conf  *Config
+	be    *backend.Backend
+	store Store
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewManager creates a new instance of Manager.
+func NewManager(conf *Config, be *backend.Backend, store Store) *Manager {
+	return &Manager{
+		conf:  conf,
+		be:    be,
+		store: store,
+	}
+}
+
+// Start starts taking backups at the configured interval.
+func (m *Manager) Start() {
+	if m.conf.IntervalSec == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancel = cancel
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+
+		ticker := time.NewTicker(m.conf.IntervalSec * time.Second)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				// NOTE: Only the leader runs it if leader election is enabled.
+				if !m.be.IsLeader() {
+					continue
+				}
+				if _, err := m.Backup(ctx); err != nil {
+					logger.Error(err)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops taking backups and waits for the running one to finish.
+func (m *Manager) Stop() {
+	if m.cancel == nil {
+		return
+	}
+
+	m.cancel()
+	m.wg.Wait()
+}
+
+// Backup takes a backup of all documents and returns its name. The backups
+// older than the configured retention are deleted.
+func (m *Manager) Backup(ctx context.Context) (string, error) {
+	start := time.Now().UTC()
+
+	docInfos, err := m.be.DB.FindDocInfos(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	buf := &bytes.Buffer{}
+	if err := writeRecord(buf, &header{
+		Version:        formatVersion,
+		CreatedAt:      start,
+		IncludeChanges: m.conf.IncludeChanges,
+	}); err != nil {
+		return "", err
+	}
+
+	for _, docInfo := range docInfos {
+		e, err := export(ctx, m.be, docInfo, m.conf.IncludeChanges)
+		if err != nil {
+			return "", err
+		}
+		if err := writeRecord(buf, e); err != nil {
+			return "", err
+		}
+	}
+
+	name := namePrefix + start.Format(nameLayout) + nameSuffix
+	if err := m.store.Put(name, buf.Bytes()); err != nil {
+		return "", err
+	}
+
+	logger.Infof(
+		"BACKUP: '%s' with %d documents in %s",
+		name,
+		len(docInfos),
+		time.Since(start),
+	)
+
+	if err := m.deleteExpired(); err != nil {
+		return "", err
+	}
+
+	return name, nil
+}
+
+// Archive exports the given document as a backup of its own to the given
+// store and returns the name of it. Archives are not listed as backups, so
+// they are kept regardless of the retention, and can be restored with Restore.
+func Archive(
+	ctx context.Context,
+	be *backend.Backend,
+	store Store,
+	docInfo *types.DocInfo,
+) (string, error) {
+	start := time.Now().UTC()
+
+	buf := &bytes.Buffer{}
+	if err := writeRecord(buf, &header{
+		Version:   formatVersion,
+		CreatedAt: start,
+	}); err != nil {
+		return "", err
+	}
+
+	e, err := export(ctx, be, docInfo, false)
+	if err != nil {
+		return "", err
+	}
+	if err := writeRecord(buf, e); err != nil {
+		return "", err
+	}
+
+	name := archiveNamePrefix + docInfo.ID.Hex() + "-" + start.Format(nameLayout) + nameSuffix
+	if err := store.Put(name, buf.Bytes()); err != nil {
+		return "", err
+	}
+
+	return name, nil
+}
+
+// export stores the snapshot of the given document as of now and returns the
+// entry of it. End-to-end encrypted documents have no snapshots, so their
+// changes are always exported.
+func export(
+	ctx context.Context,
+	be *backend.Backend,
+	docInfo *types.DocInfo,
+	includeChanges bool,
+) (*entry, error) {
+	e := &entry{
+		Key:             docInfo.Key,
+		Owner:           docInfo.Owner,
+		ACL:             docInfo.ACL,
+		Encrypted:       docInfo.Encrypted,
+		ServerSeq:       docInfo.ServerSeq,
+		PrunedServerSeq: docInfo.PrunedServerSeq,
+	}
+
+	if !docInfo.Encrypted {
+		serverSeq, _, err := packs.ForceSnapshot(ctx, be, docInfo, false)
+		if err != nil {
+			return nil, err
+		}
+
+		snapshotInfo, err := be.DB.FindLastSnapshotInfo(ctx, docInfo.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		e.ServerSeq = serverSeq
+		e.SnapshotServerSeq = snapshotInfo.ServerSeq
+		e.Snapshot = snapshotInfo.Snapshot
+	}
+
+	if (includeChanges || docInfo.Encrypted) && docInfo.PrunedServerSeq < e.ServerSeq {
+		changes, err := be.DB.FindChangeInfosBetweenServerSeqs(
+			ctx,
+			docInfo.ID,
+			docInfo.PrunedServerSeq+1,
+			e.ServerSeq,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, c := range changes {
+			e.Changes = append(e.Changes, types.NewChangeInfo(docInfo.ID, c))
+		}
+	}
+
+	return e, nil
+}
+
+// deleteExpired deletes the backups older than the configured retention.
+func (m *Manager) deleteExpired() error {
+	if m.conf.Retention <= 0 {
+		return nil
+	}
+
+	names, err := m.store.List()
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < len(names)-m.conf.Retention; i++ {
+		if err := m.store.Delete(names[i]); err != nil {
+			return err
+		}
+		logger.Infof("BACKUP: '%s' expired", names[i])
+	}
+
+	return nil
+}
+
+// Restore restores the documents of the backup of the given name into the
+// given backend, and returns the number of restored documents. Documents that
+// already have changes in the backend are skipped.
+func Restore(
+	ctx context.Context,
+	be *backend.Backend,
+	store Store,
+	name string,
+) (int, error) {
+	data, err := store.Get(name)
+	if err != nil {
+		return 0, err
+	}
+
+	reader := bytes.NewReader(data)
+	h := &header{}
+	if err := readRecord(reader, h); err != nil {
+		return 0, err
+	}
+	if h.Version != formatVersion {
+		return 0, fmt.Errorf("version %d: %w", h.Version, ErrInvalidBackup)
+	}
+
+	restored := 0
+	for {
+		e := &entry{}
+		if err := readRecord(reader, e); err == io.EOF {
+			break
+		} else if err != nil {
+			return restored, err
+		}
+
+		ok, err := restoreEntry(ctx, be, h, e)
+		if err != nil {
+			return restored, err
+		}
+		if ok {
+			restored++
+		}
+	}
+
+	logger.Infof("RESTORE: '%s' restored %d documents", name, restored)
+
+	return restored, nil
+}
+
+// restoreEntry restores the document of the given entry. It returns false if
+// the document already has changes.
+func restoreEntry(
+	ctx context.Context,
+	be *backend.Backend,
+	h *header,
+	e *entry,
+) (bool, error) {
+	docInfo, err := be.DB.FindDocInfoByKey(ctx, &types.ClientInfo{ID: e.Owner}, e.Key, true)
+	if err != nil {
+		return false, err
+	}
+	if docInfo.ServerSeq > 0 {
+		logger.Warnf("RESTORE: '%s' is skipped, it already has changes", e.Key)
+		return false, nil
+	}
+
+	if err := restoreChanges(ctx, be, docInfo, e.Changes); err != nil {
+		return false, err
+	}
+
+	if e.SnapshotServerSeq > 0 {
+		if err := be.DB.CreateSnapshotInfo(ctx, docInfo.ID, e.SnapshotServerSeq, e.Snapshot); err != nil {
+			return false, err
+		}
+	}
+
+	if e.ACL != nil {
+		if _, err := be.DB.UpdateDocACL(ctx, e.Key, e.ACL); err != nil {
+			return false, err
+		}
+	}
+
+	docInfo.ServerSeq = e.ServerSeq
+	docInfo.Encrypted = e.Encrypted
+	if err := be.DB.UpdateDocInfo(ctx, docInfo); err != nil {
+		return false, err
+	}
+
+	// NOTE: Without the changes, clients can only be synchronized with the
+	// snapshot, so the changes are marked as pruned up to it.
+	prunedServerSeq := e.PrunedServerSeq
+	if !h.IncludeChanges && !e.Encrypted {
+		prunedServerSeq = e.SnapshotServerSeq
+	}
+	if prunedServerSeq > 0 {
+		if _, err := be.DB.PruneChangeInfos(ctx, docInfo.ID, prunedServerSeq); err != nil {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+func restoreChanges(
+	ctx context.Context,
+	be *backend.Backend,
+	docInfo *types.DocInfo,
+	changeInfos []*types.ChangeInfo,
+) error {
+	if len(changeInfos) == 0 {
+		return nil
+	}
+
+	changes := make([]*change.Change, 0, len(changeInfos))
+	for _, info := range changeInfos {
+		c, err := info.ToChange()
+		if err != nil {
+			return err
+		}
+		changes = append(changes, c)
+	}
+
+	return be.DB.CreateChangeInfos(ctx, docInfo.ID, changes)
+}
+
+func writeRecord(w io.Writer, v interface{}) error {
+	data, err := bson.Marshal(v)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	if _, err := w.Write(data); err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+// readRecord reads a BSON document, which is prefixed with its length, from
+// the given reader into the given value. It returns io.EOF if there are no
+// more records.
+func readRecord(r *bytes.Reader, v interface{}) error {
+	var size [4]byte
+	if _, err := io.ReadFull(r, size[:]); err == io.EOF {
+		return io.EOF
+	} else if err != nil {
+		return ErrInvalidBackup
+	}
+
+	n := binary.LittleEndian.Uint32(size[:])
+	if n < uint32(len(size)) || int64(n-uint32(len(size))) > int64(r.Len()) {
+		return ErrInvalidBackup
+	}
+
+	data := make([]byte, n)
+	copy(data, size[:])
+	if _, err := io.ReadFull(r, data[len(size):]); err != nil {
+		return ErrInvalidBackup
+	}
+
+	if err := bson.Unmarshal(data, v); err != nil {
+		logger.Error(err)
+		return ErrInvalidBackup
+	}
+
+	return nil
+}
@@ -0,0 +1,128 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backup_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yorkie-team/yorkie/api/converter"
+	"github.com/yorkie-team/yorkie/pkg/document"
+	"github.com/yorkie-team/yorkie/pkg/document/proxy"
+	"github.com/yorkie-team/yorkie/yorkie/backend"
+	"github.com/yorkie-team/yorkie/yorkie/backend/embedded"
+	"github.com/yorkie-team/yorkie/yorkie/backup"
+)
+
+func newBackend(t *testing.T, path string) *backend.Backend {
+	db, err := embedded.Open(&embedded.Config{Path: path, NoSync: true})
+	assert.NoError(t, err)
+
+	be, err := backend.NewWithDatabase(&backend.Config{SnapshotThreshold: 500}, db)
+	assert.NoError(t, err)
+	return be
+}
+
+func TestBackup(t *testing.T) {
+	ctx := context.Background()
+
+	dir, err := ioutil.TempDir("", "yorkie-backup")
+	assert.NoError(t, err)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+
+	t.Run("backup and restore test", func(t *testing.T) {
+		be := newBackend(t, filepath.Join(dir, "origin.db"))
+		defer func() {
+			assert.NoError(t, be.Close())
+		}()
+
+		doc := document.New("c", "d")
+		for i := 0; i < 3; i++ {
+			assert.NoError(t, doc.Update(func(root *proxy.ObjectProxy) error {
+				root.SetNewArray("list").AddInteger(i)
+				return nil
+			}))
+		}
+
+		clientInfo, err := be.DB.ActivateClient(ctx, "client")
+		assert.NoError(t, err)
+		docInfo, err := be.DB.FindDocInfoByKey(ctx, clientInfo, doc.Key().BSONKey(), true)
+		assert.NoError(t, err)
+
+		changes := doc.CreateChangePack().Changes
+		for _, c := range changes {
+			c.SetServerSeq(docInfo.IncreaseServerSeq())
+		}
+		assert.NoError(t, be.DB.CreateChangeInfos(ctx, docInfo.ID, changes))
+		assert.NoError(t, be.DB.UpdateDocInfo(ctx, docInfo))
+
+		store, err := backup.NewDirStore(filepath.Join(dir, "backups"))
+		assert.NoError(t, err)
+		manager := backup.NewManager(&backup.Config{
+			Retention:      1,
+			IncludeChanges: true,
+		}, be, store)
+
+		_, err = manager.Backup(ctx)
+		assert.NoError(t, err)
+		name, err := manager.Backup(ctx)
+		assert.NoError(t, err)
+
+		names, err := store.List()
+		assert.NoError(t, err)
+		assert.Equal(t, []string{name}, names)
+
+		restoredBe := newBackend(t, filepath.Join(dir, "restored.db"))
+		defer func() {
+			assert.NoError(t, restoredBe.Close())
+		}()
+
+		restored, err := backup.Restore(ctx, restoredBe, store, name)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, restored)
+
+		restoredDocInfo, err := restoredBe.DB.FindDocInfoByKey(ctx, nil, doc.Key().BSONKey(), false)
+		assert.NoError(t, err)
+		assert.Equal(t, docInfo.ServerSeq, restoredDocInfo.ServerSeq)
+
+		snapshotInfo, err := restoredBe.DB.FindLastSnapshotInfo(ctx, restoredDocInfo.ID)
+		assert.NoError(t, err)
+		obj, err := converter.BytesToObject(snapshotInfo.Snapshot)
+		assert.NoError(t, err)
+		assert.Equal(t, doc.Marshal(), obj.Marshal())
+
+		restoredChanges, err := restoredBe.DB.FindChangeInfosBetweenServerSeqs(
+			ctx,
+			restoredDocInfo.ID,
+			1,
+			restoredDocInfo.ServerSeq,
+		)
+		assert.NoError(t, err)
+		assert.Len(t, restoredChanges, len(changes))
+
+		restored, err = backup.Restore(ctx, restoredBe, store, name)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, restored)
+	})
+}
@@ -0,0 +1,112 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backup
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Store is a storage of backups. Backups can be kept in an object store by
+// implementing this interface.
+type Store interface {
+	// Put stores the given backup with the given name.
+	Put(name string, data []byte) error
+
+	// Get returns the backup of the given name.
+	Get(name string) ([]byte, error)
+
+	// List returns the names of the stored backups in ascending order.
+	List() ([]string, error)
+
+	// Delete deletes the backup of the given name.
+	Delete(name string) error
+}
+
+// DirStore is a Store that keeps backups as files in a local directory.
+type DirStore struct {
+	dir string
+}
+
+// NewDirStore creates a new instance of DirStore, creating the given
+// directory if it doesn't exist.
+func NewDirStore(dir string) (*DirStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	return &DirStore{dir: dir}, nil
+}
+
+// Put stores the given backup with the given name. The file is written under
+// a temporary name first, so that a partially written backup is never listed.
+func (s *DirStore) Put(name string, data []byte) error {
+	name = filepath.Base(name)
+	tmpPath := filepath.Join(s.dir, "."+name+".tmp")
+	if err := ioutil.WriteFile(tmpPath, data, 0600); err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	if err := os.Rename(tmpPath, filepath.Join(s.dir, name)); err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+// Get returns the backup of the given name.
+func (s *DirStore) Get(name string) ([]byte, error) {
+	data, err := ioutil.ReadFile(filepath.Join(s.dir, filepath.Base(name)))
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// List returns the names of the stored backups in ascending order.
+func (s *DirStore) List() ([]string, error) {
+	matches, err := filepath.Glob(filepath.Join(s.dir, namePrefix+"*"+nameSuffix))
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	var names []string
+	for _, match := range matches {
+		names = append(names, filepath.Base(match))
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
+
+// Delete deletes the backup of the given name.
+func (s *DirStore) Delete(name string) error {
+	if err := os.Remove(filepath.Join(s.dir, filepath.Base(name))); err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	return nil
+}
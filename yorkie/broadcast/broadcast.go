@@ -0,0 +1,195 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package broadcast keeps track of the messages broadcast by the agent to the
+// clients watching documents, and of their delivery and acknowledgment.
+//
+// TODO: The tracker is kept in memory, so each agent only knows the messages
+//  broadcast through it and the clients connected to it.
+package broadcast
+
+import (
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// DefaultCapacity is the number of the recent broadcasts kept by a Tracker.
+const DefaultCapacity = 1000
+
+var (
+	// ErrBroadcastNotFound is returned when the broadcast is not found, or
+	// has already been evicted from the tracker.
+	ErrBroadcastNotFound = errors.New("broadcast not found")
+
+	// ErrNotRecipient is returned when a client acknowledges a broadcast
+	// which was not sent to it.
+	ErrNotRecipient = errors.New("client is not a recipient of the broadcast")
+)
+
+// Broadcast is a message sent to the clients watching a document.
+type Broadcast struct {
+	ID      string
+	DocKey  string
+	Type    string
+	Payload []byte
+}
+
+// Status is the delivery status of a broadcast.
+type Status struct {
+	Broadcast *Broadcast
+
+	// Recipients are the clients watching the document when the message was
+	// broadcast.
+	Recipients []string
+
+	// Delivered are the clients the message was sent to.
+	Delivered []string
+
+	// Acknowledged are the clients that acknowledged the message.
+	Acknowledged []string
+}
+
+// entry is the record of a broadcast in the tracker.
+type entry struct {
+	broadcast    *Broadcast
+	recipients   map[string]bool
+	delivered    map[string]bool
+	acknowledged map[string]bool
+}
+
+// Tracker keeps track of the recent broadcasts.
+type Tracker struct {
+	mu       sync.RWMutex
+	capacity int
+	entries  map[string]*entry
+	order    []string
+}
+
+// NewTracker creates a new instance of Tracker which keeps the given number
+// of the recent broadcasts.
+func NewTracker(capacity int) *Tracker {
+	return &Tracker{
+		capacity: capacity,
+		entries:  make(map[string]*entry),
+	}
+}
+
+// Create registers a new broadcast of the given message to the given
+// recipients. The oldest broadcast is evicted if the tracker is full.
+func (t *Tracker) Create(docKey, typ string, payload []byte, recipients []string) *Broadcast {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	b := &Broadcast{
+		ID:      uuid.New().String(),
+		DocKey:  docKey,
+		Type:    typ,
+		Payload: payload,
+	}
+
+	e := &entry{
+		broadcast:    b,
+		recipients:   make(map[string]bool),
+		delivered:    make(map[string]bool),
+		acknowledged: make(map[string]bool),
+	}
+	for _, clientID := range recipients {
+		e.recipients[clientID] = true
+	}
+
+	t.entries[b.ID] = e
+	t.order = append(t.order, b.ID)
+	if len(t.order) > t.capacity {
+		delete(t.entries, t.order[0])
+		t.order = t.order[1:]
+	}
+
+	return b
+}
+
+// Find returns the broadcast of the given ID.
+func (t *Tracker) Find(id string) (*Broadcast, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	e, ok := t.entries[id]
+	if !ok {
+		return nil, ErrBroadcastNotFound
+	}
+
+	return e.broadcast, nil
+}
+
+// Deliver marks the given broadcast as sent to the given client.
+func (t *Tracker) Deliver(id, clientID string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	e, ok := t.entries[id]
+	if !ok {
+		return ErrBroadcastNotFound
+	}
+
+	e.delivered[clientID] = true
+	return nil
+}
+
+// Acknowledge marks the given broadcast as acknowledged by the given client.
+func (t *Tracker) Acknowledge(id, clientID string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	e, ok := t.entries[id]
+	if !ok {
+		return ErrBroadcastNotFound
+	}
+	if !e.recipients[clientID] && !e.delivered[clientID] {
+		return ErrNotRecipient
+	}
+
+	e.acknowledged[clientID] = true
+	return nil
+}
+
+// Status returns the delivery status of the given broadcast.
+func (t *Tracker) Status(id string) (*Status, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	e, ok := t.entries[id]
+	if !ok {
+		return nil, ErrBroadcastNotFound
+	}
+
+	return &Status{
+		Broadcast:    e.broadcast,
+		Recipients:   sortedKeys(e.recipients),
+		Delivered:    sortedKeys(e.delivered),
+		Acknowledged: sortedKeys(e.acknowledged),
+	}, nil
+}
+
+func sortedKeys(m map[string]bool) []string {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
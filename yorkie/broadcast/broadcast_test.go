@@ -0,0 +1,63 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package broadcast_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yorkie-team/yorkie/yorkie/broadcast"
+)
+
+func TestTracker(t *testing.T) {
+	t.Run("deliver/acknowledge test", func(t *testing.T) {
+		tracker := broadcast.NewTracker(broadcast.DefaultCapacity)
+		b := tracker.Create("c$d1", "archived", []byte("bye"), []string{"b", "a"})
+
+		found, err := tracker.Find(b.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, "archived", found.Type)
+
+		assert.NoError(t, tracker.Deliver(b.ID, "a"))
+		assert.NoError(t, tracker.Deliver(b.ID, "b"))
+		assert.NoError(t, tracker.Acknowledge(b.ID, "b"))
+		assert.Equal(t, broadcast.ErrNotRecipient, tracker.Acknowledge(b.ID, "c"))
+
+		status, err := tracker.Status(b.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"a", "b"}, status.Recipients)
+		assert.Equal(t, []string{"a", "b"}, status.Delivered)
+		assert.Equal(t, []string{"b"}, status.Acknowledged)
+	})
+
+	t.Run("eviction test", func(t *testing.T) {
+		tracker := broadcast.NewTracker(2)
+		b1 := tracker.Create("c$d1", "refresh", nil, nil)
+		b2 := tracker.Create("c$d1", "refresh", nil, nil)
+		b3 := tracker.Create("c$d1", "refresh", nil, nil)
+
+		_, err := tracker.Find(b1.ID)
+		assert.Equal(t, broadcast.ErrBroadcastNotFound, err)
+		assert.Equal(t, broadcast.ErrBroadcastNotFound, tracker.Acknowledge(b1.ID, "a"))
+
+		_, err = tracker.Find(b2.ID)
+		assert.NoError(t, err)
+		_, err = tracker.Find(b3.ID)
+		assert.NoError(t, err)
+	})
+}
@@ -18,18 +18,104 @@ package clients
 
 import (
 	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
 
 	"github.com/yorkie-team/yorkie/pkg/document/change"
 	"github.com/yorkie-team/yorkie/yorkie/backend"
+	"github.com/yorkie-team/yorkie/yorkie/backend/database"
 	"github.com/yorkie-team/yorkie/yorkie/types"
 )
 
+// ErrInvalidSessionToken is returned when the session token to resume is not
+// the token of the current session of the client.
+var ErrInvalidSessionToken = errors.New("invalid session token")
+
+// Activate activates the client of the given key and returns the token of
+// its session. If the given session token is not empty, the current session
+// of the client is resumed with the documents attached in it, and
+// ErrInvalidSessionToken is returned without activating the client if the
+// token is not of the session. Otherwise, a new session is started with a new
+// token, and the documents attached in the previous session are detached.
 func Activate(
 	ctx context.Context,
 	be *backend.Backend,
 	clientKey string,
-) (*types.ClientInfo, error) {
-	return be.Mongo.ActivateClient(ctx, clientKey)
+	sessionToken string,
+) (*types.ClientInfo, string, error) {
+	if sessionToken != "" {
+		clientInfo, err := be.DB.FindClientInfoByKey(ctx, clientKey)
+		if err == database.ErrClientNotFound {
+			return nil, "", ErrInvalidSessionToken
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		if !clientInfo.VerifySessionToken(sessionToken) {
+			return nil, "", ErrInvalidSessionToken
+		}
+
+		if clientInfo, err = be.DB.ActivateClient(ctx, clientKey); err != nil {
+			return nil, "", err
+		}
+		return clientInfo, sessionToken, nil
+	}
+
+	clientInfo, err := be.DB.ActivateClient(ctx, clientKey)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// NOTE: The attachments of the previous session can't be resumed without
+	// its token, so the new session starts without them.
+	for _, hexDocID := range clientInfo.AttachedDocuments() {
+		docID, err := primitive.ObjectIDFromHex(hexDocID)
+		if err != nil {
+			return nil, "", err
+		}
+		if err := clientInfo.DetachDocument(docID); err != nil {
+			return nil, "", err
+		}
+	}
+
+	token, err := types.NewSessionToken()
+	if err != nil {
+		return nil, "", err
+	}
+	clientInfo.SetSessionToken(token)
+	if err := be.DB.UpdateClientInfoSession(ctx, clientInfo); err != nil {
+		return nil, "", err
+	}
+
+	return clientInfo, token, nil
+}
+
+// Attachments returns the documents attached to the given client. Documents
+// purged since they were attached are skipped.
+func Attachments(
+	ctx context.Context,
+	be *backend.Backend,
+	clientInfo *types.ClientInfo,
+) ([]*types.DocInfo, error) {
+	var docInfos []*types.DocInfo
+	for _, hexDocID := range clientInfo.AttachedDocuments() {
+		docID, err := primitive.ObjectIDFromHex(hexDocID)
+		if err != nil {
+			return nil, err
+		}
+
+		docInfo, err := be.DB.FindDocInfoByID(ctx, docID)
+		if err == database.ErrDocumentNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		docInfos = append(docInfos, docInfo)
+	}
+
+	return docInfos, nil
 }
 
 func Deactivate(
@@ -37,7 +123,15 @@ func Deactivate(
 	be *backend.Backend,
 	clientID string,
 ) (*types.ClientInfo, error) {
-	return be.Mongo.DeactivateClient(ctx, clientID)
+	return be.DB.DeactivateClient(ctx, clientID)
+}
+
+func Find(
+	ctx context.Context,
+	be *backend.Backend,
+	clientID string,
+) (*types.ClientInfo, error) {
+	return be.DB.FindClientInfoByID(ctx, clientID)
 }
 
 func FindClientAndDocument(
@@ -47,12 +141,12 @@ func FindClientAndDocument(
 	pack *change.Pack,
 	createDocIfNotExist bool,
 ) (*types.ClientInfo, *types.DocInfo, error) {
-	clientInfo, err := be.Mongo.FindClientInfoByID(ctx, clientID)
+	clientInfo, err := be.DB.FindClientInfoByID(ctx, clientID)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	docInfo, err := be.Mongo.FindDocInfoByKey(
+	docInfo, err := be.DB.FindDocInfoByKey(
 		ctx,
 		clientInfo,
 		pack.DocumentKey.BSONKey(),
@@ -25,11 +25,17 @@ import (
 	"github.com/yorkie-team/yorkie/pkg/log"
 	"github.com/yorkie-team/yorkie/yorkie/backend"
 	"github.com/yorkie-team/yorkie/yorkie/backend/mongo"
+	"github.com/yorkie-team/yorkie/yorkie/backup"
+	"github.com/yorkie-team/yorkie/yorkie/housekeeping"
 	"github.com/yorkie-team/yorkie/yorkie/rpc"
 )
 
+var logger = log.Named(log.Agent)
+
 const (
-	DefaultRPCPort = 9090
+	DefaultRPCPort              = 9090
+	DefaultRPCMaxMessageSize    = 4 * 1024 * 1024
+	DefaultRPCSnapshotChunkSize = rpc.DefaultSnapshotChunkSize
 
 	DefaultMongoConnectionURI        = "mongodb://localhost:27017"
 	DefaultMongoConnectionTimeoutSec = 5
@@ -44,6 +50,18 @@ type Config struct {
 	RPC     *rpc.Config     `json:"RPC"`
 	Mongo   *mongo.Config   `json:"Mongo"`
 	Backend *backend.Config `json:"Backend"`
+
+	// Backup is the configuration of the scheduled backups. Backups are
+	// disabled if it is nil.
+	Backup *backup.Config `json:"Backup"`
+
+	// Housekeeping is the configuration of the purge of inactive documents.
+	// Housekeeping is disabled if it is nil.
+	Housekeeping *housekeeping.Config `json:"Housekeeping"`
+
+	// Log is the configuration of the loggers. The loggers write info logs
+	// to the standard output in the console format if it is nil.
+	Log *log.Config `json:"Log"`
 }
 
 // RPCAddr returns the RPC address.
@@ -67,18 +85,18 @@ func NewConfigFromFile(path string) (*Config, error) {
 	conf := &Config{}
 	file, err := os.Open(path)
 	if err != nil {
-		log.Logger.Error(err)
+		logger.Error(err)
 		return nil, err
 	}
 
 	bytes, err := ioutil.ReadAll(file)
 	if err != nil {
-		log.Logger.Error(err)
+		logger.Error(err)
 		return nil, err
 	}
 
 	if err := json.Unmarshal(bytes, conf); err != nil {
-		log.Logger.Error(err)
+		logger.Error(err)
 		return nil, err
 	}
 
@@ -88,7 +106,9 @@ func NewConfigFromFile(path string) (*Config, error) {
 func newConfig(port int, dbName string) *Config {
 	return &Config{
 		RPC: &rpc.Config{
-			Port: port,
+			Port:              port,
+			MaxMessageSize:    DefaultRPCMaxMessageSize,
+			SnapshotChunkSize: DefaultRPCSnapshotChunkSize,
 		},
 		Backend: &backend.Config{
 			SnapshotThreshold: DefaultSnapshotThreshold,
@@ -33,9 +33,14 @@ func TestNewConfigFromFile(t *testing.T) {
 	assert.Equal(t, conf.RPC.Port, yorkie.DefaultRPCPort)
 	assert.Equal(t, conf.RPC.CertFile, "")
 	assert.Equal(t, conf.RPC.KeyFile, "")
+	assert.Equal(t, conf.RPC.MaxMessageSize, yorkie.DefaultRPCMaxMessageSize)
+	assert.Equal(t, conf.RPC.SnapshotChunkSize, yorkie.DefaultRPCSnapshotChunkSize)
 	assert.Equal(t, conf.Mongo.ConnectionTimeoutSec, time.Duration(yorkie.DefaultMongoConnectionTimeoutSec))
 	assert.Equal(t, conf.Mongo.ConnectionURI, yorkie.DefaultMongoConnectionURI)
 	assert.Equal(t, conf.Mongo.YorkieDatabase, yorkie.DefaultMongoYorkieDatabase)
 	assert.Equal(t, conf.Mongo.PingTimeoutSec, time.Duration(yorkie.DefaultMongoPingTimeoutSec))
 	assert.Equal(t, conf.Backend.SnapshotThreshold, uint64(yorkie.DefaultSnapshotThreshold))
+	assert.Equal(t, conf.Backend.SnapshotThresholdOf("c"), uint64(yorkie.DefaultSnapshotThreshold))
+	assert.Equal(t, conf.Log.Level, "info")
+	assert.Equal(t, conf.Log.Format, "console")
 }
@@ -0,0 +1,177 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package documents
+
+import (
+	"context"
+
+	"github.com/yorkie-team/yorkie/pkg/document"
+	"github.com/yorkie-team/yorkie/pkg/document/change"
+	"github.com/yorkie-team/yorkie/pkg/document/checkpoint"
+	"github.com/yorkie-team/yorkie/pkg/document/key"
+	"github.com/yorkie-team/yorkie/yorkie/auth"
+	"github.com/yorkie-team/yorkie/yorkie/backend"
+	"github.com/yorkie-team/yorkie/yorkie/labels"
+	"github.com/yorkie-team/yorkie/yorkie/types"
+)
+
+// Find returns the information of the document of the given key.
+func Find(
+	ctx context.Context,
+	be *backend.Backend,
+	docKey *key.Key,
+) (*types.DocInfo, error) {
+	return be.DB.FindDocInfoByKey(ctx, nil, docKey.BSONKey(), false)
+}
+
+// UpdateACL replaces the ACL of the document of the given key. If the given
+// ACL is nil, the document can be accessed by every client.
+func UpdateACL(
+	ctx context.Context,
+	be *backend.Backend,
+	docKey *key.Key,
+	acl *auth.ACL,
+) (*types.DocInfo, error) {
+	return be.DB.UpdateDocACL(ctx, docKey.BSONKey(), acl)
+}
+
+// DefaultListLimit is the default number of documents returned by List.
+const DefaultListLimit = 100
+
+// List returns the documents under the given prefix whose labels match the
+// given selector in the order of their keys, up to the given limit.
+// DefaultListLimit is used if the limit is not positive.
+func List(
+	ctx context.Context,
+	be *backend.Backend,
+	prefix *key.Prefix,
+	selector labels.Selector,
+	limit int,
+) ([]*types.DocInfo, error) {
+	if limit <= 0 {
+		limit = DefaultListLimit
+	}
+
+	return be.DB.FindDocInfosByKeyPrefix(ctx, prefix.BSONKey(), selector, limit)
+}
+
+// UpdateLabels sets the given labels of the document of the given key and
+// removes the labels of the given keys.
+func UpdateLabels(
+	ctx context.Context,
+	be *backend.Backend,
+	docKey *key.Key,
+	set labels.Labels,
+	remove []string,
+) (*types.DocInfo, error) {
+	docInfo, err := Find(ctx, be, docKey)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := docInfo.Labels.Update(set, remove)
+	if err := updated.Validate(); err != nil {
+		return nil, err
+	}
+
+	return be.DB.UpdateDocLabels(ctx, docKey.BSONKey(), updated)
+}
+
+// DefaultHistoryLimit is the default number of changes returned by
+// FindHistory.
+const DefaultHistoryLimit = 100
+
+// FindHistory returns the changes of the given document from the given server
+// sequence, up to the given limit. DefaultHistoryLimit is used if the limit
+// is not positive.
+func FindHistory(
+	ctx context.Context,
+	be *backend.Backend,
+	docInfo *types.DocInfo,
+	from uint64,
+	limit int,
+) ([]*change.Change, error) {
+	if limit <= 0 {
+		limit = DefaultHistoryLimit
+	}
+	if from == 0 {
+		from = 1
+	}
+	if from > docInfo.ServerSeq {
+		return nil, nil
+	}
+
+	to := from + uint64(limit) - 1
+	if to > docInfo.ServerSeq {
+		to = docInfo.ServerSeq
+	}
+
+	return be.DB.FindChangeInfosBetweenServerSeqs(ctx, docInfo.ID, from, to)
+}
+
+// Build builds the current document of the given docInfo from the last
+// snapshot and the changes after it.
+func Build(
+	ctx context.Context,
+	be *backend.Backend,
+	docInfo *types.DocInfo,
+) (*document.Document, error) {
+	snapshotInfo, err := be.DB.FindLastSnapshotInfo(ctx, docInfo.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	docKey, err := docInfo.GetKey()
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := document.FromSnapshot(
+		docKey.Collection,
+		docKey.Document,
+		snapshotInfo.ServerSeq,
+		snapshotInfo.Snapshot,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if snapshotInfo.ServerSeq >= docInfo.ServerSeq {
+		return doc, nil
+	}
+
+	changes, err := be.DB.FindChangeInfosBetweenServerSeqs(
+		ctx,
+		docInfo.ID,
+		snapshotInfo.ServerSeq+1,
+		docInfo.ServerSeq,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := doc.ApplyChangePack(change.NewPack(
+		docKey,
+		checkpoint.Initial.NextServerSeq(docInfo.ServerSeq),
+		changes,
+		nil,
+	)); err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
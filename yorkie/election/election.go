@@ -0,0 +1,156 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package election elects the leader of the agents sharing a database with
+// the leases granted by the database, so that the background jobs such as
+// backups and housekeeping run on exactly one of them.
+//
+// The leader renews its lease periodically. If it stops, e.g. crashes, the
+// lease expires and another agent takes over on its next try.
+package election
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/yorkie-team/yorkie/pkg/log"
+	"github.com/yorkie-team/yorkie/yorkie/backend/database"
+)
+
+var logger = log.Named(log.Agent)
+
+// DefaultLeaseDuration is the default duration of the lease of the leader.
+const DefaultLeaseDuration = 15 * time.Second
+
+// Elector campaigns for the lease of the given name with the other agents.
+type Elector struct {
+	leaser database.Leaser
+	name   string
+	holder string
+	ttl    time.Duration
+
+	mu        sync.RWMutex
+	expiresAt time.Time
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// New creates a new instance of Elector which campaigns as the given holder
+// for the lease of the given name with the given duration.
+func New(leaser database.Leaser, name, holder string, ttl time.Duration) *Elector {
+	if ttl == 0 {
+		ttl = DefaultLeaseDuration
+	}
+
+	return &Elector{
+		leaser: leaser,
+		name:   name,
+		holder: holder,
+		ttl:    ttl,
+	}
+}
+
+// Start starts campaigning. The lease is tried at a third of its duration,
+// so that the leader renews it before it expires.
+func (e *Elector) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	e.cancel = cancel
+
+	e.Campaign(ctx)
+
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+
+		ticker := time.NewTicker(e.ttl / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				e.Campaign(ctx)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops campaigning and releases the lease if this elector has it.
+func (e *Elector) Stop() {
+	if e.cancel == nil {
+		return
+	}
+
+	e.cancel()
+	e.wg.Wait()
+
+	if !e.IsLeader() {
+		return
+	}
+
+	e.mu.Lock()
+	e.expiresAt = time.Time{}
+	e.mu.Unlock()
+
+	if err := e.leaser.ReleaseLease(context.Background(), e.name, e.holder); err != nil {
+		logger.Error(err)
+	}
+}
+
+// Campaign tries to acquire or renew the lease once and returns whether this
+// elector is the leader.
+func (e *Elector) Campaign(ctx context.Context) bool {
+	// NOTE: The expiration is taken before trying the lease, so that it is
+	// never later than the one in the database.
+	expiresAt := time.Now().Add(e.ttl)
+
+	acquired, err := e.leaser.TryLease(ctx, e.name, e.holder, e.ttl)
+	if err != nil {
+		// NOTE: The leader keeps its leadership until the lease expires,
+		// because the failure may be transient.
+		logger.Error(err)
+		return e.IsLeader()
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	wasLeader := time.Now().Before(e.expiresAt)
+	if acquired {
+		e.expiresAt = expiresAt
+		if !wasLeader {
+			logger.Infof("became the leader of %s: %s", e.name, e.holder)
+		}
+	} else {
+		e.expiresAt = time.Time{}
+		if wasLeader {
+			logger.Infof("lost the leadership of %s: %s", e.name, e.holder)
+		}
+	}
+
+	return acquired
+}
+
+// IsLeader returns whether this elector has the lease that has not expired.
+func (e *Elector) IsLeader() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return time.Now().Before(e.expiresAt)
+}
@@ -0,0 +1,140 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package election_test
+
+import (
+	"context"
+	"errors"
+	gosync "sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yorkie-team/yorkie/yorkie/election"
+)
+
+var errUnavailable = errors.New("unavailable")
+
+// memoryLeaser is a Leaser keeping the leases in memory.
+type memoryLeaser struct {
+	mu          gosync.Mutex
+	holders     map[string]string
+	expirations map[string]time.Time
+	unavailable bool
+}
+
+func newMemoryLeaser() *memoryLeaser {
+	return &memoryLeaser{
+		holders:     make(map[string]string),
+		expirations: make(map[string]time.Time),
+	}
+}
+
+func (l *memoryLeaser) TryLease(_ context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.unavailable {
+		return false, errUnavailable
+	}
+
+	now := time.Now()
+	if l.holders[name] != holder && now.Before(l.expirations[name]) {
+		return false, nil
+	}
+
+	l.holders[name] = holder
+	l.expirations[name] = now.Add(ttl)
+	return true, nil
+}
+
+func (l *memoryLeaser) ReleaseLease(_ context.Context, name, holder string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.holders[name] == holder {
+		delete(l.holders, name)
+		delete(l.expirations, name)
+	}
+	return nil
+}
+
+func (l *memoryLeaser) setUnavailable(unavailable bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.unavailable = unavailable
+}
+
+func TestElector(t *testing.T) {
+	t.Run("single leader test", func(t *testing.T) {
+		leaser := newMemoryLeaser()
+		e1 := election.New(leaser, "jobs", "agent1", time.Minute)
+		e2 := election.New(leaser, "jobs", "agent2", time.Minute)
+
+		assert.True(t, e1.Campaign(context.Background()))
+		assert.False(t, e2.Campaign(context.Background()))
+		assert.True(t, e1.IsLeader())
+		assert.False(t, e2.IsLeader())
+
+		// renewing the lease keeps the leadership.
+		assert.True(t, e1.Campaign(context.Background()))
+		assert.True(t, e1.IsLeader())
+	})
+
+	t.Run("failover test", func(t *testing.T) {
+		leaser := newMemoryLeaser()
+		e1 := election.New(leaser, "jobs", "agent1", 50*time.Millisecond)
+		e2 := election.New(leaser, "jobs", "agent2", 50*time.Millisecond)
+
+		assert.True(t, e1.Campaign(context.Background()))
+		assert.False(t, e2.Campaign(context.Background()))
+
+		// agent1 stops renewing the lease, e.g. it crashes.
+		time.Sleep(60 * time.Millisecond)
+		assert.False(t, e1.IsLeader())
+		assert.True(t, e2.Campaign(context.Background()))
+		assert.False(t, e1.Campaign(context.Background()))
+	})
+
+	t.Run("release on stop test", func(t *testing.T) {
+		leaser := newMemoryLeaser()
+		e1 := election.New(leaser, "jobs", "agent1", time.Minute)
+		e2 := election.New(leaser, "jobs", "agent2", time.Minute)
+
+		e1.Start()
+		assert.True(t, e1.IsLeader())
+		e1.Stop()
+		assert.False(t, e1.IsLeader())
+
+		assert.True(t, e2.Campaign(context.Background()))
+	})
+
+	t.Run("unavailable leaser test", func(t *testing.T) {
+		leaser := newMemoryLeaser()
+		e := election.New(leaser, "jobs", "agent1", 50*time.Millisecond)
+		assert.True(t, e.Campaign(context.Background()))
+
+		// the leadership is kept until the lease expires.
+		leaser.setUnavailable(true)
+		assert.True(t, e.Campaign(context.Background()))
+
+		time.Sleep(60 * time.Millisecond)
+		assert.False(t, e.Campaign(context.Background()))
+	})
+}
@@ -0,0 +1,225 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package housekeeping periodically cleans up the data of the agent that is
+// no longer used, so that the database stays bounded.
+//
+// Documents that are not attached to any client and have not been updated
+// for the configured period are archived and then purged from the database.
+package housekeeping
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/yorkie-team/yorkie/pkg/log"
+	"github.com/yorkie-team/yorkie/yorkie/backend"
+	"github.com/yorkie-team/yorkie/yorkie/backup"
+	"github.com/yorkie-team/yorkie/yorkie/types"
+)
+
+var logger = log.Named(log.Agent)
+
+// DefaultCandidatesLimit is the default number of documents purged at once.
+const DefaultCandidatesLimit = 100
+
+// Config is the configuration for creating a Housekeeping instance.
+type Config struct {
+	// IntervalSec is the interval between housekeeping runs. Housekeeping
+	// is not scheduled if it is zero.
+	IntervalSec time.Duration `json:"IntervalSec"`
+
+	// DocumentTTLSec is the period after which documents without attached
+	// clients and updates are purged. Documents are never purged if it is
+	// zero. It can be overridden by the profiles of collections.
+	DocumentTTLSec time.Duration `json:"DocumentTTLSec"`
+
+	// CandidatesLimit is the maximum number of documents purged in a run.
+	// DefaultCandidatesLimit is used if it is zero.
+	CandidatesLimit int `json:"CandidatesLimit"`
+
+	// ArchiveDir is the directory where the archives of the purged documents
+	// are stored.
+	ArchiveDir string `json:"ArchiveDir"`
+}
+
+// Housekeeping purges inactive documents periodically.
+type Housekeeping struct {
+	conf  *Config
+	be    *backend.Backend
+	store backup.Store
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// New creates a new instance of Housekeeping. The archives of the purged
+// documents are stored in the given store.
+func New(conf *Config, be *backend.Backend, store backup.Store) *Housekeeping {
+	return &Housekeeping{
+		conf:  conf,
+		be:    be,
+		store: store,
+	}
+}
+
+// Start starts housekeeping at the configured interval.
+func (h *Housekeeping) Start() {
+	if h.conf.IntervalSec == 0 || h.shortestTTL() == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	h.cancel = cancel
+
+	h.wg.Add(1)
+	go func() {
+		defer h.wg.Done()
+
+		ticker := time.NewTicker(h.conf.IntervalSec * time.Second)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				// NOTE: Only the leader runs it if leader election is enabled.
+				if !h.be.IsLeader() {
+					continue
+				}
+				if _, err := h.PurgeInactiveDocuments(ctx); err != nil {
+					logger.Error(err)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops housekeeping and waits for the running one to finish.
+func (h *Housekeeping) Stop() {
+	if h.cancel == nil {
+		return
+	}
+
+	h.cancel()
+	h.wg.Wait()
+}
+
+// PurgeInactiveDocuments archives and purges the documents that have not been
+// attached or updated for the configured period, and returns the number of
+// purged documents.
+func (h *Housekeeping) PurgeInactiveDocuments(ctx context.Context) (int, error) {
+	shortestTTL := h.shortestTTL()
+	if shortestTTL == 0 {
+		return 0, nil
+	}
+
+	limit := h.conf.CandidatesLimit
+	if limit <= 0 {
+		limit = DefaultCandidatesLimit
+	}
+
+	// NOTE: Documents are found with the shortest TTL of all collections,
+	// and then filtered by the TTL of their own collections.
+	now := time.Now()
+	docInfos, err := h.be.DB.FindInactiveDocInfos(ctx, now.Add(-shortestTTL), func(docInfo *types.DocInfo) bool {
+		return h.isExpired(docInfo, now)
+	}, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, docInfo := range docInfos {
+		name, err := backup.Archive(ctx, h.be, h.store, docInfo)
+		if err != nil {
+			return purged, err
+		}
+
+		// NOTE: The document can be attached or updated while it is being
+		// archived. Then it is not purged and the archive is discarded.
+		var ok bool
+		if err := h.be.DB.WithTransaction(ctx, func(ctx context.Context) error {
+			ok, err = h.be.DB.PurgeDocInfo(ctx, docInfo)
+			return err
+		}); err != nil {
+			return purged, err
+		}
+
+		if !ok {
+			logger.Infof("HOUSEKEEPING: '%s' is active again, not purged", docInfo.Key)
+			if err := h.store.Delete(name); err != nil {
+				return purged, err
+			}
+			continue
+		}
+
+		logger.Infof("HOUSEKEEPING: '%s' purged, archived to '%s'", docInfo.Key, name)
+		purged++
+	}
+
+	return purged, nil
+}
+
+// isExpired returns whether the given document has been inactive for longer
+// than the TTL of its collection at the given time.
+func (h *Housekeeping) isExpired(docInfo *types.DocInfo, now time.Time) bool {
+	docKey, err := docInfo.GetKey()
+	if err != nil {
+		logger.Error(err)
+		return false
+	}
+
+	ttl := h.ttlOf(docKey.Collection)
+	if ttl == 0 {
+		return false
+	}
+
+	updatedAt := docInfo.UpdatedAt
+	if updatedAt.IsZero() {
+		updatedAt = docInfo.CreatedAt
+	}
+	return updatedAt.Before(now.Add(-ttl))
+}
+
+// ttlOf returns the period after which the inactive documents of the given
+// collection are purged.
+func (h *Housekeeping) ttlOf(collection string) time.Duration {
+	if ttl := h.be.Config.ProfileOf(collection).DocumentTTLSec; ttl != nil {
+		return *ttl * time.Second
+	}
+
+	return h.conf.DocumentTTLSec * time.Second
+}
+
+// shortestTTL returns the shortest TTL of the documents of all collections.
+// It returns zero if no documents are purged.
+func (h *Housekeeping) shortestTTL() time.Duration {
+	shortest := h.conf.DocumentTTLSec * time.Second
+	for _, profile := range h.be.Config.Profiles {
+		if profile == nil || profile.DocumentTTLSec == nil || *profile.DocumentTTLSec == 0 {
+			continue
+		}
+
+		if ttl := *profile.DocumentTTLSec * time.Second; shortest == 0 || ttl < shortest {
+			shortest = ttl
+		}
+	}
+
+	return shortest
+}
@@ -0,0 +1,152 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package housekeeping_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yorkie-team/yorkie/api/converter"
+	"github.com/yorkie-team/yorkie/pkg/document"
+	"github.com/yorkie-team/yorkie/pkg/document/proxy"
+	"github.com/yorkie-team/yorkie/yorkie/auth"
+	"github.com/yorkie-team/yorkie/yorkie/backend"
+	"github.com/yorkie-team/yorkie/yorkie/backend/database"
+	"github.com/yorkie-team/yorkie/yorkie/backend/embedded"
+	"github.com/yorkie-team/yorkie/yorkie/backup"
+	"github.com/yorkie-team/yorkie/yorkie/housekeeping"
+)
+
+func TestHousekeeping(t *testing.T) {
+	ctx := context.Background()
+
+	dir, err := ioutil.TempDir("", "yorkie-housekeeping")
+	assert.NoError(t, err)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+
+	t.Run("purge inactive documents test", func(t *testing.T) {
+		db, err := embedded.Open(&embedded.Config{Path: filepath.Join(dir, "yorkie.db"), NoSync: true})
+		assert.NoError(t, err)
+		be, err := backend.NewWithDatabase(&backend.Config{SnapshotThreshold: 500}, db)
+		assert.NoError(t, err)
+		defer func() {
+			assert.NoError(t, be.Close())
+		}()
+
+		doc := document.New("c", "d")
+		assert.NoError(t, doc.Update(func(root *proxy.ObjectProxy) error {
+			root.SetString("k", "v")
+			return nil
+		}))
+
+		clientInfo, err := be.DB.ActivateClient(ctx, "client")
+		assert.NoError(t, err)
+		docInfo, err := be.DB.FindDocInfoByKey(ctx, clientInfo, doc.Key().BSONKey(), true)
+		assert.NoError(t, err)
+		changes := doc.CreateChangePack().Changes
+		for _, c := range changes {
+			c.SetServerSeq(docInfo.IncreaseServerSeq())
+		}
+		assert.NoError(t, be.DB.CreateChangeInfos(ctx, docInfo.ID, changes))
+		assert.NoError(t, be.DB.UpdateDocInfo(ctx, docInfo))
+		assert.NoError(t, clientInfo.AttachDocument(docInfo.ID, auth.ReadWrite))
+		assert.NoError(t, be.DB.UpdateClientInfoAfterPushPull(ctx, clientInfo, docInfo))
+
+		archiveDir := filepath.Join(dir, "archives")
+		store, err := backup.NewDirStore(archiveDir)
+		assert.NoError(t, err)
+		hk := housekeeping.New(&housekeeping.Config{DocumentTTLSec: 1}, be, store)
+
+		time.Sleep(1100 * time.Millisecond)
+
+		purged, err := hk.PurgeInactiveDocuments(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, purged)
+
+		assert.NoError(t, clientInfo.DetachDocument(docInfo.ID))
+		assert.NoError(t, be.DB.UpdateClientInfoAfterPushPull(ctx, clientInfo, docInfo))
+
+		purged, err = hk.PurgeInactiveDocuments(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, purged)
+
+		_, err = be.DB.FindDocInfoByKey(ctx, nil, doc.Key().BSONKey(), false)
+		assert.Equal(t, database.ErrDocumentNotFound, err)
+
+		files, err := ioutil.ReadDir(archiveDir)
+		assert.NoError(t, err)
+		assert.Len(t, files, 1)
+
+		restored, err := backup.Restore(ctx, be, store, files[0].Name())
+		assert.NoError(t, err)
+		assert.Equal(t, 1, restored)
+
+		restoredDocInfo, err := be.DB.FindDocInfoByKey(ctx, nil, doc.Key().BSONKey(), false)
+		assert.NoError(t, err)
+		snapshotInfo, err := be.DB.FindLastSnapshotInfo(ctx, restoredDocInfo.ID)
+		assert.NoError(t, err)
+		obj, err := converter.BytesToObject(snapshotInfo.Snapshot)
+		assert.NoError(t, err)
+		assert.Equal(t, doc.Marshal(), obj.Marshal())
+	})
+	t.Run("purge with profiles test", func(t *testing.T) {
+		db, err := embedded.Open(&embedded.Config{Path: filepath.Join(dir, "profiles.db"), NoSync: true})
+		assert.NoError(t, err)
+		chatTTL := time.Duration(1)
+		be, err := backend.NewWithDatabase(&backend.Config{
+			SnapshotThreshold: 500,
+			Profiles: map[string]*backend.Profile{
+				"chat": {DocumentTTLSec: &chatTTL},
+			},
+		}, db)
+		assert.NoError(t, err)
+		defer func() {
+			assert.NoError(t, be.Close())
+		}()
+
+		clientInfo, err := be.DB.ActivateClient(ctx, "client")
+		assert.NoError(t, err)
+		_, err = be.DB.FindDocInfoByKey(ctx, clientInfo, "chat$room", true)
+		assert.NoError(t, err)
+		_, err = be.DB.FindDocInfoByKey(ctx, clientInfo, "designs$logo", true)
+		assert.NoError(t, err)
+
+		store, err := backup.NewDirStore(filepath.Join(dir, "profile-archives"))
+		assert.NoError(t, err)
+		hk := housekeeping.New(&housekeeping.Config{}, be, store)
+
+		time.Sleep(1100 * time.Millisecond)
+
+		// only the documents of the collection with TTL are purged.
+		purged, err := hk.PurgeInactiveDocuments(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, purged)
+
+		_, err = be.DB.FindDocInfoByKey(ctx, nil, "chat$room", false)
+		assert.Equal(t, database.ErrDocumentNotFound, err)
+		_, err = be.DB.FindDocInfoByKey(ctx, nil, "designs$logo", false)
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,211 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package labels provides the labels of documents, the key-value pairs which
+// applications use to organize documents, and the selectors to filter
+// documents by their labels.
+package labels
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	// MaxKeyLength is the maximum length of the key of a label.
+	MaxKeyLength = 63
+
+	// MaxValueLength is the maximum length of the value of a label.
+	MaxValueLength = 256
+
+	// MaxLabels is the maximum number of the labels of a document.
+	MaxLabels = 64
+)
+
+var (
+	// ErrInvalidLabel is returned when the key or the value of a label is
+	// invalid.
+	ErrInvalidLabel = errors.New("invalid label")
+
+	// ErrTooManyLabels is returned when a document has more labels than
+	// MaxLabels.
+	ErrTooManyLabels = errors.New("too many labels")
+
+	// ErrInvalidSelector is returned when the selector can't be parsed.
+	ErrInvalidSelector = errors.New("invalid label selector")
+)
+
+// NOTE: Keys are stored as field names of the database, so "." and "$" are
+// not allowed.
+var keyPattern = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9_-]*[A-Za-z0-9])?$`)
+
+// Labels is the key-value pairs attached to a document.
+type Labels map[string]string
+
+// Validate checks whether the keys and values of the labels are valid.
+func (l Labels) Validate() error {
+	if len(l) > MaxLabels {
+		return fmt.Errorf("%d labels: %w", len(l), ErrTooManyLabels)
+	}
+
+	for k, v := range l {
+		if err := ValidateKey(k); err != nil {
+			return err
+		}
+		if len(v) > MaxValueLength {
+			return fmt.Errorf("value of %q: %w", k, ErrInvalidLabel)
+		}
+	}
+
+	return nil
+}
+
+// Update returns new labels with the given labels set and the given keys
+// removed.
+func (l Labels) Update(set Labels, remove []string) Labels {
+	updated := make(Labels)
+	for k, v := range l {
+		updated[k] = v
+	}
+	for k, v := range set {
+		updated[k] = v
+	}
+	for _, k := range remove {
+		delete(updated, k)
+	}
+
+	return updated
+}
+
+// ValidateKey checks whether the given key of a label is valid.
+func ValidateKey(k string) error {
+	if len(k) > MaxKeyLength || !keyPattern.MatchString(k) {
+		return fmt.Errorf("key %q: %w", k, ErrInvalidLabel)
+	}
+
+	return nil
+}
+
+// Operator is the operator of a requirement of a selector.
+type Operator string
+
+// The operators of requirements.
+const (
+	Equals    Operator = "="
+	NotEquals Operator = "!="
+	Exists    Operator = "exists"
+	NotExists Operator = "!exists"
+)
+
+// Requirement is a condition on a label of a document.
+type Requirement struct {
+	Key      string
+	Operator Operator
+	Value    string
+}
+
+// Matches returns whether the given labels satisfy the requirement. The
+// requirement of NotEquals is satisfied if the label doesn't exist.
+func (r Requirement) Matches(l Labels) bool {
+	v, ok := l[r.Key]
+	switch r.Operator {
+	case Equals:
+		return ok && v == r.Value
+	case NotEquals:
+		return !ok || v != r.Value
+	case Exists:
+		return ok
+	case NotExists:
+		return !ok
+	default:
+		return false
+	}
+}
+
+func (r Requirement) String() string {
+	switch r.Operator {
+	case Exists:
+		return r.Key
+	case NotExists:
+		return "!" + r.Key
+	default:
+		return r.Key + string(r.Operator) + r.Value
+	}
+}
+
+// Selector is the requirements that the labels of the selected documents
+// satisfy all of. An empty selector selects all documents.
+type Selector []Requirement
+
+// ParseSelector parses the given selector of comma-separated requirements,
+// e.g. "team=design,status!=archived,starred,!deleted". The values can't
+// contain commas.
+func ParseSelector(s string) (Selector, error) {
+	var selector Selector
+	if strings.TrimSpace(s) == "" {
+		return selector, nil
+	}
+
+	for _, term := range strings.Split(s, ",") {
+		term = strings.TrimSpace(term)
+
+		var r Requirement
+		switch {
+		case strings.Contains(term, "!="):
+			splits := strings.SplitN(term, "!=", 2)
+			r = Requirement{Key: splits[0], Operator: NotEquals, Value: splits[1]}
+		case strings.Contains(term, "="):
+			splits := strings.SplitN(term, "=", 2)
+			r = Requirement{Key: splits[0], Operator: Equals, Value: splits[1]}
+		case strings.HasPrefix(term, "!"):
+			r = Requirement{Key: term[1:], Operator: NotExists}
+		default:
+			r = Requirement{Key: term, Operator: Exists}
+		}
+
+		r.Key = strings.TrimSpace(r.Key)
+		r.Value = strings.TrimSpace(r.Value)
+		if err := ValidateKey(r.Key); err != nil {
+			return nil, fmt.Errorf("%q: %w", term, ErrInvalidSelector)
+		}
+		selector = append(selector, r)
+	}
+
+	return selector, nil
+}
+
+// Matches returns whether the given labels satisfy all requirements of the
+// selector.
+func (s Selector) Matches(l Labels) bool {
+	for _, r := range s {
+		if !r.Matches(l) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (s Selector) String() string {
+	var terms []string
+	for _, r := range s {
+		terms = append(terms, r.String())
+	}
+
+	return strings.Join(terms, ",")
+}
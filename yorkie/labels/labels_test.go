@@ -0,0 +1,77 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package labels_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yorkie-team/yorkie/yorkie/labels"
+)
+
+func TestLabels(t *testing.T) {
+	t.Run("validate test", func(t *testing.T) {
+		assert.NoError(t, labels.Labels{"team": "design", "tier-1": "", "a_b": "c"}.Validate())
+
+		for _, k := range []string{"", "a.b", "$a", "-a", "a-", strings.Repeat("a", labels.MaxKeyLength+1)} {
+			err := labels.Labels{k: "v"}.Validate()
+			assert.True(t, errors.Is(err, labels.ErrInvalidLabel), k)
+		}
+
+		err := labels.Labels{"k": strings.Repeat("v", labels.MaxValueLength+1)}.Validate()
+		assert.True(t, errors.Is(err, labels.ErrInvalidLabel))
+
+		many := labels.Labels{}
+		for i := 0; i <= labels.MaxLabels; i++ {
+			many[fmt.Sprintf("k%d", i)] = ""
+		}
+		assert.True(t, errors.Is(many.Validate(), labels.ErrTooManyLabels))
+	})
+
+	t.Run("update test", func(t *testing.T) {
+		l := labels.Labels{"team": "design", "status": "draft"}
+		updated := l.Update(labels.Labels{"status": "published", "starred": ""}, []string{"team"})
+		assert.Equal(t, labels.Labels{"status": "published", "starred": ""}, updated)
+		assert.Equal(t, "draft", l["status"])
+	})
+
+	t.Run("selector test", func(t *testing.T) {
+		selector, err := labels.ParseSelector("team=design, status!=archived,starred,!deleted")
+		assert.NoError(t, err)
+		assert.Equal(t, "team=design,status!=archived,starred,!deleted", selector.String())
+
+		assert.True(t, selector.Matches(labels.Labels{"team": "design", "starred": ""}))
+		assert.True(t, selector.Matches(labels.Labels{"team": "design", "starred": "", "status": "draft"}))
+		assert.False(t, selector.Matches(labels.Labels{"team": "design", "starred": "", "status": "archived"}))
+		assert.False(t, selector.Matches(labels.Labels{"team": "design", "starred": "", "deleted": ""}))
+		assert.False(t, selector.Matches(labels.Labels{"team": "infra", "starred": ""}))
+		assert.False(t, selector.Matches(nil))
+
+		empty, err := labels.ParseSelector(" ")
+		assert.NoError(t, err)
+		assert.True(t, empty.Matches(nil))
+
+		for _, s := range []string{"=design", "a.b=c", "team=design,", "!"} {
+			_, err := labels.ParseSelector(s)
+			assert.True(t, errors.Is(err, labels.ErrInvalidSelector), s)
+		}
+	})
+}
@@ -0,0 +1,49 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package packs
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// alertTimeout is the timeout of the alerts posted to webhooks.
+const alertTimeout = 3 * time.Second
+
+// postAlert posts the given alert to the given URL as JSON. Failures are only
+// logged, since alerts should not fail the operations raising them.
+func postAlert(url string, alert interface{}) {
+	payload, err := json.Marshal(alert)
+	if err != nil {
+		logger.Error(err)
+		return
+	}
+
+	client := &http.Client{Timeout: alertTimeout}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		logger.Error(err)
+		return
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		logger.Errorf("alert to %s failed: status %d", url, resp.StatusCode)
+	}
+}
@@ -0,0 +1,283 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package packs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/yorkie-team/yorkie/pkg/document/change"
+	"github.com/yorkie-team/yorkie/pkg/document/key"
+	"github.com/yorkie-team/yorkie/pkg/document/time"
+	"github.com/yorkie-team/yorkie/yorkie/backend"
+	"github.com/yorkie-team/yorkie/yorkie/backend/database"
+	"github.com/yorkie-team/yorkie/yorkie/pubsub"
+	"github.com/yorkie-team/yorkie/yorkie/types"
+)
+
+var (
+	// ErrBranchAlreadyExists is returned when a document is forked into the
+	// key of an existing document.
+	ErrBranchAlreadyExists = errors.New("the document of the branch already exists")
+
+	// ErrNotBranch is returned when a document not forked from another one
+	// is merged.
+	ErrNotBranch = errors.New("the document is not a branch")
+
+	// ErrMergeConflict is returned when a branch is merged while the actors
+	// who edited it also edited the document after the fork.
+	ErrMergeConflict = errors.New("the actors of the branch also edited the document")
+)
+
+// Fork forks the given document into a new document of the given key, the
+// branch. The branch starts with the snapshot of the document at its current
+// server sequence, and the elements in it keep their identities, so that the
+// changes of the branch can be merged back into the document later.
+func Fork(
+	ctx context.Context,
+	be *backend.Backend,
+	clientInfo *types.ClientInfo,
+	docInfo *types.DocInfo,
+	branchKey *key.Key,
+) (*types.DocInfo, error) {
+	if docInfo.Encrypted {
+		return nil, ErrEncryptedDocument
+	}
+
+	if _, err := be.DB.FindDocInfoByKey(ctx, nil, branchKey.BSONKey(), false); err == nil {
+		return nil, ErrBranchAlreadyExists
+	} else if err != database.ErrDocumentNotFound {
+		return nil, err
+	}
+
+	snapshotInfo, err := lastSnapshot(ctx, be, docInfo)
+	if err != nil {
+		return nil, err
+	}
+	forkServerSeq := snapshotInfo.ServerSeq
+
+	branchInfo, err := be.DB.FindDocInfoByKey(ctx, clientInfo, branchKey.BSONKey(), true)
+	if err != nil {
+		return nil, err
+	}
+
+	// NOTE: The branch continues the server sequences of the document. The
+	// changes up to the fork are marked as pruned, so that the clients
+	// attaching the branch receive the snapshot instead of them.
+	branchInfo.ServerSeq = forkServerSeq
+	branchInfo.PrunedServerSeq = forkServerSeq
+	branchInfo.Branch = &types.BranchInfo{
+		ParentKey:       docInfo.Key,
+		ForkServerSeq:   forkServerSeq,
+		MergedServerSeq: forkServerSeq,
+	}
+	if err := be.DB.WithTransaction(ctx, func(ctx context.Context) error {
+		if forkServerSeq > 0 {
+			if err := be.DB.CreateSnapshotInfo(ctx, branchInfo.ID, forkServerSeq, snapshotInfo.Snapshot); err != nil {
+				return err
+			}
+			if err := be.DB.UpdateDocInfo(ctx, branchInfo); err != nil {
+				return err
+			}
+			if _, err := be.DB.PruneChangeInfos(ctx, branchInfo.ID, forkServerSeq); err != nil {
+				return err
+			}
+		}
+
+		if docInfo.ACL != nil {
+			if _, err := be.DB.UpdateDocACL(ctx, branchInfo.Key, docInfo.ACL); err != nil {
+				return err
+			}
+			branchInfo.ACL = docInfo.ACL
+		}
+
+		return be.DB.UpdateDocBranch(ctx, branchInfo.ID, branchInfo.Branch)
+	}); err != nil {
+		return nil, err
+	}
+
+	logger.Infof("FORK: '%s' forked into '%s', serverSeq: %d", docInfo.Key, branchInfo.Key, forkServerSeq)
+
+	return branchInfo, nil
+}
+
+// Merge merges the changes of the given branch made since the last merge into
+// the document it was forked from, and returns the number of merged changes.
+// The changes are replayed after the changes of the document, and the
+// conflicts with them are resolved by CRDTs like those of clients.
+//
+// NOTE: The elements are identified by the tickets of the actors, and the
+// tickets of an actor in the branch and in the document can collide once they
+// diverge. So the merge is rejected with ErrMergeConflict if any actor of the
+// changes also edited the document after the fork.
+func Merge(
+	ctx context.Context,
+	be *backend.Backend,
+	branchInfo *types.DocInfo,
+) (int, error) {
+	if branchInfo.Branch == nil {
+		return 0, ErrNotBranch
+	}
+
+	lockKey := fmt.Sprintf("merge-%s", branchInfo.Key)
+	if err := be.Lock(lockKey); err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err := be.Unlock(lockKey); err != nil {
+			logger.Error(err)
+		}
+	}()
+
+	branch := *branchInfo.Branch
+	if branch.MergedServerSeq >= branchInfo.ServerSeq {
+		return 0, nil
+	}
+
+	changes, err := be.DB.FindChangeInfosBetweenServerSeqs(
+		ctx,
+		branchInfo.ID,
+		branch.MergedServerSeq+1,
+		branchInfo.ServerSeq,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	parentInfo, err := be.DB.FindDocInfoByKey(ctx, nil, branch.ParentKey, false)
+	if err != nil {
+		return 0, err
+	}
+
+	// NOTE: The changes are merged by the actor of the branch, so that the
+	// clients who made them also pull them from the document.
+	actor := time.ActorIDFromHex(branchInfo.ID.Hex())
+	branch.MergedServerSeq = branchInfo.ServerSeq
+
+	actors := make(map[string]bool)
+	for _, c := range changes {
+		actors[c.ID().Actor().String()] = true
+	}
+	checkedServerSeq := branch.ForkServerSeq
+
+	for retry := 0; ; retry++ {
+		initialServerSeq := parentInfo.ServerSeq
+		if err := checkMergeActors(ctx, be, parentInfo, checkedServerSeq, actors); err != nil {
+			return 0, err
+		}
+		checkedServerSeq = initialServerSeq
+
+		var merged []*change.Change
+		for _, c := range changes {
+			id := change.NewID(c.ClientSeq(), c.ID().Lamport(), actor)
+			mergedChange := change.New(id, c.Message(), c.Operations())
+			mergedChange.SetUser(c.User())
+			mergedChange.SetMetadata(c.Metadata())
+			mergedChange.SetServerSeq(parentInfo.IncreaseServerSeq())
+			merged = append(merged, mergedChange)
+		}
+
+		err = be.DB.WithTransaction(ctx, func(ctx context.Context) error {
+			if err := be.DB.UpdateDocInfoAfterPushPull(ctx, parentInfo, initialServerSeq); err != nil {
+				return err
+			}
+			if err := be.DB.CreateChangeInfos(ctx, parentInfo.ID, merged); err != nil {
+				return err
+			}
+			return be.DB.UpdateDocBranch(ctx, branchInfo.ID, &branch)
+		})
+		if !errors.Is(err, database.ErrConflict) || retry >= maxPushPullRetries {
+			break
+		}
+
+		logger.Warnf("MERGE: '%s' conflicts on '%s', retry: %d", branchInfo.Key, parentInfo.Key, retry+1)
+		if parentInfo, err = be.DB.FindDocInfoByKey(ctx, nil, branch.ParentKey, false); err != nil {
+			return 0, err
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+	branchInfo.Branch = &branch
+
+	logger.Infof(
+		"MERGE: '%s' merged %d changes into '%s', serverSeq: %d",
+		branchInfo.Key,
+		len(changes),
+		parentInfo.Key,
+		parentInfo.ServerSeq,
+	)
+
+	be.Publish(time.InitialActorID, parentInfo.Key, pubsub.Event{
+		Type:  pubsub.DocumentChangeEvent,
+		Value: parentInfo.Key,
+	})
+
+	return len(changes), nil
+}
+
+// checkMergeActors returns ErrMergeConflict if any of the given actors made
+// the changes of the given document after the given server sequence.
+func checkMergeActors(
+	ctx context.Context,
+	be *backend.Backend,
+	docInfo *types.DocInfo,
+	from uint64,
+	actors map[string]bool,
+) error {
+	if from >= docInfo.ServerSeq {
+		return nil
+	}
+
+	changes, err := be.DB.FindChangeInfosBetweenServerSeqs(ctx, docInfo.ID, from+1, docInfo.ServerSeq)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range changes {
+		if actors[c.ID().Actor().String()] {
+			return fmt.Errorf("%s: %w", c.ID().Actor().String(), ErrMergeConflict)
+		}
+	}
+
+	return nil
+}
+
+// lastSnapshot stores the snapshot of the given document at its current
+// server sequence if needed, and returns it.
+func lastSnapshot(
+	ctx context.Context,
+	be *backend.Backend,
+	docInfo *types.DocInfo,
+) (*types.SnapshotInfo, error) {
+	key := fmt.Sprintf("snapshot-%s", docInfo.Key)
+	if err := be.Lock(key); err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := be.Unlock(key); err != nil {
+			logger.Error(err)
+		}
+	}()
+
+	if err := storeSnapshot(ctx, be, docInfo); err != nil {
+		return nil, err
+	}
+
+	return be.DB.FindLastSnapshotInfo(ctx, docInfo.ID)
+}
@@ -0,0 +1,173 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package packs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/yorkie-team/yorkie/pkg/document"
+	"github.com/yorkie-team/yorkie/pkg/document/change"
+	"github.com/yorkie-team/yorkie/pkg/document/checkpoint"
+	"github.com/yorkie-team/yorkie/pkg/document/key"
+	"github.com/yorkie-team/yorkie/yorkie/backend"
+	"github.com/yorkie-team/yorkie/yorkie/types"
+)
+
+// ErrUnappliableChange is returned when a stored change can't be applied to
+// its document and it is not skipped.
+var ErrUnappliableChange = errors.New("the change can't be applied to the document")
+
+// deadLetterAlert is the body of the alerts of quarantined changes.
+type deadLetterAlert struct {
+	DocumentKey string `json:"document_key"`
+	ServerSeq   uint64 `json:"server_seq"`
+	Actor       string `json:"actor"`
+	ClientSeq   uint32 `json:"client_seq"`
+	Cause       string `json:"cause"`
+	Skipped     bool   `json:"skipped"`
+}
+
+// buildDocument builds the document of the given snapshot with the given
+// changes applied. If the changes can't be applied together, they are applied
+// one by one to find the ones that can't be applied, which are quarantined.
+func buildDocument(
+	ctx context.Context,
+	be *backend.Backend,
+	docInfo *types.DocInfo,
+	snapshotInfo *types.SnapshotInfo,
+	changes []*change.Change,
+) (*document.Document, error) {
+	docKey, err := docInfo.GetKey()
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := newDocument(docKey, snapshotInfo, docInfo.ServerSeq, changes)
+	if err == nil {
+		return doc, nil
+	}
+
+	if doc, err = newDocument(docKey, snapshotInfo, snapshotInfo.ServerSeq, nil); err != nil {
+		return nil, err
+	}
+
+	var applied []*change.Change
+	for _, c := range changes {
+		cause := doc.ApplyChangePack(change.NewPack(
+			docKey,
+			checkpoint.Initial.NextServerSeq(c.ServerSeq()),
+			[]*change.Change{c},
+			nil,
+		))
+		if cause == nil {
+			applied = append(applied, c)
+			continue
+		}
+
+		if err := quarantine(ctx, be, docInfo, c, cause); err != nil {
+			return nil, err
+		}
+
+		// NOTE: The document may have been partially updated by the change,
+		// so it is rebuilt without the change.
+		if doc, err = newDocument(docKey, snapshotInfo, c.ServerSeq(), applied); err != nil {
+			return nil, err
+		}
+	}
+
+	return doc, nil
+}
+
+// newDocument creates the document of the given snapshot with the given
+// changes applied up to the given server sequence.
+func newDocument(
+	docKey *key.Key,
+	snapshotInfo *types.SnapshotInfo,
+	serverSeq uint64,
+	changes []*change.Change,
+) (*document.Document, error) {
+	doc, err := document.FromSnapshot(
+		docKey.Collection,
+		docKey.Document,
+		snapshotInfo.ServerSeq,
+		snapshotInfo.Snapshot,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := doc.ApplyChangePack(change.NewPack(
+		docKey,
+		checkpoint.Initial.NextServerSeq(serverSeq),
+		changes,
+		nil,
+	)); err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+// quarantine quarantines the given change which can't be applied for the given
+// cause, and alerts the operators the first time. It returns
+// ErrUnappliableChange unless the change is skipped.
+func quarantine(
+	ctx context.Context,
+	be *backend.Backend,
+	docInfo *types.DocInfo,
+	c *change.Change,
+	cause error,
+) error {
+	skipped := be.Config.SkipUnappliableChanges
+
+	created, err := be.DB.CreateDeadLetterInfo(ctx, docInfo.ID, c, cause.Error())
+	if err != nil {
+		return err
+	}
+
+	if created {
+		logger.Errorf(
+			"DEAD: '%s', serverSeq:%d, actor:%s, clientSeq:%d, skipped:%t, %s",
+			docInfo.Key,
+			c.ServerSeq(),
+			c.ID().Actor().String(),
+			c.ID().ClientSeq(),
+			skipped,
+			cause.Error(),
+		)
+		be.Stats.DeadLetters.Inc()
+
+		if be.Config.DeadLetterWebhookURL != "" {
+			go postAlert(be.Config.DeadLetterWebhookURL, &deadLetterAlert{
+				DocumentKey: docInfo.Key,
+				ServerSeq:   c.ServerSeq(),
+				Actor:       c.ID().Actor().String(),
+				ClientSeq:   c.ID().ClientSeq(),
+				Cause:       cause.Error(),
+				Skipped:     skipped,
+			})
+		}
+	}
+
+	if skipped {
+		return nil
+	}
+
+	return fmt.Errorf("%s at %d: %s: %w", docInfo.Key, c.ServerSeq(), cause.Error(), ErrUnappliableChange)
+}
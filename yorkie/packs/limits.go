@@ -0,0 +1,164 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package packs
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/yorkie-team/yorkie/api/converter"
+	"github.com/yorkie-team/yorkie/pkg/document"
+	"github.com/yorkie-team/yorkie/pkg/document/change"
+	"github.com/yorkie-team/yorkie/pkg/document/json"
+	"github.com/yorkie-team/yorkie/pkg/document/operation"
+	"github.com/yorkie-team/yorkie/yorkie/backend"
+	"github.com/yorkie-team/yorkie/yorkie/validation"
+)
+
+// ErrDocumentLimitExceeded is returned when the changes pushed by a client
+// make the document exceed the hard limits.
+var ErrDocumentLimitExceeded = errors.New("the document exceeds the limit")
+
+// LimitError is returned when the changes pushed by a client make the
+// document exceed a hard limit. It wraps ErrDocumentLimitExceeded.
+type LimitError struct {
+	// Limit is the name of the limit, "size", "elements" or the one of the
+	// structure limits.
+	Limit string
+	Value int
+	Max   int
+}
+
+// Error returns the description of the exceeded limit.
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%s %d above %d: %s", e.Limit, e.Value, e.Max, ErrDocumentLimitExceeded.Error())
+}
+
+// Unwrap returns ErrDocumentLimitExceeded.
+func (e *LimitError) Unwrap() error {
+	return ErrDocumentLimitExceeded
+}
+
+// limitAlert is the body of the warnings of the documents above the soft
+// limits.
+type limitAlert struct {
+	DocumentKey string `json:"document_key"`
+	Size        int    `json:"size"`
+	Elements    int    `json:"elements"`
+}
+
+// checkLimits checks the limits of the document of the given request with
+// the changes applied. The limits of encrypted documents are not checked,
+// since the agent can't decode them.
+func checkLimits(be *backend.Backend, req *validation.Request) error {
+	limits := be.Config.DocumentLimits
+	if limits == nil || req.Encrypted {
+		return nil
+	}
+
+	root, err := req.Root()
+	if err != nil {
+		return err
+	}
+
+	snapshot, err := converter.ObjectToBytes(root)
+	if err != nil {
+		return err
+	}
+	size, elements := len(snapshot), countElements(root)-1
+
+	// NOTE: Changes only removing elements are accepted above the hard
+	// limits, so that the documents can be shrunk back below them.
+	if !onlyRemoves(req.Changes) {
+		if limits.HardSize > 0 && size > limits.HardSize {
+			be.Stats.HardLimitRejections.Inc()
+			return &LimitError{Limit: "size", Value: size, Max: limits.HardSize}
+		}
+		if limits.HardElements > 0 && elements > limits.HardElements {
+			be.Stats.HardLimitRejections.Inc()
+			return &LimitError{Limit: "elements", Value: elements, Max: limits.HardElements}
+		}
+
+		structureLimits := document.StructureLimits{
+			MaxDepth:     limits.MaxDepth,
+			MaxChildren:  limits.MaxChildren,
+			MaxKeyLength: limits.MaxKeyLength,
+		}
+		var structureErr *document.StructureLimitError
+		if err := structureLimits.Check(root); errors.As(err, &structureErr) {
+			be.Stats.HardLimitRejections.Inc()
+			return &LimitError{Limit: structureErr.Limit, Value: structureErr.Value, Max: structureErr.Max}
+		}
+	}
+
+	limited := (limits.SoftSize > 0 && size > limits.SoftSize) ||
+		(limits.SoftElements > 0 && elements > limits.SoftElements)
+	docKey := req.DocumentKey.BSONKey()
+	if be.MarkSoftLimited(docKey, limited) {
+		logger.Warnf("LIMIT: '%s', size:%d, elements:%d", docKey, size, elements)
+		be.Stats.SoftLimitWarnings.Inc()
+
+		if limits.WebhookURL != "" {
+			go postAlert(limits.WebhookURL, &limitAlert{
+				DocumentKey: docKey,
+				Size:        size,
+				Elements:    elements,
+			})
+		}
+	}
+
+	return nil
+}
+
+// countElements returns the number of the elements in the given element,
+// including itself, which are not removed.
+func countElements(elem json.Element) int {
+	count := 1
+	switch elem := elem.(type) {
+	case *json.Object:
+		elem.ForEach(func(k string, e json.Element) bool {
+			count += countElements(e)
+			return true
+		})
+	case *json.Array:
+		for _, e := range elem.Elements() {
+			count += countElements(e)
+		}
+	}
+
+	return count
+}
+
+// onlyRemoves returns whether the given changes only remove elements or
+// contents of texts.
+func onlyRemoves(changes []*change.Change) bool {
+	for _, c := range changes {
+		for _, op := range c.Operations() {
+			switch op := op.(type) {
+			case *operation.Remove, *operation.Select:
+			case *operation.Edit:
+				if op.Content() != "" {
+					return false
+				}
+			default:
+				return false
+			}
+		}
+	}
+
+	return true
+}
@@ -18,6 +18,7 @@ package packs
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/yorkie-team/yorkie/api/converter"
@@ -27,32 +28,140 @@ import (
 	"github.com/yorkie-team/yorkie/pkg/document/time"
 	"github.com/yorkie-team/yorkie/pkg/log"
 	"github.com/yorkie-team/yorkie/yorkie/backend"
+	"github.com/yorkie-team/yorkie/yorkie/backend/database"
 	"github.com/yorkie-team/yorkie/yorkie/pubsub"
 	"github.com/yorkie-team/yorkie/yorkie/types"
 )
 
+var logger = log.Named(log.Agent)
+
+var (
+	// ErrEncryptionMismatch is returned when the changes pushed to a document
+	// are not encrypted the same way as the changes already stored.
+	ErrEncryptionMismatch = errors.New("encryption mode of the changes does not match the document")
+
+	// ErrEncryptedDocument is returned when a snapshot of an end-to-end
+	// encrypted document is requested.
+	ErrEncryptedDocument = errors.New("the document is encrypted end-to-end")
+
+	// ErrDocumentAlreadyExists is returned by PushPullNew when the document
+	// already has changes.
+	ErrDocumentAlreadyExists = errors.New("document already exists")
+)
+
+// maxPushPullRetries is the maximum number of retries of a push-pull that
+// conflicts with those of other agents.
+const maxPushPullRetries = 5
+
+// snapshotBufferPool is used to reuse the buffers of encoded snapshots, which
+// are no longer needed once they are stored.
+var snapshotBufferPool = converter.NewBufferPool()
+
+// PushPull stores the changes of the given pack and returns the pack of the
+// changes the client doesn't have yet.
+//
+// The document is locked only within this agent, so another agent sharing the
+// database can store changes of the document at the same time. The conflict
+// is detected when the document info is written, and the push-pull is retried
+// with the latest document info. Changes of CRDTs commute, so the pushed
+// changes are merged simply by giving them the next server sequences.
 func PushPull(
 	ctx context.Context,
 	be *backend.Backend,
 	clientInfo *types.ClientInfo,
 	docInfo *types.DocInfo,
 	reqPack *change.Pack,
+) (*change.Pack, error) {
+	return pushPullWithRetries(ctx, be, clientInfo, docInfo, reqPack, false)
+}
+
+// PushPullNew is PushPull for a client creating the document. It fails with
+// ErrDocumentAlreadyExists if the document already has changes. The check is
+// atomic with the push, because the document info is only written if its
+// server sequence is still the one checked.
+func PushPullNew(
+	ctx context.Context,
+	be *backend.Backend,
+	clientInfo *types.ClientInfo,
+	docInfo *types.DocInfo,
+	reqPack *change.Pack,
+) (*change.Pack, error) {
+	return pushPullWithRetries(ctx, be, clientInfo, docInfo, reqPack, true)
+}
+
+func pushPullWithRetries(
+	ctx context.Context,
+	be *backend.Backend,
+	clientInfo *types.ClientInfo,
+	docInfo *types.DocInfo,
+	reqPack *change.Pack,
+	failIfExists bool,
+) (*change.Pack, error) {
+	hexDocID := docInfo.ID.Hex()
+	var clientDocInfo *types.ClientDocInfo
+	if info, ok := clientInfo.Documents[hexDocID]; ok {
+		infoCopy := *info
+		clientDocInfo = &infoCopy
+	}
+
+	for retry := 0; ; retry++ {
+		respPack, err := pushPull(ctx, be, clientInfo, docInfo, reqPack, failIfExists)
+		if !errors.Is(err, database.ErrConflict) || retry >= maxPushPullRetries {
+			return respPack, err
+		}
+
+		be.Stats.PushPullConflicts.Mark(1)
+		logger.Warnf("PUSH: '%s' conflicts on '%s', retry: %d", clientInfo.ID.Hex(), docInfo.Key, retry+1)
+
+		latest, err := be.DB.FindDocInfoByKey(ctx, clientInfo, docInfo.Key, false)
+		if err != nil {
+			return nil, err
+		}
+		*docInfo = *latest
+
+		if clientDocInfo != nil {
+			infoCopy := *clientDocInfo
+			clientInfo.Documents[hexDocID] = &infoCopy
+		}
+	}
+}
+
+func pushPull(
+	ctx context.Context,
+	be *backend.Backend,
+	clientInfo *types.ClientInfo,
+	docInfo *types.DocInfo,
+	reqPack *change.Pack,
+	failIfExists bool,
 ) (*change.Pack, error) {
 	// TODO Changes may be reordered or missing during communication on the network.
 	// We should check the change.pack with checkpoint to make sure the changes are in the correct order.
-
-	// TODO We need to prevent the same document from being modified at the same time.
-	// For this, We may want to consider introducing distributed lock or DB transaction.
-	// To improve read performance, we can also consider something like read-write lock,
-	// because simple read operations do not break consistency.
 	initialServerSeq := docInfo.ServerSeq
+	if failIfExists && initialServerSeq > 0 {
+		return nil, ErrDocumentAlreadyExists
+	}
+
+	// The first changes decide whether the document is encrypted end-to-end,
+	// and the mode can't be changed afterwards.
+	if reqPack.HasChanges() {
+		if initialServerSeq == 0 {
+			docInfo.Encrypted = reqPack.Encrypted
+		} else if docInfo.Encrypted != reqPack.Encrypted {
+			return nil, ErrEncryptionMismatch
+		}
+	}
 
 	// 01. push changes.
-	pushedCP, pushedChanges, err := pushChanges(clientInfo, docInfo, reqPack, initialServerSeq)
+	pushedCP, pushedChanges, err := pushChanges(be, clientInfo, docInfo, reqPack, initialServerSeq)
 	if err != nil {
 		return nil, err
 	}
 
+	req := newRequest(ctx, be, clientInfo, docInfo, reqPack, pushedChanges, initialServerSeq)
+	if err := validateChanges(ctx, be, req); err != nil {
+		return nil, err
+	}
+
 	// 02. pull change pack.
 	respPack, err := pullPack(ctx, be, clientInfo, docInfo, reqPack, pushedCP, initialServerSeq)
 	if err != nil {
@@ -63,16 +172,24 @@ func PushPull(
 		return nil, err
 	}
 
-	// 03. save pushed changes, document info and checkpoint of the client to MongoDB.
-	if err := be.Mongo.CreateChangeInfos(ctx, docInfo.ID, pushedChanges); err != nil {
-		return nil, err
-	}
+	// 03. save pushed changes, document info and checkpoint of the client to
+	// MongoDB atomically, so a failure in the middle can't leave them
+	// inconsistent. The document info is written first, so that the server
+	// sequences of the pushed changes are reserved before the changes are
+	// written even without transactions.
+	if err := be.DB.WithTransaction(ctx, func(ctx context.Context) error {
+		if len(pushedChanges) > 0 {
+			if err := be.DB.UpdateDocInfoAfterPushPull(ctx, docInfo, initialServerSeq); err != nil {
+				return err
+			}
 
-	if err := be.Mongo.UpdateDocInfo(ctx, docInfo); err != nil {
-		return nil, err
-	}
+			if err := be.DB.CreateChangeInfos(ctx, docInfo.ID, pushedChanges); err != nil {
+				return err
+			}
+		}
 
-	if err := be.Mongo.UpdateClientInfoAfterPushPull(ctx, clientInfo, docInfo); err != nil {
+		return be.DB.UpdateClientInfoAfterPushPull(ctx, clientInfo, docInfo)
+	}); err != nil {
 		return nil, err
 	}
 
@@ -82,25 +199,26 @@ func PushPull(
 			time.ActorIDFromHex(clientInfo.ID.Hex()),
 			reqPack.DocumentKey.BSONKey(),
 			pubsub.Event{
-				Type:  pubsub.DocumentChangeEvent,
-				Value: reqPack.DocumentKey.BSONKey(),
+				Type:         pubsub.DocumentChangeEvent,
+				Value:        reqPack.DocumentKey.BSONKey(),
+				ChangedPaths: summarizeChanges(be, req),
 			},
 		)
 
 		go func() {
-			key := fmt.Sprintf("snapshot-%s", clientInfo.Key)
+			key := fmt.Sprintf("snapshot-%s", docInfo.Key)
 			if err := be.Lock(key); err != nil {
-				log.Logger.Error(err)
+				logger.Error(err)
 			}
 			defer func() {
 				if err := be.Unlock(key); err != nil {
-					log.Logger.Error(err)
+					logger.Error(err)
 				}
 			}()
 
 			// TODO We need to increase interval of storing snapshot.
 			if err := storeSnapshot(context.Background(), be, docInfo); err != nil {
-				log.Logger.Error(err)
+				logger.Error(err)
 			}
 		}()
 	}
@@ -110,11 +228,12 @@ func PushPull(
 
 // pushChanges returns the changes excluding already saved in MongoDB.
 func pushChanges(
+	be *backend.Backend,
 	clientInfo *types.ClientInfo,
 	docInfo *types.DocInfo,
 	pack *change.Pack,
 	initialServerSeq uint64,
-) (*checkpoint.Checkpoint, []*change.Change, error) {
+) (checkpoint.Checkpoint, []*change.Change, error) {
 	cp := clientInfo.GetCheckpoint(docInfo.ID)
 
 	var pushedChanges []*change.Change
@@ -123,16 +242,23 @@ func pushChanges(
 			serverSeq := docInfo.IncreaseServerSeq()
 			cp = cp.NextServerSeq(serverSeq)
 			c.SetServerSeq(serverSeq)
+			c.SetUser(pack.User)
 			pushedChanges = append(pushedChanges, c)
 		} else {
-			log.Logger.Warnf("change is rejected: %v", c)
+			logger.Warnf("change is rejected: %v", c)
 		}
 
 		cp = cp.SyncClientSeq(c.ClientSeq())
 	}
 
+	var pushedOps int64
+	for _, c := range pushedChanges {
+		pushedOps += int64(len(c.Operations()))
+	}
+	be.Stats.PushedOperations.Mark(pushedOps)
+
 	if len(pack.Changes) > 0 {
-		log.Logger.Infof(
+		logger.Infof(
 			"PUSH: '%s' pushes %d changes into '%s', rejected %d changes, serverSeq: %d -> %d, cp: %s",
 			clientInfo.ID.Hex(),
 			len(pushedChanges),
@@ -153,7 +279,7 @@ func pullPack(
 	clientInfo *types.ClientInfo,
 	docInfo *types.DocInfo,
 	requestPack *change.Pack,
-	pushedCP *checkpoint.Checkpoint,
+	pushedCP checkpoint.Checkpoint,
 	initialServerSeq uint64,
 ) (*change.Pack, error) {
 	docKey, err := docInfo.GetKey()
@@ -161,7 +287,12 @@ func pullPack(
 		return nil, err
 	}
 
-	if initialServerSeq-requestPack.Checkpoint.ServerSeq < be.Config.SnapshotThreshold {
+	// Changes up to PrunedServerSeq are no longer stored, so clients behind it
+	// can only be synchronized with a snapshot. Encrypted documents have no
+	// snapshots, so their changes are always pulled.
+	if docInfo.Encrypted ||
+		initialServerSeq-requestPack.Checkpoint.ServerSeq < be.Config.SnapshotThresholdOf(docKey.Collection) &&
+			requestPack.Checkpoint.ServerSeq >= docInfo.PrunedServerSeq {
 		pulledCP, pulledChanges, err := pullChanges(ctx, be, clientInfo, docInfo, requestPack, pushedCP, initialServerSeq)
 		if err != nil {
 			return nil, err
@@ -182,22 +313,27 @@ func pullChanges(
 	clientInfo *types.ClientInfo,
 	docInfo *types.DocInfo,
 	pack *change.Pack,
-	pushedCP *checkpoint.Checkpoint,
+	pushedCP checkpoint.Checkpoint,
 	initialServerSeq uint64,
-) (*checkpoint.Checkpoint, []*change.Change, error) {
-	fetchedChanges, err := be.Mongo.FindChangeInfosBetweenServerSeqs(
+) (checkpoint.Checkpoint, []*change.Change, error) {
+	fetchedChanges, err := be.DB.FindChangeInfosBetweenServerSeqs(
 		ctx,
 		docInfo.ID,
 		pack.Checkpoint.ServerSeq+1,
 		initialServerSeq,
 	)
 	if err != nil {
-		return nil, nil, err
+		return checkpoint.Initial, nil, err
 	}
 
+	// NOTE: The changes of the client are skipped if it already has them.
+	// A client resuming its session with a new replica of the document
+	// doesn't have them, and it tells so with a lower client sequence.
+	clientActor := time.ActorID(clientInfo.ID)
 	var pulledChanges []*change.Change
 	for _, fetchedChange := range fetchedChanges {
-		if fetchedChange.ID().Actor().String() == clientInfo.ID.Hex() {
+		if *fetchedChange.ID().Actor() == clientActor &&
+			fetchedChange.ClientSeq() <= pack.Checkpoint.ClientSeq {
 			continue
 		}
 
@@ -207,7 +343,7 @@ func pullChanges(
 	pulledCP := pushedCP.NextServerSeq(docInfo.ServerSeq)
 
 	if len(pulledChanges) > 0 {
-		log.Logger.Infof(
+		logger.Infof(
 			"PULL: '%s' pulls %d changes(%d~%d) from '%s', cp: %s",
 			clientInfo.ID.Hex(),
 			len(pulledChanges),
@@ -227,17 +363,17 @@ func pullSnapshot(
 	clientInfo *types.ClientInfo,
 	docInfo *types.DocInfo,
 	pack *change.Pack,
-	pushedCP *checkpoint.Checkpoint,
+	pushedCP checkpoint.Checkpoint,
 	initialServerSeq uint64,
-) (*checkpoint.Checkpoint, []byte, error) {
-	snapshotInfo, err := be.Mongo.FindLastSnapshotInfo(ctx, docInfo.ID)
+) (checkpoint.Checkpoint, []byte, error) {
+	snapshotInfo, err := be.DB.FindLastSnapshotInfo(ctx, docInfo.ID)
 	if err != nil {
-		return nil, nil, err
+		return checkpoint.Initial, nil, err
 	}
 
 	if snapshotInfo.ServerSeq >= initialServerSeq {
 		pulledCP := pushedCP.NextServerSeq(docInfo.ServerSeq)
-		log.Logger.Infof(
+		logger.Infof(
 			"PULL: '%s' pulls snapshot without changes from '%s', cp: %s",
 			clientInfo.ID.Hex(),
 			docInfo.Key,
@@ -248,7 +384,7 @@ func pullSnapshot(
 
 	docKey, err := docInfo.GetKey()
 	if err != nil {
-		return nil, nil, err
+		return checkpoint.Initial, nil, err
 	}
 
 	doc, err := document.FromSnapshot(
@@ -258,17 +394,17 @@ func pullSnapshot(
 		snapshotInfo.Snapshot,
 	)
 	if err != nil {
-		return nil, nil, err
+		return checkpoint.Initial, nil, err
 	}
 
-	changes, err := be.Mongo.FindChangeInfosBetweenServerSeqs(
+	changes, err := be.DB.FindChangeInfosBetweenServerSeqs(
 		ctx,
 		docInfo.ID,
 		pack.Checkpoint.ServerSeq+1,
 		initialServerSeq,
 	)
 	if err != nil {
-		return nil, nil, err
+		return checkpoint.Initial, nil, err
 	}
 
 	if err := doc.ApplyChangePack(change.NewPack(
@@ -277,12 +413,12 @@ func pullSnapshot(
 		changes,
 		nil,
 	)); err != nil {
-		return nil, nil, err
+		return checkpoint.Initial, nil, err
 	}
 
 	pulledCP := pushedCP.NextServerSeq(docInfo.ServerSeq)
 
-	log.Logger.Infof(
+	logger.Infof(
 		"PULL: '%s' pulls snapshot with changes(%d~%d) from '%s', cp: %s",
 		clientInfo.ID.Hex(),
 		pack.Checkpoint.ServerSeq+1,
@@ -293,19 +429,99 @@ func pullSnapshot(
 
 	snapshot, err := converter.ObjectToBytes(doc.RootObject())
 	if err != nil {
-		return nil, nil, err
+		return checkpoint.Initial, nil, err
 	}
 
 	return pulledCP, snapshot, nil
 }
 
+// ForceSnapshot stores the snapshot of the given document immediately
+// regardless of the threshold. If pruneChanges is true, the changes already
+// included in the snapshot are deleted. It returns the server sequence of
+// the snapshot and the number of pruned changes.
+func ForceSnapshot(
+	ctx context.Context,
+	be *backend.Backend,
+	docInfo *types.DocInfo,
+	pruneChanges bool,
+) (uint64, int64, error) {
+	if docInfo.Encrypted {
+		return 0, 0, ErrEncryptedDocument
+	}
+
+	key := fmt.Sprintf("snapshot-%s", docInfo.Key)
+	if err := be.Lock(key); err != nil {
+		return 0, 0, err
+	}
+	defer func() {
+		if err := be.Unlock(key); err != nil {
+			logger.Error(err)
+		}
+	}()
+
+	if err := storeSnapshot(ctx, be, docInfo); err != nil {
+		return 0, 0, err
+	}
+
+	if !pruneChanges || docInfo.ServerSeq == 0 {
+		return docInfo.ServerSeq, 0, nil
+	}
+
+	pruned, err := be.DB.PruneChangeInfos(ctx, docInfo.ID, docInfo.ServerSeq)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	logger.Infof(
+		"PRUNE: '%s' pruned %d changes, serverSeq: %d",
+		docInfo.Key,
+		pruned,
+		docInfo.ServerSeq,
+	)
+
+	return docInfo.ServerSeq, pruned, nil
+}
+
+// CreateTag tags the current server sequence of the given document with the
+// given name. The snapshot of the document at the server sequence is stored
+// with the tag, so that the tag can be opened after the changes are pruned.
+func CreateTag(
+	ctx context.Context,
+	be *backend.Backend,
+	docInfo *types.DocInfo,
+	name string,
+) (*types.TagInfo, error) {
+	if docInfo.Encrypted {
+		return nil, ErrEncryptedDocument
+	}
+
+	snapshotInfo, err := lastSnapshot(ctx, be, docInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	tagInfo, err := be.DB.CreateTagInfo(ctx, docInfo.ID, name, snapshotInfo.ServerSeq, snapshotInfo.Snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Infof("TAG: '%s' tagged '%s', serverSeq: %d", docInfo.Key, name, tagInfo.ServerSeq)
+
+	return tagInfo, nil
+}
+
 func storeSnapshot(
 	ctx context.Context,
 	be *backend.Backend,
 	docInfo *types.DocInfo,
 ) error {
+	// NOTE: The agent can't decode the payloads of encrypted documents.
+	if docInfo.Encrypted {
+		return nil
+	}
+
 	// 01. get the last snapshot of this docInfo
-	snapshotInfo, err := be.Mongo.FindLastSnapshotInfo(ctx, docInfo.ID)
+	snapshotInfo, err := be.DB.FindLastSnapshotInfo(ctx, docInfo.ID)
 	if err != nil {
 		return err
 	}
@@ -315,7 +531,7 @@ func storeSnapshot(
 	}
 
 	// 02. retrieve the changes between last snapshot and current docInfo
-	changes, err := be.Mongo.FindChangeInfosBetweenServerSeqs(
+	changes, err := be.DB.FindChangeInfosBetweenServerSeqs(
 		ctx,
 		docInfo.ID,
 		snapshotInfo.ServerSeq+1,
@@ -326,34 +542,35 @@ func storeSnapshot(
 	}
 
 	// 03. create document instance of the docInfo
-	docKey, err := docInfo.GetKey()
+	doc, err := buildDocument(ctx, be, docInfo, snapshotInfo, changes)
 	if err != nil {
 		return err
 	}
 
-	doc, err := document.FromSnapshot(
-		docKey.Collection,
-		docKey.Document,
-		snapshotInfo.ServerSeq,
-		snapshotInfo.Snapshot,
+	logger.Infof("SNAP: '%s', serverSeq:%d", docInfo.Key, doc.Checkpoint().ServerSeq)
+
+	// NOTE: Each change is applied once here since the last snapshot, so the
+	// conflicts resolved in the document are counted once.
+	docStats := doc.Stats()
+	be.Stats.ConcurrentSets.Mark(docStats.ConcurrentSets)
+	be.Stats.InterleavedTextEdits.Mark(docStats.InterleavedTextEdits)
+
+	// 04. encode the snapshot reusing the subtrees untouched since the last one
+	encoder := be.SnapshotEncoder(docInfo.ID.Hex(), snapshotInfo.ServerSeq)
+	encoder.Touch(changes)
+	serverSeq := doc.Checkpoint().ServerSeq
+	snapshot, err := encoder.Encode(
+		doc.RootObject(),
+		serverSeq,
+		converter.WithBufferPool(snapshotBufferPool),
 	)
 	if err != nil {
 		return err
 	}
+	defer snapshotBufferPool.Put(snapshot)
 
-	if err := doc.ApplyChangePack(change.NewPack(
-		docKey,
-		checkpoint.Initial.NextServerSeq(docInfo.ServerSeq),
-		changes,
-		nil,
-	)); err != nil {
-		return err
-	}
-
-	log.Logger.Infof("SNAP: '%s', serverSeq:%d", docInfo.Key, doc.Checkpoint().ServerSeq)
-
-	// 04. save the snapshot of the docInfo
-	if err := be.Mongo.CreateSnapshotInfo(ctx, docInfo.ID, doc); err != nil {
+	// 05. save the snapshot of the docInfo
+	if err := be.DB.CreateSnapshotInfo(ctx, docInfo.ID, serverSeq, snapshot); err != nil {
 		return err
 	}
 
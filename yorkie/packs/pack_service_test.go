@@ -0,0 +1,423 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package packs_test
+
+import (
+	"context"
+	gojson "encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yorkie-team/yorkie/pkg/document"
+	"github.com/yorkie-team/yorkie/pkg/document/change"
+	"github.com/yorkie-team/yorkie/pkg/document/json"
+	"github.com/yorkie-team/yorkie/pkg/document/operation"
+	"github.com/yorkie-team/yorkie/pkg/document/proxy"
+	"github.com/yorkie-team/yorkie/pkg/document/time"
+	"github.com/yorkie-team/yorkie/yorkie/auth"
+	"github.com/yorkie-team/yorkie/yorkie/backend"
+	"github.com/yorkie-team/yorkie/yorkie/backend/embedded"
+	"github.com/yorkie-team/yorkie/yorkie/packs"
+	"github.com/yorkie-team/yorkie/yorkie/types"
+	"github.com/yorkie-team/yorkie/yorkie/validation"
+)
+
+func TestPushPull(t *testing.T) {
+	ctx := context.Background()
+
+	dir, err := ioutil.TempDir("", "yorkie-packs")
+	assert.NoError(t, err)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+
+	db, err := embedded.Open(&embedded.Config{Path: filepath.Join(dir, "yorkie.db"), NoSync: true})
+	assert.NoError(t, err)
+	be, err := backend.NewWithDatabase(&backend.Config{SnapshotThreshold: 500}, db)
+	assert.NoError(t, err)
+	defer func() {
+		assert.NoError(t, be.Close())
+	}()
+
+	newClient := func(key string) (*types.ClientInfo, *document.Document) {
+		clientInfo, err := be.DB.ActivateClient(ctx, key)
+		assert.NoError(t, err)
+
+		doc := document.New("c", "d")
+		doc.SetActor(time.ActorIDFromHex(clientInfo.ID.Hex()))
+		return clientInfo, doc
+	}
+
+	t.Run("retry on conflict test", func(t *testing.T) {
+		clientA, docA := newClient("client-a")
+		clientB, docB := newClient("client-b")
+
+		docInfoA, err := be.DB.FindDocInfoByKey(ctx, clientA, docA.Key().BSONKey(), true)
+		assert.NoError(t, err)
+		assert.NoError(t, clientA.AttachDocument(docInfoA.ID, auth.ReadWrite))
+
+		// NOTE: B reads the document before A stores its changes, as if B
+		// were served by another agent at the same time.
+		docInfoB, err := be.DB.FindDocInfoByKey(ctx, clientB, docB.Key().BSONKey(), false)
+		assert.NoError(t, err)
+		assert.NoError(t, clientB.AttachDocument(docInfoB.ID, auth.ReadWrite))
+
+		assert.NoError(t, docA.Update(func(root *proxy.ObjectProxy) error {
+			root.SetString("a", "1")
+			return nil
+		}))
+		_, err = packs.PushPull(ctx, be, clientA, docInfoA, docA.CreateChangePack())
+		assert.NoError(t, err)
+
+		assert.NoError(t, docB.Update(func(root *proxy.ObjectProxy) error {
+			root.SetString("b", "2")
+			return nil
+		}))
+		respPack, err := packs.PushPull(ctx, be, clientB, docInfoB, docB.CreateChangePack())
+		assert.NoError(t, err)
+		assert.Len(t, respPack.Changes, 1)
+		assert.Equal(t, uint64(2), docInfoB.ServerSeq)
+
+		changes, err := be.DB.FindChangeInfosBetweenServerSeqs(ctx, docInfoB.ID, 1, 2)
+		assert.NoError(t, err)
+		assert.Len(t, changes, 2)
+		assert.Equal(t, clientA.ID.Hex(), changes[0].ID().Actor().String())
+		assert.Equal(t, clientB.ID.Hex(), changes[1].ID().Actor().String())
+		assert.True(t, be.Stats.PushPullConflicts.Rate() > 0)
+	})
+
+	t.Run("push-pull new test", func(t *testing.T) {
+		clientA, _ := newClient("client-new-a")
+		clientB, _ := newClient("client-new-b")
+		docA := document.New("c", "new")
+		docA.SetActor(time.ActorIDFromHex(clientA.ID.Hex()))
+		docB := document.New("c", "new")
+		docB.SetActor(time.ActorIDFromHex(clientB.ID.Hex()))
+
+		docInfoA, err := be.DB.FindDocInfoByKey(ctx, clientA, docA.Key().BSONKey(), true)
+		assert.NoError(t, err)
+		assert.NoError(t, clientA.AttachDocument(docInfoA.ID, auth.ReadWrite))
+
+		// NOTE: B checks the document before A creates it, as if B were
+		// served by another agent at the same time.
+		docInfoB, err := be.DB.FindDocInfoByKey(ctx, clientB, docB.Key().BSONKey(), false)
+		assert.NoError(t, err)
+		assert.NoError(t, clientB.AttachDocument(docInfoB.ID, auth.ReadWrite))
+
+		assert.NoError(t, docA.Update(func(root *proxy.ObjectProxy) error {
+			root.SetString("a", "1")
+			return nil
+		}))
+		_, err = packs.PushPullNew(ctx, be, clientA, docInfoA, docA.CreateChangePack())
+		assert.NoError(t, err)
+
+		assert.NoError(t, docB.Update(func(root *proxy.ObjectProxy) error {
+			root.SetString("b", "2")
+			return nil
+		}))
+		_, err = packs.PushPullNew(ctx, be, clientB, docInfoB, docB.CreateChangePack())
+		assert.Equal(t, packs.ErrDocumentAlreadyExists, err)
+
+		changes, err := be.DB.FindChangeInfosBetweenServerSeqs(ctx, docInfoA.ID, 1, 2)
+		assert.NoError(t, err)
+		assert.Len(t, changes, 1)
+	})
+}
+
+func TestValidation(t *testing.T) {
+	ctx := context.Background()
+
+	dir, err := ioutil.TempDir("", "yorkie-packs")
+	assert.NoError(t, err)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+
+	forbidden := validation.ValidatorFunc(func(ctx context.Context, req *validation.Request) error {
+		root, err := req.Root()
+		if err != nil {
+			return err
+		}
+		if root.Has("forbidden") {
+			return validation.Reject("forbidden", validation.Violation{
+				Path:        "$.forbidden",
+				Description: "forbidden key",
+			})
+		}
+		return nil
+	})
+
+	db, err := embedded.Open(&embedded.Config{Path: filepath.Join(dir, "yorkie.db"), NoSync: true})
+	assert.NoError(t, err)
+	be, err := backend.NewWithDatabase(&backend.Config{
+		SnapshotThreshold: 500,
+		Validators:        []validation.Validator{forbidden},
+	}, db)
+	assert.NoError(t, err)
+	defer func() {
+		assert.NoError(t, be.Close())
+	}()
+
+	t.Run("reject changes test", func(t *testing.T) {
+		clientInfo, err := be.DB.ActivateClient(ctx, "client")
+		assert.NoError(t, err)
+		doc := document.New("c", "d")
+		doc.SetActor(time.ActorIDFromHex(clientInfo.ID.Hex()))
+
+		docInfo, err := be.DB.FindDocInfoByKey(ctx, clientInfo, doc.Key().BSONKey(), true)
+		assert.NoError(t, err)
+		assert.NoError(t, clientInfo.AttachDocument(docInfo.ID, auth.ReadWrite))
+
+		assert.NoError(t, doc.Update(func(root *proxy.ObjectProxy) error {
+			root.SetString("allowed", "1")
+			return nil
+		}))
+		_, err = packs.PushPull(ctx, be, clientInfo, docInfo, doc.CreateChangePack())
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(1), docInfo.ServerSeq)
+
+		// NOTE: The snapshot of the previous push is stored asynchronously
+		// with its docInfo, so the next push loads its own as the RPC server
+		// does.
+		docInfo, err = be.DB.FindDocInfoByKey(ctx, clientInfo, doc.Key().BSONKey(), false)
+		assert.NoError(t, err)
+
+		assert.NoError(t, doc.Update(func(root *proxy.ObjectProxy) error {
+			root.SetString("forbidden", "2")
+			return nil
+		}))
+		_, err = packs.PushPull(ctx, be, clientInfo, docInfo, doc.CreateChangePack())
+		var verr *validation.Error
+		assert.True(t, errors.As(err, &verr))
+		assert.Equal(t, "$.forbidden", verr.Violations[0].Path)
+
+		changes, err := be.DB.FindChangeInfosBetweenServerSeqs(ctx, docInfo.ID, 1, 10)
+		assert.NoError(t, err)
+		assert.Len(t, changes, 1)
+	})
+}
+
+func TestDeadLetter(t *testing.T) {
+	ctx := context.Background()
+
+	dir, err := ioutil.TempDir("", "yorkie-packs")
+	assert.NoError(t, err)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+
+	alerts := make(chan map[string]interface{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		alert := make(map[string]interface{})
+		assert.NoError(t, gojson.NewDecoder(r.Body).Decode(&alert))
+		alerts <- alert
+	}))
+	defer server.Close()
+
+	db, err := embedded.Open(&embedded.Config{Path: filepath.Join(dir, "yorkie.db"), NoSync: true})
+	assert.NoError(t, err)
+	be, err := backend.NewWithDatabase(&backend.Config{
+		SnapshotThreshold:    500,
+		DeadLetterWebhookURL: server.URL,
+	}, db)
+	assert.NoError(t, err)
+	defer func() {
+		assert.NoError(t, be.Close())
+	}()
+
+	t.Run("quarantine unappliable change test", func(t *testing.T) {
+		clientInfo, err := be.DB.ActivateClient(ctx, "client")
+		assert.NoError(t, err)
+		actorID := time.ActorIDFromHex(clientInfo.ID.Hex())
+		doc := document.New("c", "d")
+		doc.SetActor(actorID)
+
+		docInfo, err := be.DB.FindDocInfoByKey(ctx, clientInfo, doc.Key().BSONKey(), true)
+		assert.NoError(t, err)
+		assert.NoError(t, clientInfo.AttachDocument(docInfo.ID, auth.ReadWrite))
+
+		assert.NoError(t, doc.Update(func(root *proxy.ObjectProxy) error {
+			root.SetString("a", "1")
+			return nil
+		}))
+		_, err = packs.PushPull(ctx, be, clientInfo, docInfo, doc.CreateChangePack())
+		assert.NoError(t, err)
+
+		// NOTE: The snapshot of the push is stored asynchronously with its
+		// docInfo, so the broken change is stored with a fresh one.
+		docInfo, err = be.DB.FindDocInfoByKey(ctx, clientInfo, doc.Key().BSONKey(), false)
+		assert.NoError(t, err)
+
+		// NOTE: The change sets a value to an object that doesn't exist, as if
+		// it were corrupted.
+		unknown := time.NewTicket(100, 1, actorID)
+		broken := change.New(change.NewID(10, 100, actorID), "broken", []operation.Operation{
+			operation.NewSet(unknown, "b", json.NewPrimitive("2", time.NewTicket(101, 1, actorID)), unknown),
+		})
+		broken.SetServerSeq(docInfo.IncreaseServerSeq())
+		assert.NoError(t, be.DB.CreateChangeInfos(ctx, docInfo.ID, []*change.Change{broken}))
+		assert.NoError(t, be.DB.UpdateDocInfo(ctx, docInfo))
+
+		_, _, err = packs.ForceSnapshot(ctx, be, docInfo, false)
+		assert.True(t, errors.Is(err, packs.ErrUnappliableChange))
+
+		deadLetterInfos, err := be.DB.FindDeadLetterInfos(ctx, docInfo.ID)
+		assert.NoError(t, err)
+		assert.Len(t, deadLetterInfos, 1)
+		assert.Equal(t, uint64(2), deadLetterInfos[0].ServerSeq)
+		assert.Equal(t, "broken", deadLetterInfos[0].Change.Message)
+		assert.Equal(t, int64(1), be.Stats.DeadLetters.Value())
+
+		alert := <-alerts
+		assert.Equal(t, docInfo.Key, alert["document_key"])
+		assert.Equal(t, false, alert["skipped"])
+
+		be.Config.SkipUnappliableChanges = true
+		serverSeq, _, err := packs.ForceSnapshot(ctx, be, docInfo, false)
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(2), serverSeq)
+		assert.Equal(t, int64(1), be.Stats.DeadLetters.Value())
+
+		snapshotInfo, err := be.DB.FindLastSnapshotInfo(ctx, docInfo.ID)
+		assert.NoError(t, err)
+		snapshot, err := document.FromSnapshot("c", "d", snapshotInfo.ServerSeq, snapshotInfo.Snapshot)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"a":"1"}`, snapshot.Marshal())
+	})
+}
+
+func TestLimits(t *testing.T) {
+	ctx := context.Background()
+
+	dir, err := ioutil.TempDir("", "yorkie-packs")
+	assert.NoError(t, err)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+
+	alerts := make(chan map[string]interface{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		alert := make(map[string]interface{})
+		assert.NoError(t, gojson.NewDecoder(r.Body).Decode(&alert))
+		alerts <- alert
+	}))
+	defer server.Close()
+
+	db, err := embedded.Open(&embedded.Config{Path: filepath.Join(dir, "yorkie.db"), NoSync: true})
+	assert.NoError(t, err)
+	be, err := backend.NewWithDatabase(&backend.Config{
+		SnapshotThreshold: 500,
+		DocumentLimits: &backend.DocumentLimits{
+			SoftElements: 2,
+			HardElements: 3,
+			WebhookURL:   server.URL,
+		},
+	}, db)
+	assert.NoError(t, err)
+	defer func() {
+		assert.NoError(t, be.Close())
+	}()
+
+	t.Run("invalid limits test", func(t *testing.T) {
+		_, err := backend.NewWithDatabase(&backend.Config{
+			DocumentLimits: &backend.DocumentLimits{SoftSize: 10, HardSize: 5},
+		}, db)
+		assert.True(t, errors.Is(err, backend.ErrInvalidDocumentLimits))
+	})
+
+	t.Run("soft and hard limits test", func(t *testing.T) {
+		clientInfo, err := be.DB.ActivateClient(ctx, "client")
+		assert.NoError(t, err)
+		doc := document.New("c", "d")
+		doc.SetActor(time.ActorIDFromHex(clientInfo.ID.Hex()))
+
+		docInfo, err := be.DB.FindDocInfoByKey(ctx, clientInfo, doc.Key().BSONKey(), true)
+		assert.NoError(t, err)
+		assert.NoError(t, clientInfo.AttachDocument(docInfo.ID, auth.ReadWrite))
+
+		// NOTE: The snapshot of the previous push is stored asynchronously
+		// with its docInfo, so each push loads its own as the RPC server does.
+		push := func(updater func(root *proxy.ObjectProxy) error) error {
+			assert.NoError(t, doc.Update(updater))
+			docInfo, err := be.DB.FindDocInfoByKey(ctx, clientInfo, doc.Key().BSONKey(), false)
+			assert.NoError(t, err)
+			_, err = packs.PushPull(ctx, be, clientInfo, docInfo, doc.CreateChangePack())
+			return err
+		}
+
+		assert.NoError(t, push(func(root *proxy.ObjectProxy) error {
+			root.SetString("a", "1")
+			root.SetString("b", "2")
+			return nil
+		}))
+		assert.Equal(t, int64(0), be.Stats.SoftLimitWarnings.Value())
+
+		assert.NoError(t, push(func(root *proxy.ObjectProxy) error {
+			root.SetString("c", "3")
+			return nil
+		}))
+		assert.Equal(t, int64(1), be.Stats.SoftLimitWarnings.Value())
+		alert := <-alerts
+		assert.Equal(t, float64(3), alert["elements"])
+
+		err = push(func(root *proxy.ObjectProxy) error {
+			root.SetString("d", "4")
+			return nil
+		})
+		var limitErr *packs.LimitError
+		assert.True(t, errors.As(err, &limitErr))
+		assert.True(t, errors.Is(err, packs.ErrDocumentLimitExceeded))
+		assert.Equal(t, "elements", limitErr.Limit)
+		assert.Equal(t, 4, limitErr.Value)
+		assert.Equal(t, int64(1), be.Stats.HardLimitRejections.Value())
+		assert.Equal(t, int64(1), be.Stats.SoftLimitWarnings.Value())
+	})
+
+	t.Run("structure limits test", func(t *testing.T) {
+		be.Config.DocumentLimits.MaxDepth = 1
+		defer func() {
+			be.Config.DocumentLimits.MaxDepth = 0
+		}()
+
+		clientInfo, err := be.DB.ActivateClient(ctx, "client")
+		assert.NoError(t, err)
+		doc := document.New("c", "structure")
+		doc.SetActor(time.ActorIDFromHex(clientInfo.ID.Hex()))
+
+		docInfo, err := be.DB.FindDocInfoByKey(ctx, clientInfo, doc.Key().BSONKey(), true)
+		assert.NoError(t, err)
+		assert.NoError(t, clientInfo.AttachDocument(docInfo.ID, auth.ReadWrite))
+
+		assert.NoError(t, doc.Update(func(root *proxy.ObjectProxy) error {
+			root.SetNewObject("a").SetNewObject("b")
+			return nil
+		}))
+		_, err = packs.PushPull(ctx, be, clientInfo, docInfo, doc.CreateChangePack())
+		var limitErr *packs.LimitError
+		assert.True(t, errors.As(err, &limitErr))
+		assert.Equal(t, "depth", limitErr.Limit)
+		assert.Equal(t, 2, limitErr.Value)
+		assert.Equal(t, 1, limitErr.Max)
+	})
+}
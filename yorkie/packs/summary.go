@@ -0,0 +1,76 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package packs
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/yorkie-team/yorkie/yorkie/backend"
+	"github.com/yorkie-team/yorkie/yorkie/pubsub"
+	"github.com/yorkie-team/yorkie/yorkie/validation"
+)
+
+// summarizeChanges returns the paths changed by the changes of the given
+// request with the types of the operations applied to them, ordered by the
+// paths. It returns nil if the changes are not summarized, or if any of the
+// paths can't be resolved, since then any path may be changed.
+func summarizeChanges(be *backend.Backend, req *validation.Request) []pubsub.ChangedPath {
+	if !be.Config.ChangeSummaries || req.Encrypted || len(req.Changes) == 0 {
+		return nil
+	}
+
+	summaries, err := req.OperationSummaries()
+	if err != nil {
+		logger.Error(err)
+		return nil
+	}
+
+	var paths []pubsub.ChangedPath
+	indexOf := make(map[string]int)
+	for _, summary := range summaries {
+		// NOTE: The elements not found in the root are described by their
+		// creation time, e.g. "@1:0:abc", instead of their paths.
+		if strings.HasPrefix(summary.Path, "@") {
+			return nil
+		}
+
+		i, ok := indexOf[summary.Path]
+		if !ok {
+			i = len(paths)
+			indexOf[summary.Path] = i
+			paths = append(paths, pubsub.ChangedPath{Path: summary.Path})
+		}
+		if !containsString(paths[i].Types, summary.Type) {
+			paths[i].Types = append(paths[i].Types, summary.Type)
+		}
+	}
+
+	sort.Slice(paths, func(i, j int) bool {
+		return paths[i].Path < paths[j].Path
+	})
+	return paths
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,125 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package packs
+
+import (
+	"context"
+
+	"github.com/yorkie-team/yorkie/pkg/document"
+	"github.com/yorkie-team/yorkie/pkg/document/change"
+	"github.com/yorkie-team/yorkie/pkg/document/checkpoint"
+	"github.com/yorkie-team/yorkie/pkg/document/json"
+	"github.com/yorkie-team/yorkie/yorkie/backend"
+	"github.com/yorkie-team/yorkie/yorkie/types"
+	"github.com/yorkie-team/yorkie/yorkie/validation"
+)
+
+// newRequest creates the request of the changes being pushed to the given
+// document. The root of the document is loaded only if it is used, and only
+// once for the validation and the summary of the changes.
+func newRequest(
+	ctx context.Context,
+	be *backend.Backend,
+	clientInfo *types.ClientInfo,
+	docInfo *types.DocInfo,
+	pack *change.Pack,
+	pushedChanges []*change.Change,
+	initialServerSeq uint64,
+) *validation.Request {
+	return validation.NewRequest(
+		pack.DocumentKey,
+		clientInfo.ID.Hex(),
+		pushedChanges,
+		pack.Encrypted,
+		func() (*json.Object, error) {
+			return loadRoot(ctx, be, docInfo, pushedChanges, initialServerSeq)
+		},
+	)
+}
+
+// validateChanges validates the changes of the given request with the limits
+// of the documents and the validators of the backend.
+func validateChanges(
+	ctx context.Context,
+	be *backend.Backend,
+	req *validation.Request,
+) error {
+	validators := be.Validators()
+	if (len(validators) == 0 && be.Config.DocumentLimits == nil) || len(req.Changes) == 0 {
+		return nil
+	}
+
+	if err := checkLimits(be, req); err != nil {
+		return err
+	}
+
+	return validation.Validate(ctx, validators, req)
+}
+
+// loadRoot returns the root of the given document at the given server
+// sequence with the given changes applied.
+func loadRoot(
+	ctx context.Context,
+	be *backend.Backend,
+	docInfo *types.DocInfo,
+	pushedChanges []*change.Change,
+	serverSeq uint64,
+) (*json.Object, error) {
+	snapshotInfo, err := be.DB.FindLastSnapshotInfo(ctx, docInfo.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []*change.Change
+	if snapshotInfo.ServerSeq < serverSeq {
+		if changes, err = be.DB.FindChangeInfosBetweenServerSeqs(
+			ctx,
+			docInfo.ID,
+			snapshotInfo.ServerSeq+1,
+			serverSeq,
+		); err != nil {
+			return nil, err
+		}
+	}
+	changes = append(changes, pushedChanges...)
+
+	docKey, err := docInfo.GetKey()
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := document.FromSnapshot(
+		docKey.Collection,
+		docKey.Document,
+		snapshotInfo.ServerSeq,
+		snapshotInfo.Snapshot,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := doc.ApplyChangePack(change.NewPack(
+		docKey,
+		checkpoint.Initial.NextServerSeq(docInfo.ServerSeq),
+		changes,
+		nil,
+	)); err != nil {
+		return nil, err
+	}
+
+	return doc.RootObject(), nil
+}
@@ -0,0 +1,275 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package presence keeps track of the peers of the documents, the clients
+// that attached or are watching them, with their presence.
+//
+// The registry is kept in memory, so each agent only knows the peers connected
+// to it. The backend shares them with the other agents through the database.
+package presence
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultTTL is the default period after which the peers that are attached
+// but neither watching nor synchronizing the documents are expired.
+const DefaultTTL = time.Minute
+
+// Peer is a client that attached or is watching a document.
+type Peer struct {
+	ClientID string
+	Presence map[string]string
+	LastSeen time.Time
+	Watching bool
+}
+
+// peer is the entry of a client in the registry.
+type peer struct {
+	presence map[string]string
+	lastSeen time.Time
+	attached bool
+	watches  int
+}
+
+// Registry is the registry of the peers of the documents.
+type Registry struct {
+	mu    sync.RWMutex
+	ttl   time.Duration
+	peers map[string]map[string]*peer
+}
+
+// NewRegistry creates a new instance of Registry whose peers that are not
+// watching the documents expire after the given period since they were last
+// seen. DefaultTTL is used if it is zero.
+func NewRegistry(ttl time.Duration) *Registry {
+	if ttl == 0 {
+		ttl = DefaultTTL
+	}
+
+	return &Registry{
+		ttl:   ttl,
+		peers: make(map[string]map[string]*peer),
+	}
+}
+
+// TTL returns the period after which the peers not watching the documents
+// expire.
+func (r *Registry) TTL() time.Duration {
+	return r.ttl
+}
+
+// Attach registers the given client as a peer of the given document.
+func (r *Registry) Attach(docKey, clientID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p := r.getOrCreate(docKey, clientID)
+	p.attached = true
+}
+
+// Detach unregisters the given client from the peers of the given document
+// unless it is still watching the document.
+func (r *Registry) Detach(docKey, clientID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, ok := r.peers[docKey][clientID]
+	if !ok {
+		return
+	}
+
+	p.attached = false
+	r.removeIfGone(docKey, clientID, p)
+}
+
+// Deactivate unregisters the given client from the peers of all the
+// documents.
+func (r *Registry) Deactivate(clientID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for docKey, peers := range r.peers {
+		delete(peers, clientID)
+		if len(peers) == 0 {
+			delete(r.peers, docKey)
+		}
+	}
+}
+
+// Watch marks the given client as watching the given documents.
+func (r *Registry) Watch(docKeys []string, clientID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, docKey := range docKeys {
+		r.getOrCreate(docKey, clientID).watches++
+	}
+}
+
+// Unwatch marks the given client as no longer watching the given documents.
+func (r *Registry) Unwatch(docKeys []string, clientID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, docKey := range docKeys {
+		p, ok := r.peers[docKey][clientID]
+		if !ok {
+			continue
+		}
+
+		p.watches--
+		p.lastSeen = time.Now()
+		r.removeIfGone(docKey, clientID, p)
+	}
+}
+
+// Touch updates the last seen time of the given client attached to the given
+// document. The client is registered again if it has expired.
+func (r *Registry) Touch(docKey, clientID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.getOrCreate(docKey, clientID).attached = true
+}
+
+// UpdatePresence replaces the presence of the given client for the given
+// document.
+func (r *Registry) UpdatePresence(docKey, clientID string, presence map[string]string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p := r.getOrCreate(docKey, clientID)
+	p.presence = presence
+	p.attached = true
+}
+
+// Peers returns the peers of the given document ordered by the client ID.
+func (r *Registry) Peers(docKey string) []*Peer {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.peersOf(docKey, time.Now())
+}
+
+// All returns the peers of all the documents by the keys of the documents.
+func (r *Registry) All() map[string][]*Peer {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	now := time.Now()
+	all := make(map[string][]*Peer, len(r.peers))
+	for docKey := range r.peers {
+		if peers := r.peersOf(docKey, now); len(peers) > 0 {
+			all[docKey] = peers
+		}
+	}
+
+	return all
+}
+
+// Expire removes the peers that are not watching the documents and have not
+// been seen for the TTL, e.g. the clients that crashed without detaching the
+// documents, and returns the number of the removed peers.
+func (r *Registry) Expire() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	expired := 0
+	now := time.Now()
+	for docKey, peers := range r.peers {
+		for clientID, p := range peers {
+			if r.expired(p, now) {
+				delete(peers, clientID)
+				expired++
+			}
+		}
+		if len(peers) == 0 {
+			delete(r.peers, docKey)
+		}
+	}
+
+	return expired
+}
+
+// peersOf returns the peers of the given document that have not expired at
+// the given time, ordered by the client ID. It must be called with the lock
+// held.
+func (r *Registry) peersOf(docKey string, now time.Time) []*Peer {
+	var peers []*Peer
+	for clientID, p := range r.peers[docKey] {
+		if r.expired(p, now) {
+			continue
+		}
+
+		presence := make(map[string]string, len(p.presence))
+		for k, v := range p.presence {
+			presence[k] = v
+		}
+
+		peers = append(peers, &Peer{
+			ClientID: clientID,
+			Presence: presence,
+			LastSeen: p.lastSeen,
+			Watching: p.watches > 0,
+		})
+	}
+
+	sort.Slice(peers, func(i, j int) bool {
+		return peers[i].ClientID < peers[j].ClientID
+	})
+
+	return peers
+}
+
+// expired returns whether the given peer has expired at the given time.
+func (r *Registry) expired(p *peer, now time.Time) bool {
+	return p.watches == 0 && now.Sub(p.lastSeen) > r.ttl
+}
+
+// getOrCreate returns the entry of the given client, creating it if it
+// doesn't exist. It must be called with the lock held.
+func (r *Registry) getOrCreate(docKey, clientID string) *peer {
+	peers, ok := r.peers[docKey]
+	if !ok {
+		peers = make(map[string]*peer)
+		r.peers[docKey] = peers
+	}
+
+	p, ok := peers[clientID]
+	if !ok {
+		p = &peer{}
+		peers[clientID] = p
+	}
+	p.lastSeen = time.Now()
+
+	return p
+}
+
+// removeIfGone removes the entry of the given client if it is neither
+// attached nor watching. It must be called with the lock held.
+func (r *Registry) removeIfGone(docKey, clientID string, p *peer) {
+	if p.attached || p.watches > 0 {
+		return
+	}
+
+	delete(r.peers[docKey], clientID)
+	if len(r.peers[docKey]) == 0 {
+		delete(r.peers, docKey)
+	}
+}
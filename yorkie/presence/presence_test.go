@@ -0,0 +1,87 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package presence_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yorkie-team/yorkie/yorkie/presence"
+)
+
+func TestRegistry(t *testing.T) {
+	t.Run("attach/watch test", func(t *testing.T) {
+		r := presence.NewRegistry(0)
+		r.Attach("c$d1", "a")
+		r.Watch([]string{"c$d1", "c$d2"}, "b")
+		r.UpdatePresence("c$d1", "a", map[string]string{"name": "A"})
+
+		peers := r.Peers("c$d1")
+		assert.Len(t, peers, 2)
+		assert.Equal(t, "a", peers[0].ClientID)
+		assert.Equal(t, "A", peers[0].Presence["name"])
+		assert.False(t, peers[0].Watching)
+		assert.Equal(t, "b", peers[1].ClientID)
+		assert.True(t, peers[1].Watching)
+		assert.False(t, peers[1].LastSeen.IsZero())
+
+		// peers that stop watching are removed unless they attached.
+		r.Attach("c$d2", "b")
+		r.Unwatch([]string{"c$d1", "c$d2"}, "b")
+		assert.Len(t, r.Peers("c$d1"), 1)
+		assert.Len(t, r.Peers("c$d2"), 1)
+		assert.False(t, r.Peers("c$d2")[0].Watching)
+
+		r.Detach("c$d1", "a")
+		assert.Len(t, r.Peers("c$d1"), 0)
+	})
+
+	t.Run("deactivate test", func(t *testing.T) {
+		r := presence.NewRegistry(0)
+		r.Attach("c$d1", "a")
+		r.Attach("c$d2", "a")
+		r.Attach("c$d2", "b")
+
+		r.Deactivate("a")
+		assert.Len(t, r.Peers("c$d1"), 0)
+		assert.Len(t, r.Peers("c$d2"), 1)
+	})
+
+	t.Run("expire test", func(t *testing.T) {
+		r := presence.NewRegistry(10 * time.Millisecond)
+		r.Attach("c$d1", "a")
+		r.Attach("c$d1", "b")
+		r.Watch([]string{"c$d1"}, "c")
+
+		time.Sleep(20 * time.Millisecond)
+		r.Touch("c$d1", "b")
+
+		// peers attached but neither watching nor synchronizing expire.
+		peers := r.Peers("c$d1")
+		assert.Len(t, peers, 2)
+		assert.Equal(t, "b", peers[0].ClientID)
+		assert.Equal(t, "c", peers[1].ClientID)
+		assert.Len(t, r.All()["c$d1"], 2)
+		assert.Equal(t, 1, r.Expire())
+
+		// expired peers are registered again when they synchronize.
+		r.Touch("c$d1", "a")
+		assert.Len(t, r.Peers("c$d1"), 3)
+	})
+}
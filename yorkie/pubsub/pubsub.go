@@ -17,6 +17,7 @@
 package pubsub
 
 import (
+	"strings"
 	"sync"
 
 	"github.com/google/uuid"
@@ -26,11 +27,26 @@ import (
 
 const (
 	DocumentChangeEvent = "document-change"
+
+	// BroadcastEvent is the event of a message broadcast by the agent. The
+	// value of the event is the ID of the broadcast.
+	BroadcastEvent = "broadcast"
 )
 
 type Event struct {
 	Type  string
 	Value string
+
+	// ChangedPaths is the summary of the paths changed by the changes of a
+	// document change event. It is empty if the changes are not summarized.
+	ChangedPaths []ChangedPath
+}
+
+// ChangedPath is a path of a document changed by the changes with the types
+// of the operations applied to it.
+type ChangedPath struct {
+	Path  string
+	Types []string
 }
 
 type Subscription struct {
@@ -59,12 +75,17 @@ type Subscriptions map[string]*Subscription
 type PubSub struct {
 	mu               *sync.RWMutex
 	subscriptionsMap map[string]Subscriptions
+
+	// prefixSubscriptionsMap is the subscriptions of the topics starting with
+	// the prefixes.
+	prefixSubscriptionsMap map[string]Subscriptions
 }
 
 func NewPubSub() *PubSub {
 	return &PubSub{
-		mu:               &sync.RWMutex{},
-		subscriptionsMap: make(map[string]Subscriptions),
+		mu:                     &sync.RWMutex{},
+		subscriptionsMap:       make(map[string]Subscriptions),
+		prefixSubscriptionsMap: make(map[string]Subscriptions),
 	}
 }
 
@@ -72,44 +93,102 @@ func NewPubSub() *PubSub {
 func (m *PubSub) Subscribe(
 	actor *time.ActorID,
 	topics []string,
+) (*Subscription, error) {
+	return m.SubscribeWithPrefixes(actor, topics, nil)
+}
+
+// SubscribeWithPrefixes subscribes to the given topics and all topics
+// starting with the given prefixes.
+func (m *PubSub) SubscribeWithPrefixes(
+	actor *time.ActorID,
+	topics []string,
+	prefixes []string,
 ) (*Subscription, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	subscription := newSubscription(actor)
-
-	for _, topic := range topics {
-		if _, ok := m.subscriptionsMap[topic]; !ok {
-			m.subscriptionsMap[topic] = make(Subscriptions)
-		}
-		m.subscriptionsMap[topic][subscription.id] = subscription
-	}
+	add(m.subscriptionsMap, topics, subscription)
+	add(m.prefixSubscriptionsMap, prefixes, subscription)
 
 	return subscription, nil
 }
 
 // Unsubscribe unsubscribes the given topics.
 func (m *PubSub) Unsubscribe(topics []string, subscription *Subscription) {
+	m.UnsubscribeWithPrefixes(topics, nil, subscription)
+}
+
+// UnsubscribeWithPrefixes unsubscribes the given topics and prefixes.
+func (m *PubSub) UnsubscribeWithPrefixes(
+	topics []string,
+	prefixes []string,
+	subscription *Subscription,
+) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	for _, topic := range topics {
-		if subscriptions, ok := m.subscriptionsMap[topic]; ok {
-			delete(subscriptions, subscription.id)
-		}
-	}
+	remove(m.subscriptionsMap, topics, subscription)
+	remove(m.prefixSubscriptionsMap, prefixes, subscription)
 }
 
-// Publish publishes the given event to the given topic.
+// Publish publishes the given event to the given topic. A subscription
+// receives the event once even if it subscribes to the topic more than once.
 func (m *PubSub) Publish(actor *time.ActorID, topic string, event Event) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	if subscriptions, ok := m.subscriptionsMap[topic]; ok {
+	sent := make(map[string]bool)
+	send := func(subscriptions Subscriptions) {
 		for _, subscription := range subscriptions {
-			if subscription.actor.Compare(actor) != 0 {
-				subscription.events <- event
+			if sent[subscription.id] || subscription.actor.Compare(actor) == 0 {
+				continue
 			}
+			subscription.events <- event
+			sent[subscription.id] = true
+		}
+	}
+
+	if subscriptions, ok := m.subscriptionsMap[topic]; ok {
+		send(subscriptions)
+	}
+	for prefix, subscriptions := range m.prefixSubscriptionsMap {
+		if strings.HasPrefix(topic, prefix) {
+			send(subscriptions)
+		}
+	}
+}
+
+// Size returns the number of topics and subscriptions. Prefixes are counted
+// as topics.
+func (m *PubSub) Size() (int, int) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	subscriptions := 0
+	for _, subs := range m.subscriptionsMap {
+		subscriptions += len(subs)
+	}
+	for _, subs := range m.prefixSubscriptionsMap {
+		subscriptions += len(subs)
+	}
+
+	return len(m.subscriptionsMap) + len(m.prefixSubscriptionsMap), subscriptions
+}
+
+func add(subscriptionsMap map[string]Subscriptions, topics []string, subscription *Subscription) {
+	for _, topic := range topics {
+		if _, ok := subscriptionsMap[topic]; !ok {
+			subscriptionsMap[topic] = make(Subscriptions)
+		}
+		subscriptionsMap[topic][subscription.id] = subscription
+	}
+}
+
+func remove(subscriptionsMap map[string]Subscriptions, topics []string, subscription *Subscription) {
+	for _, topic := range topics {
+		if subscriptions, ok := subscriptionsMap[topic]; ok {
+			delete(subscriptions, subscription.id)
 		}
 	}
 }
@@ -0,0 +1,504 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rpc
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	time2 "time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/yorkie-team/yorkie/api/converter"
+	api "github.com/yorkie-team/yorkie/api/yorkie/v1"
+	"github.com/yorkie-team/yorkie/pkg/document/json"
+	"github.com/yorkie-team/yorkie/pkg/document/key"
+	"github.com/yorkie-team/yorkie/pkg/document/time"
+	"github.com/yorkie-team/yorkie/yorkie/auth"
+	"github.com/yorkie-team/yorkie/yorkie/backend/database"
+	"github.com/yorkie-team/yorkie/yorkie/broadcast"
+	"github.com/yorkie-team/yorkie/yorkie/documents"
+	"github.com/yorkie-team/yorkie/yorkie/labels"
+	"github.com/yorkie-team/yorkie/yorkie/packs"
+	"github.com/yorkie-team/yorkie/yorkie/pubsub"
+)
+
+const adminTokenMetadataKey = "authorization"
+
+var (
+	errAdminDisabled      = errors.New("admin API is disabled without an admin token")
+	errAdminTokenRequired = errors.New("admin token required")
+	errInvalidAdminToken  = errors.New("invalid admin token")
+)
+
+// GetDocumentACL returns the ACL of the given document.
+func (s *Server) GetDocumentACL(
+	ctx context.Context,
+	req *api.GetDocumentACLRequest,
+) (*api.GetDocumentACLResponse, error) {
+	if err := s.authorizeAdmin(ctx); err != nil {
+		return nil, err
+	}
+	if req.DocumentKey == nil {
+		return nil, toStatusError(
+			codes.InvalidArgument,
+			"invalid document key",
+			[]fieldViolation{{
+				field:       "document_key",
+				description: "the document key must not be empty",
+			}},
+		)
+	}
+
+	docInfo, err := documents.Find(ctx, s.backend, converter.FromDocumentKey(req.DocumentKey))
+	if err != nil {
+		if err == database.ErrDocumentNotFound {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &api.GetDocumentACLResponse{
+		Acl: toACL(docInfo.ACL),
+	}, nil
+}
+
+// UpdateDocumentACL replaces the ACL of the given document.
+func (s *Server) UpdateDocumentACL(
+	ctx context.Context,
+	req *api.UpdateDocumentACLRequest,
+) (*api.UpdateDocumentACLResponse, error) {
+	if err := s.authorizeAdmin(ctx); err != nil {
+		return nil, err
+	}
+	if req.DocumentKey == nil {
+		return nil, toStatusError(
+			codes.InvalidArgument,
+			"invalid document key",
+			[]fieldViolation{{
+				field:       "document_key",
+				description: "the document key must not be empty",
+			}},
+		)
+	}
+
+	docInfo, err := documents.UpdateACL(
+		ctx,
+		s.backend,
+		converter.FromDocumentKey(req.DocumentKey),
+		fromACL(req.Acl),
+	)
+	if err != nil {
+		if err == database.ErrDocumentNotFound {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &api.UpdateDocumentACLResponse{
+		Acl: toACL(docInfo.ACL),
+	}, nil
+}
+
+// ForceSnapshot stores the snapshot of the given document immediately and
+// optionally prunes the changes included in the snapshot.
+func (s *Server) ForceSnapshot(
+	ctx context.Context,
+	req *api.ForceSnapshotRequest,
+) (*api.ForceSnapshotResponse, error) {
+	if err := s.authorizeAdmin(ctx); err != nil {
+		return nil, err
+	}
+	if req.DocumentKey == nil {
+		return nil, toStatusError(
+			codes.InvalidArgument,
+			"invalid document key",
+			[]fieldViolation{{
+				field:       "document_key",
+				description: "the document key must not be empty",
+			}},
+		)
+	}
+
+	docInfo, err := documents.Find(ctx, s.backend, converter.FromDocumentKey(req.DocumentKey))
+	if err != nil {
+		if err == database.ErrDocumentNotFound {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	serverSeq, pruned, err := packs.ForceSnapshot(ctx, s.backend, docInfo, req.PruneChanges)
+	if err != nil {
+		if err == packs.ErrEncryptedDocument {
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &api.ForceSnapshotResponse{
+		ServerSeq:     serverSeq,
+		PrunedChanges: pruned,
+	}, nil
+}
+
+// GetStats returns the runtime statistics of the agent.
+func (s *Server) GetStats(
+	ctx context.Context,
+	req *api.GetStatsRequest,
+) (*api.GetStatsResponse, error) {
+	if err := s.authorizeAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	activatedClients, err := s.backend.DB.CountActivatedClients(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	attachedDocuments, err := s.backend.DB.CountAttachedDocuments(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	topics, subscriptions := s.backend.SubscriptionSize()
+	resp := &api.GetStatsResponse{
+		ActivatedClients:           activatedClients,
+		AttachedDocuments:          attachedDocuments,
+		WatchStreams:               s.backend.Stats.WatchStreams.Value(),
+		OperationsPerSec:           s.backend.Stats.PushedOperations.Rate(),
+		SubscriptionTopics:         int64(topics),
+		Subscriptions:              int64(subscriptions),
+		Locks:                      int64(s.backend.Locks()),
+		PushPullConflictsPerSec:    s.backend.Stats.PushPullConflicts.Rate(),
+		ConcurrentSetsPerSec:       s.backend.Stats.ConcurrentSets.Rate(),
+		InterleavedTextEditsPerSec: s.backend.Stats.InterleavedTextEdits.Rate(),
+		DeadLetters:                s.backend.Stats.DeadLetters.Value(),
+		SoftLimitWarnings:          s.backend.Stats.SoftLimitWarnings.Value(),
+		HardLimitRejections:        s.backend.Stats.HardLimitRejections.Value(),
+		ChangeStreamErrors:         s.backend.Stats.ChangeStreamErrors.Value(),
+	}
+
+	if reporter, ok := s.backend.DB.(database.LatencyReporter); ok {
+		latency := reporter.Latency()
+		resp.DbLatencyP50Ms = toMilliseconds(latency.Percentile(50))
+		resp.DbLatencyP90Ms = toMilliseconds(latency.Percentile(90))
+		resp.DbLatencyP99Ms = toMilliseconds(latency.Percentile(99))
+	}
+
+	return resp, nil
+}
+
+// GetDocumentHistory returns the changes of the given document with the users
+// who made them.
+func (s *Server) GetDocumentHistory(
+	ctx context.Context,
+	req *api.GetDocumentHistoryRequest,
+) (*api.GetDocumentHistoryResponse, error) {
+	if err := s.authorizeAdmin(ctx); err != nil {
+		return nil, err
+	}
+	if req.DocumentKey == nil {
+		return nil, toStatusError(
+			codes.InvalidArgument,
+			"invalid document key",
+			[]fieldViolation{{
+				field:       "document_key",
+				description: "the document key must not be empty",
+			}},
+		)
+	}
+
+	docInfo, err := documents.Find(ctx, s.backend, converter.FromDocumentKey(req.DocumentKey))
+	if err != nil {
+		if err == database.ErrDocumentNotFound {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	changes, err := documents.FindHistory(
+		ctx,
+		s.backend,
+		docInfo,
+		req.FromServerSeq,
+		int(req.Limit),
+	)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	// NOTE: The agent can't decode the payloads of encrypted documents, so
+	// their operations are omitted.
+	var root *json.Object
+	if !docInfo.Encrypted {
+		doc, err := documents.Build(ctx, s.backend, docInfo)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		root = doc.RootObject()
+	}
+
+	return &api.GetDocumentHistoryResponse{
+		Changes: converter.ToChangeSummaries(root, changes),
+	}, nil
+}
+
+// BroadcastDocument sends the given message to the clients watching the
+// given document through their watch streams.
+func (s *Server) BroadcastDocument(
+	ctx context.Context,
+	req *api.BroadcastDocumentRequest,
+) (*api.BroadcastDocumentResponse, error) {
+	if err := s.authorizeAdmin(ctx); err != nil {
+		return nil, err
+	}
+	if req.DocumentKey == nil {
+		return nil, toStatusError(
+			codes.InvalidArgument,
+			"invalid document key",
+			[]fieldViolation{{
+				field:       "document_key",
+				description: "the document key must not be empty",
+			}},
+		)
+	}
+
+	docKey := converter.FromDocumentKey(req.DocumentKey)
+	if _, err := documents.Find(ctx, s.backend, docKey); err != nil {
+		if err == database.ErrDocumentNotFound {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	// NOTE: Broadcasts are delivered through the subscriptions of this agent,
+	// so only the peers connected to it are the recipients.
+	var recipients []string
+	for _, peer := range s.backend.Presence.Peers(docKey.BSONKey()) {
+		if peer.Watching {
+			recipients = append(recipients, peer.ClientID)
+		}
+	}
+
+	b := s.backend.Broadcasts.Create(docKey.BSONKey(), req.Type, req.Payload, recipients)
+
+	// NOTE: The agent publishes the event as itself, so that it is sent to
+	// all the subscribers.
+	s.backend.Publish(time.InitialActorID, docKey.BSONKey(), pubsub.Event{
+		Type:  pubsub.BroadcastEvent,
+		Value: b.ID,
+	})
+
+	return &api.BroadcastDocumentResponse{
+		BroadcastId: b.ID,
+		Recipients:  recipients,
+	}, nil
+}
+
+// GetBroadcast returns the given broadcast with the clients it was delivered
+// to and the clients that acknowledged it.
+func (s *Server) GetBroadcast(
+	ctx context.Context,
+	req *api.GetBroadcastRequest,
+) (*api.GetBroadcastResponse, error) {
+	if err := s.authorizeAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	st, err := s.backend.Broadcasts.Status(req.BroadcastId)
+	if err != nil {
+		if err == broadcast.ErrBroadcastNotFound {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &api.GetBroadcastResponse{
+		Broadcast:    toBroadcast(st.Broadcast),
+		Recipients:   st.Recipients,
+		Delivered:    st.Delivered,
+		Acknowledged: st.Acknowledged,
+	}, nil
+}
+
+// ListDocuments returns the keys of the documents under the given prefix of
+// the paths of documents.
+func (s *Server) ListDocuments(
+	ctx context.Context,
+	req *api.ListDocumentsRequest,
+) (*api.ListDocumentsResponse, error) {
+	if err := s.authorizeAdmin(ctx); err != nil {
+		return nil, err
+	}
+	if req.KeyPrefix == nil {
+		return nil, toStatusError(
+			codes.InvalidArgument,
+			"invalid key prefix",
+			[]fieldViolation{{
+				field:       "key_prefix",
+				description: "the key prefix must not be empty",
+			}},
+		)
+	}
+
+	prefix, err := key.NewPrefix(req.KeyPrefix.Collection, req.KeyPrefix.Document)
+	if err != nil {
+		return nil, toStatusError(
+			codes.InvalidArgument,
+			"invalid key prefix",
+			[]fieldViolation{{
+				field:       "key_prefix",
+				description: err.Error(),
+			}},
+		)
+	}
+
+	selector, err := labels.ParseSelector(req.LabelSelector)
+	if err != nil {
+		return nil, toStatusError(
+			codes.InvalidArgument,
+			"invalid label selector",
+			[]fieldViolation{{
+				field:       "label_selector",
+				description: err.Error(),
+			}},
+		)
+	}
+
+	docInfos, err := documents.List(ctx, s.backend, prefix, selector, int(req.Limit))
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	resp := &api.ListDocumentsResponse{}
+	for _, docInfo := range docInfos {
+		docKey, err := docInfo.GetKey()
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		pbKey := converter.ToDocumentKey(docKey)
+		resp.DocumentKeys = append(resp.DocumentKeys, pbKey)
+		resp.Documents = append(resp.Documents, &api.DocumentSummary{
+			Key:    pbKey,
+			Labels: docInfo.Labels,
+		})
+	}
+
+	return resp, nil
+}
+
+// UpdateDocumentLabels updates the labels of the given document.
+func (s *Server) UpdateDocumentLabels(
+	ctx context.Context,
+	req *api.UpdateDocumentLabelsRequest,
+) (*api.UpdateDocumentLabelsResponse, error) {
+	if err := s.authorizeAdmin(ctx); err != nil {
+		return nil, err
+	}
+	if req.DocumentKey == nil {
+		return nil, toStatusError(
+			codes.InvalidArgument,
+			"invalid document key",
+			[]fieldViolation{{
+				field:       "document_key",
+				description: "the document key must not be empty",
+			}},
+		)
+	}
+
+	docInfo, err := documents.UpdateLabels(
+		ctx,
+		s.backend,
+		converter.FromDocumentKey(req.DocumentKey),
+		req.Set,
+		req.Remove,
+	)
+	if err != nil {
+		return nil, toLabelsStatusError(err)
+	}
+
+	return &api.UpdateDocumentLabelsResponse{
+		Labels: docInfo.Labels,
+	}, nil
+}
+
+// authorizeAdmin checks the admin token in the metadata of the given context.
+// If no admin token is configured, admin requests are always refused because
+// the Admin service shares the port of the client API.
+func (s *Server) authorizeAdmin(ctx context.Context) error {
+	if s.conf.AdminToken == "" {
+		return status.Error(codes.PermissionDenied, errAdminDisabled.Error())
+	}
+
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok || len(md.Get(adminTokenMetadataKey)) == 0 {
+		return status.Error(codes.Unauthenticated, errAdminTokenRequired.Error())
+	}
+
+	token := md.Get(adminTokenMetadataKey)[0]
+	if subtle.ConstantTimeCompare([]byte(token), []byte(s.conf.AdminToken)) != 1 {
+		return status.Error(codes.PermissionDenied, errInvalidAdminToken.Error())
+	}
+
+	return nil
+}
+
+func toMilliseconds(d time2.Duration) float64 {
+	return float64(d) / float64(time2.Millisecond)
+}
+
+func toACL(acl *auth.ACL) *api.ACL {
+	if acl == nil {
+		return nil
+	}
+
+	return &api.ACL{
+		Owner:   acl.Owner,
+		Writers: acl.Writers,
+		Readers: acl.Readers,
+	}
+}
+
+func fromACL(pbACL *api.ACL) *auth.ACL {
+	if pbACL == nil {
+		return nil
+	}
+
+	return &auth.ACL{
+		Owner:   pbACL.Owner,
+		Writers: pbACL.Writers,
+		Readers: pbACL.Readers,
+	}
+}
+
+func toBroadcast(b *broadcast.Broadcast) *api.Broadcast {
+	pbBroadcast := &api.Broadcast{
+		Id:      b.ID,
+		Type:    b.Type,
+		Payload: b.Payload,
+	}
+
+	if k, err := key.FromBSONKey(b.DocKey); err == nil {
+		pbBroadcast.DocumentKey = converter.ToDocumentKeys(k)[0]
+	}
+
+	return pbBroadcast
+}
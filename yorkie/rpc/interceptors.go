@@ -21,8 +21,6 @@ import (
 	"time"
 
 	"google.golang.org/grpc"
-
-	"github.com/yorkie-team/yorkie/pkg/log"
 )
 
 func unaryInterceptor(
@@ -34,9 +32,9 @@ func unaryInterceptor(
 	start := time.Now()
 	resp, err := handler(ctx, req)
 	if err == nil {
-		log.Logger.Infof("RPC : %q %s", info.FullMethod, time.Since(start))
+		logger.Infof("RPC : %q %s", info.FullMethod, time.Since(start))
 	} else {
-		log.Logger.Errorf("RPC : %q %s: %q => %q", info.FullMethod, time.Since(start), req, err)
+		logger.Errorf("RPC : %q %s: %q => %q", info.FullMethod, time.Since(start), req, err)
 	}
 
 	return resp, err
@@ -50,12 +48,52 @@ func streamInterceptor(
 ) error {
 	err := handler(srv, ss)
 	if err == nil {
-		log.Logger.Infof("stream %q => ok", info.FullMethod)
+		logger.Infof("stream %q => ok", info.FullMethod)
 	} else {
-		log.Logger.Infof(
+		logger.Infof(
 			"stream %q => %s", info.FullMethod, err.Error(),
 		)
 	}
 
 	return err
 }
+
+// chainUnaryInterceptors returns an interceptor that calls the given
+// interceptors in order, the first one being the outermost.
+func chainUnaryInterceptors(interceptors []grpc.UnaryServerInterceptor) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		chained := handler
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			interceptor, next := interceptors[i], chained
+			chained = func(ctx context.Context, req interface{}) (interface{}, error) {
+				return interceptor(ctx, req, info, next)
+			}
+		}
+		return chained(ctx, req)
+	}
+}
+
+// chainStreamInterceptors returns an interceptor that calls the given
+// interceptors in order, the first one being the outermost.
+func chainStreamInterceptors(interceptors []grpc.StreamServerInterceptor) grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		chained := handler
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			interceptor, next := interceptors[i], chained
+			chained = func(srv interface{}, ss grpc.ServerStream) error {
+				return interceptor(srv, ss, info, next)
+			}
+		}
+		return chained(srv, ss)
+	}
+}
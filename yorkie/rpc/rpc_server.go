@@ -18,27 +18,53 @@ package rpc
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
+	"strings"
+	time2 "time"
 
 	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
+	// The gzip compressor is registered, so that the requests compressed by
+	// clients are decompressed and their responses are compressed.
+	_ "google.golang.org/grpc/encoding/gzip"
 	"google.golang.org/grpc/status"
 
-	"github.com/yorkie-team/yorkie/api"
 	"github.com/yorkie-team/yorkie/api/converter"
+	api "github.com/yorkie-team/yorkie/api/yorkie/v1"
+	"github.com/yorkie-team/yorkie/pkg/document/change"
 	"github.com/yorkie-team/yorkie/pkg/document/key"
 	"github.com/yorkie-team/yorkie/pkg/document/time"
 	"github.com/yorkie-team/yorkie/pkg/log"
+	"github.com/yorkie-team/yorkie/yorkie/auth"
 	"github.com/yorkie-team/yorkie/yorkie/backend"
-	"github.com/yorkie-team/yorkie/yorkie/backend/mongo"
+	"github.com/yorkie-team/yorkie/yorkie/backend/database"
+	"github.com/yorkie-team/yorkie/yorkie/broadcast"
 	"github.com/yorkie-team/yorkie/yorkie/clients"
+	"github.com/yorkie-team/yorkie/yorkie/documents"
+	"github.com/yorkie-team/yorkie/yorkie/labels"
 	"github.com/yorkie-team/yorkie/yorkie/packs"
+	"github.com/yorkie-team/yorkie/yorkie/pubsub"
 	"github.com/yorkie-team/yorkie/yorkie/types"
+	"github.com/yorkie-team/yorkie/yorkie/validation"
 )
 
+var logger = log.Named(log.RPC)
+
+var (
+	errAccessTokenRequired    = errors.New("access token required")
+	errAccessTokenKeyMismatch = errors.New("access token is not for the document")
+	errChangesNotPermitted    = errors.New("changes are not permitted with the access")
+	errNotInACL               = errors.New("client is not in the ACL of the document")
+)
+
+// DefaultSnapshotChunkSize is the default size in bytes of the chunks of the
+// snapshots sent with FetchSnapshot.
+const DefaultSnapshotChunkSize = 1024 * 1024
+
 type fieldViolation struct {
 	field       string
 	description string
@@ -48,25 +74,67 @@ type Config struct {
 	Port     int
 	CertFile string
 	KeyFile  string
+
+	// MaxMessageSize is the maximum size in bytes of the messages the agent
+	// receives and sends. The default size of gRPC, 4MiB, is used if it is 0.
+	MaxMessageSize int
+
+	// SnapshotChunkSize is the size in bytes of the chunks of the snapshots
+	// sent with FetchSnapshot. Snapshots larger than it are omitted from
+	// change packs so that very large documents can still be attached.
+	// DefaultSnapshotChunkSize is used if it is 0.
+	SnapshotChunkSize int
+
+	// AccessTokenSecret is the secret used to verify access tokens. If it is
+	// set, clients must present a valid token to attach or watch documents.
+	// Documents with ACLs can only be accessed with tokens issued to their
+	// members, so they are inaccessible if it is empty.
+	AccessTokenSecret string
+
+	// AdminToken is the token that admin requests must present in the
+	// "authorization" metadata. If it is empty, the admin API is disabled.
+	AdminToken string
+
+	// UnaryInterceptors are the custom interceptors of unary RPCs, such as
+	// auth, quota or chaos testing. They are called in the given order after
+	// the built-in logging interceptor.
+	UnaryInterceptors []grpc.UnaryServerInterceptor `json:"-"`
+
+	// StreamInterceptors are the custom interceptors of stream RPCs. They are
+	// called in the given order after the built-in logging interceptor.
+	StreamInterceptors []grpc.StreamServerInterceptor `json:"-"`
 }
 
 type Server struct {
 	conf       *Config
 	grpcServer *grpc.Server
 	backend    *backend.Backend
+	snapshots  *snapshotStore
 }
 
 // NewServer creates a new instance of Server.
 func NewServer(conf *Config, be *backend.Backend) (*Server, error) {
 	opts := []grpc.ServerOption{
-		grpc.UnaryInterceptor(unaryInterceptor),
-		grpc.StreamInterceptor(streamInterceptor),
+		grpc.UnaryInterceptor(chainUnaryInterceptors(
+			append([]grpc.UnaryServerInterceptor{unaryInterceptor}, conf.UnaryInterceptors...),
+		)),
+		grpc.StreamInterceptor(chainStreamInterceptors(
+			append([]grpc.StreamServerInterceptor{streamInterceptor}, conf.StreamInterceptors...),
+		)),
+	}
+
+	if conf.MaxMessageSize > 0 {
+		opts = append(
+			opts,
+			grpc.MaxRecvMsgSize(conf.MaxMessageSize),
+			grpc.MaxSendMsgSize(conf.MaxMessageSize),
+		)
 	}
 
 	if conf.CertFile != "" && conf.KeyFile != "" {
 		creds, err := credentials.NewServerTLSFromFile(conf.CertFile, conf.KeyFile)
 		if err != nil {
-			log.Logger.Error(err)
+			logger.Error(err)
 			return nil, err
 		}
 		opts = append(opts, grpc.Creds(creds))
@@ -76,8 +144,11 @@ func NewServer(conf *Config, be *backend.Backend) (*Server, error) {
 		conf:       conf,
 		grpcServer: grpc.NewServer(opts...),
 		backend:    be,
+		snapshots:  newSnapshotStore(be.DB),
 	}
 	api.RegisterYorkieServer(rpcServer.grpcServer, rpcServer)
+	api.RegisterAdminServer(rpcServer.grpcServer, rpcServer)
+	api.RegisterLegacyServers(rpcServer.grpcServer, rpcServer, rpcServer)
 
 	return rpcServer, nil
 }
@@ -108,15 +179,41 @@ func (s *Server) ActivateClient(
 			}},
 		)
 	}
-	client, err := clients.Activate(ctx, s.backend, req.ClientKey)
+	client, sessionToken, err := clients.Activate(ctx, s.backend, req.ClientKey, req.SessionToken)
 	if err != nil {
+		if err == clients.ErrInvalidSessionToken {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
-	return &api.ActivateClientResponse{
-		ClientKey: client.Key,
-		ClientId:  client.ID.Hex(),
-	}, nil
+	resp := &api.ActivateClientResponse{
+		ClientKey:    client.Key,
+		ClientId:     client.ID.Hex(),
+		ApiVersion:   api.NegotiateRevision(req.Header),
+		SessionToken: sessionToken,
+	}
+
+	if req.SessionToken != "" {
+		docInfos, err := clients.Attachments(ctx, s.backend, client)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+
+		for _, docInfo := range docInfos {
+			docKey, err := docInfo.GetKey()
+			if err != nil {
+				return nil, status.Error(codes.Internal, err.Error())
+			}
+
+			resp.Attachments = append(resp.Attachments, &api.Attachment{
+				DocumentKey: converter.ToDocumentKey(docKey),
+				Checkpoint:  converter.ToCheckpoint(client.GetCheckpoint(docInfo.ID)),
+			})
+		}
+	}
+
+	return resp, nil
 }
 
 func (s *Server) DeactivateClient(
@@ -136,11 +233,12 @@ func (s *Server) DeactivateClient(
 
 	client, err := clients.Deactivate(ctx, s.backend, req.ClientId)
 	if err != nil {
-		if err == mongo.ErrClientNotFound {
+		if err == database.ErrClientNotFound {
 			return nil, status.Error(codes.NotFound, err.Error())
 		}
 		return nil, status.Error(codes.Internal, err.Error())
 	}
+	s.backend.Presence.Deactivate(client.ID.Hex())
 
 	return &api.DeactivateClientResponse{
 		ClientId: client.ID.Hex(),
@@ -155,6 +253,16 @@ func (s *Server) AttachDocument(
 	if err != nil {
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
+	if err := pack.DocumentKey.Validate(); err != nil {
+		return nil, toStatusError(
+			codes.InvalidArgument,
+			"invalid document key",
+			[]fieldViolation{{
+				field:       "change_pack.document_key",
+				description: err.Error(),
+			}},
+		)
+	}
 
 	// if pack.HasChanges() {
 	lockKey := fmt.Sprintf("pushpull-%s", req.ClientId)
@@ -163,19 +271,28 @@ func (s *Server) AttachDocument(
 	}
 	defer func() {
 		if err := s.backend.Unlock(lockKey); err != nil {
-			log.Logger.Error(err)
+			logger.Error(err)
 		}
 	}()
 	// }
 
+	tokenAccess, subject, err := s.verifyAccessToken(req.AccessToken, pack.DocumentKey)
+	if err != nil {
+		return nil, err
+	}
+
 	clientInfo, docInfo, err := clients.FindClientAndDocument(ctx, s.backend, req.ClientId, pack, true)
 	if err != nil {
-		if err == mongo.ErrClientNotFound || err == mongo.ErrDocumentNotFound {
+		if err == database.ErrClientNotFound || err == database.ErrDocumentNotFound {
 			return nil, status.Error(codes.NotFound, err.Error())
 		}
 		return nil, status.Error(codes.Internal, err.Error())
 	}
-	if err := clientInfo.AttachDocument(docInfo.ID); err != nil {
+	access, err := checkPermission(tokenAccess, subject, docInfo, pack)
+	if err != nil {
+		return nil, err
+	}
+	if err := clientInfo.AttachDocumentAs(docInfo.ID, access, subject); err != nil {
 		if err == types.ErrClientNotActivated {
 			return nil, status.Error(codes.FailedPrecondition, err.Error())
 		}
@@ -185,13 +302,23 @@ func (s *Server) AttachDocument(
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
-	pulled, err := packs.PushPull(ctx, s.backend, clientInfo, docInfo, pack)
+	pushPull := packs.PushPull
+	if req.FailIfExists {
+		pushPull = packs.PushPullNew
+	}
+	pulled, err := pushPull(ctx, s.backend, clientInfo, docInfo, pack)
+	if err != nil {
+		return nil, toPushPullStatusError(err)
+	}
+	s.backend.Presence.Attach(pack.DocumentKey.BSONKey(), req.ClientId)
+
+	pbPack, err := s.toChangePack(ctx, req.Header, req.ClientId, pulled)
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
 	return &api.AttachDocumentResponse{
-		ChangePack: converter.ToChangePack(pulled),
+		ChangePack: pbPack,
 	}, nil
 }
 
@@ -203,6 +330,16 @@ func (s *Server) DetachDocument(
 	if err != nil {
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
+	if err := pack.DocumentKey.Validate(); err != nil {
+		return nil, toStatusError(
+			codes.InvalidArgument,
+			"invalid document key",
+			[]fieldViolation{{
+				field:       "change_pack.document_key",
+				description: err.Error(),
+			}},
+		)
+	}
 
 	// if pack.HasChanges() {
 	lockKey := fmt.Sprintf("pushpull-%s", req.ClientId)
@@ -211,14 +348,14 @@ func (s *Server) DetachDocument(
 	}
 	defer func() {
 		if err := s.backend.Unlock(lockKey); err != nil {
-			log.Logger.Error(err)
+			logger.Error(err)
 		}
 	}()
 	// }
 
 	clientInfo, docInfo, err := clients.FindClientAndDocument(ctx, s.backend, req.ClientId, pack, false)
 	if err != nil {
-		if err == mongo.ErrClientNotFound || err == mongo.ErrDocumentNotFound {
+		if err == database.ErrClientNotFound || err == database.ErrDocumentNotFound {
 			return nil, status.Error(codes.NotFound, err.Error())
 		}
 		return nil, status.Error(codes.Internal, err.Error())
@@ -229,17 +366,31 @@ func (s *Server) DetachDocument(
 		}
 		return nil, status.Error(codes.Internal, err.Error())
 	}
+	if _, err := checkPermission(
+		clientInfo.DocumentAccess(docInfo.ID.Hex()),
+		clientInfo.DocumentSubject(docInfo.ID.Hex()),
+		docInfo,
+		pack,
+	); err != nil {
+		return nil, err
+	}
 	if err := clientInfo.DetachDocument(docInfo.ID); err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
 	pulled, err := packs.PushPull(ctx, s.backend, clientInfo, docInfo, pack)
+	if err != nil {
+		return nil, toPushPullStatusError(err)
+	}
+	s.backend.Presence.Detach(pack.DocumentKey.BSONKey(), req.ClientId)
+
+	pbPack, err := s.toChangePack(ctx, req.Header, req.ClientId, pulled)
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
 	return &api.DetachDocumentResponse{
-		ChangePack: converter.ToChangePack(pulled),
+		ChangePack: pbPack,
 	}, nil
 }
 
@@ -260,14 +411,14 @@ func (s *Server) PushPull(
 	}
 	defer func() {
 		if err := s.backend.Unlock(lockKey); err != nil {
-			log.Logger.Error(err)
+			logger.Error(err)
 		}
 	}()
 	// }
 
 	clientInfo, docInfo, err := clients.FindClientAndDocument(ctx, s.backend, req.ClientId, pack, false)
 	if err != nil {
-		if err == mongo.ErrClientNotFound || err == mongo.ErrDocumentNotFound {
+		if err == database.ErrClientNotFound || err == database.ErrDocumentNotFound {
 			return nil, status.Error(codes.NotFound, err.Error())
 		}
 		return nil, status.Error(codes.Internal, err.Error())
@@ -278,77 +429,878 @@ func (s *Server) PushPull(
 		}
 		return nil, status.Error(codes.Internal, err.Error())
 	}
+	if _, err := checkPermission(
+		clientInfo.DocumentAccess(docInfo.ID.Hex()),
+		clientInfo.DocumentSubject(docInfo.ID.Hex()),
+		docInfo,
+		pack,
+	); err != nil {
+		return nil, err
+	}
 
 	pulled, err := packs.PushPull(ctx, s.backend, clientInfo, docInfo, pack)
+	if err != nil {
+		return nil, toPushPullStatusError(err)
+	}
+	s.backend.Presence.Touch(pack.DocumentKey.BSONKey(), req.ClientId)
+
+	pbPack, err := s.toChangePack(ctx, req.Header, req.ClientId, pulled)
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
 	return &api.PushPullResponse{
-		ChangePack: converter.ToChangePack(pulled),
+		ChangePack: pbPack,
 	}, nil
 }
 
+// toChangePack converts the given pack pulled by the given client. If the
+// snapshot of the pack is larger than the chunk size and the client can fetch
+// it in chunks, it is omitted from the pack and kept for FetchSnapshot.
+func (s *Server) toChangePack(
+	ctx context.Context,
+	header *api.RequestHeader,
+	clientID string,
+	pack *change.Pack,
+) (*api.ChangePack, error) {
+	pbPack := converter.ToChangePack(pack)
+	if len(pbPack.Snapshot) <= s.snapshotChunkSize() ||
+		api.NegotiateRevision(header) < api.SnapshotChunkRevision {
+		return pbPack, nil
+	}
+
+	snapshotID, err := s.snapshots.put(ctx, clientID, pbPack.Snapshot)
+	if err != nil {
+		return nil, err
+	}
+	pbPack.SnapshotId = snapshotID
+	pbPack.Snapshot = nil
+	return pbPack, nil
+}
+
+// FetchSnapshot sends the snapshot omitted from a change pack in chunks. The
+// snapshot is removed only after all the chunks are sent, so that the client
+// can fetch it again if the transfer fails.
+func (s *Server) FetchSnapshot(
+	req *api.FetchSnapshotRequest,
+	stream api.Yorkie_FetchSnapshotServer,
+) error {
+	ctx := stream.Context()
+	snapshot, err := s.snapshots.get(ctx, req.ClientId, req.SnapshotId)
+	if err != nil {
+		if err == database.ErrStagedSnapshotNotFound {
+			return status.Error(codes.NotFound, err.Error())
+		}
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	chunkSize := s.snapshotChunkSize()
+	for len(snapshot) > 0 {
+		size := chunkSize
+		if len(snapshot) < size {
+			size = len(snapshot)
+		}
+		if err := stream.Send(&api.FetchSnapshotResponse{
+			Chunk: snapshot[:size],
+		}); err != nil {
+			logger.Error(err)
+			return err
+		}
+		snapshot = snapshot[size:]
+	}
+
+	if err := s.snapshots.remove(ctx, req.SnapshotId); err != nil {
+		logger.Error(err)
+	}
+
+	return nil
+}
+
+func (s *Server) snapshotChunkSize() int {
+	if s.conf.SnapshotChunkSize > 0 {
+		return s.conf.SnapshotChunkSize
+	}
+	return DefaultSnapshotChunkSize
+}
+
 func (s *Server) WatchDocuments(
 	req *api.WatchDocumentsRequest,
 	stream api.Yorkie_WatchDocumentsServer,
 ) error {
+	if _, err := clients.Find(stream.Context(), s.backend, req.ClientId); err != nil {
+		if err == database.ErrClientNotFound {
+			return status.Error(codes.NotFound, err.Error())
+		}
+		return status.Error(codes.Internal, err.Error())
+	}
+
 	var docKeys []string
+	watched := make(map[string]bool)
 	for _, docKey := range converter.FromDocumentKeys(req.DocumentKeys) {
+		subject, err := s.verifyWatchAccess(req.AccessTokens, docKey)
+		if err != nil {
+			return err
+		}
+		if err := s.verifyWatchACL(stream.Context(), subject, docKey); err != nil {
+			return err
+		}
 		docKeys = append(docKeys, docKey.BSONKey())
+		watched[docKey.BSONKey()] = true
+	}
+
+	prefixes, err := converter.FromDocumentKeyPrefixes(req.DocumentKeyPrefixes)
+	if err != nil {
+		return toStatusError(
+			codes.InvalidArgument,
+			"invalid key prefix",
+			[]fieldViolation{{
+				field:       "document_key_prefixes",
+				description: err.Error(),
+			}},
+		)
 	}
+	var bsonPrefixes []string
+	prefixSubjects := make(map[string]string)
+	for _, prefix := range prefixes {
+		subject, err := s.verifyWatchPrefixAccess(req.AccessTokens, prefix)
+		if err != nil {
+			return err
+		}
+		bsonPrefixes = append(bsonPrefixes, prefix.BSONKey())
+		prefixSubjects[prefix.BSONKey()] = subject
+	}
+
+	s.backend.Stats.WatchStreams.Inc()
+	defer s.backend.Stats.WatchStreams.Dec()
+
+	s.backend.Presence.Watch(docKeys, req.ClientId)
+	defer s.backend.Presence.Unwatch(docKeys, req.ClientId)
 
-	subscription, err := s.backend.Subscribe(
+	subscription, err := s.backend.SubscribeWithPrefixes(
 		time.ActorIDFromHex(req.ClientId),
 		docKeys,
+		bsonPrefixes,
 	)
 	if err != nil {
-		log.Logger.Error(err)
+		logger.Error(err)
 		return err
 	}
+	defer s.backend.UnsubscribeWithPrefixes(docKeys, bsonPrefixes, subscription)
 
 	for {
 		select {
 		case <-stream.Context().Done():
-			s.backend.Unsubscribe(docKeys, subscription)
 			return nil
 		case event := <-subscription.Events():
+			if event.Type == pubsub.BroadcastEvent {
+				if err := s.sendBroadcast(req.ClientId, event.Value, watched, stream); err != nil {
+					return err
+				}
+				continue
+			}
+
 			k, err := key.FromBSONKey(event.Value)
 			if err != nil {
-				s.backend.Unsubscribe(docKeys, subscription)
 				return err
 			}
 
+			// NOTE: The documents under the prefixes are not checked when the
+			// watch is started, so the ACL of each of them is checked here.
+			if !watched[event.Value] && !s.allowedByPrefixes(stream.Context(), prefixSubjects, k) {
+				continue
+			}
+
 			if err := stream.Send(&api.WatchDocumentsResponse{
 				ClientId:     req.ClientId,
 				DocumentKeys: converter.ToDocumentKeys(k),
+				ChangedPaths: toChangedPaths(event.ChangedPaths),
 			}); err != nil {
-				s.backend.Unsubscribe(docKeys, subscription)
-				log.Logger.Error(err)
+				logger.Error(err)
 				return err
 			}
 		}
 	}
 }
 
+// toChangedPaths converts the given changed paths of an event to Protobuf
+// format.
+func toChangedPaths(paths []pubsub.ChangedPath) []*api.ChangedPath {
+	var pbPaths []*api.ChangedPath
+	for _, path := range paths {
+		pbPaths = append(pbPaths, &api.ChangedPath{
+			Path:  path.Path,
+			Types: path.Types,
+		})
+	}
+	return pbPaths
+}
+
+// sendBroadcast sends the broadcast of the given ID to the given stream and
+// marks it as delivered to the client. Broadcasts are only sent for the
+// watched documents, not for the documents under the watched prefixes.
+func (s *Server) sendBroadcast(
+	clientID string,
+	broadcastID string,
+	watched map[string]bool,
+	stream api.Yorkie_WatchDocumentsServer,
+) error {
+	b, err := s.backend.Broadcasts.Find(broadcastID)
+	if err != nil {
+		// NOTE: The broadcast has been evicted by the newer ones.
+		logger.Warn(err)
+		return nil
+	}
+	if !watched[b.DocKey] {
+		return nil
+	}
+
+	k, err := key.FromBSONKey(b.DocKey)
+	if err != nil {
+		return err
+	}
+
+	if err := stream.Send(&api.WatchDocumentsResponse{
+		ClientId:     clientID,
+		DocumentKeys: converter.ToDocumentKeys(k),
+		Broadcast:    toBroadcast(b),
+	}); err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	if err := s.backend.Broadcasts.Deliver(broadcastID, clientID); err != nil {
+		logger.Warn(err)
+	}
+	return nil
+}
+
+// AcknowledgeBroadcast marks the given broadcast as acknowledged by the
+// client.
+func (s *Server) AcknowledgeBroadcast(
+	ctx context.Context,
+	req *api.AcknowledgeBroadcastRequest,
+) (*api.AcknowledgeBroadcastResponse, error) {
+	if _, err := clients.Find(ctx, s.backend, req.ClientId); err != nil {
+		if err == database.ErrClientNotFound {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if err := s.backend.Broadcasts.Acknowledge(req.BroadcastId, req.ClientId); err != nil {
+		if err == broadcast.ErrBroadcastNotFound {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		if err == broadcast.ErrNotRecipient {
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &api.AcknowledgeBroadcastResponse{}, nil
+}
+
+// CreateTag tags the current server sequence of the given document with the
+// given name.
+func (s *Server) CreateTag(
+	ctx context.Context,
+	req *api.CreateTagRequest,
+) (*api.CreateTagResponse, error) {
+	if req.Name == "" {
+		return nil, toStatusError(
+			codes.InvalidArgument,
+			"invalid tag name",
+			[]fieldViolation{{
+				field:       "name",
+				description: "the name of the tag must not be empty",
+			}},
+		)
+	}
+
+	docInfo, access, err := s.checkDocumentAccess(ctx, req.ClientId, req.DocumentKey, req.AccessToken)
+	if err != nil {
+		return nil, err
+	}
+	if access != auth.ReadWrite {
+		return nil, status.Error(codes.PermissionDenied, errChangesNotPermitted.Error())
+	}
+
+	tagInfo, err := packs.CreateTag(ctx, s.backend, docInfo, req.Name)
+	if err != nil {
+		if err == database.ErrTagAlreadyExists {
+			return nil, status.Error(codes.AlreadyExists, err.Error())
+		}
+		if err == packs.ErrEncryptedDocument {
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &api.CreateTagResponse{
+		Tag: toTag(tagInfo),
+	}, nil
+}
+
+// ListTags returns the tags of the given document.
+func (s *Server) ListTags(
+	ctx context.Context,
+	req *api.ListTagsRequest,
+) (*api.ListTagsResponse, error) {
+	docInfo, _, err := s.checkDocumentAccess(ctx, req.ClientId, req.DocumentKey, req.AccessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	tagInfos, err := s.backend.DB.FindTagInfos(ctx, docInfo.ID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	var pbTags []*api.Tag
+	for _, tagInfo := range tagInfos {
+		pbTags = append(pbTags, toTag(tagInfo))
+	}
+
+	return &api.ListTagsResponse{
+		Tags: pbTags,
+	}, nil
+}
+
+// GetTag returns the tag of the given name with the snapshot of the document
+// at the tag.
+func (s *Server) GetTag(
+	ctx context.Context,
+	req *api.GetTagRequest,
+) (*api.GetTagResponse, error) {
+	docInfo, _, err := s.checkDocumentAccess(ctx, req.ClientId, req.DocumentKey, req.AccessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	tagInfo, err := s.backend.DB.FindTagInfoByName(ctx, docInfo.ID, req.Name)
+	if err != nil {
+		if err == database.ErrTagNotFound {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &api.GetTagResponse{
+		Tag:      toTag(tagInfo),
+		Snapshot: tagInfo.Snapshot,
+	}, nil
+}
+
+// UpdateLabels updates the labels of the given document.
+func (s *Server) UpdateLabels(
+	ctx context.Context,
+	req *api.UpdateLabelsRequest,
+) (*api.UpdateLabelsResponse, error) {
+	docInfo, access, err := s.checkDocumentAccess(ctx, req.ClientId, req.DocumentKey, req.AccessToken)
+	if err != nil {
+		return nil, err
+	}
+	if access != auth.ReadWrite {
+		return nil, status.Error(codes.PermissionDenied, errChangesNotPermitted.Error())
+	}
+
+	docKey, err := docInfo.GetKey()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	docInfo, err = documents.UpdateLabels(ctx, s.backend, docKey, req.Set, req.Remove)
+	if err != nil {
+		return nil, toLabelsStatusError(err)
+	}
+
+	return &api.UpdateLabelsResponse{
+		Labels: docInfo.Labels,
+	}, nil
+}
+
+// GetLabels returns the labels of the given document.
+func (s *Server) GetLabels(
+	ctx context.Context,
+	req *api.GetLabelsRequest,
+) (*api.GetLabelsResponse, error) {
+	docInfo, _, err := s.checkDocumentAccess(ctx, req.ClientId, req.DocumentKey, req.AccessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.GetLabelsResponse{
+		Labels: docInfo.Labels,
+	}, nil
+}
+
+// checkDocumentAccess verifies the given token and the ACL of the document of the
+// given key for the given client, and returns the document and the access
+// granted to the client.
+func (s *Server) checkDocumentAccess(
+	ctx context.Context,
+	clientID string,
+	pbDocKey *api.DocumentKey,
+	token string,
+) (*types.DocInfo, auth.Access, error) {
+	if pbDocKey == nil {
+		return nil, "", toStatusError(
+			codes.InvalidArgument,
+			"invalid document key",
+			[]fieldViolation{{
+				field:       "document_key",
+				description: "the document key must not be empty",
+			}},
+		)
+	}
+	docKey := converter.FromDocumentKey(pbDocKey)
+
+	tokenAccess, subject, err := s.verifyAccessToken(token, docKey)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if _, err := clients.Find(ctx, s.backend, clientID); err != nil {
+		if err == database.ErrClientNotFound {
+			return nil, "", status.Error(codes.NotFound, err.Error())
+		}
+		return nil, "", status.Error(codes.Internal, err.Error())
+	}
+
+	docInfo, err := documents.Find(ctx, s.backend, docKey)
+	if err != nil {
+		if err == database.ErrDocumentNotFound {
+			return nil, "", status.Error(codes.NotFound, err.Error())
+		}
+		return nil, "", status.Error(codes.Internal, err.Error())
+	}
+
+	aclAccess, ok := docInfo.ACL.AccessOf(subject)
+	if !ok {
+		return nil, "", status.Error(codes.PermissionDenied, errNotInACL.Error())
+	}
+
+	return docInfo, tokenAccess.Min(aclAccess), nil
+}
+
+// toTag converts the given tag to Protobuf format.
+func toTag(tagInfo *types.TagInfo) *api.Tag {
+	return &api.Tag{
+		Name:      tagInfo.Name,
+		ServerSeq: tagInfo.ServerSeq,
+		CreatedAt: tagInfo.CreatedAt.UnixNano() / int64(time2.Millisecond),
+	}
+}
+
+// ForkDocument forks the given document into a new document of the branch
+// key.
+func (s *Server) ForkDocument(
+	ctx context.Context,
+	req *api.ForkDocumentRequest,
+) (*api.ForkDocumentResponse, error) {
+	if req.BranchKey == nil {
+		return nil, toStatusError(
+			codes.InvalidArgument,
+			"invalid branch key",
+			[]fieldViolation{{
+				field:       "branch_key",
+				description: "the branch key must not be empty",
+			}},
+		)
+	}
+	branchKey := converter.FromDocumentKey(req.BranchKey)
+	if err := branchKey.Validate(); err != nil {
+		return nil, toStatusError(
+			codes.InvalidArgument,
+			"invalid branch key",
+			[]fieldViolation{{
+				field:       "branch_key",
+				description: err.Error(),
+			}},
+		)
+	}
+
+	docInfo, _, err := s.checkDocumentAccess(ctx, req.ClientId, req.DocumentKey, req.AccessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	branchAccess, _, err := s.verifyAccessToken(req.AccessToken, branchKey)
+	if err != nil {
+		return nil, err
+	}
+	if branchAccess != auth.ReadWrite {
+		return nil, status.Error(codes.PermissionDenied, errChangesNotPermitted.Error())
+	}
+
+	clientInfo, err := clients.Find(ctx, s.backend, req.ClientId)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	branchInfo, err := packs.Fork(ctx, s.backend, clientInfo, docInfo, branchKey)
+	if err != nil {
+		if err == packs.ErrBranchAlreadyExists {
+			return nil, status.Error(codes.AlreadyExists, err.Error())
+		}
+		if err == packs.ErrEncryptedDocument {
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &api.ForkDocumentResponse{
+		ForkServerSeq: branchInfo.Branch.ForkServerSeq,
+	}, nil
+}
+
+// MergeDocument merges the changes of the given branch into the document it
+// was forked from.
+func (s *Server) MergeDocument(
+	ctx context.Context,
+	req *api.MergeDocumentRequest,
+) (*api.MergeDocumentResponse, error) {
+	branchInfo, _, err := s.checkDocumentAccess(ctx, req.ClientId, req.BranchKey, req.AccessToken)
+	if err != nil {
+		return nil, err
+	}
+	if branchInfo.Branch == nil {
+		return nil, status.Error(codes.FailedPrecondition, packs.ErrNotBranch.Error())
+	}
+
+	parentKey, err := key.FromBSONKey(branchInfo.Branch.ParentKey)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	_, access, err := s.checkDocumentAccess(
+		ctx,
+		req.ClientId,
+		converter.ToDocumentKeys(parentKey)[0],
+		req.AccessToken,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if access != auth.ReadWrite {
+		return nil, status.Error(codes.PermissionDenied, errChangesNotPermitted.Error())
+	}
+
+	merged, err := packs.Merge(ctx, s.backend, branchInfo)
+	if err != nil {
+		if err == database.ErrDocumentNotFound {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		if errors.Is(err, packs.ErrMergeConflict) {
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &api.MergeDocumentResponse{
+		MergedChanges: int32(merged),
+	}, nil
+}
+
+// UpdatePresence replaces the presence of the client for the given document.
+func (s *Server) UpdatePresence(
+	ctx context.Context,
+	req *api.UpdatePresenceRequest,
+) (*api.UpdatePresenceResponse, error) {
+	docKey, err := s.checkPeer(ctx, req.ClientId, req.DocumentKey)
+	if err != nil {
+		return nil, err
+	}
+
+	s.backend.Presence.UpdatePresence(docKey.BSONKey(), req.ClientId, req.Presence)
+
+	return &api.UpdatePresenceResponse{}, nil
+}
+
+// GetPeers returns the peers of the given document with their presence.
+func (s *Server) GetPeers(
+	ctx context.Context,
+	req *api.GetPeersRequest,
+) (*api.GetPeersResponse, error) {
+	docKey, err := s.checkPeer(ctx, req.ClientId, req.DocumentKey)
+	if err != nil {
+		return nil, err
+	}
+
+	peers, err := s.backend.Peers(ctx, docKey.BSONKey())
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	var pbPeers []*api.Peer
+	for _, peer := range peers {
+		pbPeers = append(pbPeers, &api.Peer{
+			ClientId:   peer.ClientID,
+			Presence:   peer.Presence,
+			LastSeenAt: peer.LastSeen.UnixNano() / int64(time2.Millisecond),
+			Watching:   peer.Watching,
+		})
+	}
+
+	return &api.GetPeersResponse{
+		Peers: pbPeers,
+	}, nil
+}
+
+// checkPeer checks that the given client attached the document of the given
+// key, and returns the key.
+func (s *Server) checkPeer(
+	ctx context.Context,
+	clientID string,
+	pbDocKey *api.DocumentKey,
+) (*key.Key, error) {
+	if pbDocKey == nil {
+		return nil, toStatusError(
+			codes.InvalidArgument,
+			"invalid document key",
+			[]fieldViolation{{
+				field:       "document_key",
+				description: "the document key must not be empty",
+			}},
+		)
+	}
+	docKey := converter.FromDocumentKey(pbDocKey)
+
+	clientInfo, err := clients.Find(ctx, s.backend, clientID)
+	if err != nil {
+		if err == database.ErrClientNotFound {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	docInfo, err := documents.Find(ctx, s.backend, docKey)
+	if err != nil {
+		if err == database.ErrDocumentNotFound {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if err := clientInfo.CheckDocumentAttached(docInfo.ID.Hex()); err != nil {
+		if err == types.ErrClientNotActivated || err == types.ErrDocumentNotAttached {
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return docKey, nil
+}
+
+// verifyAccessToken verifies the given token for the document of the given
+// key and returns the access level it grants and the subject it was issued
+// to. If no secret is configured, every client has full access. Clients
+// without tokens are granted the anonymous access of the profile of the
+// collection, if any. Clients without verified tokens have no subject.
+func (s *Server) verifyAccessToken(token string, docKey *key.Key) (auth.Access, string, error) {
+	if s.conf.AccessTokenSecret == "" {
+		return auth.ReadWrite, "", nil
+	}
+
+	if token == "" {
+		if access := s.backend.Config.ProfileOf(docKey.Collection).AnonymousAccess; access != "" {
+			return access, "", nil
+		}
+		return "", "", status.Error(codes.Unauthenticated, errAccessTokenRequired.Error())
+	}
+
+	claims, err := auth.VerifyToken([]byte(s.conf.AccessTokenSecret), token, time2.Now())
+	if err != nil {
+		return "", "", status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	if !claims.Covers(docKey) {
+		return "", "", status.Error(codes.PermissionDenied, errAccessTokenKeyMismatch.Error())
+	}
+
+	return claims.Access, claims.Subject, nil
+}
+
+// verifyWatchAccess verifies that one of the given tokens grants access to
+// the document of the given key, and returns the subject of the token.
+func (s *Server) verifyWatchAccess(tokens []string, docKey *key.Key) (string, error) {
+	if s.conf.AccessTokenSecret == "" {
+		return "", nil
+	}
+
+	var lastErr error = status.Error(codes.Unauthenticated, errAccessTokenRequired.Error())
+	for _, token := range tokens {
+		_, subject, err := s.verifyAccessToken(token, docKey)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		return subject, nil
+	}
+
+	if s.backend.Config.ProfileOf(docKey.Collection).AnonymousAccess != "" {
+		return "", nil
+	}
+
+	return "", lastErr
+}
+
+// verifyWatchPrefixAccess verifies that one of the given tokens grants access
+// to all documents under the given prefix, and returns the subject of the
+// token.
+func (s *Server) verifyWatchPrefixAccess(tokens []string, prefix *key.Prefix) (string, error) {
+	if s.conf.AccessTokenSecret == "" {
+		return "", nil
+	}
+
+	var lastErr error = status.Error(codes.Unauthenticated, errAccessTokenRequired.Error())
+	for _, token := range tokens {
+		claims, err := auth.VerifyToken([]byte(s.conf.AccessTokenSecret), token, time2.Now())
+		if err != nil {
+			lastErr = status.Error(codes.Unauthenticated, err.Error())
+			continue
+		}
+		if !claims.CoversPrefix(prefix) {
+			lastErr = status.Error(codes.PermissionDenied, errAccessTokenKeyMismatch.Error())
+			continue
+		}
+		return claims.Subject, nil
+	}
+
+	if s.backend.Config.ProfileOf(prefix.Collection).AnonymousAccess != "" {
+		return "", nil
+	}
+
+	return "", lastErr
+}
+
+// allowedByPrefixes returns whether the ACL of the document of the given key
+// allows the subject of one of the watched prefixes covering it to read it.
+func (s *Server) allowedByPrefixes(
+	ctx context.Context,
+	prefixSubjects map[string]string,
+	docKey *key.Key,
+) bool {
+	for prefix, subject := range prefixSubjects {
+		if !strings.HasPrefix(docKey.BSONKey(), prefix) {
+			continue
+		}
+		if err := s.verifyWatchACL(ctx, subject, docKey); err == nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// verifyWatchACL verifies that the ACL of the document of the given key
+// allows the given subject to read it.
+func (s *Server) verifyWatchACL(
+	ctx context.Context,
+	subject string,
+	docKey *key.Key,
+) error {
+	docInfo, err := documents.Find(ctx, s.backend, docKey)
+	if err != nil {
+		if err == database.ErrDocumentNotFound {
+			return nil
+		}
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	if _, ok := docInfo.ACL.AccessOf(subject); !ok {
+		return status.Error(codes.PermissionDenied, errNotInACL.Error())
+	}
+
+	return nil
+}
+
 func (s *Server) listenAndServeGRPC() error {
 	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", s.conf.Port))
 	if err != nil {
-		log.Logger.Error(err)
+		logger.Error(err)
 		return err
 	}
 
 	go func() {
-		log.Logger.Infof("serving API on %d", s.conf.Port)
+		logger.Infof("serving API on %d", s.conf.Port)
 
 		if err := s.grpcServer.Serve(lis); err != nil {
-			log.Logger.Error(err)
+			logger.Error(err)
 		}
 	}()
 
 	return nil
 }
 
+// checkPermission combines the given access with the access the ACL of the
+// document grants to the given subject and checks whether the changes of the
+// given pack can be pushed with it.
+func checkPermission(
+	access auth.Access,
+	subject string,
+	docInfo *types.DocInfo,
+	pack *change.Pack,
+) (auth.Access, error) {
+	aclAccess, ok := docInfo.ACL.AccessOf(subject)
+	if !ok {
+		return "", status.Error(codes.PermissionDenied, errNotInACL.Error())
+	}
+
+	access = access.Min(aclAccess)
+	if !access.Permits(pack) {
+		return "", status.Error(codes.PermissionDenied, errChangesNotPermitted.Error())
+	}
+
+	return access, nil
+}
+
+// toPushPullStatusError converts the given error of a push-pull to a status
+// error.
+func toPushPullStatusError(err error) error {
+	if err == packs.ErrEncryptionMismatch {
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	if err == packs.ErrDocumentAlreadyExists {
+		return status.Error(codes.AlreadyExists, err.Error())
+	}
+
+	var lerr *packs.LimitError
+	if errors.As(err, &lerr) {
+		return toStatusError(codes.ResourceExhausted, lerr.Error(), []fieldViolation{{
+			field:       lerr.Limit,
+			description: fmt.Sprintf("%d above %d", lerr.Value, lerr.Max),
+		}})
+	}
+
+	var verr *validation.Error
+	if errors.As(err, &verr) {
+		var violations []fieldViolation
+		for _, violation := range verr.Violations {
+			violations = append(violations, fieldViolation{
+				field:       violation.Path,
+				description: violation.Description,
+			})
+		}
+		return toStatusError(codes.InvalidArgument, verr.Error(), violations)
+	}
+
+	return status.Error(codes.Internal, err.Error())
+}
+
+func toLabelsStatusError(err error) error {
+	if err == database.ErrDocumentNotFound {
+		return status.Error(codes.NotFound, err.Error())
+	}
+	if errors.Is(err, labels.ErrInvalidLabel) || errors.Is(err, labels.ErrTooManyLabels) {
+		return toStatusError(codes.InvalidArgument, "invalid labels", []fieldViolation{{
+			field:       "set",
+			description: err.Error(),
+		}})
+	}
+
+	return status.Error(codes.Internal, err.Error())
+}
+
 func toStatusError(code codes.Code, msg string, violations []fieldViolation) error {
 	br := &errdetails.BadRequest{}
 
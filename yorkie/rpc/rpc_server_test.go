@@ -2,15 +2,18 @@ package rpc_test
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"os"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 
-	"github.com/yorkie-team/yorkie/api"
+	api "github.com/yorkie-team/yorkie/api/yorkie/v1"
 	"github.com/yorkie-team/yorkie/testhelper"
 	"github.com/yorkie-team/yorkie/yorkie/backend"
 	"github.com/yorkie-team/yorkie/yorkie/backend/mongo"
@@ -22,7 +25,10 @@ const (
 	testRPCPort = testhelper.RPCPort + 1
 )
 
-var testRPCServer *rpc.Server
+var (
+	testBackend   *backend.Backend
+	testRPCServer *rpc.Server
+)
 
 func TestMain(m *testing.M) {
 	be, err := backend.New(&backend.Config{
@@ -36,6 +42,7 @@ func TestMain(m *testing.M) {
 	if err != nil {
 		log.Fatal(err)
 	}
+	testBackend = be
 
 	testRPCServer, err = rpc.NewServer(&rpc.Config{
 		Port: testRPCPort,
@@ -244,3 +251,81 @@ func TestRPCServerBackend(t *testing.T) {
 		assert.Equal(t, codes.FailedPrecondition, status.Convert(err).Code())
 	})
 }
+
+func TestInterceptors(t *testing.T) {
+	t.Run("custom interceptors test", func(t *testing.T) {
+		var calls []string
+		unary := func(name string) grpc.UnaryServerInterceptor {
+			return func(
+				ctx context.Context,
+				req interface{},
+				info *grpc.UnaryServerInfo,
+				handler grpc.UnaryHandler,
+			) (interface{}, error) {
+				calls = append(calls, name+" "+info.FullMethod)
+				return handler(ctx, req)
+			}
+		}
+		quota := func(
+			ctx context.Context,
+			req interface{},
+			info *grpc.UnaryServerInfo,
+			handler grpc.UnaryHandler,
+		) (interface{}, error) {
+			if req.(*api.ActivateClientRequest).ClientKey == "over-quota" {
+				return nil, status.Error(codes.ResourceExhausted, "quota exceeded")
+			}
+			return handler(ctx, req)
+		}
+
+		server, err := rpc.NewServer(&rpc.Config{
+			Port:              testRPCPort + 1,
+			UnaryInterceptors: []grpc.UnaryServerInterceptor{unary("first"), unary("second"), quota},
+		}, testBackend)
+		assert.NoError(t, err)
+		assert.NoError(t, server.Start())
+		defer server.Shutdown(true)
+
+		conn, err := grpc.Dial(fmt.Sprintf("localhost:%d", testRPCPort+1), grpc.WithInsecure())
+		assert.NoError(t, err)
+		defer func() {
+			assert.NoError(t, conn.Close())
+		}()
+		cli := api.NewYorkieClient(conn)
+
+		_, err = cli.ActivateClient(context.Background(), &api.ActivateClientRequest{ClientKey: t.Name()})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{
+			"first /yorkie.v1.Yorkie/ActivateClient",
+			"second /yorkie.v1.Yorkie/ActivateClient",
+		}, calls)
+
+		_, err = cli.ActivateClient(context.Background(), &api.ActivateClientRequest{ClientKey: "over-quota"})
+		assert.Equal(t, codes.ResourceExhausted, status.Convert(err).Code())
+	})
+}
+
+func TestAdminServer(t *testing.T) {
+	t.Run("admin token test", func(t *testing.T) {
+		_, err := testRPCServer.ListDocuments(context.Background(), &api.ListDocumentsRequest{})
+		assert.Equal(t, codes.PermissionDenied, status.Convert(err).Code())
+
+		server, err := rpc.NewServer(&rpc.Config{
+			Port:       testRPCPort + 2,
+			AdminToken: "admin-token",
+		}, testBackend)
+		assert.NoError(t, err)
+
+		_, err = server.ListDocuments(context.Background(), &api.ListDocumentsRequest{})
+		assert.Equal(t, codes.Unauthenticated, status.Convert(err).Code())
+
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "wrong-token"))
+		_, err = server.ListDocuments(ctx, &api.ListDocumentsRequest{})
+		assert.Equal(t, codes.PermissionDenied, status.Convert(err).Code())
+
+		// NOTE: a valid token passes the check and reaches request validation.
+		ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "admin-token"))
+		_, err = server.ListDocuments(ctx, &api.ListDocumentsRequest{})
+		assert.Equal(t, codes.InvalidArgument, status.Convert(err).Code())
+	})
+}
@@ -0,0 +1,116 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rpc
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/yorkie-team/yorkie/yorkie/backend/database"
+)
+
+// snapshotTTL is the time the snapshots omitted from change packs are kept
+// for the clients to fetch them.
+const snapshotTTL = time.Minute
+
+type storedSnapshot struct {
+	clientID  string
+	snapshot  []byte
+	expiresAt time.Time
+}
+
+// snapshotStore keeps the snapshots that are too large to be sent in change
+// packs until the clients fetch them with FetchSnapshot.
+//
+// The snapshots are staged in the database if it supports it, so that the
+// clients can fetch them from any of the agents sharing the database.
+// Otherwise, they are kept in the memory of the agent, and the clients should
+// fetch them from the agent that returned the change packs.
+type snapshotStore struct {
+	stager database.SnapshotStager
+
+	mu        sync.Mutex
+	snapshots map[string]*storedSnapshot
+}
+
+func newSnapshotStore(db database.Database) *snapshotStore {
+	stager, _ := db.(database.SnapshotStager)
+	return &snapshotStore{
+		stager:    stager,
+		snapshots: make(map[string]*storedSnapshot),
+	}
+}
+
+// put stores the given snapshot for the given client and returns its ID.
+func (s *snapshotStore) put(ctx context.Context, clientID string, snapshot []byte) (string, error) {
+	if s.stager != nil {
+		return s.stager.StageSnapshot(ctx, clientID, snapshot, time.Now().Add(snapshotTTL))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for id, stored := range s.snapshots {
+		if now.After(stored.expiresAt) {
+			delete(s.snapshots, id)
+		}
+	}
+
+	id := uuid.New().String()
+	s.snapshots[id] = &storedSnapshot{
+		clientID:  clientID,
+		snapshot:  snapshot,
+		expiresAt: now.Add(snapshotTTL),
+	}
+	return id, nil
+}
+
+// get returns the snapshot of the given ID stored for the given client. The
+// snapshot is kept until it is removed, so that the client can fetch it again
+// if the transfer fails.
+func (s *snapshotStore) get(ctx context.Context, clientID, id string) ([]byte, error) {
+	if s.stager != nil {
+		return s.stager.FindStagedSnapshot(ctx, clientID, id)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.snapshots[id]
+	if !ok || stored.clientID != clientID || time.Now().After(stored.expiresAt) {
+		return nil, database.ErrStagedSnapshotNotFound
+	}
+
+	return stored.snapshot, nil
+}
+
+// remove removes the snapshot of the given ID.
+func (s *snapshotStore) remove(ctx context.Context, id string) error {
+	if s.stager != nil {
+		return s.stager.DeleteStagedSnapshot(ctx, id)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.snapshots, id)
+	return nil
+}
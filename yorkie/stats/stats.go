@@ -0,0 +1,211 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package stats
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	meterWindowSec = 10
+	latencySamples = 1024
+)
+
+// Stats is a set of runtime statistics of the agent.
+type Stats struct {
+	// PushedOperations measures the rate of operations pushed by clients.
+	PushedOperations *Meter
+
+	// PushPullConflicts measures the rate of push-pulls conflicting with
+	// those of other agents.
+	PushPullConflicts *Meter
+
+	// ConcurrentSets measures the rate of values set concurrently with the
+	// values of other actors, counted when snapshots are created.
+	ConcurrentSets *Meter
+
+	// InterleavedTextEdits measures the rate of text edits interleaved with
+	// concurrent edits of other actors, counted when snapshots are created.
+	InterleavedTextEdits *Meter
+
+	// WatchStreams is the number of active watch streams.
+	WatchStreams *Gauge
+
+	// DeadLetters is the number of changes quarantined because they could
+	// not be applied when snapshots are created.
+	DeadLetters *Counter
+
+	// SoftLimitWarnings is the number of warnings of the documents going
+	// above the soft limits.
+	SoftLimitWarnings *Counter
+
+	// HardLimitRejections is the number of pushes rejected for the documents
+	// above the hard limits.
+	HardLimitRejections *Counter
+
+	// ChangeStreamErrors is the number of failures of the change stream,
+	// after each of which some changes of other agents may be notified late.
+	ChangeStreamErrors *Counter
+}
+
+// New creates a new instance of Stats.
+func New() *Stats {
+	return &Stats{
+		PushedOperations:     NewMeter(meterWindowSec),
+		PushPullConflicts:    NewMeter(meterWindowSec),
+		ConcurrentSets:       NewMeter(meterWindowSec),
+		InterleavedTextEdits: NewMeter(meterWindowSec),
+		WatchStreams:         &Gauge{},
+		DeadLetters:          &Counter{},
+		SoftLimitWarnings:    &Counter{},
+		HardLimitRejections:  &Counter{},
+		ChangeStreamErrors:   &Counter{},
+	}
+}
+
+// Gauge is a value that can go up and down.
+type Gauge struct {
+	value int64
+}
+
+// Inc increases the value of this gauge.
+func (g *Gauge) Inc() {
+	atomic.AddInt64(&g.value, 1)
+}
+
+// Dec decreases the value of this gauge.
+func (g *Gauge) Dec() {
+	atomic.AddInt64(&g.value, -1)
+}
+
+// Value returns the current value of this gauge.
+func (g *Gauge) Value() int64 {
+	return atomic.LoadInt64(&g.value)
+}
+
+// Counter is a value that only goes up.
+type Counter struct {
+	value int64
+}
+
+// Inc increases the value of this counter.
+func (c *Counter) Inc() {
+	atomic.AddInt64(&c.value, 1)
+}
+
+// Value returns the current value of this counter.
+func (c *Counter) Value() int64 {
+	return atomic.LoadInt64(&c.value)
+}
+
+// Meter measures the rate of events over a sliding window of seconds.
+type Meter struct {
+	mu      sync.Mutex
+	counts  []int64
+	seconds []int64
+}
+
+// NewMeter creates a new instance of Meter with the given window.
+func NewMeter(windowSec int) *Meter {
+	return &Meter{
+		counts:  make([]int64, windowSec),
+		seconds: make([]int64, windowSec),
+	}
+}
+
+// Mark records the given number of events.
+func (m *Meter) Mark(n int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now().Unix()
+	idx := now % int64(len(m.counts))
+	if m.seconds[idx] != now {
+		m.seconds[idx] = now
+		m.counts[idx] = 0
+	}
+	m.counts[idx] += n
+}
+
+// Rate returns the average number of events per second in the window.
+func (m *Meter) Rate() float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now().Unix()
+	window := int64(len(m.counts))
+
+	var total int64
+	for i, second := range m.seconds {
+		if now-second < window {
+			total += m.counts[i]
+		}
+	}
+
+	return float64(total) / float64(window)
+}
+
+// Latency keeps the recent durations and reports percentiles of them.
+type Latency struct {
+	mu      sync.Mutex
+	samples []time.Duration
+	next    int
+}
+
+// NewLatency creates a new instance of Latency.
+func NewLatency() *Latency {
+	return &Latency{
+		samples: make([]time.Duration, 0, latencySamples),
+	}
+}
+
+// Record records the given duration.
+func (l *Latency) Record(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.samples) < cap(l.samples) {
+		l.samples = append(l.samples, d)
+		return
+	}
+
+	l.samples[l.next] = d
+	l.next = (l.next + 1) % len(l.samples)
+}
+
+// Percentile returns the duration at the given percentile(0~100) of the
+// recorded durations.
+func (l *Latency) Percentile(p float64) time.Duration {
+	l.mu.Lock()
+	sorted := make([]time.Duration, len(l.samples))
+	copy(sorted, l.samples)
+	l.mu.Unlock()
+
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i] < sorted[j]
+	})
+
+	idx := int(float64(len(sorted)-1) * p / 100)
+	return sorted[idx]
+}
@@ -0,0 +1,63 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package stats_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yorkie-team/yorkie/yorkie/stats"
+)
+
+func TestStats(t *testing.T) {
+	t.Run("gauge test", func(t *testing.T) {
+		g := &stats.Gauge{}
+		g.Inc()
+		g.Inc()
+		g.Dec()
+		assert.Equal(t, int64(1), g.Value())
+	})
+
+	t.Run("counter test", func(t *testing.T) {
+		c := &stats.Counter{}
+		c.Inc()
+		c.Inc()
+		assert.Equal(t, int64(2), c.Value())
+	})
+
+	t.Run("meter test", func(t *testing.T) {
+		m := stats.NewMeter(10)
+		assert.Equal(t, float64(0), m.Rate())
+
+		m.Mark(50)
+		m.Mark(50)
+		assert.Equal(t, float64(10), m.Rate())
+	})
+
+	t.Run("latency test", func(t *testing.T) {
+		l := stats.NewLatency()
+		assert.Equal(t, time.Duration(0), l.Percentile(50))
+
+		for i := 1; i <= 100; i++ {
+			l.Record(time.Duration(i) * time.Millisecond)
+		}
+		assert.Equal(t, 50*time.Millisecond, l.Percentile(50))
+		assert.Equal(t, 100*time.Millisecond, l.Percentile(100))
+	})
+}
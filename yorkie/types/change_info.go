@@ -19,14 +19,16 @@ package types
 import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 
-	"github.com/yorkie-team/yorkie/api"
 	"github.com/yorkie-team/yorkie/api/converter"
+	api "github.com/yorkie-team/yorkie/api/yorkie/v1"
 	"github.com/yorkie-team/yorkie/pkg/document/change"
 	"github.com/yorkie-team/yorkie/pkg/document/operation"
 	"github.com/yorkie-team/yorkie/pkg/document/time"
 	"github.com/yorkie-team/yorkie/pkg/log"
 )
 
+var logger = log.Named(log.Agent)
+
 type ChangeInfo struct {
 	DocID      primitive.ObjectID `bson:"doc_id"`
 	ServerSeq  uint64             `bson:"server_seq"`
@@ -35,6 +37,30 @@ type ChangeInfo struct {
 	Actor      primitive.ObjectID `bson:"actor"`
 	Message    string             `bson:"message"`
 	Operations [][]byte           `bson:"operations"`
+	UserID     string             `bson:"user_id,omitempty"`
+	UserName   string             `bson:"user_name,omitempty"`
+	Metadata   map[string]string  `bson:"metadata,omitempty"`
+}
+
+// NewChangeInfo creates a new ChangeInfo of the given change of the given
+// document.
+func NewChangeInfo(docID primitive.ObjectID, c *change.Change) *ChangeInfo {
+	info := &ChangeInfo{
+		DocID:      docID,
+		ServerSeq:  c.ServerSeq(),
+		ClientSeq:  c.ID().ClientSeq(),
+		Lamport:    c.ID().Lamport(),
+		Actor:      EncodeActorID(c.ID().Actor()),
+		Message:    c.Message(),
+		Operations: EncodeOperation(c.Operations()),
+		Metadata:   c.Metadata(),
+	}
+	if user := c.User(); user != nil {
+		info.UserID = user.ID
+		info.UserName = user.Name
+	}
+
+	return info
 }
 
 func EncodeOperation(operations []operation.Operation) [][]byte {
@@ -66,14 +92,26 @@ func (i *ChangeInfo) ToChange() (*change.Change, error) {
 	for _, bytesOp := range i.Operations {
 		pbOp := api.Operation{}
 		if err := pbOp.Unmarshal(bytesOp); err != nil {
-			log.Logger.Error(err)
+			logger.Error(err)
 			return nil, err
 		}
 		pbOps = append(pbOps, &pbOp)
 	}
 
-	c := change.New(changeID, i.Message, converter.FromOperations(pbOps))
+	ops, err := converter.FromOperations(pbOps)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	c := change.New(changeID, i.Message, ops)
 	c.SetServerSeq(i.ServerSeq)
+	if i.UserID != "" || i.UserName != "" {
+		c.SetUser(&change.User{ID: i.UserID, Name: i.UserName})
+	}
+	if len(i.Metadata) > 0 {
+		c.SetMetadata(i.Metadata)
+	}
 
 	return c, nil
 }
@@ -17,12 +17,18 @@
 package types
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
 	"errors"
+	"sort"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 
 	"github.com/yorkie-team/yorkie/pkg/document/checkpoint"
+	"github.com/yorkie-team/yorkie/yorkie/auth"
 )
 
 var (
@@ -32,6 +38,9 @@ var (
 	ErrDocumentAlreadyAttached = errors.New("document already attached")
 )
 
+// sessionTokenLen is the length of the tokens of sessions in bytes.
+const sessionTokenLen = 32
+
 const (
 	ClientDeactivated = "deactivated"
 	ClientActivated   = "activated"
@@ -43,9 +52,14 @@ const (
 )
 
 type ClientDocInfo struct {
-	Status    string `bson:"status"`
-	ServerSeq uint64 `bson:"server_seq"`
-	ClientSeq uint32 `bson:"client_seq"`
+	Status    string      `bson:"status"`
+	ServerSeq uint64      `bson:"server_seq"`
+	ClientSeq uint32      `bson:"client_seq"`
+	Access    auth.Access `bson:"access"`
+
+	// Subject is the subject of the access token that the document was
+	// attached with. The ACL of the document is checked against it.
+	Subject string `bson:"subject,omitempty"`
 }
 
 type ClientInfo struct {
@@ -55,9 +69,23 @@ type ClientInfo struct {
 	Documents map[string]*ClientDocInfo `bson:"documents"`
 	CreatedAt time.Time                 `bson:"created_at"`
 	UpdatedAt time.Time                 `bson:"updated_at"`
+
+	// SessionToken is the hash of the token of the current session, which
+	// resumes the session when the client is activated again.
+	SessionToken string `bson:"session_token,omitempty"`
+}
+
+func (i *ClientInfo) AttachDocument(docID primitive.ObjectID, access auth.Access) error {
+	return i.AttachDocumentAs(docID, access, "")
 }
 
-func (i *ClientInfo) AttachDocument(docID primitive.ObjectID) error {
+// AttachDocumentAs attaches the given document on behalf of the given subject
+// of an access token.
+func (i *ClientInfo) AttachDocumentAs(
+	docID primitive.ObjectID,
+	access auth.Access,
+	subject string,
+) error {
 	if i.Status != ClientActivated {
 		return ErrClientNotActivated
 	}
@@ -68,7 +96,9 @@ func (i *ClientInfo) AttachDocument(docID primitive.ObjectID) error {
 
 	hexDocID := docID.Hex()
 
-	if i.hasDocument(hexDocID) {
+	// NOTE: Documents detached before can be attached again, starting over
+	// from the initial checkpoint.
+	if i.hasDocument(hexDocID) && i.Documents[hexDocID].Status == DocumentAttached {
 		return ErrDocumentAlreadyAttached
 	}
 
@@ -76,6 +106,8 @@ func (i *ClientInfo) AttachDocument(docID primitive.ObjectID) error {
 		Status:    DocumentAttached,
 		ServerSeq: 0,
 		ClientSeq: 0,
+		Access:    access,
+		Subject:   subject,
 	}
 	i.UpdatedAt = time.Now()
 
@@ -94,7 +126,7 @@ func (i *ClientInfo) DetachDocument(docID primitive.ObjectID) error {
 	return nil
 }
 
-func (i *ClientInfo) GetCheckpoint(id primitive.ObjectID) *checkpoint.Checkpoint {
+func (i *ClientInfo) GetCheckpoint(id primitive.ObjectID) checkpoint.Checkpoint {
 	clientDocInfo := i.Documents[id.Hex()]
 	if clientDocInfo == nil {
 		return checkpoint.Initial
@@ -103,7 +135,7 @@ func (i *ClientInfo) GetCheckpoint(id primitive.ObjectID) *checkpoint.Checkpoint
 	return checkpoint.New(clientDocInfo.ServerSeq, clientDocInfo.ClientSeq)
 }
 
-func (i *ClientInfo) UpdateCheckpoint(docID primitive.ObjectID, cp *checkpoint.Checkpoint) error {
+func (i *ClientInfo) UpdateCheckpoint(docID primitive.ObjectID, cp checkpoint.Checkpoint) error {
 	hexDocID := docID.Hex()
 	if !i.hasDocument(hexDocID) {
 		return ErrDocumentNeverAttached
@@ -116,6 +148,26 @@ func (i *ClientInfo) UpdateCheckpoint(docID primitive.ObjectID, cp *checkpoint.C
 	return nil
 }
 
+// DocumentAccess returns the access level granted to the client for the
+// given document. Documents attached without an access level are writable.
+func (i *ClientInfo) DocumentAccess(hexDocID string) auth.Access {
+	if !i.hasDocument(hexDocID) || i.Documents[hexDocID].Access == "" {
+		return auth.ReadWrite
+	}
+
+	return i.Documents[hexDocID].Access
+}
+
+// DocumentSubject returns the subject that the given document was attached
+// on behalf of.
+func (i *ClientInfo) DocumentSubject(hexDocID string) string {
+	if !i.hasDocument(hexDocID) {
+		return ""
+	}
+
+	return i.Documents[hexDocID].Subject
+}
+
 func (i *ClientInfo) CheckDocumentAttached(hexDocID string) error {
 	if i.Status != ClientActivated {
 		return ErrClientNotActivated
@@ -128,6 +180,50 @@ func (i *ClientInfo) CheckDocumentAttached(hexDocID string) error {
 	return nil
 }
 
+// NewSessionToken returns a new random token of a session.
+func NewSessionToken() (string, error) {
+	token := make([]byte, sessionTokenLen)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(token), nil
+}
+
+// SetSessionToken sets the given token of the current session. Only the hash
+// of the token is kept, so that the token can't be read from the database.
+func (i *ClientInfo) SetSessionToken(token string) {
+	i.SessionToken = hashSessionToken(token)
+}
+
+// VerifySessionToken returns whether the given token is the token of the
+// current session.
+func (i *ClientInfo) VerifySessionToken(token string) bool {
+	if i.SessionToken == "" || token == "" {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(i.SessionToken), []byte(hashSessionToken(token))) == 1
+}
+
+// AttachedDocuments returns the IDs of the documents attached to the client.
+func (i *ClientInfo) AttachedDocuments() []string {
+	var hexDocIDs []string
+	for hexDocID, docInfo := range i.Documents {
+		if docInfo.Status == DocumentAttached {
+			hexDocIDs = append(hexDocIDs, hexDocID)
+		}
+	}
+	sort.Strings(hexDocIDs)
+
+	return hexDocIDs
+}
+
+func hashSessionToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
 func (i *ClientInfo) hasDocument(hexDocID string) bool {
 	return i.Documents != nil && i.Documents[hexDocID] != nil
 }
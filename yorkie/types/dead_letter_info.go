@@ -0,0 +1,36 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package types
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DeadLetterInfo is a change quarantined because it could not be applied to
+// its document, e.g. for corruption or version skew. It keeps a copy of the
+// change with the cause, so that the change can be inspected after the
+// changes are pruned.
+type DeadLetterInfo struct {
+	ID        primitive.ObjectID `bson:"_id"`
+	DocID     primitive.ObjectID `bson:"doc_id"`
+	ServerSeq uint64             `bson:"server_seq"`
+	Change    *ChangeInfo        `bson:"change"`
+	Cause     string             `bson:"cause"`
+	CreatedAt time.Time          `bson:"created_at"`
+}
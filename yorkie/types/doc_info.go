@@ -22,17 +22,39 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 
 	"github.com/yorkie-team/yorkie/pkg/document/key"
+	"github.com/yorkie-team/yorkie/yorkie/auth"
+	"github.com/yorkie-team/yorkie/yorkie/labels"
 )
 
 // DocInfo is a structure representing information of the document.
+// Changes up to PrunedServerSeq have been pruned, so clients behind it can
+// only be synchronized with a snapshot. The payloads of the changes of an
+// Encrypted document are opaque to the agent, so it has no snapshots. A
+// document forked from another one has the Branch information. Labels are
+// the key-value pairs attached by applications to organize documents.
 type DocInfo struct {
-	ID         primitive.ObjectID `bson:"_id"`
-	Key        string             `bson:"key"`
-	ServerSeq  uint64             `bson:"server_seq"`
-	Owner      primitive.ObjectID `bson:"owner"`
-	CreatedAt  time.Time          `bson:"created_at"`
-	AccessedAt time.Time          `bson:"accessed_at"`
-	UpdatedAt  time.Time          `bson:"updated_at"`
+	ID              primitive.ObjectID `bson:"_id"`
+	Key             string             `bson:"key"`
+	ServerSeq       uint64             `bson:"server_seq"`
+	PrunedServerSeq uint64             `bson:"pruned_server_seq"`
+	Encrypted       bool               `bson:"encrypted"`
+	Owner           primitive.ObjectID `bson:"owner"`
+	ACL             *auth.ACL          `bson:"acl"`
+	CreatedAt       time.Time          `bson:"created_at"`
+	AccessedAt      time.Time          `bson:"accessed_at"`
+	UpdatedAt       time.Time          `bson:"updated_at"`
+	Branch          *BranchInfo        `bson:"branch,omitempty"`
+	Labels          labels.Labels      `bson:"labels,omitempty"`
+}
+
+// BranchInfo is the information of a document forked from another one, the
+// parent. The branch shares the history of the parent up to ForkServerSeq,
+// and its server sequences continue from there. The changes of the branch up
+// to MergedServerSeq have been merged into the parent.
+type BranchInfo struct {
+	ParentKey       string `bson:"parent_key"`
+	ForkServerSeq   uint64 `bson:"fork_server_seq"`
+	MergedServerSeq uint64 `bson:"merged_server_seq"`
 }
 
 // IncreaseServerSeq increases server sequence of the document.
@@ -0,0 +1,32 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package types
+
+import (
+	"time"
+)
+
+// PeerInfo is a peer of a document registered by an agent, which is shared
+// with the other agents sharing the database.
+type PeerInfo struct {
+	AgentID  string            `bson:"agent_id"`
+	DocKey   string            `bson:"doc_key"`
+	ClientID string            `bson:"client_id"`
+	Presence map[string]string `bson:"presence"`
+	Watching bool              `bson:"watching"`
+	LastSeen time.Time         `bson:"last_seen"`
+}
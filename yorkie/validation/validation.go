@@ -0,0 +1,157 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package validation provides the stage of the push path where the changes
+// pushed by clients are inspected before they are stored. Operators register
+// validators, in Go or as a webhook, which reject the pack of the changes with
+// the violations returned to the client, e.g. to enforce size limits,
+// forbidden paths or profanity filters.
+package validation
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/yorkie-team/yorkie/api/converter"
+	api "github.com/yorkie-team/yorkie/api/yorkie/v1"
+	"github.com/yorkie-team/yorkie/pkg/document/change"
+	"github.com/yorkie-team/yorkie/pkg/document/json"
+	"github.com/yorkie-team/yorkie/pkg/document/key"
+)
+
+// Validator inspects the changes pushed to a document. It returns an Error to
+// reject them, or another error if it fails to validate them.
+type Validator interface {
+	Validate(ctx context.Context, req *Request) error
+}
+
+// ValidatorFunc is an adapter to use ordinary functions as validators.
+type ValidatorFunc func(ctx context.Context, req *Request) error
+
+// Validate calls f(ctx, req).
+func (f ValidatorFunc) Validate(ctx context.Context, req *Request) error {
+	return f(ctx, req)
+}
+
+// Request is the changes pushed by a client to a document.
+type Request struct {
+	DocumentKey *key.Key
+	ClientID    string
+
+	// Changes are the changes being pushed. The operations of the changes of
+	// Encrypted documents are opaque to the agent, so they are empty.
+	Changes   []*change.Change
+	Encrypted bool
+
+	loadRoot func() (*json.Object, error)
+	rootOnce sync.Once
+	root     *json.Object
+	rootErr  error
+}
+
+// NewRequest creates a new instance of Request. The given function loads the
+// root of the document with the changes applied, and it is called only if a
+// validator needs the root.
+func NewRequest(
+	docKey *key.Key,
+	clientID string,
+	changes []*change.Change,
+	encrypted bool,
+	loadRoot func() (*json.Object, error),
+) *Request {
+	return &Request{
+		DocumentKey: docKey,
+		ClientID:    clientID,
+		Changes:     changes,
+		Encrypted:   encrypted,
+		loadRoot:    loadRoot,
+	}
+}
+
+// Root returns the root of the document with the changes applied. It is
+// loaded from the database on the first call, so validators that don't need
+// it should not call it.
+func (r *Request) Root() (*json.Object, error) {
+	r.rootOnce.Do(func() {
+		r.root, r.rootErr = r.loadRoot()
+	})
+	return r.root, r.rootErr
+}
+
+// OperationSummaries returns the operations of the changes in a
+// human-readable form, with the paths of the elements they target.
+func (r *Request) OperationSummaries() ([]*api.OperationSummary, error) {
+	root, err := r.Root()
+	if err != nil {
+		return nil, err
+	}
+
+	var summaries []*api.OperationSummary
+	for _, c := range r.Changes {
+		summaries = append(summaries, converter.ToOperationSummaries(root, c)...)
+	}
+	return summaries, nil
+}
+
+// Violation is a reason the changes are rejected for.
+type Violation struct {
+	// Path is the path of the element violating the rule, e.g. $.title. It
+	// is empty if the violation is not about an element.
+	Path        string `json:"path"`
+	Description string `json:"description"`
+}
+
+// Error is returned by validators to reject the changes.
+type Error struct {
+	Validator  string
+	Violations []Violation
+}
+
+// Reject returns an Error of the given validator with the given violations.
+func Reject(validator string, violations ...Violation) *Error {
+	return &Error{
+		Validator:  validator,
+		Violations: violations,
+	}
+}
+
+// Error returns the description of the violations.
+func (e *Error) Error() string {
+	var descriptions []string
+	for _, violation := range e.Violations {
+		if violation.Path == "" {
+			descriptions = append(descriptions, violation.Description)
+			continue
+		}
+		descriptions = append(descriptions, fmt.Sprintf("%s: %s", violation.Path, violation.Description))
+	}
+
+	return fmt.Sprintf("changes rejected by %s: %s", e.Validator, strings.Join(descriptions, ", "))
+}
+
+// Validate validates the given request with the given validators in order,
+// and returns the error of the first validator rejecting it.
+func Validate(ctx context.Context, validators []Validator, req *Request) error {
+	for _, validator := range validators {
+		if err := validator.Validate(ctx, req); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
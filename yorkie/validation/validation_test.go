@@ -0,0 +1,120 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package validation_test
+
+import (
+	"context"
+	gojson "encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yorkie-team/yorkie/pkg/document"
+	"github.com/yorkie-team/yorkie/pkg/document/json"
+	"github.com/yorkie-team/yorkie/pkg/document/proxy"
+	"github.com/yorkie-team/yorkie/yorkie/validation"
+)
+
+func TestValidation(t *testing.T) {
+	ctx := context.Background()
+
+	newRequest := func(t *testing.T) *validation.Request {
+		doc := document.New("c", "d")
+		assert.NoError(t, doc.Update(func(root *proxy.ObjectProxy) error {
+			root.SetString("title", "hello")
+			return nil
+		}, "set title"))
+		pack := doc.CreateChangePack()
+
+		return validation.NewRequest(pack.DocumentKey, "client", pack.Changes, false, func() (*json.Object, error) {
+			return doc.RootObject(), nil
+		})
+	}
+
+	t.Run("validators in order test", func(t *testing.T) {
+		var called []string
+		validators := []validation.Validator{
+			validation.ValidatorFunc(func(ctx context.Context, req *validation.Request) error {
+				called = append(called, "first")
+				return validation.Reject("first", validation.Violation{Path: "$.title", Description: "too short"})
+			}),
+			validation.ValidatorFunc(func(ctx context.Context, req *validation.Request) error {
+				called = append(called, "second")
+				return nil
+			}),
+		}
+
+		err := validation.Validate(ctx, validators, newRequest(t))
+		var verr *validation.Error
+		assert.True(t, errors.As(err, &verr))
+		assert.Equal(t, "first", verr.Validator)
+		assert.Equal(t, "changes rejected by first: $.title: too short", err.Error())
+		assert.Equal(t, []string{"first"}, called)
+	})
+
+	t.Run("operation summaries test", func(t *testing.T) {
+		summaries, err := newRequest(t).OperationSummaries()
+		assert.NoError(t, err)
+		assert.Len(t, summaries, 1)
+		assert.Equal(t, "$.title", summaries[0].Path)
+	})
+
+	t.Run("webhook test", func(t *testing.T) {
+		var received struct {
+			DocumentKey string `json:"document_key"`
+			Changes     []struct {
+				Message string `json:"message"`
+			} `json:"changes"`
+		}
+		allowed := true
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.NoError(t, gojson.NewDecoder(r.Body).Decode(&received))
+			if allowed {
+				_, _ = w.Write([]byte(`{"allowed": true}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"allowed": false, "violations": [{"path": "$.title", "description": "profanity"}]}`))
+		}))
+		defer server.Close()
+
+		webhook := validation.NewWebhook(&validation.WebhookConfig{URL: server.URL})
+		assert.NoError(t, webhook.Validate(ctx, newRequest(t)))
+		assert.Equal(t, "c$d", received.DocumentKey)
+		assert.Equal(t, "set title", received.Changes[0].Message)
+
+		allowed = false
+		err := webhook.Validate(ctx, newRequest(t))
+		var verr *validation.Error
+		assert.True(t, errors.As(err, &verr))
+		assert.Equal(t, []validation.Violation{{Path: "$.title", Description: "profanity"}}, verr.Violations)
+	})
+
+	t.Run("webhook failure test", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		webhook := validation.NewWebhook(&validation.WebhookConfig{URL: server.URL})
+		err := webhook.Validate(ctx, newRequest(t))
+		assert.False(t, errors.As(err, new(*validation.Error)))
+		assert.True(t, errors.Is(err, validation.ErrWebhookFailed))
+	})
+}
@@ -0,0 +1,148 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package validation
+
+import (
+	"bytes"
+	"context"
+	gojson "encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/yorkie-team/yorkie/api/converter"
+	api "github.com/yorkie-team/yorkie/api/yorkie/v1"
+	"github.com/yorkie-team/yorkie/pkg/document/json"
+)
+
+// DefaultWebhookTimeout is the default timeout of the requests to webhooks.
+const DefaultWebhookTimeout = 3 * time.Second
+
+// ErrWebhookFailed is returned when the webhook doesn't respond with a
+// decision.
+var ErrWebhookFailed = errors.New("validation webhook failed")
+
+// WebhookConfig is the configuration of a webhook validating the changes.
+type WebhookConfig struct {
+	// URL is the URL of the webhook. The changes are posted to it as JSON.
+	URL string `json:"URL"`
+
+	// TimeoutSec is the timeout of the requests to the webhook.
+	// DefaultWebhookTimeout is used if it is zero.
+	TimeoutSec time.Duration `json:"TimeoutSec"`
+}
+
+// webhookRequest is the body posted to webhooks.
+type webhookRequest struct {
+	DocumentKey string          `json:"document_key"`
+	ClientID    string          `json:"client_id"`
+	Encrypted   bool            `json:"encrypted"`
+	Changes     []webhookChange `json:"changes"`
+}
+
+type webhookChange struct {
+	Message    string                  `json:"message"`
+	Metadata   map[string]string       `json:"metadata,omitempty"`
+	Operations []*api.OperationSummary `json:"operations"`
+}
+
+// webhookResponse is the body webhooks respond with.
+type webhookResponse struct {
+	Allowed    bool        `json:"allowed"`
+	Violations []Violation `json:"violations"`
+}
+
+// Webhook is a validator which asks a webhook whether to accept the changes.
+type Webhook struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhook creates a new instance of Webhook of the given configuration.
+func NewWebhook(conf *WebhookConfig) *Webhook {
+	timeout := conf.TimeoutSec * time.Second
+	if timeout == 0 {
+		timeout = DefaultWebhookTimeout
+	}
+
+	return &Webhook{
+		url:    conf.URL,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// Validate posts the changes of the given request to the webhook and returns
+// an Error if the webhook doesn't allow them.
+func (w *Webhook) Validate(ctx context.Context, req *Request) error {
+	body := &webhookRequest{
+		DocumentKey: req.DocumentKey.BSONKey(),
+		ClientID:    req.ClientID,
+		Encrypted:   req.Encrypted,
+	}
+
+	// NOTE: The root is needed to describe the elements by their paths.
+	var root *json.Object
+	if !req.Encrypted {
+		var err error
+		if root, err = req.Root(); err != nil {
+			return err
+		}
+	}
+	for _, c := range req.Changes {
+		body.Changes = append(body.Changes, webhookChange{
+			Message:    c.Message(),
+			Metadata:   c.Metadata(),
+			Operations: converter.ToOperationSummaries(root, c),
+		})
+	}
+
+	payload, err := gojson.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	httpReq, err := http.NewRequest(http.MethodPost, w.url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	httpReq = httpReq.WithContext(ctx)
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	httpResp, err := w.client.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("%s: %w", err.Error(), ErrWebhookFailed)
+	}
+	defer func() {
+		_ = httpResp.Body.Close()
+	}()
+
+	if httpResp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status %d: %w", httpResp.StatusCode, ErrWebhookFailed)
+	}
+
+	resp := &webhookResponse{}
+	if err := gojson.NewDecoder(httpResp.Body).Decode(resp); err != nil {
+		return fmt.Errorf("%s: %w", err.Error(), ErrWebhookFailed)
+	}
+
+	if !resp.Allowed {
+		return Reject(w.url, resp.Violations...)
+	}
+
+	return nil
+}
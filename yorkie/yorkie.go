@@ -19,7 +19,10 @@ package yorkie
 import (
 	"sync"
 
+	"github.com/yorkie-team/yorkie/pkg/log"
 	"github.com/yorkie-team/yorkie/yorkie/backend"
+	"github.com/yorkie-team/yorkie/yorkie/backup"
+	"github.com/yorkie-team/yorkie/yorkie/housekeeping"
 	"github.com/yorkie-team/yorkie/yorkie/rpc"
 )
 
@@ -32,6 +35,9 @@ type Yorkie struct {
 	conf      *Config
 	backend   *backend.Backend
 	rpcServer *rpc.Server
+	backup    *backup.Manager
+
+	housekeeping *housekeeping.Housekeeping
 
 	shutdown   bool
 	shutdownCh chan struct{}
@@ -39,6 +45,12 @@ type Yorkie struct {
 
 // New creates a new instance of Yorkie.
 func New(conf *Config) (*Yorkie, error) {
+	if conf.Log != nil {
+		if err := log.Configure(conf.Log); err != nil {
+			return nil, err
+		}
+	}
+
 	be, err := backend.New(conf.Backend, conf.Mongo)
 	if err != nil {
 		return nil, err
@@ -49,11 +61,31 @@ func New(conf *Config) (*Yorkie, error) {
 		return nil, err
 	}
 
+	var backupManager *backup.Manager
+	if conf.Backup != nil && conf.Backup.Dir != "" {
+		store, err := backup.NewDirStore(conf.Backup.Dir)
+		if err != nil {
+			return nil, err
+		}
+		backupManager = backup.NewManager(conf.Backup, be, store)
+	}
+
+	var hk *housekeeping.Housekeeping
+	if conf.Housekeeping != nil && conf.Housekeeping.ArchiveDir != "" {
+		store, err := backup.NewDirStore(conf.Housekeeping.ArchiveDir)
+		if err != nil {
+			return nil, err
+		}
+		hk = housekeeping.New(conf.Housekeeping, be, store)
+	}
+
 	return &Yorkie{
-		conf:       conf,
-		backend:    be,
-		rpcServer:  rpcServer,
-		shutdownCh: make(chan struct{}),
+		conf:         conf,
+		backend:      be,
+		rpcServer:    rpcServer,
+		backup:       backupManager,
+		housekeeping: hk,
+		shutdownCh:   make(chan struct{}),
 	}, nil
 }
 
@@ -61,7 +93,19 @@ func (r *Yorkie) Start() error {
 	r.lock.Lock()
 	defer r.lock.Unlock()
 
-	return r.rpcServer.Start()
+	if err := r.rpcServer.Start(); err != nil {
+		return err
+	}
+
+	if r.backup != nil {
+		r.backup.Start()
+	}
+
+	if r.housekeeping != nil {
+		r.housekeeping.Start()
+	}
+
+	return nil
 }
 
 func (r *Yorkie) Shutdown(graceful bool) error {
@@ -71,6 +115,14 @@ func (r *Yorkie) Shutdown(graceful bool) error {
 		return nil
 	}
 
+	if r.backup != nil {
+		r.backup.Stop()
+	}
+
+	if r.housekeeping != nil {
+		r.housekeeping.Stop()
+	}
+
 	if err := r.backend.Close(); err != nil {
 		return err
 	}